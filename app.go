@@ -124,6 +124,173 @@ func (a *App) UpdateBatchStatuses() error {
 	return a.services.BatchService.UpdateBatchStatuses()
 }
 
+// ProsesBatchKadaluarsa runs the expired batch job now
+func (a *App) ProsesBatchKadaluarsa() (*models.HasilProsesKadaluarsa, error) {
+	log.Println("Processing expired batches")
+	return a.services.BatchService.ProsesKadaluarsa()
+}
+
+// TarikBatch recalls a batch and writes off its remaining stock
+func (a *App) TarikBatch(req *models.TarikBatchRequest) (*models.PenarikanBatch, error) {
+	log.Printf("Recalling batch: %s", req.BatchID)
+	return a.services.BatchService.TarikBatch(req)
+}
+
+// GetPenarikanBatch lists the sales and customers that received stock from a batch
+func (a *App) GetPenarikanBatch(batchID string) (*models.PenarikanBatch, error) {
+	return a.services.BatchService.GetPenarikanBatch(batchID)
+}
+
+// ExportPenarikanBatch returns the recall list of a batch as CSV
+func (a *App) ExportPenarikanBatch(batchID string) (string, error) {
+	data, _, err := a.services.BatchService.ExportPenarikanBatch(batchID)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ==================== SUPPLIER API ====================
+
+// GetAllSupplier retrieves all suppliers, including inactive ones
+func (a *App) GetAllSupplier() ([]*models.Supplier, error) {
+	return a.services.SupplierService.GetAllSupplier()
+}
+
+// GetSupplierByID retrieves a supplier by ID
+func (a *App) GetSupplierByID(id int) (*models.Supplier, error) {
+	return a.services.SupplierService.GetSupplierByID(id)
+}
+
+// CreateSupplier adds a supplier
+func (a *App) CreateSupplier(supplier models.Supplier) (*models.Supplier, error) {
+	log.Printf("Creating supplier: %s", supplier.Nama)
+	if err := a.services.SupplierService.CreateSupplier(&supplier); err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// UpdateSupplier updates a supplier
+func (a *App) UpdateSupplier(supplier models.Supplier) (*models.Supplier, error) {
+	log.Printf("Updating supplier ID: %d", supplier.ID)
+	if err := a.services.SupplierService.UpdateSupplier(&supplier); err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// GabungSupplier merges a duplicate supplier into the supplier that is kept
+func (a *App) GabungSupplier(req models.GabungSupplierRequest) (*models.Supplier, error) {
+	log.Printf("Merging supplier ID: %d into supplier ID: %d", req.DuplikatID, req.TujuanID)
+	return a.services.SupplierService.GabungSupplier(&req)
+}
+
+// DeleteSupplier removes a supplier that has no batches
+func (a *App) DeleteSupplier(id int) error {
+	log.Printf("Deleting supplier ID: %d", id)
+	return a.services.SupplierService.DeleteSupplier(id)
+}
+
+// GetLaporanSupplier summarizes the stock received, expired and written off per supplier
+func (a *App) GetLaporanSupplier(startDate, endDate string) ([]*models.LaporanSupplier, error) {
+	log.Printf("Getting supplier report from %s to %s", startDate, endDate)
+
+	start, err := a.parseDate(startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+
+	end, err := a.parseDate(endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	return a.services.SupplierService.GetLaporanSupplier(start, end)
+}
+
+// ==================== PURCHASE ORDER API ====================
+
+// GetAllPurchaseOrder retrieves purchase orders, optionally filtered by status
+func (a *App) GetAllPurchaseOrder(status string) ([]*models.PurchaseOrder, error) {
+	return a.services.POService.GetAllPurchaseOrder(status)
+}
+
+// GetPurchaseOrderByID retrieves a purchase order with its lines and goods receipts
+func (a *App) GetPurchaseOrderByID(id int) (*models.PurchaseOrder, error) {
+	return a.services.POService.GetPurchaseOrderByID(id)
+}
+
+// SimpanPurchaseOrder creates a draft purchase order or replaces the lines of a draft
+func (a *App) SimpanPurchaseOrder(req models.SimpanPurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	log.Printf("Saving purchase order ID: %d for supplier ID: %d", req.ID, req.SupplierID)
+	return a.services.POService.SimpanPurchaseOrder(&req)
+}
+
+// KirimPurchaseOrder marks a draft purchase order as sent to the supplier
+func (a *App) KirimPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	log.Printf("Sending purchase order ID: %d", id)
+	return a.services.POService.KirimPurchaseOrder(id)
+}
+
+// TerimaBarang records goods received for a purchase order
+func (a *App) TerimaBarang(req models.TerimaBarangRequest) (*models.PenerimaanBarang, error) {
+	log.Printf("Receiving goods for purchase order ID: %d", req.POID)
+	return a.services.POService.TerimaBarang(&req)
+}
+
+// TutupPurchaseOrder closes a purchase order that will not be received further
+func (a *App) TutupPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	log.Printf("Closing purchase order ID: %d", id)
+	return a.services.POService.TutupPurchaseOrder(id)
+}
+
+// GetSaranPembelian proposes order quantities per supplier for products at their reorder point
+func (a *App) GetSaranPembelian(req models.SaranPembelianRequest) ([]*models.SaranPembelianSupplier, error) {
+	return a.services.POService.GetSaranPembelian(&req)
+}
+
+// DeletePurchaseOrder removes a draft purchase order
+func (a *App) DeletePurchaseOrder(id int) error {
+	log.Printf("Deleting purchase order ID: %d", id)
+	return a.services.POService.DeletePurchaseOrder(id)
+}
+
+// ==================== STOK OPNAME API ====================
+
+// GetAllStokOpname retrieves stock opname sessions, optionally filtered by status
+func (a *App) GetAllStokOpname(status string) ([]*models.StokOpname, error) {
+	return a.services.OpnameService.GetAllOpname(status)
+}
+
+// GetStokOpnameByID retrieves a stock opname session with its items and variances
+func (a *App) GetStokOpnameByID(id int) (*models.StokOpname, error) {
+	return a.services.OpnameService.GetOpnameByID(id)
+}
+
+// MulaiStokOpname starts a stock opname and freezes the system stock
+func (a *App) MulaiStokOpname(req models.MulaiOpnameRequest) (*models.StokOpname, error) {
+	log.Printf("Starting stock opname for category: %q", req.Kategori)
+	return a.services.OpnameService.MulaiOpname(&req)
+}
+
+// HitungStokOpname records a counted quantity by product ID or barcode scan
+func (a *App) HitungStokOpname(req models.HitungOpnameRequest) (*models.StokOpnameItem, error) {
+	return a.services.OpnameService.HitungOpname(&req)
+}
+
+// SetujuiStokOpname approves a stock opname and posts its variances to stock
+func (a *App) SetujuiStokOpname(req models.SetujuiOpnameRequest) (*models.StokOpname, error) {
+	log.Printf("Approving stock opname ID: %d", req.OpnameID)
+	return a.services.OpnameService.SetujuiOpname(&req)
+}
+
+// BatalkanStokOpname cancels a running stock opname without changing stock
+func (a *App) BatalkanStokOpname(id int) (*models.StokOpname, error) {
+	log.Printf("Cancelling stock opname ID: %d", id)
+	return a.services.OpnameService.BatalkanOpname(id)
+}
+
 func (a *App) UpdateProduk(produk models.Produk) error {
 	return a.services.ProdukService.UpdateProduk(&produk)
 }
@@ -134,6 +301,61 @@ func (a *App) ScanBarcode(barcode string, jumlah int) (*models.ScanBarcodeRespon
 	return a.services.ProdukService.ScanBarcode(barcode, jumlah)
 }
 
+// CariBarcode resolves a barcode to its product and the scanned unit of measure
+func (a *App) CariBarcode(barcode string) (*models.ProdukBarcode, error) {
+	return a.services.ProdukService.CariBarcode(barcode)
+}
+
+// GetSatuanProduk retrieves the additional units of measure of a product
+func (a *App) GetSatuanProduk(produkID int) ([]*models.ProdukSatuan, error) {
+	return a.services.ProdukService.GetSatuanProduk(produkID)
+}
+
+// SimpanSatuanProduk replaces the additional units of measure of a product
+func (a *App) SimpanSatuanProduk(req models.SimpanProdukSatuanRequest) ([]*models.ProdukSatuan, error) {
+	return a.services.ProdukService.SimpanSatuanProduk(&req)
+}
+
+// GetKomponenProduk retrieves the components of a kit product
+func (a *App) GetKomponenProduk(produkID int) ([]*models.ProdukKomponen, error) {
+	return a.services.ProdukService.GetKomponenProduk(produkID)
+}
+
+// SimpanKomponenProduk replaces the components of a kit product
+func (a *App) SimpanKomponenProduk(req models.SimpanProdukKomponenRequest) ([]*models.ProdukKomponen, error) {
+	return a.services.ProdukService.SimpanKomponenProduk(&req)
+}
+
+// ==================== PRODUK INDUK (VARIAN) API ====================
+
+// GetAllProdukInduk retrieves all parent products with the aggregated stock of their variants
+func (a *App) GetAllProdukInduk() ([]*models.ProdukInduk, error) {
+	return a.services.ProdukIndukService.GetAllInduk()
+}
+
+// GetProdukInduk retrieves a parent product with its variants
+func (a *App) GetProdukInduk(id int) (*models.ProdukInduk, error) {
+	return a.services.ProdukIndukService.GetInduk(id)
+}
+
+// CreateProdukInduk creates a parent product with its variant matrix
+func (a *App) CreateProdukInduk(req models.SimpanProdukIndukRequest) (*models.ProdukInduk, error) {
+	log.Printf("Creating parent product %s with %d variants", req.Nama, len(req.Varian))
+	return a.services.ProdukIndukService.CreateInduk(&req)
+}
+
+// UpdateProdukInduk edits a parent product and replaces its variant matrix
+func (a *App) UpdateProdukInduk(req models.SimpanProdukIndukRequest) (*models.ProdukInduk, error) {
+	log.Printf("Updating parent product ID: %d with %d variants", req.ID, len(req.Varian))
+	return a.services.ProdukIndukService.UpdateInduk(&req)
+}
+
+// DeleteProdukInduk removes a parent product; its variants stay as standalone products
+func (a *App) DeleteProdukInduk(id int) error {
+	log.Printf("Deleting parent product ID: %d", id)
+	return a.services.ProdukIndukService.DeleteInduk(id)
+}
+
 // ==================== KERANJANG API ====================
 
 // GetKeranjang retrieves all cart items
@@ -249,6 +471,168 @@ func (a *App) GetTodayStats() (map[string]interface{}, error) {
 	return a.services.TransaksiService.GetTodayStats()
 }
 
+// VoidTransaksi cancels a completed transaction (requires admin approval credentials)
+func (a *App) VoidTransaksi(req models.VoidTransaksiRequest) (*models.TransaksiResponse, error) {
+	log.Printf("Voiding transaction ID: %d", req.TransaksiID)
+	return a.services.TransaksiService.VoidTransaksi(&req)
+}
+
+// ParkirTransaksi parks (holds) a transaction draft so it can be resumed later
+func (a *App) ParkirTransaksi(req models.ParkirTransaksiRequest) (*models.TransaksiParkir, error) {
+	log.Printf("Parking transaction with %d items", len(req.Draft.Items))
+	return a.services.ParkirService.ParkirTransaksi(&req)
+}
+
+// GetTransaksiParkir lists active parked transactions per staff and terminal
+func (a *App) GetTransaksiParkir(filter models.TransaksiParkirFilter) ([]*models.TransaksiParkir, error) {
+	return a.services.ParkirService.GetTransaksiParkir(filter)
+}
+
+// LanjutkanTransaksiParkir resumes a parked transaction and returns its draft
+func (a *App) LanjutkanTransaksiParkir(id int) (*models.TransaksiParkir, error) {
+	log.Printf("Resuming parked transaction ID: %d", id)
+	return a.services.ParkirService.LanjutkanTransaksiParkir(id)
+}
+
+// BatalkanTransaksiParkir discards a parked transaction
+func (a *App) BatalkanTransaksiParkir(id int) error {
+	log.Printf("Discarding parked transaction ID: %d", id)
+	return a.services.ParkirService.BatalkanTransaksiParkir(id)
+}
+
+// ==================== METODE PEMBAYARAN API ====================
+
+// GetAllMetodePembayaran retrieves all payment methods, including inactive ones
+func (a *App) GetAllMetodePembayaran() ([]*models.MetodePembayaran, error) {
+	return a.services.MetodeService.GetAllMetodePembayaran()
+}
+
+// GetMetodePembayaranAktif retrieves the payment methods available at the cashier
+func (a *App) GetMetodePembayaranAktif() ([]*models.MetodePembayaran, error) {
+	return a.services.MetodeService.GetMetodePembayaranAktif()
+}
+
+// CreateMetodePembayaran adds a payment method to the registry
+func (a *App) CreateMetodePembayaran(metode models.MetodePembayaran) (*models.MetodePembayaran, error) {
+	log.Printf("Creating payment method: %s", metode.Kode)
+	if err := a.services.MetodeService.CreateMetodePembayaran(&metode); err != nil {
+		return nil, err
+	}
+	return &metode, nil
+}
+
+// UpdateMetodePembayaran updates a payment method
+func (a *App) UpdateMetodePembayaran(metode models.MetodePembayaran) (*models.MetodePembayaran, error) {
+	log.Printf("Updating payment method ID: %d", metode.ID)
+	if err := a.services.MetodeService.UpdateMetodePembayaran(&metode); err != nil {
+		return nil, err
+	}
+	return &metode, nil
+}
+
+// ==================== BARCODE TIMBANG API ====================
+
+// GetAllAturanBarcodeTimbang retrieves all scale label rules
+func (a *App) GetAllAturanBarcodeTimbang() ([]*models.AturanBarcodeTimbang, error) {
+	return a.services.TimbangService.GetAllAturan()
+}
+
+// CreateAturanBarcodeTimbang adds a scale label rule
+func (a *App) CreateAturanBarcodeTimbang(aturan models.AturanBarcodeTimbang) (*models.AturanBarcodeTimbang, error) {
+	log.Printf("Creating scale label rule for prefix %s", aturan.Prefix)
+	if err := a.services.TimbangService.CreateAturan(&aturan); err != nil {
+		return nil, err
+	}
+	return &aturan, nil
+}
+
+// UpdateAturanBarcodeTimbang updates a scale label rule
+func (a *App) UpdateAturanBarcodeTimbang(aturan models.AturanBarcodeTimbang) (*models.AturanBarcodeTimbang, error) {
+	log.Printf("Updating scale label rule ID: %d", aturan.ID)
+	if err := a.services.TimbangService.UpdateAturan(&aturan); err != nil {
+		return nil, err
+	}
+	return &aturan, nil
+}
+
+// DeleteAturanBarcodeTimbang removes a scale label rule
+func (a *App) DeleteAturanBarcodeTimbang(id int) error {
+	log.Printf("Deleting scale label rule ID: %d", id)
+	return a.services.TimbangService.DeleteAturan(id)
+}
+
+// ==================== ATURAN MARKDOWN API ====================
+
+// GetAllAturanMarkdown retrieves all near-expiry markdown rules
+func (a *App) GetAllAturanMarkdown() ([]*models.AturanMarkdown, error) {
+	return a.services.MarkdownService.GetAllAturan()
+}
+
+// CreateAturanMarkdown adds a near-expiry markdown rule
+func (a *App) CreateAturanMarkdown(aturan models.AturanMarkdown) (*models.AturanMarkdown, error) {
+	log.Printf("Creating markdown rule: %s", aturan.Nama)
+	if err := a.services.MarkdownService.CreateAturan(&aturan); err != nil {
+		return nil, err
+	}
+	return &aturan, nil
+}
+
+// UpdateAturanMarkdown updates a near-expiry markdown rule
+func (a *App) UpdateAturanMarkdown(aturan models.AturanMarkdown) (*models.AturanMarkdown, error) {
+	log.Printf("Updating markdown rule ID: %d", aturan.ID)
+	if err := a.services.MarkdownService.UpdateAturan(&aturan); err != nil {
+		return nil, err
+	}
+	return &aturan, nil
+}
+
+// DeleteAturanMarkdown removes a near-expiry markdown rule
+func (a *App) DeleteAturanMarkdown(id int) error {
+	log.Printf("Deleting markdown rule ID: %d", id)
+	return a.services.MarkdownService.DeleteAturan(id)
+}
+
+// ==================== SHIFT KASIR API ====================
+
+// BukaShift opens a cashier shift with the opening cash
+func (a *App) BukaShift(req models.BukaShiftRequest) (*models.ShiftKasir, error) {
+	log.Printf("Opening shift on terminal %q with opening cash %d", req.KodeTerminal, req.KasAwal)
+	return a.services.ShiftService.BukaShift(&req)
+}
+
+// GetShiftAktif retrieves the open shift of a terminal (nil when no shift is open)
+func (a *App) GetShiftAktif(kodeTerminal string) (*models.ShiftKasir, error) {
+	return a.services.ShiftService.GetShiftAktif(kodeTerminal)
+}
+
+// GetAllShift lists cashier shifts, optionally filtered by terminal
+func (a *App) GetAllShift(kodeTerminal string, limit, offset int) ([]*models.ShiftKasir, error) {
+	return a.services.ShiftService.GetAllShift(kodeTerminal, limit, offset)
+}
+
+// CatatMutasiKas records cash put into or taken out of the drawer during a shift
+func (a *App) CatatMutasiKas(req models.MutasiKasRequest) (*models.ShiftKasMutasi, error) {
+	log.Printf("Recording cash %s of %d on terminal %q", req.Jenis, req.Jumlah, req.KodeTerminal)
+	return a.services.ShiftService.CatatMutasiKas(&req)
+}
+
+// TutupShift closes the open shift with the counted cash and returns the Z report
+func (a *App) TutupShift(req models.TutupShiftRequest) (*models.LaporanShift, error) {
+	log.Printf("Closing shift on terminal %q with counted cash %d", req.KodeTerminal, req.KasDihitung)
+	return a.services.ShiftService.TutupShift(&req)
+}
+
+// GetLaporanShift retrieves the X report (open shift) or Z report (closed shift)
+func (a *App) GetLaporanShift(shiftID int) (*models.LaporanShift, error) {
+	return a.services.ShiftService.GetLaporanShift(shiftID)
+}
+
+// PrintLaporanShift prints the X or Z report of a shift
+func (a *App) PrintLaporanShift(req models.PrintLaporanShiftRequest) error {
+	log.Printf("Printing shift report for shift ID: %d", req.ShiftID)
+	return a.services.PrinterService.PrintLaporanShift(&req)
+}
+
 // ==================== PELANGGAN API ====================
 
 // CreatePelanggan creates a new customer
@@ -299,6 +683,58 @@ func (a *App) GetPelangganByTipe(tipe string) ([]*models.Pelanggan, error) {
 	return a.services.PelangganService.GetPelangganByTipe(tipe)
 }
 
+// ==================== PIUTANG (KASBON) API ====================
+
+// GetAllPiutang lists customer receivables, optionally filtered by status
+func (a *App) GetAllPiutang(status string) ([]*models.Piutang, error) {
+	return a.services.PiutangService.GetAllPiutang(status)
+}
+
+// GetPiutangPelanggan retrieves the receivable ledger of a customer
+func (a *App) GetPiutangPelanggan(pelangganID int) (*models.PiutangPelanggan, error) {
+	return a.services.PiutangService.GetPiutangPelanggan(pelangganID)
+}
+
+// GetAgingPiutang retrieves the outstanding receivables grouped by age
+func (a *App) GetAgingPiutang() (*models.LaporanAgingPiutang, error) {
+	return a.services.PiutangService.GetAgingPiutang()
+}
+
+// BayarPiutang records a (partial) repayment by a customer
+func (a *App) BayarPiutang(req models.BayarPiutangRequest) (*models.BayarPiutangResponse, error) {
+	log.Printf("Recording repayment of %d for pelanggan ID: %d", req.Jumlah, req.PelangganID)
+	return a.services.PiutangService.BayarPiutang(&req)
+}
+
+// UpdateBatasKredit sets the credit limit of a customer
+func (a *App) UpdateBatasKredit(req models.UpdateBatasKreditRequest) (*models.Pelanggan, error) {
+	log.Printf("Updating credit limit of pelanggan ID: %d", req.PelangganID)
+	return a.services.PiutangService.UpdateBatasKredit(&req)
+}
+
+// ==================== KARTU HADIAH API ====================
+
+// GetAllKartuHadiah lists gift cards, optionally filtered by status
+func (a *App) GetAllKartuHadiah(status string) ([]*models.KartuHadiah, error) {
+	return a.services.KartuHadiahService.GetAllKartuHadiah(status)
+}
+
+// GetKartuHadiah retrieves a gift card with its balance history
+func (a *App) GetKartuHadiah(kode string) (*models.KartuHadiahDetail, error) {
+	return a.services.KartuHadiahService.GetKartuHadiah(kode)
+}
+
+// JualKartuHadiah sells a new gift card
+func (a *App) JualKartuHadiah(req models.JualKartuHadiahRequest) (*models.JualKartuHadiahResponse, error) {
+	log.Printf("Selling gift card of %d", req.Nominal)
+	return a.services.KartuHadiahService.JualKartuHadiah(&req)
+}
+
+// KedaluwarsakanKartuHadiah forfeits the balance of gift cards past their expiry date
+func (a *App) KedaluwarsakanKartuHadiah() (*models.KedaluwarsaKartuHadiahResponse, error) {
+	return a.services.KartuHadiahService.KedaluwarsakanKartuHadiah()
+}
+
 // ==================== SETTINGS API ====================
 
 // GetPoinSettings retrieves point system settings
@@ -313,6 +749,72 @@ func (a *App) UpdatePoinSettings(req models.UpdatePoinSettingsRequest) (*models.
 	return a.services.SettingsService.UpdatePoinSettings(&req)
 }
 
+// GetNomorTransaksiSettings retrieves the transaction numbering scheme
+func (a *App) GetNomorTransaksiSettings() (*models.NomorTransaksiSettings, error) {
+	return a.services.SettingsService.GetNomorTransaksiSettings()
+}
+
+// UpdateNomorTransaksiSettings updates the transaction numbering scheme
+func (a *App) UpdateNomorTransaksiSettings(req models.UpdateNomorTransaksiSettingsRequest) (*models.NomorTransaksiSettings, error) {
+	log.Println("Updating nomor transaksi settings")
+	return a.services.SettingsService.UpdateNomorTransaksiSettings(&req)
+}
+
+// GetPajakSettings retrieves the PPN configuration
+func (a *App) GetPajakSettings() (*models.PajakSettings, error) {
+	return a.services.SettingsService.GetPajakSettings()
+}
+
+// UpdatePajakSettings updates the PPN configuration
+func (a *App) UpdatePajakSettings(req models.UpdatePajakSettingsRequest) (*models.PajakSettings, error) {
+	log.Println("Updating pajak settings")
+	return a.services.SettingsService.UpdatePajakSettings(&req)
+}
+
+// GetHargaSettings retrieves the price override limit
+func (a *App) GetHargaSettings() (*models.HargaSettings, error) {
+	return a.services.SettingsService.GetHargaSettings()
+}
+
+// UpdateHargaSettings updates the price override limit
+func (a *App) UpdateHargaSettings(req models.UpdateHargaSettingsRequest) (*models.HargaSettings, error) {
+	log.Println("Updating harga settings")
+	return a.services.SettingsService.UpdateHargaSettings(&req)
+}
+
+// GetPembulatanSettings retrieves the cash rounding policy
+func (a *App) GetPembulatanSettings() (*models.PembulatanSettings, error) {
+	return a.services.SettingsService.GetPembulatanSettings()
+}
+
+// UpdatePembulatanSettings updates the cash rounding policy
+func (a *App) UpdatePembulatanSettings(req models.UpdatePembulatanSettingsRequest) (*models.PembulatanSettings, error) {
+	log.Println("Updating pembulatan settings")
+	return a.services.SettingsService.UpdatePembulatanSettings(&req)
+}
+
+// GetKartuHadiahSettings retrieves the validity rules of gift cards and store credit
+func (a *App) GetKartuHadiahSettings() (*models.KartuHadiahSettings, error) {
+	return a.services.SettingsService.GetKartuHadiahSettings()
+}
+
+// UpdateKartuHadiahSettings updates the validity rules of gift cards and store credit
+func (a *App) UpdateKartuHadiahSettings(req models.UpdateKartuHadiahSettingsRequest) (*models.KartuHadiahSettings, error) {
+	log.Printf("Updating gift card settings: %d days, store credit %d days", req.MasaBerlakuHari, req.MasaBerlakuReturHari)
+	return a.services.SettingsService.UpdateKartuHadiahSettings(&req)
+}
+
+// GetBatchKadaluarsaSettings retrieves the schedule of the expired batch job
+func (a *App) GetBatchKadaluarsaSettings() (*models.BatchKadaluarsaSettings, error) {
+	return a.services.SettingsService.GetBatchKadaluarsaSettings()
+}
+
+// UpdateBatchKadaluarsaSettings updates the schedule of the expired batch job
+func (a *App) UpdateBatchKadaluarsaSettings(req models.UpdateBatchKadaluarsaSettingsRequest) (*models.BatchKadaluarsaSettings, error) {
+	log.Printf("Updating expired batch settings: auto write-off %v, every %d hours", req.HapusOtomatis, req.IntervalJam)
+	return a.services.SettingsService.UpdateBatchKadaluarsaSettings(&req)
+}
+
 // ==================== HARDWARE API ====================
 
 // DetectHardware detects all connected hardware devices
@@ -458,7 +960,13 @@ func (a *App) SavePrintSettings(settings models.PrintSettings) error {
 // GetTopProducts retrieves top selling products within date range
 func (a *App) GetTopProducts(startDate, endDate string, limit int) ([]*models.TopProductsResponse, error) {
 	log.Printf("Getting top products from %s to %s (limit: %d)", startDate, endDate, limit)
-	return a.services.AnalyticsService.GetTopProducts(startDate, endDate, limit)
+	return a.services.AnalyticsService.GetTopProducts(startDate, endDate, limit, false)
+}
+
+// GetTopProductsPerInduk retrieves top selling products with variants rolled up per parent product
+func (a *App) GetTopProductsPerInduk(startDate, endDate string, limit int) ([]*models.TopProductsResponse, error) {
+	log.Printf("Getting top products per parent from %s to %s (limit: %d)", startDate, endDate, limit)
+	return a.services.AnalyticsService.GetTopProducts(startDate, endDate, limit, true)
 }
 
 // GetPaymentMethodBreakdown retrieves payment method statistics
@@ -476,7 +984,13 @@ func (a *App) GetSalesTrend(startDate, endDate string) ([]*models.SalesTrendResp
 // GetCategoryBreakdown retrieves sales by category
 func (a *App) GetCategoryBreakdown(startDate, endDate string) ([]*models.CategoryBreakdownResponse, error) {
 	log.Printf("Getting category breakdown from %s to %s", startDate, endDate)
-	return a.services.AnalyticsService.GetCategoryBreakdown(startDate, endDate)
+	return a.services.AnalyticsService.GetCategoryBreakdown(startDate, endDate, false)
+}
+
+// GetCategoryBreakdownPerInduk retrieves sales by category, counting variants under their parent's category
+func (a *App) GetCategoryBreakdownPerInduk(startDate, endDate string) ([]*models.CategoryBreakdownResponse, error) {
+	log.Printf("Getting category breakdown per parent from %s to %s", startDate, endDate)
+	return a.services.AnalyticsService.GetCategoryBreakdown(startDate, endDate, true)
 }
 
 // GetHourlySales retrieves sales grouped by hour and day
@@ -592,6 +1106,23 @@ func (a *App) GetStaffReport(staffID int, startDate, endDate string) (*models.St
 	return a.services.StaffReportService.GetStaffReport(staffID, start, end)
 }
 
+// GetLaporanOverrideHarga lists manual price overrides and line discounts per staff
+func (a *App) GetLaporanOverrideHarga(startDate, endDate string) ([]*models.LaporanOverrideHargaStaff, error) {
+	log.Printf("Getting price override report from %s to %s", startDate, endDate)
+
+	start, err := a.parseDate(startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+
+	end, err := a.parseDate(endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	return a.services.StaffReportService.GetLaporanOverrideHarga(start, end)
+}
+
 // GetStaffReportDetail gets detailed report with transaction list
 func (a *App) GetStaffReportDetail(staffID int, startDate, endDate string) (*models.StaffReportDetailWithItems, error) {
 	log.Printf("Getting detailed staff report for staff ID: %d from %s to %s", staffID, startDate, endDate)
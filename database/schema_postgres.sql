@@ -28,11 +28,25 @@ CREATE TABLE IF NOT EXISTS kategori (
     nama VARCHAR(255) UNIQUE NOT NULL,
     deskripsi TEXT,
     icon TEXT,
+    kode_pajak VARCHAR(20) DEFAULT '',
+    kebijakan_batch VARCHAR(50) DEFAULT '',
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW(),
     deleted_at TIMESTAMP NULL
 );
 
+-- Produk Induk table (Parent of product variants; each variant is a produk row)
+CREATE TABLE IF NOT EXISTS produk_induk (
+    id SERIAL PRIMARY KEY,
+    nama VARCHAR(255) NOT NULL,
+    kategori VARCHAR(255) DEFAULT '',
+    deskripsi TEXT DEFAULT '',
+    gambar TEXT DEFAULT '',
+    atribut TEXT DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
 -- Produk table (Products)
 CREATE TABLE IF NOT EXISTS produk (
     id SERIAL PRIMARY KEY,
@@ -52,9 +66,20 @@ CREATE TABLE IF NOT EXISTS produk (
     gambar TEXT,
     masa_simpan_hari INTEGER DEFAULT 0,
     hari_pemberitahuan_kadaluarsa INTEGER DEFAULT 30,
+    kode_pajak VARCHAR(20) DEFAULT '',
+    plu VARCHAR(20) DEFAULT '',
+    stok_minimum REAL DEFAULT 0,
+    stok_maksimum REAL DEFAULT 0,
+    lead_time_hari INTEGER DEFAULT 0,
+    supplier_id INTEGER,
+    kebijakan_batch VARCHAR(50) DEFAULT '',
+    induk_id INTEGER,
+    atribut_varian TEXT DEFAULT '',
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW(),
-    deleted_at TIMESTAMP NULL
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_produk_induk FOREIGN KEY (induk_id)
+        REFERENCES produk_induk(id) ON DELETE SET NULL
 );
 
 -- Keranjang table (Shopping cart for scanned items)
@@ -69,6 +94,63 @@ CREATE TABLE IF NOT EXISTS keranjang (
         REFERENCES produk(id) ON DELETE RESTRICT
 );
 
+-- Produk Satuan table (Additional units of measure with conversion to the base unit)
+CREATE TABLE IF NOT EXISTS produk_satuan (
+    id SERIAL PRIMARY KEY,
+    produk_id INTEGER NOT NULL,
+    nama VARCHAR(50) NOT NULL,
+    konversi REAL NOT NULL,
+    barcode VARCHAR(255) UNIQUE,
+    harga_jual INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT uq_produk_satuan_nama UNIQUE (produk_id, nama),
+    CONSTRAINT fk_produk_satuan_produk FOREIGN KEY (produk_id)
+        REFERENCES produk(id) ON DELETE CASCADE
+);
+
+-- Produk Komponen table (Bill of materials of a kit product; qty in base units of the component per kit)
+CREATE TABLE IF NOT EXISTS produk_komponen (
+    id SERIAL PRIMARY KEY,
+    paket_id INTEGER NOT NULL,
+    komponen_id INTEGER NOT NULL,
+    qty REAL NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT uq_produk_komponen UNIQUE (paket_id, komponen_id),
+    CONSTRAINT fk_produk_komponen_paket FOREIGN KEY (paket_id)
+        REFERENCES produk(id) ON DELETE CASCADE,
+    CONSTRAINT fk_produk_komponen_komponen FOREIGN KEY (komponen_id)
+        REFERENCES produk(id) ON DELETE RESTRICT
+);
+
+-- Aturan Barcode Timbang table (EAN-13 scale labels with embedded PLU and weight or price)
+CREATE TABLE IF NOT EXISTS aturan_barcode_timbang (
+    id SERIAL PRIMARY KEY,
+    nama VARCHAR(255) NOT NULL,
+    prefix VARCHAR(2) UNIQUE NOT NULL,
+    plu_dari INTEGER NOT NULL,
+    plu_sampai INTEGER NOT NULL,
+    nilai_dari INTEGER NOT NULL,
+    nilai_sampai INTEGER NOT NULL,
+    tipe_nilai VARCHAR(20) NOT NULL DEFAULT 'berat',
+    desimal INTEGER DEFAULT 3,
+    aktif INTEGER DEFAULT 1,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Aturan Markdown table (Near-expiry markdown by days left before batch expiry)
+CREATE TABLE IF NOT EXISTS aturan_markdown (
+    id SERIAL PRIMARY KEY,
+    nama VARCHAR(255) NOT NULL,
+    hari_sebelum INTEGER NOT NULL DEFAULT 0,
+    persen REAL NOT NULL,
+    aktif INTEGER DEFAULT 1,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
 -- Pelanggan table (Customer management)
 CREATE TABLE IF NOT EXISTS pelanggan (
     id SERIAL PRIMARY KEY,
@@ -81,6 +163,7 @@ CREATE TABLE IF NOT EXISTS pelanggan (
     diskon_persen INTEGER DEFAULT 0,
     total_transaksi INTEGER DEFAULT 0,
     total_belanja INTEGER DEFAULT 0,
+    batas_kredit INTEGER DEFAULT 0,
     alamat TEXT,
     last_transaction_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT NOW(),
@@ -123,6 +206,18 @@ CREATE TABLE IF NOT EXISTS transaksi (
     status VARCHAR(50) DEFAULT 'selesai',
     catatan TEXT,
     kasir VARCHAR(255),
+    void_alasan TEXT,
+    void_oleh_id INTEGER,
+    void_oleh_nama VARCHAR(255),
+    void_at TIMESTAMP,
+    void_shift_id INTEGER,
+    promo_id INTEGER,
+    mode_pajak VARCHAR(20) DEFAULT '',
+    total_dpp INTEGER DEFAULT 0,
+    total_pajak INTEGER DEFAULT 0,
+    shift_id INTEGER,
+    pembulatan INTEGER DEFAULT 0,
+    jenis VARCHAR(20) DEFAULT 'penjualan',
     created_at TIMESTAMP DEFAULT NOW()
 );
 
@@ -138,6 +233,23 @@ CREATE TABLE IF NOT EXISTS transaksi_item (
     jumlah INTEGER NOT NULL,
     beratgram REAL DEFAULT 0,
     subtotal INTEGER NOT NULL,
+    kode_pajak VARCHAR(20) DEFAULT '',
+    tarif_pajak REAL DEFAULT 0,
+    dpp INTEGER DEFAULT 0,
+    pajak INTEGER DEFAULT 0,
+    harga_asli INTEGER DEFAULT 0,
+    harga_override INTEGER,
+    diskon_tipe VARCHAR(20) DEFAULT '',
+    diskon_nilai REAL DEFAULT 0,
+    diskon_item INTEGER DEFAULT 0,
+    alasan_override TEXT,
+    approver_id INTEGER,
+    approver_nama VARCHAR(255),
+    satuan VARCHAR(50) DEFAULT '',
+    konversi REAL DEFAULT 1,
+    harga_pokok INTEGER,
+    diskon_markdown INTEGER DEFAULT 0,
+    keterangan_markdown TEXT DEFAULT '',
     created_at TIMESTAMP DEFAULT NOW(),
     CONSTRAINT fk_transaksi_item_transaksi FOREIGN KEY (transaksi_id)
         REFERENCES transaksi(id) ON DELETE RESTRICT,
@@ -152,6 +264,8 @@ CREATE TABLE IF NOT EXISTS pembayaran (
     metode VARCHAR(50) NOT NULL,
     jumlah INTEGER NOT NULL,
     referensi TEXT,
+    shift_id INTEGER,
+    piutang_id INTEGER,
     created_at TIMESTAMP DEFAULT NOW(),
     CONSTRAINT fk_pembayaran_transaksi FOREIGN KEY (transaksi_id)
         REFERENCES transaksi(id) ON DELETE RESTRICT
@@ -214,6 +328,8 @@ CREATE TABLE IF NOT EXISTS returns (
     refund_method VARCHAR(50),
     refund_status VARCHAR(50) DEFAULT 'pending',
     notes TEXT,
+    shift_id INTEGER,
+    potong_piutang INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW(),
     CONSTRAINT fk_returns_transaksi FOREIGN KEY (transaksi_id)
@@ -227,6 +343,7 @@ CREATE TABLE IF NOT EXISTS return_items (
     id SERIAL PRIMARY KEY,
     return_id INTEGER NOT NULL,
     product_id INTEGER NOT NULL,
+    transaksi_item_id INTEGER,
     quantity INTEGER NOT NULL,
     created_at TIMESTAMP DEFAULT NOW(),
     CONSTRAINT fk_return_items_return FOREIGN KEY (return_id)
@@ -270,12 +387,28 @@ CREATE TABLE IF NOT EXISTS stok_history (
     jenis_perubahan VARCHAR(50) NOT NULL,
     tipe_kerugian VARCHAR(100),
     nilai_kerugian INTEGER DEFAULT 0,
+    harga_beli INTEGER DEFAULT 0,
     keterangan TEXT,
     created_at TIMESTAMP DEFAULT NOW(),
     CONSTRAINT fk_stok_history_produk FOREIGN KEY (produk_id)
         REFERENCES produk(id) ON DELETE CASCADE
 );
 
+-- Supplier table (Supplier master data referenced by batches)
+CREATE TABLE IF NOT EXISTS supplier (
+    id SERIAL PRIMARY KEY,
+    nama VARCHAR(255) UNIQUE NOT NULL,
+    kontak VARCHAR(255) DEFAULT '',
+    alamat TEXT DEFAULT '',
+    npwp VARCHAR(30) DEFAULT '',
+    termin_hari INTEGER DEFAULT 0,
+    aktif INTEGER DEFAULT 1,
+    digabung_ke_id INTEGER REFERENCES supplier(id),
+    deleted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
 -- Batch table (FIFO stock management with expiry tracking)
 CREATE TABLE IF NOT EXISTS batch (
     id VARCHAR(255) PRIMARY KEY,
@@ -287,13 +420,388 @@ CREATE TABLE IF NOT EXISTS batch (
     tanggal_kadaluarsa TIMESTAMP NOT NULL,
     status VARCHAR(50) DEFAULT 'fresh',
     supplier VARCHAR(255) DEFAULT '',
+    supplier_id INTEGER,
     keterangan TEXT DEFAULT '',
+    ditarik_at TIMESTAMP NULL,
+    alasan_penarikan TEXT DEFAULT '',
+    ditarik_oleh VARCHAR(255) DEFAULT '',
+    harga_beli INTEGER DEFAULT 0,
+    dihapus_at TIMESTAMP NULL,
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW(),
     CONSTRAINT fk_batch_produk FOREIGN KEY (produk_id)
+        REFERENCES produk(id) ON DELETE CASCADE,
+    CONSTRAINT fk_batch_supplier FOREIGN KEY (supplier_id)
+        REFERENCES supplier(id)
+);
+
+-- Transaksi Item Batch table (Batch rows deducted for each sold item)
+CREATE TABLE IF NOT EXISTS transaksi_item_batch (
+    id SERIAL PRIMARY KEY,
+    transaksi_id INTEGER NOT NULL,
+    transaksi_item_id INTEGER NOT NULL,
+    produk_id INTEGER NOT NULL,
+    batch_id VARCHAR(255) NOT NULL,
+    qty REAL NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_transaksi_item_batch_transaksi FOREIGN KEY (transaksi_id)
+        REFERENCES transaksi(id) ON DELETE CASCADE,
+    CONSTRAINT fk_transaksi_item_batch_item FOREIGN KEY (transaksi_item_id)
+        REFERENCES transaksi_item(id) ON DELETE CASCADE
+);
+
+-- Transaksi Item Komponen table (Component stock deducted for a kit sold, frozen at sale time)
+CREATE TABLE IF NOT EXISTS transaksi_item_komponen (
+    id SERIAL PRIMARY KEY,
+    transaksi_id INTEGER NOT NULL,
+    transaksi_item_id INTEGER NOT NULL,
+    paket_id INTEGER,
+    komponen_id INTEGER,
+    komponen_nama VARCHAR(255) NOT NULL,
+    qty_per_paket REAL NOT NULL DEFAULT 0,
+    qty REAL NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_transaksi_item_komponen_transaksi FOREIGN KEY (transaksi_id)
+        REFERENCES transaksi(id) ON DELETE CASCADE,
+    CONSTRAINT fk_transaksi_item_komponen_item FOREIGN KEY (transaksi_item_id)
+        REFERENCES transaksi_item(id) ON DELETE CASCADE,
+    CONSTRAINT fk_transaksi_item_komponen_paket FOREIGN KEY (paket_id)
+        REFERENCES produk(id) ON DELETE SET NULL,
+    CONSTRAINT fk_transaksi_item_komponen_komponen FOREIGN KEY (komponen_id)
+        REFERENCES produk(id) ON DELETE SET NULL
+);
+
+-- Return Item Batch table (Returned quantity per batch it was sold from)
+CREATE TABLE IF NOT EXISTS return_item_batch (
+    id SERIAL PRIMARY KEY,
+    return_id INTEGER NOT NULL,
+    transaksi_id INTEGER NOT NULL,
+    produk_id INTEGER NOT NULL,
+    batch_id VARCHAR(255) NOT NULL,
+    qty REAL NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_return_item_batch_return FOREIGN KEY (return_id)
+        REFERENCES returns(id) ON DELETE CASCADE,
+    CONSTRAINT fk_return_item_batch_transaksi FOREIGN KEY (transaksi_id)
+        REFERENCES transaksi(id) ON DELETE CASCADE
+);
+
+-- Batch Penghapusan table (Stock written off from a batch: damaged, lost or expired)
+CREATE TABLE IF NOT EXISTS batch_penghapusan (
+    id SERIAL PRIMARY KEY,
+    batch_id VARCHAR(255) NOT NULL,
+    produk_id INTEGER NOT NULL,
+    qty REAL NOT NULL DEFAULT 0,
+    tipe_kerugian VARCHAR(50) NOT NULL,
+    keterangan TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_batch_penghapusan_batch FOREIGN KEY (batch_id)
+        REFERENCES batch(id) ON DELETE CASCADE
+);
+
+-- Purchase Order table (Orders to suppliers: draft, dikirim, diterima_sebagian, diterima, ditutup)
+CREATE TABLE IF NOT EXISTS purchase_order (
+    id SERIAL PRIMARY KEY,
+    nomor VARCHAR(50) UNIQUE NOT NULL,
+    supplier_id INTEGER NOT NULL,
+    status VARCHAR(30) NOT NULL DEFAULT 'draft',
+    tanggal_diharapkan TIMESTAMP,
+    catatan TEXT DEFAULT '',
+    total_estimasi INTEGER DEFAULT 0,
+    dibuat_oleh VARCHAR(255) DEFAULT '',
+    dikirim_at TIMESTAMP,
+    ditutup_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_purchase_order_supplier FOREIGN KEY (supplier_id)
+        REFERENCES supplier(id)
+);
+
+-- Purchase Order Item table (Ordered lines with the quantity received so far)
+CREATE TABLE IF NOT EXISTS purchase_order_item (
+    id SERIAL PRIMARY KEY,
+    po_id INTEGER NOT NULL,
+    produk_id INTEGER NOT NULL,
+    qty_pesan REAL NOT NULL,
+    harga_estimasi INTEGER NOT NULL DEFAULT 0,
+    qty_diterima REAL NOT NULL DEFAULT 0,
+    nilai_diterima INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_purchase_order_item_po FOREIGN KEY (po_id)
+        REFERENCES purchase_order(id) ON DELETE CASCADE,
+    CONSTRAINT fk_purchase_order_item_produk FOREIGN KEY (produk_id)
+        REFERENCES produk(id)
+);
+
+-- Penerimaan Barang table (Goods receipts against a purchase order)
+CREATE TABLE IF NOT EXISTS penerimaan_barang (
+    id SERIAL PRIMARY KEY,
+    nomor VARCHAR(50) UNIQUE NOT NULL,
+    po_id INTEGER NOT NULL,
+    penerima VARCHAR(255) DEFAULT '',
+    catatan TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_penerimaan_barang_po FOREIGN KEY (po_id)
+        REFERENCES purchase_order(id)
+);
+
+-- Penerimaan Barang Item table (Received quantity and actual cost per PO line)
+CREATE TABLE IF NOT EXISTS penerimaan_barang_item (
+    id SERIAL PRIMARY KEY,
+    penerimaan_id INTEGER NOT NULL,
+    po_item_id INTEGER NOT NULL,
+    produk_id INTEGER NOT NULL,
+    qty REAL NOT NULL,
+    harga_beli INTEGER NOT NULL DEFAULT 0,
+    masa_simpan_hari INTEGER DEFAULT 0,
+    batch_id VARCHAR(255),
+    CONSTRAINT fk_penerimaan_barang_item_penerimaan FOREIGN KEY (penerimaan_id)
+        REFERENCES penerimaan_barang(id) ON DELETE CASCADE,
+    CONSTRAINT fk_penerimaan_barang_item_po_item FOREIGN KEY (po_item_id)
+        REFERENCES purchase_order_item(id)
+);
+
+-- Stok Opname table (Physical inventory count sessions: berjalan, disetujui, dibatalkan)
+CREATE TABLE IF NOT EXISTS stok_opname (
+    id SERIAL PRIMARY KEY,
+    nomor VARCHAR(50) UNIQUE NOT NULL,
+    kategori VARCHAR(255) DEFAULT '',
+    status VARCHAR(30) NOT NULL DEFAULT 'berjalan',
+    catatan TEXT DEFAULT '',
+    dibuat_oleh VARCHAR(255) DEFAULT '',
+    disetujui_oleh VARCHAR(255) DEFAULT '',
+    selesai_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Stok Opname Item table (System quantity snapshot and counted quantity per product)
+CREATE TABLE IF NOT EXISTS stok_opname_item (
+    id SERIAL PRIMARY KEY,
+    opname_id INTEGER NOT NULL,
+    produk_id INTEGER NOT NULL,
+    stok_sistem REAL NOT NULL,
+    harga_pokok INTEGER NOT NULL DEFAULT 0,
+    stok_fisik REAL,
+    dihitung_at TIMESTAMP,
+    CONSTRAINT uq_stok_opname_item_produk UNIQUE (opname_id, produk_id),
+    CONSTRAINT fk_stok_opname_item_opname FOREIGN KEY (opname_id)
+        REFERENCES stok_opname(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stok_opname_item_produk FOREIGN KEY (produk_id)
         REFERENCES produk(id) ON DELETE CASCADE
 );
 
+-- Transaksi Counter table (Sequential transaction numbers per terminal and period)
+CREATE TABLE IF NOT EXISTS transaksi_counter (
+    kode_terminal VARCHAR(50) NOT NULL,
+    periode VARCHAR(20) NOT NULL,
+    periode_reset VARCHAR(8),
+    nilai INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (kode_terminal, periode)
+);
+
+-- Nomor Transaksi Settings table (Transaction numbering scheme)
+CREATE TABLE IF NOT EXISTS nomor_transaksi_settings (
+    id INTEGER PRIMARY KEY,
+    prefix VARCHAR(20) DEFAULT 'TRX',
+    kode_terminal VARCHAR(50) DEFAULT 'T01',
+    format_tanggal VARCHAR(20) DEFAULT 'YYYYMMDD',
+    panjang_counter INTEGER DEFAULT 4,
+    reset_counter VARCHAR(20) DEFAULT 'harian',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Pajak Settings table (PPN configuration)
+CREATE TABLE IF NOT EXISTS pajak_settings (
+    id INTEGER PRIMARY KEY,
+    aktif INTEGER DEFAULT 0,
+    mode_harga VARCHAR(20) DEFAULT 'inklusif',
+    kode_pajak_default VARCHAR(20) DEFAULT 'PPN11',
+    npwp VARCHAR(50) DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Harga Settings table (Manual price override and line discount limits)
+CREATE TABLE IF NOT EXISTS harga_settings (
+    id INTEGER PRIMARY KEY,
+    batas_override_persen REAL DEFAULT 10,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Pembulatan Settings table (Cash rounding policy)
+CREATE TABLE IF NOT EXISTS pembulatan_settings (
+    id INTEGER PRIMARY KEY,
+    aktif INTEGER DEFAULT 0,
+    mode VARCHAR(20) DEFAULT 'terdekat',
+    kelipatan INTEGER DEFAULT 100,
+    hanya_tunai INTEGER DEFAULT 1,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Kartu Hadiah Settings table (Validity of gift cards and store credit)
+CREATE TABLE IF NOT EXISTS kartu_hadiah_settings (
+    id INTEGER PRIMARY KEY,
+    masa_berlaku_hari INTEGER DEFAULT 365,
+    masa_berlaku_retur_hari INTEGER DEFAULT 180,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Batch Kadaluarsa Settings table (Scheduled write-off of expired batches)
+CREATE TABLE IF NOT EXISTS batch_kadaluarsa_settings (
+    id INTEGER PRIMARY KEY,
+    hapus_otomatis INTEGER DEFAULT 0,
+    interval_jam INTEGER DEFAULT 6,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Transaksi Parkir table (Held sales that can be resumed later)
+CREATE TABLE IF NOT EXISTS transaksi_parkir (
+    id SERIAL PRIMARY KEY,
+    label VARCHAR(255) NOT NULL,
+    staff_id INTEGER,
+    staff_nama VARCHAR(255),
+    kode_terminal VARCHAR(50),
+    draft TEXT NOT NULL,
+    jumlah_item INTEGER DEFAULT 0,
+    reservasi_stok INTEGER DEFAULT 0,
+    status VARCHAR(50) DEFAULT 'parkir',
+    expires_at TIMESTAMP NOT NULL,
+    resumed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Transaksi Parkir Item table (Stock soft-reserved by held sales)
+CREATE TABLE IF NOT EXISTS transaksi_parkir_item (
+    id SERIAL PRIMARY KEY,
+    parkir_id INTEGER NOT NULL,
+    produk_id INTEGER NOT NULL,
+    qty REAL NOT NULL DEFAULT 0,
+    CONSTRAINT fk_transaksi_parkir_item_parkir FOREIGN KEY (parkir_id)
+        REFERENCES transaksi_parkir(id) ON DELETE CASCADE
+);
+
+-- Shift Kasir table (Cashier sessions with opening float and cash count)
+CREATE TABLE IF NOT EXISTS shift_kasir (
+    id SERIAL PRIMARY KEY,
+    kode_terminal VARCHAR(50) NOT NULL,
+    staff_id INTEGER,
+    staff_nama VARCHAR(255),
+    kas_awal INTEGER NOT NULL DEFAULT 0,
+    kas_seharusnya INTEGER DEFAULT 0,
+    kas_dihitung INTEGER,
+    selisih INTEGER DEFAULT 0,
+    status VARCHAR(20) DEFAULT 'buka',
+    catatan TEXT,
+    dibuka_at TIMESTAMP NOT NULL,
+    ditutup_at TIMESTAMP,
+    ditutup_oleh_id INTEGER,
+    ditutup_oleh_nama VARCHAR(255),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Shift Kas Mutasi table (Cash in/out of the drawer during a shift)
+CREATE TABLE IF NOT EXISTS shift_kas_mutasi (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL,
+    jenis VARCHAR(20) NOT NULL,
+    jumlah INTEGER NOT NULL,
+    keterangan TEXT,
+    staff_id INTEGER,
+    staff_nama VARCHAR(255),
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_shift_kas_mutasi_shift FOREIGN KEY (shift_id)
+        REFERENCES shift_kasir(id) ON DELETE RESTRICT
+);
+
+-- Metode Pembayaran table (Payment method registry managed by admins)
+CREATE TABLE IF NOT EXISTS metode_pembayaran (
+    id SERIAL PRIMARY KEY,
+    kode VARCHAR(50) UNIQUE NOT NULL,
+    nama VARCHAR(100) NOT NULL,
+    aktif INTEGER DEFAULT 1,
+    boleh_kembalian INTEGER DEFAULT 0,
+    wajib_referensi INTEGER DEFAULT 0,
+    biaya_persen REAL DEFAULT 0,
+    urutan INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Piutang table (Customer credit sales / kasbon and their outstanding balance)
+CREATE TABLE IF NOT EXISTS piutang (
+    id SERIAL PRIMARY KEY,
+    transaksi_id INTEGER UNIQUE NOT NULL,
+    pelanggan_id INTEGER NOT NULL,
+    jumlah INTEGER NOT NULL,
+    sisa INTEGER NOT NULL,
+    status VARCHAR(20) DEFAULT 'belum_lunas',
+    lunas_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_piutang_transaksi FOREIGN KEY (transaksi_id)
+        REFERENCES transaksi(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_piutang_pelanggan FOREIGN KEY (pelanggan_id)
+        REFERENCES pelanggan(id) ON DELETE RESTRICT
+);
+
+-- Kartu Hadiah table (Gift cards and store credit with their remaining balance)
+CREATE TABLE IF NOT EXISTS kartu_hadiah (
+    id SERIAL PRIMARY KEY,
+    kode VARCHAR(50) UNIQUE NOT NULL,
+    sumber VARCHAR(20) NOT NULL,
+    saldo_awal INTEGER NOT NULL,
+    saldo INTEGER NOT NULL,
+    status VARCHAR(20) DEFAULT 'aktif',
+    pelanggan_id INTEGER,
+    transaksi_id INTEGER,
+    return_id INTEGER,
+    expired_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_kartu_hadiah_pelanggan FOREIGN KEY (pelanggan_id)
+        REFERENCES pelanggan(id) ON DELETE SET NULL,
+    CONSTRAINT fk_kartu_hadiah_transaksi FOREIGN KEY (transaksi_id)
+        REFERENCES transaksi(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_kartu_hadiah_return FOREIGN KEY (return_id)
+        REFERENCES returns(id) ON DELETE RESTRICT
+);
+
+-- Kartu Hadiah Mutasi table (Issue, redemption and expiry history of a gift card)
+CREATE TABLE IF NOT EXISTS kartu_hadiah_mutasi (
+    id SERIAL PRIMARY KEY,
+    kartu_id INTEGER NOT NULL,
+    tipe VARCHAR(20) NOT NULL,
+    jumlah INTEGER NOT NULL,
+    saldo_sesudah INTEGER NOT NULL,
+    transaksi_id INTEGER,
+    return_id INTEGER,
+    keterangan TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_kartu_hadiah_mutasi_kartu FOREIGN KEY (kartu_id)
+        REFERENCES kartu_hadiah(id) ON DELETE CASCADE
+);
+
+-- Idempotency Key table (Deduplicates retried write requests)
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    scope VARCHAR(50) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'processing',
+    response TEXT,
+    resource_id INTEGER,
+    created_at TIMESTAMP DEFAULT NOW(),
+    completed_at TIMESTAMP,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
 -- Poin Settings table (Loyalty points configuration)
 CREATE TABLE IF NOT EXISTS poin_settings (
     id INTEGER PRIMARY KEY,
@@ -317,6 +825,14 @@ CREATE INDEX IF NOT EXISTS idx_produk_barcode ON produk(barcode);
 CREATE INDEX IF NOT EXISTS idx_produk_sku ON produk(sku);
 CREATE INDEX IF NOT EXISTS idx_produk_kategori ON produk(kategori);
 CREATE INDEX IF NOT EXISTS idx_produk_jenis ON produk(jenis_produk);
+CREATE INDEX IF NOT EXISTS idx_produk_plu ON produk(plu);
+CREATE INDEX IF NOT EXISTS idx_produk_induk ON produk(induk_id);
+
+-- Produk Satuan indexes
+CREATE INDEX IF NOT EXISTS idx_produk_satuan_produk ON produk_satuan(produk_id);
+
+-- Produk Komponen indexes
+CREATE INDEX IF NOT EXISTS idx_produk_komponen_paket ON produk_komponen(paket_id);
 
 -- Kategori indexes
 CREATE INDEX IF NOT EXISTS idx_kategori_nama ON kategori(nama);
@@ -327,6 +843,8 @@ CREATE INDEX IF NOT EXISTS idx_transaksi_tanggal ON transaksi(tanggal);
 CREATE INDEX IF NOT EXISTS idx_transaksi_pelanggan ON transaksi(pelanggan_id);
 CREATE INDEX IF NOT EXISTS idx_transaksi_staff ON transaksi(staff_id);
 CREATE INDEX IF NOT EXISTS idx_transaksi_status ON transaksi(status);
+CREATE INDEX IF NOT EXISTS idx_transaksi_promo ON transaksi(promo_id);
+CREATE INDEX IF NOT EXISTS idx_transaksi_shift ON transaksi(shift_id);
 
 -- Transaksi Item indexes
 CREATE INDEX IF NOT EXISTS idx_transaksi_item_transaksi ON transaksi_item(transaksi_id);
@@ -360,6 +878,40 @@ CREATE INDEX IF NOT EXISTS idx_stok_history_tanggal ON stok_history(created_at);
 CREATE INDEX IF NOT EXISTS idx_batch_produk ON batch(produk_id);
 CREATE INDEX IF NOT EXISTS idx_batch_status ON batch(status);
 CREATE INDEX IF NOT EXISTS idx_batch_kadaluarsa ON batch(tanggal_kadaluarsa);
+CREATE INDEX IF NOT EXISTS idx_batch_supplier ON batch(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_batch_penghapusan_batch ON batch_penghapusan(batch_id);
+CREATE INDEX IF NOT EXISTS idx_batch_penghapusan_tanggal ON batch_penghapusan(created_at);
+
+-- Purchase order indexes
+CREATE INDEX IF NOT EXISTS idx_purchase_order_status ON purchase_order(status);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_supplier ON purchase_order(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_item_po ON purchase_order_item(po_id);
+CREATE INDEX IF NOT EXISTS idx_penerimaan_barang_po ON penerimaan_barang(po_id);
+CREATE INDEX IF NOT EXISTS idx_penerimaan_barang_item_penerimaan ON penerimaan_barang_item(penerimaan_id);
+CREATE INDEX IF NOT EXISTS idx_stok_opname_status ON stok_opname(status);
+CREATE INDEX IF NOT EXISTS idx_stok_opname_item_opname ON stok_opname_item(opname_id);
+
+-- Transaksi Item Batch indexes
+CREATE INDEX IF NOT EXISTS idx_transaksi_item_batch_transaksi ON transaksi_item_batch(transaksi_id);
+CREATE INDEX IF NOT EXISTS idx_transaksi_item_batch_batch ON transaksi_item_batch(batch_id);
+CREATE INDEX IF NOT EXISTS idx_transaksi_item_komponen_transaksi ON transaksi_item_komponen(transaksi_id);
+CREATE INDEX IF NOT EXISTS idx_return_item_batch_transaksi ON return_item_batch(transaksi_id);
+CREATE INDEX IF NOT EXISTS idx_return_item_batch_batch ON return_item_batch(batch_id);
+
+-- Transaksi Parkir indexes
+CREATE INDEX IF NOT EXISTS idx_transaksi_parkir_status ON transaksi_parkir(status);
+CREATE INDEX IF NOT EXISTS idx_transaksi_parkir_item_produk ON transaksi_parkir_item(produk_id);
+CREATE INDEX IF NOT EXISTS idx_pembayaran_shift ON pembayaran(shift_id);
+CREATE INDEX IF NOT EXISTS idx_returns_shift ON returns(shift_id);
+CREATE INDEX IF NOT EXISTS idx_shift_kasir_status ON shift_kasir(status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shift_kasir_terminal_buka ON shift_kasir(kode_terminal) WHERE status = 'buka';
+CREATE INDEX IF NOT EXISTS idx_shift_kas_mutasi_shift ON shift_kas_mutasi(shift_id);
+CREATE INDEX IF NOT EXISTS idx_pembayaran_piutang ON pembayaran(piutang_id);
+CREATE INDEX IF NOT EXISTS idx_piutang_pelanggan ON piutang(pelanggan_id);
+CREATE INDEX IF NOT EXISTS idx_piutang_status ON piutang(status);
+CREATE INDEX IF NOT EXISTS idx_kartu_hadiah_status ON kartu_hadiah(status);
+CREATE INDEX IF NOT EXISTS idx_kartu_hadiah_mutasi_kartu ON kartu_hadiah_mutasi(kartu_id);
+CREATE INDEX IF NOT EXISTS idx_kartu_hadiah_mutasi_transaksi ON kartu_hadiah_mutasi(transaksi_id);
 
 -- Users indexes
 CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
@@ -386,6 +938,62 @@ CREATE TRIGGER update_produk_timestamp
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at_column();
 
+-- Trigger for produk_induk table
+DROP TRIGGER IF EXISTS update_produk_induk_timestamp ON produk_induk;
+CREATE TRIGGER update_produk_induk_timestamp
+    BEFORE UPDATE ON produk_induk
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for aturan_barcode_timbang table
+DROP TRIGGER IF EXISTS update_aturan_barcode_timbang_timestamp ON aturan_barcode_timbang;
+CREATE TRIGGER update_aturan_barcode_timbang_timestamp
+    BEFORE UPDATE ON aturan_barcode_timbang
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for aturan_markdown table
+DROP TRIGGER IF EXISTS update_aturan_markdown_timestamp ON aturan_markdown;
+CREATE TRIGGER update_aturan_markdown_timestamp
+    BEFORE UPDATE ON aturan_markdown
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for supplier table
+DROP TRIGGER IF EXISTS update_supplier_timestamp ON supplier;
+CREATE TRIGGER update_supplier_timestamp
+    BEFORE UPDATE ON supplier
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for purchase_order table
+DROP TRIGGER IF EXISTS update_purchase_order_timestamp ON purchase_order;
+CREATE TRIGGER update_purchase_order_timestamp
+    BEFORE UPDATE ON purchase_order
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for stok_opname table
+DROP TRIGGER IF EXISTS update_stok_opname_timestamp ON stok_opname;
+CREATE TRIGGER update_stok_opname_timestamp
+    BEFORE UPDATE ON stok_opname
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for produk_satuan table
+DROP TRIGGER IF EXISTS update_produk_satuan_timestamp ON produk_satuan;
+CREATE TRIGGER update_produk_satuan_timestamp
+    BEFORE UPDATE ON produk_satuan
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for produk_komponen table
+DROP TRIGGER IF EXISTS update_produk_komponen_timestamp ON produk_komponen;
+CREATE TRIGGER update_produk_komponen_timestamp
+    BEFORE UPDATE ON produk_komponen
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
 -- Trigger for kategori table
 DROP TRIGGER IF EXISTS update_kategori_timestamp ON kategori;
 CREATE TRIGGER update_kategori_timestamp
@@ -442,6 +1050,83 @@ CREATE TRIGGER update_poin_settings_timestamp
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at_column();
 
+-- Trigger for pajak_settings table
+DROP TRIGGER IF EXISTS update_pajak_settings_timestamp ON pajak_settings;
+CREATE TRIGGER update_pajak_settings_timestamp
+    BEFORE UPDATE ON pajak_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for harga_settings table
+DROP TRIGGER IF EXISTS update_harga_settings_timestamp ON harga_settings;
+CREATE TRIGGER update_harga_settings_timestamp
+    BEFORE UPDATE ON harga_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for pembulatan_settings table
+DROP TRIGGER IF EXISTS update_pembulatan_settings_timestamp ON pembulatan_settings;
+CREATE TRIGGER update_pembulatan_settings_timestamp
+    BEFORE UPDATE ON pembulatan_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for piutang table
+DROP TRIGGER IF EXISTS update_piutang_timestamp ON piutang;
+CREATE TRIGGER update_piutang_timestamp
+    BEFORE UPDATE ON piutang
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for kartu_hadiah table
+DROP TRIGGER IF EXISTS update_kartu_hadiah_timestamp ON kartu_hadiah;
+CREATE TRIGGER update_kartu_hadiah_timestamp
+    BEFORE UPDATE ON kartu_hadiah
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for kartu_hadiah_settings table
+DROP TRIGGER IF EXISTS update_kartu_hadiah_settings_timestamp ON kartu_hadiah_settings;
+CREATE TRIGGER update_kartu_hadiah_settings_timestamp
+    BEFORE UPDATE ON kartu_hadiah_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for batch_kadaluarsa_settings table
+DROP TRIGGER IF EXISTS update_batch_kadaluarsa_settings_timestamp ON batch_kadaluarsa_settings;
+CREATE TRIGGER update_batch_kadaluarsa_settings_timestamp
+    BEFORE UPDATE ON batch_kadaluarsa_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for metode_pembayaran table
+DROP TRIGGER IF EXISTS update_metode_pembayaran_timestamp ON metode_pembayaran;
+CREATE TRIGGER update_metode_pembayaran_timestamp
+    BEFORE UPDATE ON metode_pembayaran
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for shift_kasir table
+DROP TRIGGER IF EXISTS update_shift_kasir_timestamp ON shift_kasir;
+CREATE TRIGGER update_shift_kasir_timestamp
+    BEFORE UPDATE ON shift_kasir
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for transaksi_parkir table
+DROP TRIGGER IF EXISTS update_transaksi_parkir_timestamp ON transaksi_parkir;
+CREATE TRIGGER update_transaksi_parkir_timestamp
+    BEFORE UPDATE ON transaksi_parkir
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Trigger for nomor_transaksi_settings table
+DROP TRIGGER IF EXISTS update_nomor_transaksi_settings_timestamp ON nomor_transaksi_settings;
+CREATE TRIGGER update_nomor_transaksi_settings_timestamp
+    BEFORE UPDATE ON nomor_transaksi_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
 -- ============================================
 -- COMMENTS
 -- ============================================
@@ -449,6 +1134,11 @@ CREATE TRIGGER update_poin_settings_timestamp
 COMMENT ON TABLE migrations IS 'Tracks applied database migrations';
 COMMENT ON TABLE kategori IS 'Product categories';
 COMMENT ON TABLE produk IS 'Products and inventory';
+COMMENT ON TABLE produk_induk IS 'Parent products grouping variants (size, flavour, ...) with their attribute names';
+COMMENT ON TABLE produk_satuan IS 'Additional units of measure per product with conversion to the base unit';
+COMMENT ON TABLE produk_komponen IS 'Bill of materials of kit products (component and quantity per kit)';
+COMMENT ON TABLE aturan_barcode_timbang IS 'EAN-13 scale label rules (prefix, PLU digits, embedded weight or price)';
+COMMENT ON TABLE aturan_markdown IS 'Near-expiry markdown rules (percent off within N days of batch expiry)';
 COMMENT ON TABLE keranjang IS 'Temporary shopping cart for POS';
 COMMENT ON TABLE pelanggan IS 'Customer information and loyalty data';
 COMMENT ON TABLE users IS 'Staff and admin user accounts';
@@ -461,8 +1151,35 @@ COMMENT ON TABLE returns IS 'Product return and exchange transactions';
 COMMENT ON TABLE return_items IS 'Items being returned';
 COMMENT ON TABLE print_settings IS 'Thermal printer configuration';
 COMMENT ON TABLE stok_history IS 'Stock movement audit trail';
+COMMENT ON TABLE supplier IS 'Supplier master data (contact, NPWP, payment terms)';
 COMMENT ON TABLE batch IS 'FIFO inventory batches with expiry dates';
+COMMENT ON TABLE batch_penghapusan IS 'Stock written off from batches (damaged, lost, expired)';
+COMMENT ON TABLE purchase_order IS 'Purchase orders to suppliers';
+COMMENT ON TABLE purchase_order_item IS 'Purchase order lines with ordered and received quantity';
+COMMENT ON TABLE penerimaan_barang IS 'Goods receipts against purchase orders';
+COMMENT ON TABLE penerimaan_barang_item IS 'Received quantity and actual cost per purchase order line';
+COMMENT ON TABLE stok_opname IS 'Physical inventory count (stock opname) sessions';
+COMMENT ON TABLE stok_opname_item IS 'Frozen system quantity and counted quantity per product in a stock opname';
+COMMENT ON TABLE transaksi_item_batch IS 'Batch allocations of sold transaction items';
+COMMENT ON TABLE transaksi_item_komponen IS 'Component stock deducted for each kit sold';
+COMMENT ON TABLE return_item_batch IS 'Returned quantity per batch it was sold from';
 COMMENT ON TABLE poin_settings IS 'Customer loyalty points configuration';
+COMMENT ON TABLE transaksi_counter IS 'Sequential transaction number counters per terminal and period';
+COMMENT ON TABLE nomor_transaksi_settings IS 'Transaction numbering scheme configuration';
+COMMENT ON TABLE pajak_settings IS 'PPN (VAT) configuration';
+COMMENT ON TABLE harga_settings IS 'Manual price override and line discount approval limits';
+COMMENT ON TABLE pembulatan_settings IS 'Cash rounding policy for transaction totals';
+COMMENT ON TABLE kartu_hadiah_settings IS 'Validity period of gift cards and store credit';
+COMMENT ON TABLE batch_kadaluarsa_settings IS 'Schedule and auto write-off switch for expired batches';
+COMMENT ON TABLE transaksi_parkir IS 'Held (parked) sales that cashiers can resume later';
+COMMENT ON TABLE transaksi_parkir_item IS 'Stock soft-reserved by held sales';
+COMMENT ON TABLE shift_kasir IS 'Cashier shift sessions with opening float, cash count and variance';
+COMMENT ON TABLE shift_kas_mutasi IS 'Cash drops and petty cash movements during a cashier shift';
+COMMENT ON TABLE metode_pembayaran IS 'Payment method registry (change, reference and fee rules)';
+COMMENT ON TABLE piutang IS 'Customer credit sales (kasbon) and their outstanding balance';
+COMMENT ON TABLE kartu_hadiah IS 'Gift cards and store credit vouchers with their remaining balance';
+COMMENT ON TABLE kartu_hadiah_mutasi IS 'Issue, redemption and expiry history of gift cards';
+COMMENT ON TABLE idempotency_key IS 'Idempotency keys and stored responses of retried write requests';
 
 -- ============================================
 -- COMPLETION MESSAGE
@@ -473,6 +1190,6 @@ BEGIN
     RAISE NOTICE '========================================';
     RAISE NOTICE 'Schema created successfully!';
     RAISE NOTICE 'Database: ritel_db';
-    RAISE NOTICE 'Total tables: 18';
+    RAISE NOTICE 'Total tables: 50';
     RAISE NOTICE '========================================';
 END $$;
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// ParkirConfig holds configuration for held (parked) sales
+type ParkirConfig struct {
+	Expiry time.Duration // How long a parked sale stays resumable
+}
+
+// GetParkirConfig loads parked sale configuration from environment variables
+func GetParkirConfig() ParkirConfig {
+	// Parked sales expire after 2 hours unless configured otherwise (e.g. PARKIR_EXPIRY=30m)
+	expiry := parseDuration(os.Getenv("PARKIR_EXPIRY"), 2*time.Hour)
+	if expiry <= 0 {
+		expiry = 2 * time.Hour
+	}
+
+	return ParkirConfig{
+		Expiry: expiry,
+	}
+}
@@ -10,8 +10,16 @@ import (
 // This ensures both Wails and HTTP handlers use the SAME service instances
 type ServiceContainer struct {
 	ProdukService      *service.ProdukService
+	ProdukIndukService *service.ProdukIndukService
 	KategoriService    *service.KategoriService
 	TransaksiService   *service.TransaksiService
+	ParkirService      *service.TransaksiParkirService
+	ShiftService       *service.ShiftService
+	MetodeService      *service.MetodePembayaranService
+	TimbangService     *service.BarcodeTimbangService
+	MarkdownService    *service.MarkdownService
+	PiutangService     *service.PiutangService
+	KartuHadiahService *service.KartuHadiahService
 	PelangganService   *service.PelangganService
 	PromoService       *service.PromoService
 	ReturnService      *service.ReturnService
@@ -20,10 +28,15 @@ type ServiceContainer struct {
 	HardwareService    *service.HardwareService
 	AnalyticsService   *service.AnalyticsService
 	BatchService       *service.BatchService
+	SupplierService    *service.SupplierService
+	POService          *service.PurchaseOrderService
+	OpnameService      *service.StokOpnameService
 	UserService        *service.UserService
 	StaffReportService *service.StaffReportService
 	SalesReportService *service.SalesReportService
 	DashboardService   *service.DashboardService
+
+	KadaluarsaJob *service.BatchKadaluarsaJob // Scheduled status update and write-off of expired batches
 }
 
 // NewServiceContainer initializes all services
@@ -32,8 +45,16 @@ func NewServiceContainer() *ServiceContainer {
 
 	container := &ServiceContainer{
 		ProdukService:      service.NewProdukService(),
+		ProdukIndukService: service.NewProdukIndukService(),
 		KategoriService:    service.NewKategoriService(),
 		TransaksiService:   service.NewTransaksiService(),
+		ParkirService:      service.NewTransaksiParkirService(),
+		ShiftService:       service.NewShiftService(),
+		MetodeService:      service.NewMetodePembayaranService(),
+		TimbangService:     service.NewBarcodeTimbangService(),
+		MarkdownService:    service.NewMarkdownService(),
+		PiutangService:     service.NewPiutangService(),
+		KartuHadiahService: service.NewKartuHadiahService(),
 		PelangganService:   service.NewPelangganService(),
 		PromoService:       service.NewPromoService(),
 		ReturnService:      service.NewReturnService(),
@@ -42,6 +63,9 @@ func NewServiceContainer() *ServiceContainer {
 		HardwareService:    service.NewHardwareService(),
 		AnalyticsService:   service.NewAnalyticsService(),
 		BatchService:       service.NewBatchService(),
+		SupplierService:    service.NewSupplierService(),
+		POService:          service.NewPurchaseOrderService(),
+		OpnameService:      service.NewStokOpnameService(),
 		UserService:        service.NewUserService(),
 		StaffReportService: service.NewStaffReportService(),
 		SalesReportService: service.NewSalesReportService(),
@@ -51,6 +75,10 @@ func NewServiceContainer() *ServiceContainer {
 	// Ensure default admin exists
 	container.UserService.EnsureDefaultAdmin()
 
+	// Expired batches are checked in the background on the configured schedule
+	container.KadaluarsaJob = service.NewBatchKadaluarsaJob(container.BatchService)
+	container.KadaluarsaJob.Start()
+
 	log.Println("[CONTAINER] All services initialized successfully")
 	return container
 }
@@ -58,6 +86,7 @@ func NewServiceContainer() *ServiceContainer {
 // Shutdown performs cleanup for all services
 func (c *ServiceContainer) Shutdown() {
 	log.Println("[CONTAINER] Shutting down services...")
+	c.KadaluarsaJob.Stop()
 	database.Close()
 	log.Println("[CONTAINER] Services shutdown complete")
 }
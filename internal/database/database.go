@@ -949,6 +949,18 @@ func getTableCreationQueries() []string {
             updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
         )`,
 
+		// Produk Induk table (parent of product variants; each variant is a produk row)
+		`CREATE TABLE IF NOT EXISTS produk_induk (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            nama TEXT NOT NULL,
+            kategori TEXT DEFAULT '',
+            deskripsi TEXT DEFAULT '',
+            gambar TEXT DEFAULT '',
+            atribut TEXT DEFAULT '[]',
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
 		// Produk table
 		`CREATE TABLE IF NOT EXISTS produk (
             id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -982,6 +994,60 @@ func getTableCreationQueries() []string {
             FOREIGN KEY (produk_id) REFERENCES produk(id) ON DELETE RESTRICT
         )`,
 
+		// Produk Satuan table (additional units of measure with conversion to the base unit)
+		`CREATE TABLE IF NOT EXISTS produk_satuan (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            produk_id INTEGER NOT NULL,
+            nama TEXT NOT NULL,
+            konversi REAL NOT NULL,
+            barcode TEXT UNIQUE,
+            harga_jual INTEGER DEFAULT 0,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (produk_id, nama),
+            FOREIGN KEY (produk_id) REFERENCES produk(id) ON DELETE CASCADE
+        )`,
+
+		// Produk Komponen table (bill of materials of a kit product; qty in base units of the component per kit)
+		`CREATE TABLE IF NOT EXISTS produk_komponen (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            paket_id INTEGER NOT NULL,
+            komponen_id INTEGER NOT NULL,
+            qty REAL NOT NULL,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (paket_id, komponen_id),
+            FOREIGN KEY (paket_id) REFERENCES produk(id) ON DELETE CASCADE,
+            FOREIGN KEY (komponen_id) REFERENCES produk(id) ON DELETE RESTRICT
+        )`,
+
+		// Aturan Barcode Timbang table (EAN-13 scale labels with embedded PLU and weight or price)
+		`CREATE TABLE IF NOT EXISTS aturan_barcode_timbang (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            nama TEXT NOT NULL,
+            prefix TEXT UNIQUE NOT NULL,
+            plu_dari INTEGER NOT NULL,
+            plu_sampai INTEGER NOT NULL,
+            nilai_dari INTEGER NOT NULL,
+            nilai_sampai INTEGER NOT NULL,
+            tipe_nilai TEXT NOT NULL DEFAULT 'berat',
+            desimal INTEGER DEFAULT 3,
+            aktif INTEGER DEFAULT 1,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Aturan Markdown table (near-expiry markdown by days left before batch expiry)
+		`CREATE TABLE IF NOT EXISTS aturan_markdown (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            nama TEXT NOT NULL,
+            hari_sebelum INTEGER NOT NULL DEFAULT 0,
+            persen REAL NOT NULL,
+            aktif INTEGER DEFAULT 1,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
 		// Transaksi table (Transaction header)
 		`CREATE TABLE IF NOT EXISTS transaksi (
             id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -1157,6 +1223,19 @@ func getTableCreationQueries() []string {
             FOREIGN KEY (produk_id) REFERENCES produk(id) ON DELETE CASCADE
         )`,
 
+		// Supplier table (supplier master data referenced by batches)
+		`CREATE TABLE IF NOT EXISTS supplier (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            nama TEXT UNIQUE NOT NULL,
+            kontak TEXT DEFAULT '',
+            alamat TEXT DEFAULT '',
+            npwp TEXT DEFAULT '',
+            termin_hari INTEGER DEFAULT 0,
+            aktif INTEGER DEFAULT 1,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
 		// Batch table (for FIFO stock management with expiry)
 		`CREATE TABLE IF NOT EXISTS batch (
             id TEXT PRIMARY KEY,
@@ -1174,6 +1253,346 @@ func getTableCreationQueries() []string {
             FOREIGN KEY (produk_id) REFERENCES produk(id) ON DELETE CASCADE
         )`,
 
+		// Transaksi Item Batch table (batch rows deducted for each sold item)
+		`CREATE TABLE IF NOT EXISTS transaksi_item_batch (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            transaksi_id INTEGER NOT NULL,
+            transaksi_item_id INTEGER NOT NULL,
+            produk_id INTEGER NOT NULL,
+            batch_id TEXT NOT NULL,
+            qty REAL NOT NULL DEFAULT 0,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (transaksi_id) REFERENCES transaksi(id) ON DELETE CASCADE,
+            FOREIGN KEY (transaksi_item_id) REFERENCES transaksi_item(id) ON DELETE CASCADE
+        )`,
+
+		// Transaksi Item Komponen table (component stock deducted for a kit sold, frozen at sale time)
+		`CREATE TABLE IF NOT EXISTS transaksi_item_komponen (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            transaksi_id INTEGER NOT NULL,
+            transaksi_item_id INTEGER NOT NULL,
+            paket_id INTEGER,
+            komponen_id INTEGER,
+            komponen_nama TEXT NOT NULL,
+            qty_per_paket REAL NOT NULL DEFAULT 0,
+            qty REAL NOT NULL DEFAULT 0,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (transaksi_id) REFERENCES transaksi(id) ON DELETE CASCADE,
+            FOREIGN KEY (transaksi_item_id) REFERENCES transaksi_item(id) ON DELETE CASCADE,
+            FOREIGN KEY (paket_id) REFERENCES produk(id) ON DELETE SET NULL,
+            FOREIGN KEY (komponen_id) REFERENCES produk(id) ON DELETE SET NULL
+        )`,
+
+		// Return Item Batch table (returned quantity per batch it was sold from)
+		`CREATE TABLE IF NOT EXISTS return_item_batch (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            return_id INTEGER NOT NULL,
+            transaksi_id INTEGER NOT NULL,
+            produk_id INTEGER NOT NULL,
+            batch_id TEXT NOT NULL,
+            qty REAL NOT NULL DEFAULT 0,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (return_id) REFERENCES returns(id) ON DELETE CASCADE,
+            FOREIGN KEY (transaksi_id) REFERENCES transaksi(id) ON DELETE CASCADE
+        )`,
+
+		// Batch Penghapusan table (stock written off from a batch: damaged, lost or expired)
+		`CREATE TABLE IF NOT EXISTS batch_penghapusan (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            batch_id TEXT NOT NULL,
+            produk_id INTEGER NOT NULL,
+            qty REAL NOT NULL DEFAULT 0,
+            tipe_kerugian TEXT NOT NULL,
+            keterangan TEXT DEFAULT '',
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (batch_id) REFERENCES batch(id) ON DELETE CASCADE
+        )`,
+
+		// Purchase Order table (orders to suppliers: draft, dikirim, diterima_sebagian, diterima, ditutup)
+		`CREATE TABLE IF NOT EXISTS purchase_order (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            nomor TEXT UNIQUE NOT NULL,
+            supplier_id INTEGER NOT NULL,
+            status TEXT NOT NULL DEFAULT 'draft',
+            tanggal_diharapkan DATETIME,
+            catatan TEXT DEFAULT '',
+            total_estimasi INTEGER DEFAULT 0,
+            dibuat_oleh TEXT DEFAULT '',
+            dikirim_at DATETIME,
+            ditutup_at DATETIME,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (supplier_id) REFERENCES supplier(id)
+        )`,
+
+		// Purchase Order Item table (ordered lines with the quantity received so far)
+		`CREATE TABLE IF NOT EXISTS purchase_order_item (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            po_id INTEGER NOT NULL,
+            produk_id INTEGER NOT NULL,
+            qty_pesan REAL NOT NULL,
+            harga_estimasi INTEGER NOT NULL DEFAULT 0,
+            qty_diterima REAL NOT NULL DEFAULT 0,
+            nilai_diterima INTEGER NOT NULL DEFAULT 0,
+            FOREIGN KEY (po_id) REFERENCES purchase_order(id) ON DELETE CASCADE,
+            FOREIGN KEY (produk_id) REFERENCES produk(id)
+        )`,
+
+		// Penerimaan Barang table (goods receipts against a purchase order)
+		`CREATE TABLE IF NOT EXISTS penerimaan_barang (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            nomor TEXT UNIQUE NOT NULL,
+            po_id INTEGER NOT NULL,
+            penerima TEXT DEFAULT '',
+            catatan TEXT DEFAULT '',
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (po_id) REFERENCES purchase_order(id)
+        )`,
+
+		// Penerimaan Barang Item table (received quantity and actual cost per PO line)
+		`CREATE TABLE IF NOT EXISTS penerimaan_barang_item (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            penerimaan_id INTEGER NOT NULL,
+            po_item_id INTEGER NOT NULL,
+            produk_id INTEGER NOT NULL,
+            qty REAL NOT NULL,
+            harga_beli INTEGER NOT NULL DEFAULT 0,
+            masa_simpan_hari INTEGER DEFAULT 0,
+            batch_id TEXT,
+            FOREIGN KEY (penerimaan_id) REFERENCES penerimaan_barang(id) ON DELETE CASCADE,
+            FOREIGN KEY (po_item_id) REFERENCES purchase_order_item(id)
+        )`,
+
+		// Stok Opname table (physical inventory count sessions: berjalan, disetujui, dibatalkan)
+		`CREATE TABLE IF NOT EXISTS stok_opname (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            nomor TEXT UNIQUE NOT NULL,
+            kategori TEXT DEFAULT '',
+            status TEXT NOT NULL DEFAULT 'berjalan',
+            catatan TEXT DEFAULT '',
+            dibuat_oleh TEXT DEFAULT '',
+            disetujui_oleh TEXT DEFAULT '',
+            selesai_at DATETIME,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Stok Opname Item table (system quantity snapshot and counted quantity per product)
+		`CREATE TABLE IF NOT EXISTS stok_opname_item (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            opname_id INTEGER NOT NULL,
+            produk_id INTEGER NOT NULL,
+            stok_sistem REAL NOT NULL,
+            harga_pokok INTEGER NOT NULL DEFAULT 0,
+            stok_fisik REAL,
+            dihitung_at DATETIME,
+            UNIQUE(opname_id, produk_id),
+            FOREIGN KEY (opname_id) REFERENCES stok_opname(id) ON DELETE CASCADE,
+            FOREIGN KEY (produk_id) REFERENCES produk(id) ON DELETE CASCADE
+        )`,
+
+		// Transaksi Counter table (sequential transaction numbers per terminal and period)
+		`CREATE TABLE IF NOT EXISTS transaksi_counter (
+            kode_terminal TEXT NOT NULL,
+            periode TEXT NOT NULL,
+            nilai INTEGER NOT NULL DEFAULT 0,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY (kode_terminal, periode)
+        )`,
+
+		// Nomor Transaksi Settings table (transaction numbering scheme)
+		`CREATE TABLE IF NOT EXISTS nomor_transaksi_settings (
+            id INTEGER PRIMARY KEY,
+            prefix TEXT DEFAULT 'TRX',
+            kode_terminal TEXT DEFAULT 'T01',
+            format_tanggal TEXT DEFAULT 'YYYYMMDD',
+            panjang_counter INTEGER DEFAULT 4,
+            reset_counter TEXT DEFAULT 'harian',
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Pajak Settings table (PPN configuration)
+		`CREATE TABLE IF NOT EXISTS pajak_settings (
+            id INTEGER PRIMARY KEY,
+            aktif INTEGER DEFAULT 0,
+            mode_harga TEXT DEFAULT 'inklusif',
+            kode_pajak_default TEXT DEFAULT 'PPN11',
+            npwp TEXT DEFAULT '',
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Harga Settings table (manual price override and line discount limits)
+		`CREATE TABLE IF NOT EXISTS harga_settings (
+            id INTEGER PRIMARY KEY,
+            batas_override_persen REAL DEFAULT 10,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Pembulatan Settings table (cash rounding policy)
+		`CREATE TABLE IF NOT EXISTS pembulatan_settings (
+            id INTEGER PRIMARY KEY,
+            aktif INTEGER DEFAULT 0,
+            mode TEXT DEFAULT 'terdekat',
+            kelipatan INTEGER DEFAULT 100,
+            hanya_tunai INTEGER DEFAULT 1,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Kartu Hadiah Settings table (validity of gift cards and store credit)
+		`CREATE TABLE IF NOT EXISTS kartu_hadiah_settings (
+            id INTEGER PRIMARY KEY,
+            masa_berlaku_hari INTEGER DEFAULT 365,
+            masa_berlaku_retur_hari INTEGER DEFAULT 180,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Batch Kadaluarsa Settings table (scheduled write-off of expired batches)
+		`CREATE TABLE IF NOT EXISTS batch_kadaluarsa_settings (
+            id INTEGER PRIMARY KEY,
+            hapus_otomatis INTEGER DEFAULT 0,
+            interval_jam INTEGER DEFAULT 6,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Transaksi Parkir table (held sales that can be resumed later)
+		`CREATE TABLE IF NOT EXISTS transaksi_parkir (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            label TEXT NOT NULL,
+            staff_id INTEGER,
+            staff_nama TEXT,
+            kode_terminal TEXT,
+            draft TEXT NOT NULL,
+            jumlah_item INTEGER DEFAULT 0,
+            reservasi_stok INTEGER DEFAULT 0,
+            status TEXT DEFAULT 'parkir',
+            expires_at DATETIME NOT NULL,
+            resumed_at DATETIME,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Transaksi Parkir Item table (stock soft-reserved by held sales)
+		`CREATE TABLE IF NOT EXISTS transaksi_parkir_item (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            parkir_id INTEGER NOT NULL,
+            produk_id INTEGER NOT NULL,
+            qty REAL NOT NULL DEFAULT 0,
+            FOREIGN KEY (parkir_id) REFERENCES transaksi_parkir(id) ON DELETE CASCADE
+        )`,
+
+		// Shift Kasir table (cashier sessions with opening float and cash count)
+		`CREATE TABLE IF NOT EXISTS shift_kasir (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            kode_terminal TEXT NOT NULL,
+            staff_id INTEGER,
+            staff_nama TEXT,
+            kas_awal INTEGER NOT NULL DEFAULT 0,
+            kas_seharusnya INTEGER DEFAULT 0,
+            kas_dihitung INTEGER,
+            selisih INTEGER DEFAULT 0,
+            status TEXT DEFAULT 'buka',
+            catatan TEXT,
+            dibuka_at DATETIME NOT NULL,
+            ditutup_at DATETIME,
+            ditutup_oleh_id INTEGER,
+            ditutup_oleh_nama TEXT,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Shift Kas Mutasi table (cash in/out of the drawer during a shift)
+		`CREATE TABLE IF NOT EXISTS shift_kas_mutasi (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            shift_id INTEGER NOT NULL,
+            jenis TEXT NOT NULL,
+            jumlah INTEGER NOT NULL,
+            keterangan TEXT,
+            staff_id INTEGER,
+            staff_nama TEXT,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (shift_id) REFERENCES shift_kasir(id) ON DELETE RESTRICT
+        )`,
+
+		// Metode Pembayaran table (payment method registry managed by admins)
+		`CREATE TABLE IF NOT EXISTS metode_pembayaran (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            kode TEXT UNIQUE NOT NULL,
+            nama TEXT NOT NULL,
+            aktif INTEGER DEFAULT 1,
+            boleh_kembalian INTEGER DEFAULT 0,
+            wajib_referensi INTEGER DEFAULT 0,
+            biaya_persen REAL DEFAULT 0,
+            urutan INTEGER DEFAULT 0,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )`,
+
+		// Piutang table (customer credit sales / kasbon and their outstanding balance)
+		`CREATE TABLE IF NOT EXISTS piutang (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            transaksi_id INTEGER UNIQUE NOT NULL,
+            pelanggan_id INTEGER NOT NULL,
+            jumlah INTEGER NOT NULL,
+            sisa INTEGER NOT NULL,
+            status TEXT DEFAULT 'belum_lunas',
+            lunas_at DATETIME,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (transaksi_id) REFERENCES transaksi(id) ON DELETE RESTRICT,
+            FOREIGN KEY (pelanggan_id) REFERENCES pelanggan(id) ON DELETE RESTRICT
+        )`,
+
+		// Kartu Hadiah table (gift cards and store credit with their remaining balance)
+		`CREATE TABLE IF NOT EXISTS kartu_hadiah (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            kode TEXT UNIQUE NOT NULL,
+            sumber TEXT NOT NULL,
+            saldo_awal INTEGER NOT NULL,
+            saldo INTEGER NOT NULL,
+            status TEXT DEFAULT 'aktif',
+            pelanggan_id INTEGER,
+            transaksi_id INTEGER,
+            return_id INTEGER,
+            expired_at DATETIME,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (pelanggan_id) REFERENCES pelanggan(id) ON DELETE SET NULL,
+            FOREIGN KEY (transaksi_id) REFERENCES transaksi(id) ON DELETE RESTRICT,
+            FOREIGN KEY (return_id) REFERENCES returns(id) ON DELETE RESTRICT
+        )`,
+
+		// Kartu Hadiah Mutasi table (issue, redemption and expiry history of a gift card)
+		`CREATE TABLE IF NOT EXISTS kartu_hadiah_mutasi (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            kartu_id INTEGER NOT NULL,
+            tipe TEXT NOT NULL,
+            jumlah INTEGER NOT NULL,
+            saldo_sesudah INTEGER NOT NULL,
+            transaksi_id INTEGER,
+            return_id INTEGER,
+            keterangan TEXT,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (kartu_id) REFERENCES kartu_hadiah(id) ON DELETE CASCADE
+        )`,
+
+		// Idempotency Key table (deduplicates retried write requests)
+		`CREATE TABLE IF NOT EXISTS idempotency_key (
+            scope TEXT NOT NULL,
+            idempotency_key TEXT NOT NULL,
+            request_hash TEXT NOT NULL,
+            status TEXT NOT NULL DEFAULT 'processing',
+            response TEXT,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            completed_at DATETIME,
+            PRIMARY KEY (scope, idempotency_key)
+        )`,
+
 		// Users table (for admin and staff authentication)
 		`CREATE TABLE IF NOT EXISTS users (
             id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -1226,6 +1645,32 @@ func getIndexCreationQueries() []string {
 		`CREATE INDEX IF NOT EXISTS idx_batch_produk ON batch(produk_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_batch_status ON batch(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_batch_kadaluarsa ON batch(tanggal_kadaluarsa)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaksi_item_batch_transaksi ON transaksi_item_batch(transaksi_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaksi_item_batch_batch ON transaksi_item_batch(batch_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaksi_item_komponen_transaksi ON transaksi_item_komponen(transaksi_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_return_item_batch_transaksi ON return_item_batch(transaksi_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_return_item_batch_batch ON return_item_batch(batch_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_penghapusan_batch ON batch_penghapusan(batch_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_penghapusan_tanggal ON batch_penghapusan(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_purchase_order_status ON purchase_order(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_purchase_order_supplier ON purchase_order(supplier_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_purchase_order_item_po ON purchase_order_item(po_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_penerimaan_barang_po ON penerimaan_barang(po_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_penerimaan_barang_item_penerimaan ON penerimaan_barang_item(penerimaan_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_stok_opname_status ON stok_opname(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_stok_opname_item_opname ON stok_opname_item(opname_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaksi_parkir_status ON transaksi_parkir(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaksi_parkir_item_produk ON transaksi_parkir_item(produk_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_shift_kasir_status ON shift_kasir(status)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_shift_kasir_terminal_buka ON shift_kasir(kode_terminal) WHERE status = 'buka'`,
+		`CREATE INDEX IF NOT EXISTS idx_shift_kas_mutasi_shift ON shift_kas_mutasi(shift_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_piutang_pelanggan ON piutang(pelanggan_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_piutang_status ON piutang(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_kartu_hadiah_status ON kartu_hadiah(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_kartu_hadiah_mutasi_kartu ON kartu_hadiah_mutasi(kartu_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_kartu_hadiah_mutasi_transaksi ON kartu_hadiah_mutasi(transaksi_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_produk_satuan_produk ON produk_satuan(produk_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_produk_komponen_paket ON produk_komponen(paket_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_username ON users(username)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_role ON users(role)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_status ON users(status)`,
@@ -1247,6 +1692,13 @@ func getTriggerCreationQueries() []string {
              UPDATE produk SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
          END`,
 
+		`CREATE TRIGGER IF NOT EXISTS update_produk_induk_timestamp
+         AFTER UPDATE ON produk_induk
+         FOR EACH ROW
+         BEGIN
+             UPDATE produk_induk SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
 		`CREATE TRIGGER IF NOT EXISTS update_kategori_timestamp
          AFTER UPDATE ON kategori
          FOR EACH ROW
@@ -1289,6 +1741,132 @@ func getTriggerCreationQueries() []string {
              UPDATE batch SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
          END`,
 
+		`CREATE TRIGGER IF NOT EXISTS update_nomor_transaksi_settings_timestamp
+         AFTER UPDATE ON nomor_transaksi_settings
+         FOR EACH ROW
+         BEGIN
+             UPDATE nomor_transaksi_settings SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_pajak_settings_timestamp
+         AFTER UPDATE ON pajak_settings
+         FOR EACH ROW
+         BEGIN
+             UPDATE pajak_settings SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_harga_settings_timestamp
+         AFTER UPDATE ON harga_settings
+         FOR EACH ROW
+         BEGIN
+             UPDATE harga_settings SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_pembulatan_settings_timestamp
+         AFTER UPDATE ON pembulatan_settings
+         FOR EACH ROW
+         BEGIN
+             UPDATE pembulatan_settings SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_piutang_timestamp
+         AFTER UPDATE ON piutang
+         FOR EACH ROW
+         BEGIN
+             UPDATE piutang SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_kartu_hadiah_timestamp
+         AFTER UPDATE ON kartu_hadiah
+         FOR EACH ROW
+         BEGIN
+             UPDATE kartu_hadiah SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_kartu_hadiah_settings_timestamp
+         AFTER UPDATE ON kartu_hadiah_settings
+         FOR EACH ROW
+         BEGIN
+             UPDATE kartu_hadiah_settings SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_batch_kadaluarsa_settings_timestamp
+         AFTER UPDATE ON batch_kadaluarsa_settings
+         FOR EACH ROW
+         BEGIN
+             UPDATE batch_kadaluarsa_settings SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_produk_satuan_timestamp
+         AFTER UPDATE ON produk_satuan
+         FOR EACH ROW
+         BEGIN
+             UPDATE produk_satuan SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_produk_komponen_timestamp
+         AFTER UPDATE ON produk_komponen
+         FOR EACH ROW
+         BEGIN
+             UPDATE produk_komponen SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_aturan_barcode_timbang_timestamp
+         AFTER UPDATE ON aturan_barcode_timbang
+         FOR EACH ROW
+         BEGIN
+             UPDATE aturan_barcode_timbang SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_aturan_markdown_timestamp
+         AFTER UPDATE ON aturan_markdown
+         FOR EACH ROW
+         BEGIN
+             UPDATE aturan_markdown SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_supplier_timestamp
+         AFTER UPDATE ON supplier
+         FOR EACH ROW
+         BEGIN
+             UPDATE supplier SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_purchase_order_timestamp
+         AFTER UPDATE ON purchase_order
+         FOR EACH ROW
+         BEGIN
+             UPDATE purchase_order SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_stok_opname_timestamp
+         AFTER UPDATE ON stok_opname
+         FOR EACH ROW
+         BEGIN
+             UPDATE stok_opname SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_metode_pembayaran_timestamp
+         AFTER UPDATE ON metode_pembayaran
+         FOR EACH ROW
+         BEGIN
+             UPDATE metode_pembayaran SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_shift_kasir_timestamp
+         AFTER UPDATE ON shift_kasir
+         FOR EACH ROW
+         BEGIN
+             UPDATE shift_kasir SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_transaksi_parkir_timestamp
+         AFTER UPDATE ON transaksi_parkir
+         FOR EACH ROW
+         BEGIN
+             UPDATE transaksi_parkir SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+         END`,
+
 		`CREATE TRIGGER IF NOT EXISTS update_users_timestamp
          AFTER UPDATE ON users
          FOR EACH ROW
@@ -1594,6 +2172,345 @@ func getMigrationList() []struct {
 			name:  "add_kategori_deleted_at_column",
 			query: `ALTER TABLE kategori ADD COLUMN deleted_at DATETIME`,
 		},
+		{
+			name:  "add_transaksi_void_alasan_column",
+			query: `ALTER TABLE transaksi ADD COLUMN void_alasan TEXT`,
+		},
+		{
+			name:  "add_transaksi_void_oleh_id_column",
+			query: `ALTER TABLE transaksi ADD COLUMN void_oleh_id INTEGER`,
+		},
+		{
+			name:  "add_transaksi_void_oleh_nama_column",
+			query: `ALTER TABLE transaksi ADD COLUMN void_oleh_nama TEXT`,
+		},
+		{
+			name:  "add_transaksi_void_at_column",
+			query: `ALTER TABLE transaksi ADD COLUMN void_at DATETIME`,
+		},
+		{
+			name:  "add_transaksi_promo_id_column",
+			query: `ALTER TABLE transaksi ADD COLUMN promo_id INTEGER`,
+		},
+		{
+			name:  "add_transaksi_promo_id_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_transaksi_promo ON transaksi(promo_id)`,
+		},
+		{
+			name:  "add_produk_kode_pajak_column",
+			query: `ALTER TABLE produk ADD COLUMN kode_pajak TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_kategori_kode_pajak_column",
+			query: `ALTER TABLE kategori ADD COLUMN kode_pajak TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_transaksi_mode_pajak_column",
+			query: `ALTER TABLE transaksi ADD COLUMN mode_pajak TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_transaksi_total_dpp_column",
+			query: `ALTER TABLE transaksi ADD COLUMN total_dpp INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_total_pajak_column",
+			query: `ALTER TABLE transaksi ADD COLUMN total_pajak INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_item_kode_pajak_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN kode_pajak TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_transaksi_item_tarif_pajak_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN tarif_pajak REAL DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_item_dpp_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN dpp INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_item_pajak_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN pajak INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_shift_id_column",
+			query: `ALTER TABLE transaksi ADD COLUMN shift_id INTEGER`,
+		},
+		{
+			name:  "add_transaksi_shift_id_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_transaksi_shift ON transaksi(shift_id)`,
+		},
+		{
+			name:  "add_pembayaran_shift_id_column",
+			query: `ALTER TABLE pembayaran ADD COLUMN shift_id INTEGER`,
+		},
+		{
+			name:  "add_pembayaran_shift_id_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_pembayaran_shift ON pembayaran(shift_id)`,
+		},
+		{
+			name:  "add_returns_shift_id_column",
+			query: `ALTER TABLE returns ADD COLUMN shift_id INTEGER`,
+		},
+		{
+			name:  "add_returns_shift_id_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_returns_shift ON returns(shift_id)`,
+		},
+		{
+			name:  "add_transaksi_item_harga_asli_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN harga_asli INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_item_harga_override_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN harga_override INTEGER`,
+		},
+		{
+			name:  "add_transaksi_item_diskon_tipe_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN diskon_tipe TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_transaksi_item_diskon_nilai_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN diskon_nilai REAL DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_item_diskon_item_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN diskon_item INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_item_alasan_override_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN alasan_override TEXT`,
+		},
+		{
+			name:  "add_transaksi_item_approver_id_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN approver_id INTEGER`,
+		},
+		{
+			name:  "add_transaksi_item_approver_nama_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN approver_nama TEXT`,
+		},
+		{
+			name:  "add_transaksi_pembulatan_column",
+			query: `ALTER TABLE transaksi ADD COLUMN pembulatan INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_pelanggan_batas_kredit_column",
+			query: `ALTER TABLE pelanggan ADD COLUMN batas_kredit INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_pembayaran_piutang_id_column",
+			query: `ALTER TABLE pembayaran ADD COLUMN piutang_id INTEGER`,
+		},
+		{
+			name:  "add_pembayaran_piutang_id_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_pembayaran_piutang ON pembayaran(piutang_id)`,
+		},
+		{
+			// Registry yang sudah terisi tidak di-seed ulang; kasbon baru bisa dipakai setelah pelanggan diberi batas kredit
+			name:  "seed_metode_pembayaran_kasbon",
+			query: `INSERT INTO metode_pembayaran (kode, nama, aktif, boleh_kembalian, wajib_referensi, biaya_persen, urutan)
+				SELECT 'kasbon', 'Kasbon (Piutang)', 1, 0, 0, 0, 6
+				WHERE EXISTS (SELECT 1 FROM metode_pembayaran)
+				  AND NOT EXISTS (SELECT 1 FROM metode_pembayaran WHERE kode = 'kasbon')`,
+		},
+		{
+			// Kode kartu hadiah diisi sebagai nomor referensi pembayaran
+			name:  "seed_metode_pembayaran_kartu_hadiah",
+			query: `INSERT INTO metode_pembayaran (kode, nama, aktif, boleh_kembalian, wajib_referensi, biaya_persen, urutan)
+				SELECT 'kartu_hadiah', 'Kartu Hadiah', 1, 0, 1, 0, 7
+				WHERE EXISTS (SELECT 1 FROM metode_pembayaran)
+				  AND NOT EXISTS (SELECT 1 FROM metode_pembayaran WHERE kode = 'kartu_hadiah')`,
+		},
+		{
+			name:  "add_transaksi_item_satuan_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN satuan TEXT DEFAULT ''`,
+		},
+		{
+			// Jumlah baris dijual dalam satuan ini; stok berkurang jumlah x konversi satuan dasar
+			name:  "add_transaksi_item_konversi_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN konversi REAL DEFAULT 1`,
+		},
+		{
+			// Kode PLU timbangan, dicocokkan dengan digit PLU pada label barcode berat/harga
+			name:  "add_produk_plu_column",
+			query: `ALTER TABLE produk ADD COLUMN plu TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_produk_plu_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_produk_plu ON produk(plu)`,
+		},
+		{
+			// Kolom supplier (teks) tetap diisi nama supplier untuk tampilan batch
+			name:  "add_batch_supplier_id_column",
+			query: `ALTER TABLE batch ADD COLUMN supplier_id INTEGER REFERENCES supplier(id)`,
+		},
+		{
+			name:  "add_batch_supplier_id_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_batch_supplier ON batch(supplier_id)`,
+		},
+		{
+			// Nama supplier teks bebas yang hanya beda huruf besar/kecil atau spasi digabung jadi satu supplier.
+			// "Initial Stock" adalah penanda batch awal produk, bukan supplier.
+			name: "migrate_batch_supplier_to_master",
+			query: `INSERT INTO supplier (nama, aktif)
+				SELECT MIN(TRIM(b.supplier)), 1
+				FROM batch b
+				WHERE TRIM(COALESCE(b.supplier, '')) <> '' AND TRIM(b.supplier) <> 'Initial Stock'
+				  AND NOT EXISTS (SELECT 1 FROM supplier s WHERE LOWER(s.nama) = LOWER(TRIM(b.supplier)))
+				GROUP BY LOWER(TRIM(b.supplier))`,
+		},
+		{
+			name: "link_batch_supplier_id",
+			query: `UPDATE batch
+				SET supplier_id = (SELECT s.id FROM supplier s WHERE LOWER(s.nama) = LOWER(TRIM(batch.supplier))),
+				    supplier = (SELECT s.nama FROM supplier s WHERE LOWER(s.nama) = LOWER(TRIM(batch.supplier)))
+				WHERE supplier_id IS NULL
+				  AND EXISTS (SELECT 1 FROM supplier s WHERE LOWER(s.nama) = LOWER(TRIM(batch.supplier)))`,
+		},
+		{
+			// HPP rata-rata bergerak per satuan dasar saat barang dijual, dibekukan untuk laporan
+			name:  "add_transaksi_item_harga_pokok_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN harga_pokok INTEGER`,
+		},
+		{
+			// Penjualan lama tidak menyimpan HPP; harga beli produk saat migrasi adalah perkiraan terbaik
+			name: "backfill_transaksi_item_harga_pokok",
+			query: `UPDATE transaksi_item
+				SET harga_pokok = COALESCE((SELECT p.harga_beli FROM produk p WHERE p.id = transaksi_item.produk_id), 0)
+				WHERE harga_pokok IS NULL`,
+		},
+		{
+			// Harga beli per satuan dari stok yang masuk (0 = tidak dicatat)
+			name:  "add_stok_history_harga_beli_column",
+			query: `ALTER TABLE stok_history ADD COLUMN harga_beli INTEGER DEFAULT 0`,
+		},
+		{
+			// Parameter pesan ulang untuk saran pembelian
+			name:  "add_produk_stok_minimum_column",
+			query: `ALTER TABLE produk ADD COLUMN stok_minimum REAL DEFAULT 0`,
+		},
+		{
+			name:  "add_produk_stok_maksimum_column",
+			query: `ALTER TABLE produk ADD COLUMN stok_maksimum REAL DEFAULT 0`,
+		},
+		{
+			name:  "add_produk_lead_time_hari_column",
+			query: `ALTER TABLE produk ADD COLUMN lead_time_hari INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_produk_supplier_id_column",
+			query: `ALTER TABLE produk ADD COLUMN supplier_id INTEGER REFERENCES supplier(id)`,
+		},
+		{
+			// Urutan pengambilan batch (fifo, fefo, fefo_lewati_kadaluarsa); kosong = ikut kategori, lalu FIFO
+			name:  "add_produk_kebijakan_batch_column",
+			query: `ALTER TABLE produk ADD COLUMN kebijakan_batch TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_kategori_kebijakan_batch_column",
+			query: `ALTER TABLE kategori ADD COLUMN kebijakan_batch TEXT DEFAULT ''`,
+		},
+		{
+			// Penarikan (recall) batch oleh supplier; stok batch yang ditarik tidak dapat dijual
+			name:  "add_batch_ditarik_at_column",
+			query: `ALTER TABLE batch ADD COLUMN ditarik_at DATETIME`,
+		},
+		{
+			name:  "add_batch_alasan_penarikan_column",
+			query: `ALTER TABLE batch ADD COLUMN alasan_penarikan TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_batch_ditarik_oleh_column",
+			query: `ALTER TABLE batch ADD COLUMN ditarik_oleh TEXT DEFAULT ''`,
+		},
+		{
+			// Harga beli per satuan dasar saat batch diterima, untuk nilai kerugian batch
+			name:  "add_batch_harga_beli_column",
+			query: `ALTER TABLE batch ADD COLUMN harga_beli INTEGER DEFAULT 0`,
+		},
+		{
+			// Batch kadaluarsa yang stoknya sudah dihapus tetap disimpan sebagai arsip
+			name:  "add_batch_dihapus_at_column",
+			query: `ALTER TABLE batch ADD COLUMN dihapus_at DATETIME`,
+		},
+		{
+			// Markdown batch hampir kadaluarsa, dicatat terpisah dari diskon baris dan promo
+			name:  "add_transaksi_item_diskon_markdown_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN diskon_markdown INTEGER DEFAULT 0`,
+		},
+		{
+			name:  "add_transaksi_item_keterangan_markdown_column",
+			query: `ALTER TABLE transaksi_item ADD COLUMN keterangan_markdown TEXT DEFAULT ''`,
+		},
+		{
+			// Varian produk: induk dan nilai atribut (JSON, mis. {"Ukuran":"1L"})
+			name:  "add_produk_induk_id_column",
+			query: `ALTER TABLE produk ADD COLUMN induk_id INTEGER REFERENCES produk_induk(id)`,
+		},
+		{
+			name:  "add_produk_induk_id_index",
+			query: `CREATE INDEX IF NOT EXISTS idx_produk_induk ON produk(induk_id)`,
+		},
+		{
+			name:  "add_produk_atribut_varian_column",
+			query: `ALTER TABLE produk ADD COLUMN atribut_varian TEXT DEFAULT ''`,
+		},
+		{
+			name:  "add_idempotency_key_resource_id_column",
+			query: `ALTER TABLE idempotency_key ADD COLUMN resource_id INTEGER`,
+		},
+		{
+			// Periode reset counter (YYYYMMDD/YYYYMM); kolom periode kini berisi awalan nomor yang dirender
+			name:  "add_transaksi_counter_periode_reset_column",
+			query: `ALTER TABLE transaksi_counter ADD COLUMN periode_reset TEXT`,
+		},
+		{
+			// Counter lama dikunci per periode reset, sehingga nilainya menjadi titik awal counter baru
+			name:  "backfill_transaksi_counter_periode_reset",
+			query: `UPDATE transaksi_counter SET periode_reset = periode WHERE periode_reset IS NULL`,
+		},
+		{
+			// Bagian refund yang mengurangi sisa kasbon transaksi, bukan dibayarkan ke pelanggan
+			name:  "add_returns_potong_piutang_column",
+			query: `ALTER TABLE returns ADD COLUMN potong_piutang INTEGER DEFAULT 0`,
+		},
+		{
+			// Baris transaksi yang diretur; produk yang dijual dalam dua satuan punya konversi dan harga per baris
+			name:  "add_return_items_transaksi_item_id_column",
+			query: `ALTER TABLE return_items ADD COLUMN transaksi_item_id INTEGER`,
+		},
+		{
+			// Return lama ditautkan ke barisnya jika produk hanya muncul di satu baris transaksi
+			name: "backfill_return_items_transaksi_item_id",
+			query: `UPDATE return_items SET transaksi_item_id = (
+					SELECT MIN(ti.id) FROM transaksi_item ti JOIN returns r ON r.transaksi_id = ti.transaksi_id
+					WHERE r.id = return_items.return_id AND ti.produk_id = return_items.product_id)
+				WHERE transaksi_item_id IS NULL AND (
+					SELECT COUNT(*) FROM transaksi_item ti JOIN returns r ON r.transaksi_id = ti.transaksi_id
+					WHERE r.id = return_items.return_id AND ti.produk_id = return_items.product_id) = 1`,
+		},
+		{
+			// Penjualan kartu hadiah dicatat terpisah dari penjualan barang agar tidak dihitung sebagai omzet
+			name:  "add_transaksi_jenis_column",
+			query: `ALTER TABLE transaksi ADD COLUMN jenis TEXT DEFAULT 'penjualan'`,
+		},
+		{
+			name: "backfill_transaksi_jenis_kartu_hadiah",
+			query: `UPDATE transaksi SET jenis = 'kartu_hadiah'
+				WHERE id IN (SELECT transaksi_id FROM kartu_hadiah WHERE sumber = 'penjualan' AND transaksi_id IS NOT NULL)`,
+		},
+		{
+			name:  "add_transaksi_void_shift_id_column",
+			query: `ALTER TABLE transaksi ADD COLUMN void_shift_id INTEGER`,
+		},
+		{
+			// Supplier duplikat yang digabung disembunyikan; namanya tetap mengarah ke supplier tujuan
+			name:  "add_supplier_digabung_ke_id_column",
+			query: `ALTER TABLE supplier ADD COLUMN digabung_ke_id INTEGER REFERENCES supplier(id)`,
+		},
+		{
+			name:  "add_supplier_deleted_at_column",
+			query: `ALTER TABLE supplier ADD COLUMN deleted_at DATETIME`,
+		},
 		// PostgreSQL-specific migrations for decimal stock support
 		{
 			name:  "pg_convert_produk_stok_to_decimal",
@@ -20,6 +20,9 @@ type Dialect interface {
 
 	// TranslateAutoIncrement converts AUTOINCREMENT to the dialect-specific syntax
 	TranslateAutoIncrement(query string) string
+
+	// TranslateDataTypes converts SQLite column types to the dialect-specific types
+	TranslateDataTypes(query string) string
 }
 
 // TranslateQuery applies all dialect-specific translations to a query
@@ -27,6 +30,7 @@ func TranslateQuery(query string, d Dialect) string {
 	query = d.TranslatePlaceholders(query)
 	query = d.TranslateDateTimeNow(query)
 	query = d.TranslateAutoIncrement(query)
+	query = d.TranslateDataTypes(query)
 	return query
 }
 
@@ -1,9 +1,14 @@
 package dialect
 
 import (
+	"regexp"
 	"strings"
 )
 
+// datetimeTypePattern matches the SQLite DATETIME column type (uppercase only,
+// so datetime() function calls are left alone)
+var datetimeTypePattern = regexp.MustCompile(`\bDATETIME\b`)
+
 // PostgreSQLDialect implements the Dialect interface for PostgreSQL database
 type PostgreSQLDialect struct{}
 
@@ -51,3 +56,9 @@ func (d *PostgreSQLDialect) TranslateAutoIncrement(query string) string {
 
 	return query
 }
+
+// TranslateDataTypes converts SQLite column types to PostgreSQL equivalents
+func (d *PostgreSQLDialect) TranslateDataTypes(query string) string {
+	// PostgreSQL has no DATETIME type, use TIMESTAMP instead
+	return datetimeTypePattern.ReplaceAllString(query, "TIMESTAMP")
+}
@@ -27,3 +27,9 @@ func (d *SQLiteDialect) TranslateAutoIncrement(query string) string {
 	// No translation needed
 	return query
 }
+
+// TranslateDataTypes returns the query as-is since it already uses SQLite types
+func (d *SQLiteDialect) TranslateDataTypes(query string) string {
+	// No translation needed
+	return query
+}
@@ -21,8 +21,9 @@ func (h *AnalyticsHandler) GetTopProducts(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	perInduk := c.Query("group_by") == "induk" // Gabungkan varian per produk induk
 
-	products, err := h.services.AnalyticsService.GetTopProducts(startDate, endDate, limit)
+	products, err := h.services.AnalyticsService.GetTopProducts(startDate, endDate, limit, perInduk)
 	if err != nil {
 		response.InternalServerError(c, "Failed to get top products", err)
 		return
@@ -57,8 +58,9 @@ func (h *AnalyticsHandler) GetSalesTrend(c *gin.Context) {
 func (h *AnalyticsHandler) GetCategoryBreakdown(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
+	perInduk := c.Query("group_by") == "induk" // Varian dihitung dalam kategori produk induknya
 
-	breakdown, err := h.services.AnalyticsService.GetCategoryBreakdown(startDate, endDate)
+	breakdown, err := h.services.AnalyticsService.GetCategoryBreakdown(startDate, endDate, perInduk)
 	if err != nil {
 		response.InternalServerError(c, "Failed to get category breakdown", err)
 		return
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AturanMarkdownHandler handles markdown rule HTTP requests
+type AturanMarkdownHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewAturanMarkdownHandler creates a new AturanMarkdownHandler instance
+func NewAturanMarkdownHandler(services *container.ServiceContainer) *AturanMarkdownHandler {
+	return &AturanMarkdownHandler{services: services}
+}
+
+// GetAll retrieves all near-expiry markdown rules
+func (h *AturanMarkdownHandler) GetAll(c *gin.Context) {
+	list, err := h.services.MarkdownService.GetAllAturan()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get near-expiry markdown rules", err)
+		return
+	}
+	response.Success(c, list, "Markdown rules retrieved successfully")
+}
+
+// Create adds a markdown rule
+func (h *AturanMarkdownHandler) Create(c *gin.Context) {
+	var aturan models.AturanMarkdown
+	if err := c.ShouldBindJSON(&aturan); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.MarkdownService.CreateAturan(&aturan); err != nil {
+		response.BadRequest(c, "Failed to create markdown rule", err)
+		return
+	}
+	response.Success(c, aturan, "Markdown rule created successfully")
+}
+
+// Update updates a markdown rule
+func (h *AturanMarkdownHandler) Update(c *gin.Context) {
+	var aturan models.AturanMarkdown
+	if err := c.ShouldBindJSON(&aturan); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.MarkdownService.UpdateAturan(&aturan); err != nil {
+		response.BadRequest(c, "Failed to update markdown rule", err)
+		return
+	}
+	response.Success(c, aturan, "Markdown rule updated successfully")
+}
+
+// Delete removes a markdown rule
+func (h *AturanMarkdownHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid rule ID", err)
+		return
+	}
+	if err := h.services.MarkdownService.DeleteAturan(id); err != nil {
+		response.BadRequest(c, "Failed to delete markdown rule", err)
+		return
+	}
+	response.Success(c, nil, "Markdown rule deleted successfully")
+}
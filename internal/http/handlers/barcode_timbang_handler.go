@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BarcodeTimbangHandler handles scale label rule HTTP requests
+type BarcodeTimbangHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewBarcodeTimbangHandler creates a new BarcodeTimbangHandler instance
+func NewBarcodeTimbangHandler(services *container.ServiceContainer) *BarcodeTimbangHandler {
+	return &BarcodeTimbangHandler{services: services}
+}
+
+// GetAll retrieves all scale label rules
+func (h *BarcodeTimbangHandler) GetAll(c *gin.Context) {
+	list, err := h.services.TimbangService.GetAllAturan()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get scale label rules", err)
+		return
+	}
+	response.Success(c, list, "Scale label rules retrieved successfully")
+}
+
+// Create adds a scale label rule
+func (h *BarcodeTimbangHandler) Create(c *gin.Context) {
+	var aturan models.AturanBarcodeTimbang
+	if err := c.ShouldBindJSON(&aturan); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.TimbangService.CreateAturan(&aturan); err != nil {
+		response.BadRequest(c, "Failed to create scale label rule", err)
+		return
+	}
+	response.Success(c, aturan, "Scale label rule created successfully")
+}
+
+// Update updates a scale label rule
+func (h *BarcodeTimbangHandler) Update(c *gin.Context) {
+	var aturan models.AturanBarcodeTimbang
+	if err := c.ShouldBindJSON(&aturan); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.TimbangService.UpdateAturan(&aturan); err != nil {
+		response.BadRequest(c, "Failed to update scale label rule", err)
+		return
+	}
+	response.Success(c, aturan, "Scale label rule updated successfully")
+}
+
+// Delete removes a scale label rule
+func (h *BarcodeTimbangHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid rule ID", err)
+		return
+	}
+	if err := h.services.TimbangService.DeleteAturan(id); err != nil {
+		response.BadRequest(c, "Failed to delete scale label rule", err)
+		return
+	}
+	response.Success(c, nil, "Scale label rule deleted successfully")
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"fmt"
+	"net/http"
 	"strconv"
 
 	"ritel-app/internal/container"
 	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -94,3 +97,47 @@ func (h *BatchHandler) UpdateStatuses(c *gin.Context) {
 	}
 	response.Success(c, nil, "Batch statuses updated successfully")
 }
+
+func (h *BatchHandler) ProsesKadaluarsa(c *gin.Context) {
+	hasil, err := h.services.BatchService.ProsesKadaluarsa()
+	if err != nil {
+		response.InternalServerError(c, "Failed to process expired batches", err)
+		return
+	}
+	response.Success(c, hasil, "Expired batches processed successfully")
+}
+
+func (h *BatchHandler) Tarik(c *gin.Context) {
+	var req models.TarikBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	req.BatchID = c.Param("id")
+
+	penarikan, err := h.services.BatchService.TarikBatch(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to recall batch", err)
+		return
+	}
+	response.Success(c, penarikan, "Batch recalled successfully")
+}
+
+func (h *BatchHandler) GetPenarikan(c *gin.Context) {
+	penarikan, err := h.services.BatchService.GetPenarikanBatch(c.Param("id"))
+	if err != nil {
+		response.NotFound(c, "Batch not found")
+		return
+	}
+	response.Success(c, penarikan, "Batch recall list retrieved successfully")
+}
+
+func (h *BatchHandler) ExportPenarikan(c *gin.Context) {
+	data, filename, err := h.services.BatchService.ExportPenarikanBatch(c.Param("id"))
+	if err != nil {
+		response.NotFound(c, "Batch not found")
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
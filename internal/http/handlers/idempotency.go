@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader is the header clients send to make retried writes safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// bindIdempotencyKey copies the Idempotency-Key header into the request body field.
+// The header takes precedence over a key sent in the body.
+func bindIdempotencyKey(c *gin.Context, key *string) {
+	if header := c.GetHeader(IdempotencyKeyHeader); header != "" {
+		*key = header
+	}
+}
+
+// respondIdempotencyError writes the response for idempotency key conflicts and
+// reports whether err was one of them
+func respondIdempotencyError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		response.Error(c, http.StatusUnprocessableEntity, "Idempotency key reused with a different payload", err)
+		return true
+	case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+		response.Error(c, http.StatusConflict, "Request with this idempotency key is still being processed", err)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ritel-app/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespondIdempotencyError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantHandled bool
+		wantCode    int
+	}{
+		{"payload mismatch", service.ErrIdempotencyKeyReused, true, http.StatusUnprocessableEntity},
+		{"wrapped payload mismatch", fmt.Errorf("return: %w", service.ErrIdempotencyKeyReused), true, http.StatusUnprocessableEntity},
+		{"still processing", service.ErrIdempotencyKeyInProgress, true, http.StatusConflict},
+		{"other error", errors.New("stok tidak cukup"), false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			handled := respondIdempotencyError(c, tt.err)
+
+			assert.Equal(t, tt.wantHandled, handled)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestBindIdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		body   string
+		want   string
+	}{
+		{"header overrides body", "from-header", "from-body", "from-header"},
+		{"body kept without header", "", "from-body", "from-body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set(IdempotencyKeyHeader, tt.header)
+			}
+
+			key := tt.body
+			bindIdempotencyKey(c, &key)
+
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KartuHadiahHandler handles gift card and store credit HTTP requests
+type KartuHadiahHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewKartuHadiahHandler creates a new KartuHadiahHandler instance
+func NewKartuHadiahHandler(services *container.ServiceContainer) *KartuHadiahHandler {
+	return &KartuHadiahHandler{services: services}
+}
+
+// GetAll lists gift cards, optionally filtered by status
+func (h *KartuHadiahHandler) GetAll(c *gin.Context) {
+	list, err := h.services.KartuHadiahService.GetAllKartuHadiah(c.Query("status"))
+	if err != nil {
+		response.BadRequest(c, "Failed to get gift cards", err)
+		return
+	}
+
+	response.Success(c, list, "Gift cards retrieved successfully")
+}
+
+// GetByKode retrieves a gift card with its balance history
+func (h *KartuHadiahHandler) GetByKode(c *gin.Context) {
+	detail, err := h.services.KartuHadiahService.GetKartuHadiah(c.Param("kode"))
+	if err != nil {
+		response.NotFound(c, "Gift card not found")
+		return
+	}
+
+	response.Success(c, detail, "Gift card retrieved successfully")
+}
+
+// Jual sells a new gift card
+func (h *KartuHadiahHandler) Jual(c *gin.Context) {
+	var req models.JualKartuHadiahRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.services.KartuHadiahService.JualKartuHadiah(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to sell gift card", err)
+		return
+	}
+
+	response.Success(c, result, "Gift card sold successfully")
+}
+
+// Kedaluwarsakan forfeits the balance of gift cards past their expiry date
+func (h *KartuHadiahHandler) Kedaluwarsakan(c *gin.Context) {
+	result, err := h.services.KartuHadiahService.KedaluwarsakanKartuHadiah()
+	if err != nil {
+		response.InternalServerError(c, "Failed to expire gift cards", err)
+		return
+	}
+
+	response.Success(c, result, "Expired gift cards processed successfully")
+}
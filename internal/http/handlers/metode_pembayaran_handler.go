@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetodePembayaranHandler handles payment method registry HTTP requests
+type MetodePembayaranHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewMetodePembayaranHandler creates a new MetodePembayaranHandler instance
+func NewMetodePembayaranHandler(services *container.ServiceContainer) *MetodePembayaranHandler {
+	return &MetodePembayaranHandler{services: services}
+}
+
+// GetAll retrieves all payment methods, including inactive ones
+func (h *MetodePembayaranHandler) GetAll(c *gin.Context) {
+	list, err := h.services.MetodeService.GetAllMetodePembayaran()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get payment methods", err)
+		return
+	}
+	response.Success(c, list, "Payment methods retrieved successfully")
+}
+
+// GetAktif retrieves the payment methods available at the cashier
+func (h *MetodePembayaranHandler) GetAktif(c *gin.Context) {
+	list, err := h.services.MetodeService.GetMetodePembayaranAktif()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get active payment methods", err)
+		return
+	}
+	response.Success(c, list, "Active payment methods retrieved successfully")
+}
+
+// Create adds a payment method to the registry
+func (h *MetodePembayaranHandler) Create(c *gin.Context) {
+	var metode models.MetodePembayaran
+	if err := c.ShouldBindJSON(&metode); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.MetodeService.CreateMetodePembayaran(&metode); err != nil {
+		response.BadRequest(c, "Failed to create payment method", err)
+		return
+	}
+	response.Success(c, metode, "Payment method created successfully")
+}
+
+// Update updates a payment method
+func (h *MetodePembayaranHandler) Update(c *gin.Context) {
+	var metode models.MetodePembayaran
+	if err := c.ShouldBindJSON(&metode); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.MetodeService.UpdateMetodePembayaran(&metode); err != nil {
+		response.BadRequest(c, "Failed to update payment method", err)
+		return
+	}
+	response.Success(c, metode, "Payment method updated successfully")
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PiutangHandler handles customer receivable (kasbon) HTTP requests
+type PiutangHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewPiutangHandler creates a new PiutangHandler instance
+func NewPiutangHandler(services *container.ServiceContainer) *PiutangHandler {
+	return &PiutangHandler{services: services}
+}
+
+// GetAll lists receivables, optionally filtered by status
+func (h *PiutangHandler) GetAll(c *gin.Context) {
+	piutang, err := h.services.PiutangService.GetAllPiutang(c.Query("status"))
+	if err != nil {
+		response.BadRequest(c, "Failed to get receivables", err)
+		return
+	}
+
+	response.Success(c, piutang, "Receivables retrieved successfully")
+}
+
+// GetAging retrieves the outstanding receivables grouped by age
+func (h *PiutangHandler) GetAging(c *gin.Context) {
+	laporan, err := h.services.PiutangService.GetAgingPiutang()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get receivable aging", err)
+		return
+	}
+
+	response.Success(c, laporan, "Receivable aging retrieved successfully")
+}
+
+// GetByPelanggan retrieves the receivable ledger of a customer
+func (h *PiutangHandler) GetByPelanggan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID", err)
+		return
+	}
+
+	ledger, err := h.services.PiutangService.GetPiutangPelanggan(id)
+	if err != nil {
+		response.NotFound(c, "Customer not found")
+		return
+	}
+
+	response.Success(c, ledger, "Customer receivables retrieved successfully")
+}
+
+// Bayar records a (partial) repayment by a customer
+func (h *PiutangHandler) Bayar(c *gin.Context) {
+	var req models.BayarPiutangRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.services.PiutangService.BayarPiutang(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to record repayment", err)
+		return
+	}
+
+	response.Success(c, result, "Repayment recorded successfully")
+}
+
+// UpdateBatasKredit sets the credit limit of a customer
+func (h *PiutangHandler) UpdateBatasKredit(c *gin.Context) {
+	var req models.UpdateBatasKreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	pelanggan, err := h.services.PiutangService.UpdateBatasKredit(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update credit limit", err)
+		return
+	}
+
+	response.Success(c, pelanggan, "Credit limit updated successfully")
+}
@@ -100,6 +100,103 @@ func (h *ProdukHandler) ScanBarcode(c *gin.Context) {
 	response.Success(c, result, "Barcode scanned successfully")
 }
 
+// GetByBarcode resolves a barcode to its product and the unit that was scanned
+func (h *ProdukHandler) GetByBarcode(c *gin.Context) {
+	result, err := h.services.ProdukService.CariBarcode(c.Param("barcode"))
+	if err != nil {
+		response.BadRequest(c, "Failed to find barcode", err)
+		return
+	}
+	if result == nil {
+		response.NotFound(c, "Product not found")
+		return
+	}
+
+	response.Success(c, result, "Product retrieved successfully")
+}
+
+// GetSatuan retrieves the additional units of measure of a product
+func (h *ProdukHandler) GetSatuan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID", err)
+		return
+	}
+
+	satuan, err := h.services.ProdukService.GetSatuanProduk(id)
+	if err != nil {
+		response.NotFound(c, "Product not found")
+		return
+	}
+
+	response.Success(c, satuan, "Product units retrieved successfully")
+}
+
+// SimpanSatuan replaces the additional units of measure of a product
+func (h *ProdukHandler) SimpanSatuan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID", err)
+		return
+	}
+
+	var req models.SimpanProdukSatuanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	req.ProdukID = id
+
+	satuan, err := h.services.ProdukService.SimpanSatuanProduk(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to save product units", err)
+		return
+	}
+
+	response.Success(c, satuan, "Product units saved successfully")
+}
+
+// GetKomponen retrieves the components of a kit product
+func (h *ProdukHandler) GetKomponen(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID", err)
+		return
+	}
+
+	komponen, err := h.services.ProdukService.GetKomponenProduk(id)
+	if err != nil {
+		response.NotFound(c, "Product not found")
+		return
+	}
+
+	response.Success(c, komponen, "Kit components retrieved successfully")
+}
+
+// SimpanKomponen replaces the components of a kit product
+func (h *ProdukHandler) SimpanKomponen(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid product ID", err)
+		return
+	}
+
+	var req models.SimpanProdukKomponenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	req.ProdukID = id
+
+	komponen, err := h.services.ProdukService.SimpanKomponenProduk(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to save kit components", err)
+		return
+	}
+
+	response.Success(c, komponen, "Kit components saved successfully")
+}
+
 // UpdateStok updates product stock
 func (h *ProdukHandler) UpdateStok(c *gin.Context) {
 	var req models.UpdateStokRequest
@@ -107,8 +204,12 @@ func (h *ProdukHandler) UpdateStok(c *gin.Context) {
 		response.BadRequest(c, "Invalid request body", err)
 		return
 	}
+	bindIdempotencyKey(c, &req.IdempotencyKey)
 
 	if err := h.services.ProdukService.UpdateStok(&req); err != nil {
+		if respondIdempotencyError(c, err) {
+			return
+		}
 		response.BadRequest(c, "Failed to update stock", err)
 		return
 	}
@@ -123,8 +224,12 @@ func (h *ProdukHandler) UpdateStokIncrement(c *gin.Context) {
 		response.BadRequest(c, "Invalid request body", err)
 		return
 	}
+	bindIdempotencyKey(c, &req.IdempotencyKey)
 
 	if err := h.services.ProdukService.UpdateStokIncrement(&req); err != nil {
+		if respondIdempotencyError(c, err) {
+			return
+		}
 		response.BadRequest(c, "Failed to update stock increment", err)
 		return
 	}
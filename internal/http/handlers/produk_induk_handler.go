@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProdukIndukHandler handles parent product and variant matrix HTTP requests
+type ProdukIndukHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewProdukIndukHandler creates a new ProdukIndukHandler instance
+func NewProdukIndukHandler(services *container.ServiceContainer) *ProdukIndukHandler {
+	return &ProdukIndukHandler{services: services}
+}
+
+// GetAll retrieves all parent products with the aggregated stock of their variants
+func (h *ProdukIndukHandler) GetAll(c *gin.Context) {
+	list, err := h.services.ProdukIndukService.GetAllInduk()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get parent products", err)
+		return
+	}
+	response.Success(c, list, "Parent products retrieved successfully")
+}
+
+// GetByID retrieves a parent product with its variants
+func (h *ProdukIndukHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid parent product ID", err)
+		return
+	}
+
+	induk, err := h.services.ProdukIndukService.GetInduk(id)
+	if err != nil {
+		response.NotFound(c, "Parent product not found")
+		return
+	}
+	response.Success(c, induk, "Parent product retrieved successfully")
+}
+
+// Create creates a parent product with its variant matrix
+func (h *ProdukIndukHandler) Create(c *gin.Context) {
+	var req models.SimpanProdukIndukRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	induk, err := h.services.ProdukIndukService.CreateInduk(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to create parent product", err)
+		return
+	}
+	response.Success(c, induk, "Parent product created successfully")
+}
+
+// Update edits a parent product and replaces its variant matrix
+func (h *ProdukIndukHandler) Update(c *gin.Context) {
+	var req models.SimpanProdukIndukRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	induk, err := h.services.ProdukIndukService.UpdateInduk(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update parent product", err)
+		return
+	}
+	response.Success(c, induk, "Parent product updated successfully")
+}
+
+// Delete removes a parent product; its variants stay as standalone products
+func (h *ProdukIndukHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid parent product ID", err)
+		return
+	}
+	if err := h.services.ProdukIndukService.DeleteInduk(id); err != nil {
+		response.BadRequest(c, "Failed to delete parent product", err)
+		return
+	}
+	response.Success(c, nil, "Parent product deleted successfully")
+}
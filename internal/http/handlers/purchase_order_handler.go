@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseOrderHandler handles purchase order and goods receipt HTTP requests
+type PurchaseOrderHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewPurchaseOrderHandler creates a new PurchaseOrderHandler instance
+func NewPurchaseOrderHandler(services *container.ServiceContainer) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{services: services}
+}
+
+// GetAll retrieves purchase orders, optionally filtered with ?status=
+func (h *PurchaseOrderHandler) GetAll(c *gin.Context) {
+	list, err := h.services.POService.GetAllPurchaseOrder(c.Query("status"))
+	if err != nil {
+		response.InternalServerError(c, "Failed to get purchase orders", err)
+		return
+	}
+	response.Success(c, list, "Purchase orders retrieved successfully")
+}
+
+// GetByID retrieves a purchase order with its lines and goods receipts
+func (h *PurchaseOrderHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid purchase order ID", err)
+		return
+	}
+	po, err := h.services.POService.GetPurchaseOrderByID(id)
+	if err != nil {
+		response.NotFound(c, "Purchase order not found")
+		return
+	}
+	response.Success(c, po, "Purchase order retrieved successfully")
+}
+
+// GetSaran proposes order quantities per supplier for products at their reorder point,
+// with optional ?hari_analisis=, ?hari_cakupan= and ?supplier_id=
+func (h *PurchaseOrderHandler) GetSaran(c *gin.Context) {
+	hariAnalisis, _ := strconv.Atoi(c.Query("hari_analisis"))
+	hariCakupan, _ := strconv.Atoi(c.Query("hari_cakupan"))
+	supplierID, _ := strconv.Atoi(c.Query("supplier_id"))
+
+	saran, err := h.services.POService.GetSaranPembelian(&models.SaranPembelianRequest{
+		HariAnalisis: hariAnalisis,
+		HariCakupan:  hariCakupan,
+		SupplierID:   supplierID,
+	})
+	if err != nil {
+		response.BadRequest(c, "Failed to get purchase suggestions", err)
+		return
+	}
+	response.Success(c, saran, "Purchase suggestions retrieved successfully")
+}
+
+// Simpan creates a draft purchase order or replaces the lines of a draft
+func (h *PurchaseOrderHandler) Simpan(c *gin.Context) {
+	var req models.SimpanPurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	po, err := h.services.POService.SimpanPurchaseOrder(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to save purchase order", err)
+		return
+	}
+	response.Success(c, po, "Purchase order saved successfully")
+}
+
+// Kirim marks a draft purchase order as sent to the supplier
+func (h *PurchaseOrderHandler) Kirim(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid purchase order ID", err)
+		return
+	}
+	po, err := h.services.POService.KirimPurchaseOrder(id)
+	if err != nil {
+		response.BadRequest(c, "Failed to send purchase order", err)
+		return
+	}
+	response.Success(c, po, "Purchase order sent successfully")
+}
+
+// Terima records goods received for a purchase order
+func (h *PurchaseOrderHandler) Terima(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid purchase order ID", err)
+		return
+	}
+	var req models.TerimaBarangRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	req.POID = id
+
+	penerimaan, err := h.services.POService.TerimaBarang(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to receive goods", err)
+		return
+	}
+	response.Success(c, penerimaan, "Goods received successfully")
+}
+
+// Tutup closes a purchase order that will not be received further
+func (h *PurchaseOrderHandler) Tutup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid purchase order ID", err)
+		return
+	}
+	po, err := h.services.POService.TutupPurchaseOrder(id)
+	if err != nil {
+		response.BadRequest(c, "Failed to close purchase order", err)
+		return
+	}
+	response.Success(c, po, "Purchase order closed successfully")
+}
+
+// Delete removes a draft purchase order
+func (h *PurchaseOrderHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid purchase order ID", err)
+		return
+	}
+	if err := h.services.POService.DeletePurchaseOrder(id); err != nil {
+		response.BadRequest(c, "Failed to delete purchase order", err)
+		return
+	}
+	response.Success(c, nil, "Purchase order deleted successfully")
+}
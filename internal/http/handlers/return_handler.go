@@ -47,9 +47,14 @@ func (h *ReturnHandler) Create(c *gin.Context) {
 		response.BadRequest(c, "Invalid request body", err)
 		return
 	}
+	bindIdempotencyKey(c, &req.IdempotencyKey)
 	if err := h.services.ReturnService.CreateReturn(&req); err != nil {
+		if respondIdempotencyError(c, err) {
+			return
+		}
 		response.BadRequest(c, "Failed to create return", err)
 		return
 	}
-	response.Success(c, nil, "Return created successfully")
+	// Store credit refunds return the issued gift card so its code can be handed over
+	response.Success(c, req.KartuHadiah, "Return created successfully")
 }
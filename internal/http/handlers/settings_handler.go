@@ -39,3 +39,147 @@ func (h *SettingsHandler) UpdatePoinSettings(c *gin.Context) {
 	}
 	response.Success(c, settings, "Point settings updated successfully")
 }
+
+func (h *SettingsHandler) GetNomorTransaksiSettings(c *gin.Context) {
+	settings, err := h.services.SettingsService.GetNomorTransaksiSettings()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get transaction numbering settings", err)
+		return
+	}
+	response.Success(c, settings, "Transaction numbering settings retrieved successfully")
+}
+
+func (h *SettingsHandler) UpdateNomorTransaksiSettings(c *gin.Context) {
+	var req models.UpdateNomorTransaksiSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.services.SettingsService.UpdateNomorTransaksiSettings(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update transaction numbering settings", err)
+		return
+	}
+	response.Success(c, settings, "Transaction numbering settings updated successfully")
+}
+
+func (h *SettingsHandler) GetPajakSettings(c *gin.Context) {
+	settings, err := h.services.SettingsService.GetPajakSettings()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get tax settings", err)
+		return
+	}
+	response.Success(c, settings, "Tax settings retrieved successfully")
+}
+
+func (h *SettingsHandler) UpdatePajakSettings(c *gin.Context) {
+	var req models.UpdatePajakSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.services.SettingsService.UpdatePajakSettings(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update tax settings", err)
+		return
+	}
+	response.Success(c, settings, "Tax settings updated successfully")
+}
+
+func (h *SettingsHandler) GetHargaSettings(c *gin.Context) {
+	settings, err := h.services.SettingsService.GetHargaSettings()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get price override settings", err)
+		return
+	}
+	response.Success(c, settings, "Price override settings retrieved successfully")
+}
+
+func (h *SettingsHandler) UpdateHargaSettings(c *gin.Context) {
+	var req models.UpdateHargaSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.services.SettingsService.UpdateHargaSettings(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update price override settings", err)
+		return
+	}
+	response.Success(c, settings, "Price override settings updated successfully")
+}
+
+func (h *SettingsHandler) GetPembulatanSettings(c *gin.Context) {
+	settings, err := h.services.SettingsService.GetPembulatanSettings()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get rounding settings", err)
+		return
+	}
+	response.Success(c, settings, "Rounding settings retrieved successfully")
+}
+
+func (h *SettingsHandler) UpdatePembulatanSettings(c *gin.Context) {
+	var req models.UpdatePembulatanSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.services.SettingsService.UpdatePembulatanSettings(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update rounding settings", err)
+		return
+	}
+	response.Success(c, settings, "Rounding settings updated successfully")
+}
+
+func (h *SettingsHandler) GetKartuHadiahSettings(c *gin.Context) {
+	settings, err := h.services.SettingsService.GetKartuHadiahSettings()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get gift card settings", err)
+		return
+	}
+	response.Success(c, settings, "Gift card settings retrieved successfully")
+}
+
+func (h *SettingsHandler) UpdateKartuHadiahSettings(c *gin.Context) {
+	var req models.UpdateKartuHadiahSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.services.SettingsService.UpdateKartuHadiahSettings(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update gift card settings", err)
+		return
+	}
+	response.Success(c, settings, "Gift card settings updated successfully")
+}
+
+func (h *SettingsHandler) GetBatchKadaluarsaSettings(c *gin.Context) {
+	settings, err := h.services.SettingsService.GetBatchKadaluarsaSettings()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get expired batch settings", err)
+		return
+	}
+	response.Success(c, settings, "Expired batch settings retrieved successfully")
+}
+
+func (h *SettingsHandler) UpdateBatchKadaluarsaSettings(c *gin.Context) {
+	var req models.UpdateBatchKadaluarsaSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.services.SettingsService.UpdateBatchKadaluarsaSettings(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to update expired batch settings", err)
+		return
+	}
+	response.Success(c, settings, "Expired batch settings updated successfully")
+}
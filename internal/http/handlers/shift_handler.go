@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShiftHandler handles cashier shift HTTP requests
+type ShiftHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewShiftHandler creates a new ShiftHandler instance
+func NewShiftHandler(services *container.ServiceContainer) *ShiftHandler {
+	return &ShiftHandler{services: services}
+}
+
+// GetAll lists shifts, optionally filtered by kode_terminal
+func (h *ShiftHandler) GetAll(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	shifts, err := h.services.ShiftService.GetAllShift(c.Query("kode_terminal"), limit, offset)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get shifts", err)
+		return
+	}
+
+	response.Success(c, shifts, "Shifts retrieved successfully")
+}
+
+// GetAktif retrieves the open shift of a terminal (null when no shift is open)
+func (h *ShiftHandler) GetAktif(c *gin.Context) {
+	shift, err := h.services.ShiftService.GetShiftAktif(c.Query("kode_terminal"))
+	if err != nil {
+		response.BadRequest(c, "Failed to get active shift", err)
+		return
+	}
+
+	response.Success(c, shift, "Active shift retrieved successfully")
+}
+
+// Buka opens a shift with the opening cash
+func (h *ShiftHandler) Buka(c *gin.Context) {
+	var req models.BukaShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	shift, err := h.services.ShiftService.BukaShift(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to open shift", err)
+		return
+	}
+
+	response.Success(c, shift, "Shift opened successfully")
+}
+
+// MutasiKas records cash put into or taken out of the drawer
+func (h *ShiftHandler) MutasiKas(c *gin.Context) {
+	var req models.MutasiKasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	mutasi, err := h.services.ShiftService.CatatMutasiKas(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to record cash movement", err)
+		return
+	}
+
+	response.Success(c, mutasi, "Cash movement recorded successfully")
+}
+
+// Tutup closes the open shift with the counted cash and returns the Z report
+func (h *ShiftHandler) Tutup(c *gin.Context) {
+	var req models.TutupShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	laporan, err := h.services.ShiftService.TutupShift(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to close shift", err)
+		return
+	}
+
+	response.Success(c, laporan, "Shift closed successfully")
+}
+
+// GetLaporan retrieves the X report (open shift) or Z report (closed shift)
+func (h *ShiftHandler) GetLaporan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid shift ID", err)
+		return
+	}
+
+	laporan, err := h.services.ShiftService.GetLaporanShift(id)
+	if err != nil {
+		response.NotFound(c, "Shift not found")
+		return
+	}
+
+	response.Success(c, laporan, "Shift report retrieved successfully")
+}
+
+// PrintLaporan prints the X or Z report of a shift
+func (h *ShiftHandler) PrintLaporan(c *gin.Context) {
+	var req models.PrintLaporanShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	if err := h.services.PrinterService.PrintLaporanShift(&req); err != nil {
+		response.InternalServerError(c, "Failed to print shift report", err)
+		return
+	}
+
+	response.Success(c, nil, "Shift report printed successfully")
+}
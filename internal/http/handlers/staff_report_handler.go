@@ -92,6 +92,31 @@ func (h *StaffReportHandler) GetAllStaffReports(c *gin.Context) {
 	response.Success(c, reports, "All staff reports retrieved successfully")
 }
 
+// GetOverrideHarga lists manual price overrides and line discounts per staff
+func (h *StaffReportHandler) GetOverrideHarga(c *gin.Context) {
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid start date format", err)
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid end date format", err)
+		return
+	}
+
+	laporan, err := h.services.StaffReportService.GetLaporanOverrideHarga(startDate, endDate)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get price override report", err)
+		return
+	}
+	response.Success(c, laporan, "Price override report retrieved successfully")
+}
+
 func (h *StaffReportHandler) GetAllWithTrend(c *gin.Context) {
 	reports, err := h.services.StaffReportService.GetAllStaffReportsWithTrend()
 	if err != nil {
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StokOpnameHandler handles stock opname (physical inventory count) HTTP requests
+type StokOpnameHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewStokOpnameHandler creates a new StokOpnameHandler instance
+func NewStokOpnameHandler(services *container.ServiceContainer) *StokOpnameHandler {
+	return &StokOpnameHandler{services: services}
+}
+
+// GetAll retrieves stock opname sessions, optionally filtered with ?status=
+func (h *StokOpnameHandler) GetAll(c *gin.Context) {
+	list, err := h.services.OpnameService.GetAllOpname(c.Query("status"))
+	if err != nil {
+		response.InternalServerError(c, "Failed to get stock opname", err)
+		return
+	}
+	response.Success(c, list, "Stock opname retrieved successfully")
+}
+
+// GetByID retrieves a stock opname session with its items and variances
+func (h *StokOpnameHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid stock opname ID", err)
+		return
+	}
+	opname, err := h.services.OpnameService.GetOpnameByID(id)
+	if err != nil {
+		response.NotFound(c, "Stock opname not found")
+		return
+	}
+	response.Success(c, opname, "Stock opname retrieved successfully")
+}
+
+// Mulai starts a stock opname and freezes the system stock
+func (h *StokOpnameHandler) Mulai(c *gin.Context) {
+	var req models.MulaiOpnameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	opname, err := h.services.OpnameService.MulaiOpname(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to start stock opname", err)
+		return
+	}
+	response.Success(c, opname, "Stock opname started successfully")
+}
+
+// Hitung records a counted quantity by product ID or barcode scan
+func (h *StokOpnameHandler) Hitung(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid stock opname ID", err)
+		return
+	}
+	var req models.HitungOpnameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	req.OpnameID = id
+
+	item, err := h.services.OpnameService.HitungOpname(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to record count", err)
+		return
+	}
+	response.Success(c, item, "Count recorded successfully")
+}
+
+// Setujui approves a stock opname and posts its variances to stock
+func (h *StokOpnameHandler) Setujui(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid stock opname ID", err)
+		return
+	}
+	var req models.SetujuiOpnameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	req.OpnameID = id
+
+	opname, err := h.services.OpnameService.SetujuiOpname(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to approve stock opname", err)
+		return
+	}
+	response.Success(c, opname, "Stock opname approved successfully")
+}
+
+// Batalkan cancels a running stock opname without changing stock
+func (h *StokOpnameHandler) Batalkan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid stock opname ID", err)
+		return
+	}
+	opname, err := h.services.OpnameService.BatalkanOpname(id)
+	if err != nil {
+		response.BadRequest(c, "Failed to cancel stock opname", err)
+		return
+	}
+	response.Success(c, opname, "Stock opname cancelled successfully")
+}
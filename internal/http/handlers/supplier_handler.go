@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler handles supplier master data HTTP requests
+type SupplierHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewSupplierHandler creates a new SupplierHandler instance
+func NewSupplierHandler(services *container.ServiceContainer) *SupplierHandler {
+	return &SupplierHandler{services: services}
+}
+
+// GetAll retrieves all suppliers, including inactive ones
+func (h *SupplierHandler) GetAll(c *gin.Context) {
+	list, err := h.services.SupplierService.GetAllSupplier()
+	if err != nil {
+		response.InternalServerError(c, "Failed to get suppliers", err)
+		return
+	}
+	response.Success(c, list, "Suppliers retrieved successfully")
+}
+
+// GetByID retrieves a supplier by ID
+func (h *SupplierHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID", err)
+		return
+	}
+	supplier, err := h.services.SupplierService.GetSupplierByID(id)
+	if err != nil {
+		response.NotFound(c, "Supplier not found")
+		return
+	}
+	response.Success(c, supplier, "Supplier retrieved successfully")
+}
+
+// Create adds a supplier
+func (h *SupplierHandler) Create(c *gin.Context) {
+	var supplier models.Supplier
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.SupplierService.CreateSupplier(&supplier); err != nil {
+		response.BadRequest(c, "Failed to create supplier", err)
+		return
+	}
+	response.Success(c, supplier, "Supplier created successfully")
+}
+
+// Update updates a supplier
+func (h *SupplierHandler) Update(c *gin.Context) {
+	var supplier models.Supplier
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	if err := h.services.SupplierService.UpdateSupplier(&supplier); err != nil {
+		response.BadRequest(c, "Failed to update supplier", err)
+		return
+	}
+	response.Success(c, supplier, "Supplier updated successfully")
+}
+
+// Gabung merges a duplicate supplier into the supplier that is kept
+func (h *SupplierHandler) Gabung(c *gin.Context) {
+	var req models.GabungSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	supplier, err := h.services.SupplierService.GabungSupplier(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to merge suppliers", err)
+		return
+	}
+	response.Success(c, supplier, "Suppliers merged successfully")
+}
+
+// Delete removes a supplier that has no batches
+func (h *SupplierHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID", err)
+		return
+	}
+	if err := h.services.SupplierService.DeleteSupplier(id); err != nil {
+		response.BadRequest(c, "Failed to delete supplier", err)
+		return
+	}
+	response.Success(c, nil, "Supplier deleted successfully")
+}
+
+// GetLaporan summarizes the stock received, expired and written off per supplier
+func (h *SupplierHandler) GetLaporan(c *gin.Context) {
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		response.BadRequest(c, "Invalid start date format", err)
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		response.BadRequest(c, "Invalid end date format", err)
+		return
+	}
+
+	laporan, err := h.services.SupplierService.GetLaporanSupplier(startDate, endDate)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get supplier report", err)
+		return
+	}
+	response.Success(c, laporan, "Supplier report retrieved successfully")
+}
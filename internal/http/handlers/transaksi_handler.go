@@ -29,9 +29,13 @@ func (h *TransaksiHandler) Create(c *gin.Context) {
 		response.BadRequest(c, "Invalid request body", err)
 		return
 	}
+	bindIdempotencyKey(c, &req.IdempotencyKey)
 
 	result, err := h.services.TransaksiService.CreateTransaksi(&req)
 	if err != nil {
+		if respondIdempotencyError(c, err) {
+			return
+		}
 		response.InternalServerError(c, "Failed to create transaction", err)
 		return
 	}
@@ -141,3 +145,27 @@ func (h *TransaksiHandler) GetByPelanggan(c *gin.Context) {
 
 	response.Success(c, transaksi, "Customer transactions retrieved successfully")
 }
+
+// Void cancels a completed transaction (requires admin approval credentials)
+func (h *TransaksiHandler) Void(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid transaction ID", err)
+		return
+	}
+
+	var req models.VoidTransaksiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+	req.TransaksiID = id
+
+	result, err := h.services.TransaksiService.VoidTransaksi(&req)
+	if err != nil {
+		response.InternalServerError(c, "Failed to void transaction", err)
+		return
+	}
+
+	response.Success(c, result, "Transaction void processed")
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"strconv"
+
+	"ritel-app/internal/container"
+	"ritel-app/internal/http/response"
+	"ritel-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransaksiParkirHandler handles held (parked) sale HTTP requests
+type TransaksiParkirHandler struct {
+	services *container.ServiceContainer
+}
+
+// NewTransaksiParkirHandler creates a new TransaksiParkirHandler instance
+func NewTransaksiParkirHandler(services *container.ServiceContainer) *TransaksiParkirHandler {
+	return &TransaksiParkirHandler{services: services}
+}
+
+// Create parks a transaction draft
+func (h *TransaksiParkirHandler) Create(c *gin.Context) {
+	var req models.ParkirTransaksiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	parkir, err := h.services.ParkirService.ParkirTransaksi(&req)
+	if err != nil {
+		response.BadRequest(c, "Failed to park transaction", err)
+		return
+	}
+
+	response.Success(c, parkir, "Transaction parked successfully")
+}
+
+// GetAll lists active parked sales, optionally filtered by staff_id and kode_terminal
+func (h *TransaksiParkirHandler) GetAll(c *gin.Context) {
+	staffID, _ := strconv.Atoi(c.Query("staff_id"))
+	filter := models.TransaksiParkirFilter{
+		StaffID:      staffID,
+		KodeTerminal: c.Query("kode_terminal"),
+	}
+
+	list, err := h.services.ParkirService.GetTransaksiParkir(filter)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get parked transactions", err)
+		return
+	}
+
+	response.Success(c, list, "Parked transactions retrieved successfully")
+}
+
+// Resume resumes a parked sale and returns its draft
+func (h *TransaksiParkirHandler) Resume(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid parked transaction ID", err)
+		return
+	}
+
+	parkir, err := h.services.ParkirService.LanjutkanTransaksiParkir(id)
+	if err != nil {
+		response.BadRequest(c, "Failed to resume parked transaction", err)
+		return
+	}
+
+	response.Success(c, parkir, "Parked transaction resumed successfully")
+}
+
+// Delete discards a parked sale
+func (h *TransaksiParkirHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid parked transaction ID", err)
+		return
+	}
+
+	if err := h.services.ParkirService.BatalkanTransaksiParkir(id); err != nil {
+		response.BadRequest(c, "Failed to discard parked transaction", err)
+		return
+	}
+
+	response.Success(c, nil, "Parked transaction discarded successfully")
+}
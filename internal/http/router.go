@@ -35,11 +35,22 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 	// Initialize all handlers
 	authHandler := handlers.NewAuthHandler(services, jwtManager)
 	produkHandler := handlers.NewProdukHandler(services)
+	produkIndukHandler := handlers.NewProdukIndukHandler(services)
 	transaksiHandler := handlers.NewTransaksiHandler(services)
+	transaksiParkirHandler := handlers.NewTransaksiParkirHandler(services)
+	shiftHandler := handlers.NewShiftHandler(services)
+	metodePembayaranHandler := handlers.NewMetodePembayaranHandler(services)
+	barcodeTimbangHandler := handlers.NewBarcodeTimbangHandler(services)
+	aturanMarkdownHandler := handlers.NewAturanMarkdownHandler(services)
 	pelangganHandler := handlers.NewPelangganHandler(services)
+	piutangHandler := handlers.NewPiutangHandler(services)
+	kartuHadiahHandler := handlers.NewKartuHadiahHandler(services)
 	kategoriHandler := handlers.NewKategoriHandler(services)
 	promoHandler := handlers.NewPromoHandler(services)
 	batchHandler := handlers.NewBatchHandler(services)
+	supplierHandler := handlers.NewSupplierHandler(services)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(services)
+	stokOpnameHandler := handlers.NewStokOpnameHandler(services)
 	returnHandler := handlers.NewReturnHandler(services)
 	userHandler := handlers.NewUserHandler(services)
 	analyticsHandler := handlers.NewAnalyticsHandler(services)
@@ -92,6 +103,15 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 				produk.PUT("/stok", produkHandler.UpdateStok)
 				produk.PUT("/stok/increment", produkHandler.UpdateStokIncrement)
 				produk.GET("/:id/stok-history", produkHandler.GetStokHistory)
+				produk.GET("/barcode/:barcode", produkHandler.GetByBarcode)
+
+				// Units of measure (pack, carton, ...) with conversion to the base unit
+				produk.GET("/:id/satuan", produkHandler.GetSatuan)
+				produk.PUT("/:id/satuan", produkHandler.SimpanSatuan)
+
+				// Kit components (bill of materials); selling a kit deducts its components
+				produk.GET("/:id/komponen", produkHandler.GetKomponen)
+				produk.PUT("/:id/komponen", produkHandler.SimpanKomponen)
 
 				// Cart operations
 				produk.GET("/keranjang", produkHandler.GetKeranjang)
@@ -101,6 +121,17 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 				produk.PUT("/keranjang/jumlah", produkHandler.UpdateKeranjangJumlah)
 			}
 
+			// ==================== PRODUCT VARIANTS ====================
+			// Parent products whose variants (size, flavour, ...) are products with their own SKU, price and stock
+			produkInduk := protected.Group("/produk-induk")
+			{
+				produkInduk.GET("", produkIndukHandler.GetAll)
+				produkInduk.GET("/:id", produkIndukHandler.GetByID)
+				produkInduk.POST("", produkIndukHandler.Create)
+				produkInduk.PUT("", produkIndukHandler.Update)
+				produkInduk.DELETE("/:id", produkIndukHandler.Delete)
+			}
+
 			// ==================== CATEGORIES ====================
 			kategori := protected.Group("/kategori")
 			{
@@ -121,8 +152,40 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 				transaksi.GET("/date-range", transaksiHandler.GetByDateRange)
 				transaksi.GET("/today-stats", transaksiHandler.GetTodayStats)
 				transaksi.GET("/pelanggan/:id", transaksiHandler.GetByPelanggan)
+				transaksi.POST("/:id/void", transaksiHandler.Void)
+
+				// Parked (held) sales
+				transaksi.GET("/parkir", transaksiParkirHandler.GetAll)
+				transaksi.POST("/parkir", transaksiParkirHandler.Create)
+				transaksi.POST("/parkir/:id/lanjutkan", transaksiParkirHandler.Resume)
+				transaksi.DELETE("/parkir/:id", transaksiParkirHandler.Delete)
 			}
 
+			// ==================== CASHIER SHIFTS ====================
+			shift := protected.Group("/shift")
+			{
+				shift.GET("", shiftHandler.GetAll)
+				shift.GET("/aktif", shiftHandler.GetAktif)
+				shift.POST("/buka", shiftHandler.Buka)
+				shift.POST("/kas", shiftHandler.MutasiKas)
+				shift.POST("/tutup", shiftHandler.Tutup)
+				shift.POST("/print", shiftHandler.PrintLaporan)
+				shift.GET("/:id/laporan", shiftHandler.GetLaporan)
+			}
+
+			// ==================== PAYMENT METHODS ====================
+			metodePembayaran := protected.Group("/metode-pembayaran")
+			{
+				metodePembayaran.GET("", metodePembayaranHandler.GetAll)
+				metodePembayaran.GET("/aktif", metodePembayaranHandler.GetAktif)
+			}
+
+			// ==================== SCALE LABELS ====================
+			protected.GET("/barcode-timbang", barcodeTimbangHandler.GetAll)
+
+			// ==================== MARKDOWN RULES ====================
+			protected.GET("/aturan-markdown", aturanMarkdownHandler.GetAll)
+
 			// ==================== CUSTOMERS ====================
 			pelanggan := protected.Group("/pelanggan")
 			{
@@ -137,6 +200,23 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 				pelanggan.GET("/:id/stats", pelangganHandler.GetWithStats)
 			}
 
+			// ==================== CUSTOMER RECEIVABLES (KASBON) ====================
+			piutang := protected.Group("/piutang")
+			{
+				piutang.GET("", piutangHandler.GetAll)
+				piutang.GET("/aging", piutangHandler.GetAging)
+				piutang.GET("/pelanggan/:id", piutangHandler.GetByPelanggan)
+				piutang.POST("/bayar", piutangHandler.Bayar)
+			}
+
+			// ==================== GIFT CARDS / STORE CREDIT ====================
+			kartuHadiah := protected.Group("/kartu-hadiah")
+			{
+				kartuHadiah.GET("", kartuHadiahHandler.GetAll)
+				kartuHadiah.GET("/kode/:kode", kartuHadiahHandler.GetByKode)
+				kartuHadiah.POST("/jual", kartuHadiahHandler.Jual)
+			}
+
 			// ==================== PROMOTIONS ====================
 			promo := protected.Group("/promo")
 			{
@@ -162,6 +242,40 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 				batch.DELETE("/:id/expired", batchHandler.DeleteExpired)
 				batch.GET("/summary/:id", batchHandler.GetSummary)
 				batch.PUT("/update-status", batchHandler.UpdateStatuses)
+				batch.GET("/:id/penarikan", batchHandler.GetPenarikan)
+				batch.GET("/:id/penarikan/export", batchHandler.ExportPenarikan)
+			}
+
+			// ==================== SUPPLIERS ====================
+			supplier := protected.Group("/supplier")
+			{
+				supplier.GET("", supplierHandler.GetAll)
+				supplier.GET("/laporan", supplierHandler.GetLaporan)
+				supplier.GET("/:id", supplierHandler.GetByID)
+			}
+
+			// ==================== PURCHASE ORDERS ====================
+			purchaseOrder := protected.Group("/purchase-order")
+			{
+				purchaseOrder.GET("", purchaseOrderHandler.GetAll)
+				purchaseOrder.GET("/saran", purchaseOrderHandler.GetSaran)
+				purchaseOrder.GET("/:id", purchaseOrderHandler.GetByID)
+				purchaseOrder.POST("", purchaseOrderHandler.Simpan)
+				purchaseOrder.PUT("", purchaseOrderHandler.Simpan)
+				purchaseOrder.POST("/:id/kirim", purchaseOrderHandler.Kirim)
+				purchaseOrder.POST("/:id/terima", purchaseOrderHandler.Terima)
+				purchaseOrder.POST("/:id/tutup", purchaseOrderHandler.Tutup)
+				purchaseOrder.DELETE("/:id", purchaseOrderHandler.Delete)
+			}
+
+			// ==================== STOCK OPNAME ====================
+			stokOpname := protected.Group("/stok-opname")
+			{
+				stokOpname.GET("", stokOpnameHandler.GetAll)
+				stokOpname.GET("/:id", stokOpnameHandler.GetByID)
+				stokOpname.POST("", stokOpnameHandler.Mulai)
+				stokOpname.POST("/:id/hitung", stokOpnameHandler.Hitung)
+				stokOpname.POST("/:id/batal", stokOpnameHandler.Batalkan)
 			}
 
 			// ==================== RETURNS ====================
@@ -202,6 +316,7 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 				staffReport.GET("/comprehensive", staffReportHandler.GetComprehensive)
 				staffReport.GET("/shift-productivity", staffReportHandler.GetShiftProductivity)
 				staffReport.GET("/monthly-trend", staffReportHandler.GetMonthlyTrend)
+				staffReport.GET("/override-harga", staffReportHandler.GetOverrideHarga)
 
 				// Dynamic :id routes
 				staffReport.GET("/:id", staffReportHandler.GetStaffReport)
@@ -242,6 +357,12 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 			{
 				settings.GET("/poin", settingsHandler.GetPoinSettings)
 				settings.PUT("/poin", settingsHandler.UpdatePoinSettings)
+				settings.GET("/nomor-transaksi", settingsHandler.GetNomorTransaksiSettings)
+				settings.GET("/pajak", settingsHandler.GetPajakSettings)
+				settings.GET("/harga", settingsHandler.GetHargaSettings)
+				settings.GET("/pembulatan", settingsHandler.GetPembulatanSettings)
+				settings.GET("/kartu-hadiah", settingsHandler.GetKartuHadiahSettings)
+				settings.GET("/batch-kadaluarsa", settingsHandler.GetBatchKadaluarsaSettings)
 			}
 
 			// ==================== SYNC (Offline-First Mode) ====================
@@ -268,6 +389,55 @@ func SetupRouter(services *container.ServiceContainer, jwtManager *auth.JWTManag
 					users.PUT("", userHandler.Update)
 					users.DELETE("/:id", userHandler.Delete)
 				}
+
+				// Transaction numbering scheme (affects audit trail of receipt numbers)
+				admin.PUT("/settings/nomor-transaksi", settingsHandler.UpdateNomorTransaksiSettings)
+
+				// PPN configuration (affects totals and tax reports)
+				admin.PUT("/settings/pajak", settingsHandler.UpdatePajakSettings)
+
+				// Price override limit (when manual prices and line discounts need admin approval)
+				admin.PUT("/settings/harga", settingsHandler.UpdateHargaSettings)
+
+				// Cash rounding policy (affects totals and the rounding ledger)
+				admin.PUT("/settings/pembulatan", settingsHandler.UpdatePembulatanSettings)
+
+				// Payment method registry (change, reference and fee rules at the cashier)
+				admin.POST("/metode-pembayaran", metodePembayaranHandler.Create)
+				admin.PUT("/metode-pembayaran", metodePembayaranHandler.Update)
+
+				// Scale label rules (where the PLU and weight/price sit in prefix 20-29 labels)
+				admin.POST("/barcode-timbang", barcodeTimbangHandler.Create)
+				admin.PUT("/barcode-timbang", barcodeTimbangHandler.Update)
+				admin.DELETE("/barcode-timbang/:id", barcodeTimbangHandler.Delete)
+
+				// Near-expiry markdown rules (percentage off units sold from batches close to expiry)
+				admin.POST("/aturan-markdown", aturanMarkdownHandler.Create)
+				admin.PUT("/aturan-markdown", aturanMarkdownHandler.Update)
+				admin.DELETE("/aturan-markdown/:id", aturanMarkdownHandler.Delete)
+
+				// Supplier master data (suppliers with batches can only be deactivated or merged)
+				admin.POST("/supplier", supplierHandler.Create)
+				admin.PUT("/supplier", supplierHandler.Update)
+				admin.POST("/supplier/gabung", supplierHandler.Gabung)
+				admin.DELETE("/supplier/:id", supplierHandler.Delete)
+
+				// Stock opname approval posts the counted variances to stock
+				admin.POST("/stok-opname/:id/setujui", stokOpnameHandler.Setujui)
+
+				// Batch recall writes off remaining stock and blocks the batch from sale
+				admin.POST("/batch/:id/tarik", batchHandler.Tarik)
+
+				// Expired batch job schedule; running it now writes off expired stock when enabled
+				admin.PUT("/settings/batch-kadaluarsa", settingsHandler.UpdateBatchKadaluarsaSettings)
+				admin.POST("/batch/proses-kadaluarsa", batchHandler.ProsesKadaluarsa)
+
+				// Customer credit limits (who may buy on kasbon and how much)
+				admin.PUT("/piutang/batas-kredit", piutangHandler.UpdateBatasKredit)
+
+				// Gift card validity rules and forfeiting expired balances
+				admin.PUT("/settings/kartu-hadiah", settingsHandler.UpdateKartuHadiahSettings)
+				admin.POST("/kartu-hadiah/kedaluwarsa", kartuHadiahHandler.Kedaluwarsakan)
 			}
 		}
 	}
@@ -10,11 +10,14 @@ type TopProductsResponse struct {
 	TotalRevenue int    `json:"total_revenue"`
 	TimesSold    int    `json:"times_sold"`
 	AveragePrice int    `json:"average_price"`
+	IndukID      int    `json:"induk_id"`      // Diisi jika baris adalah gabungan varian satu produk induk
+	VariantCount int    `json:"variant_count"` // Jumlah varian yang terjual dalam baris gabungan
 }
 
 // PaymentBreakdownResponse represents payment method breakdown
 type PaymentBreakdownResponse struct {
-	Method       string  `json:"method"`
+	Kode         string  `json:"kode"`   // Kode metode yang tersimpan di pembayaran
+	Method       string  `json:"method"` // Nama metode dari registry metode pembayaran
 	TotalAmount  int     `json:"total_amount"`
 	Count        int     `json:"count"`
 	Percentage   float64 `json:"percentage"`
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AturanMarkdown is a near-expiry markdown rule: units sold from a batch that
+// expires within HariSebelum days are sold at Persen off. When several rules
+// apply, the one with the largest markdown is used.
+type AturanMarkdown struct {
+	ID          int       `json:"id"`
+	Nama        string    `json:"nama"`
+	HariSebelum int       `json:"hariSebelum"` // Berlaku jika sisa hari sampai kadaluarsa <= nilai ini, 0 = hari terakhir
+	Persen      float64   `json:"persen"`      // Potongan dari harga jual, 0-100
+	Aktif       bool      `json:"aktif"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Jenis nilai yang disisipkan timbangan pada label barcode
+const (
+	TipeNilaiBerat = "berat" // Berat dalam kg dengan sejumlah angka desimal
+	TipeNilaiHarga = "harga" // Harga total dalam rupiah
+)
+
+// AturanBarcodeTimbang describes how an EAN-13 scale label (prefix 20-29) embeds
+// the product PLU and the weight or price. Digit positions are 1-based.
+type AturanBarcodeTimbang struct {
+	ID          int       `json:"id"`
+	Nama        string    `json:"nama"`
+	Prefix      string    `json:"prefix"`      // Dua digit awal label, "20" sampai "29"
+	PLUDari     int       `json:"pluDari"`     // Posisi digit pertama PLU
+	PLUSampai   int       `json:"pluSampai"`   // Posisi digit terakhir PLU
+	NilaiDari   int       `json:"nilaiDari"`   // Posisi digit pertama berat/harga
+	NilaiSampai int       `json:"nilaiSampai"` // Posisi digit terakhir berat/harga
+	TipeNilai   string    `json:"tipeNilai"`   // "berat" atau "harga"
+	Desimal     int       `json:"desimal"`     // Jumlah angka desimal nilai, mis. 3 untuk berat dalam gram
+	Aktif       bool      `json:"aktif"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// LabelTimbang represents the values decoded from a scale label
+type LabelTimbang struct {
+	AturanID  int     `json:"aturanId"`
+	TipeNilai string  `json:"tipeNilai"`
+	PLU       string  `json:"plu"`
+	BeratGram float64 `json:"beratGram"` // Dari label berat, atau dihitung dari harga label / harga per kg
+	Harga     int     `json:"harga"`     // Dari label harga, atau dihitung dari berat x harga per kg
+}
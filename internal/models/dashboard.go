@@ -14,7 +14,7 @@ type DashboardStatistikBulanan struct {
 // DashboardNotifikasi represents notification items
 type DashboardNotifikasi struct {
 	ID       int    `json:"id"`
-	Type     string `json:"type"` // low-stock, promo, new-product
+	Type     string `json:"type"` // low-stock, promo, new-product, piutang
 	Title    string `json:"title"`
 	Message  string `json:"message"`
 	Priority string `json:"priority"` // high, medium, low
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// IdempotencyRecord represents a stored idempotency key for a write request
+type IdempotencyRecord struct {
+	Scope       string     `json:"scope"`       // "transaksi", "return", "stok", "stok_increment"
+	Key         string     `json:"key"`         // Nilai header Idempotency-Key dari client
+	RequestHash string     `json:"requestHash"` // SHA-256 dari payload request
+	Status      string     `json:"status"`      // "processing" atau "completed"
+	Response    string     `json:"response"`    // Response asli (JSON) untuk replay
+	ResourceID  int        `json:"resourceId"`  // ID data yang dibuat request (mis. transaksi), untuk replay
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// MetodeKartuHadiah is the gift card tender; the card code is sent as the payment reference
+const MetodeKartuHadiah = "kartu_hadiah"
+
+// Status kartu hadiah
+const (
+	StatusKartuHadiahAktif       = "aktif"
+	StatusKartuHadiahHabis       = "habis"
+	StatusKartuHadiahKedaluwarsa = "kedaluwarsa"
+	StatusKartuHadiahBatal       = "batal" // Transaksi penjualan kartu dibatalkan (void)
+)
+
+// Sumber kartu hadiah
+const (
+	SumberKartuHadiahPenjualan = "penjualan" // Dijual sebagai transaksi
+	SumberKartuHadiahRetur     = "retur"     // Store credit dari refund retur
+)
+
+// Tipe mutasi saldo kartu hadiah
+const (
+	MutasiKartuHadiahTerbit      = "terbit"
+	MutasiKartuHadiahPakai       = "pakai"
+	MutasiKartuHadiahKembali     = "kembali" // Saldo dikembalikan karena transaksi dibatalkan
+	MutasiKartuHadiahKedaluwarsa = "kedaluwarsa"
+	MutasiKartuHadiahBatal       = "batal"
+)
+
+// KartuHadiah represents a gift card or store credit voucher
+type KartuHadiah struct {
+	ID          int        `json:"id"`
+	Kode        string     `json:"kode"`
+	Sumber      string     `json:"sumber"` // "penjualan" atau "retur"
+	SaldoAwal   int        `json:"saldoAwal"`
+	Saldo       int        `json:"saldo"`
+	Status      string     `json:"status"` // "aktif", "habis", "kedaluwarsa" atau "batal"
+	PelangganID *int       `json:"pelangganId,omitempty"`
+	TransaksiID *int       `json:"transaksiId,omitempty"` // Transaksi penjualan kartu
+	ReturnID    *int       `json:"returnId,omitempty"`    // Retur yang menerbitkan store credit
+	ExpiredAt   *time.Time `json:"expiredAt,omitempty"`   // Kosong jika tidak kedaluwarsa
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// KartuHadiahMutasi represents a balance change of a gift card
+type KartuHadiahMutasi struct {
+	ID             int       `json:"id"`
+	KartuID        int       `json:"kartuId"`
+	Tipe           string    `json:"tipe"`   // "terbit", "pakai", "kembali", "kedaluwarsa" atau "batal"
+	Jumlah         int       `json:"jumlah"` // Positif = saldo bertambah, negatif = saldo berkurang
+	SaldoSesudah   int       `json:"saldoSesudah"`
+	TransaksiID    *int      `json:"transaksiId,omitempty"`
+	NomorTransaksi string    `json:"nomorTransaksi,omitempty"`
+	ReturnID       *int      `json:"returnId,omitempty"`
+	Keterangan     string    `json:"keterangan"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// KartuHadiahDetail represents a gift card with its balance history
+type KartuHadiahDetail struct {
+	Kartu  *KartuHadiah         `json:"kartu"`
+	Mutasi []*KartuHadiahMutasi `json:"mutasi"`
+}
+
+// JualKartuHadiahRequest represents request to sell a gift card as a transaction
+type JualKartuHadiahRequest struct {
+	Nominal       int                 `json:"nominal"`
+	PelangganID   int                 `json:"pelangganId"` // Opsional
+	PelangganNama string              `json:"pelangganNama"`
+	PelangganTelp string              `json:"pelangganTelp"`
+	Pembayaran    []PembayaranRequest `json:"pembayaran"`
+	Kasir         string              `json:"kasir"`
+	StaffID       int                 `json:"staffId"`
+	StaffNama     string              `json:"staffNama"`
+	KodeTerminal  string              `json:"kodeTerminal"`
+	ShiftID       int                 `json:"-"` // Diisi service dari shift kasir yang aktif
+}
+
+// JualKartuHadiahResponse represents the issued card and the sale that paid for it
+type JualKartuHadiahResponse struct {
+	Kartu     *KartuHadiah     `json:"kartu"`
+	Transaksi *TransaksiDetail `json:"transaksi"`
+}
+
+// KedaluwarsaKartuHadiahResponse represents the result of expiring overdue gift cards
+type KedaluwarsaKartuHadiahResponse struct {
+	JumlahKartu int `json:"jumlahKartu"`
+	TotalSaldo  int `json:"totalSaldo"` // Saldo yang hangus
+}
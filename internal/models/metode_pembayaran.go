@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// MetodePembayaran represents a payment method in the registry managed by admins
+type MetodePembayaran struct {
+	ID             int       `json:"id"`
+	Kode           string    `json:"kode"` // Disimpan di pembayaran.metode, mis. "tunai", "qris"
+	Nama           string    `json:"nama"`
+	Aktif          bool      `json:"aktif"`
+	BolehKembalian bool      `json:"bolehKembalian"` // Hanya metode ini yang boleh menghasilkan kembalian
+	WajibReferensi bool      `json:"wajibReferensi"` // Nomor referensi (approval code, ID transfer) wajib diisi
+	BiayaPersen    float64   `json:"biayaPersen"`    // Biaya MDR/admin dalam persen
+	Urutan         int       `json:"urutan"`         // Urutan tampil di layar kasir
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
@@ -15,6 +15,7 @@ type Pelanggan struct {
 	DiskonPersen   int       `json:"diskonPersen"` // Persentase diskon berdasarkan level
 	TotalTransaksi int       `json:"totalTransaksi"`
 	TotalBelanja   int       `json:"totalBelanja"`
+	BatasKredit    int       `json:"batasKredit"` // Batas kasbon (piutang), 0 = tidak boleh kasbon
 	CreatedAt      time.Time `json:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt"`
 }
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Tipe kerugian stok batch pada stok_history
+const (
+	TipeKerugianDitarik    = "ditarik"    // Batch ditarik oleh supplier
+	TipeKerugianKadaluarsa = "kadaluarsa" // Batch melewati tanggal kadaluarsa
+)
+
+// TarikBatchRequest recalls a batch
+type TarikBatchRequest struct {
+	BatchID     string `json:"batchId"`
+	Alasan      string `json:"alasan"`
+	DitarikOleh string `json:"ditarikOleh"`
+}
+
+// PenarikanBatch lists where the stock of a batch went, for a supplier recall
+type PenarikanBatch struct {
+	Batch           *Batch                `json:"batch"`
+	SKU             string                `json:"sku"`
+	NamaProduk      string                `json:"namaProduk"`
+	QtyTerjual      float64               `json:"qtyTerjual"`      // Terjual dikurangi yang sudah dikembalikan pelanggan
+	QtyDikembalikan float64               `json:"qtyDikembalikan"` // Dikembalikan pelanggan melalui return
+	JumlahPelanggan int                   `json:"jumlahPelanggan"` // Pelanggan terdaftar yang menerima batch ini
+	Transaksi       []*PenarikanTransaksi `json:"transaksi"`
+}
+
+// PenarikanTransaksi is a sale that took stock from a recalled batch
+type PenarikanTransaksi struct {
+	TransaksiID     int       `json:"transaksiId"`
+	NomorTransaksi  string    `json:"nomorTransaksi"`
+	Tanggal         time.Time `json:"tanggal"`
+	Status          string    `json:"status"`
+	Qty             float64   `json:"qty"`             // Dari batch ini, setelah dikurangi return
+	QtyDikembalikan float64   `json:"qtyDikembalikan"` // Sudah dikembalikan pelanggan
+	PelangganID     int       `json:"pelangganId"`     // 0 = bukan pelanggan terdaftar
+	NamaPelanggan   string    `json:"namaPelanggan"`
+	Telepon         string    `json:"telepon"`
+	Email           string    `json:"email"`
+}
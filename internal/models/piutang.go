@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+// MetodeKasbon is the "on account" tender; the unpaid part of a sale becomes a receivable
+const MetodeKasbon = "kasbon"
+
+// Status piutang
+const (
+	StatusPiutangBelumLunas = "belum_lunas"
+	StatusPiutangLunas      = "lunas"
+	StatusPiutangBatal      = "batal" // Transaksi kasbon dibatalkan (void)
+)
+
+// Piutang represents the receivable of one credit sale (kasbon)
+type Piutang struct {
+	ID             int        `json:"id"`
+	TransaksiID    int        `json:"transaksiId"`
+	NomorTransaksi string     `json:"nomorTransaksi"`
+	PelangganID    int        `json:"pelangganId"`
+	PelangganNama  string     `json:"pelangganNama"`
+	Jumlah         int        `json:"jumlah"` // Nilai kasbon saat transaksi
+	Sisa           int        `json:"sisa"`   // Sisa yang belum dibayar
+	Status         string     `json:"status"` // "belum_lunas", "lunas" atau "batal"
+	UmurHari       int        `json:"umurHari"`
+	LunasAt        *time.Time `json:"lunasAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// PembayaranPiutang represents a repayment recorded against a credit sale
+type PembayaranPiutang struct {
+	ID             int       `json:"id"` // ID baris pembayaran
+	PiutangID      int       `json:"piutangId"`
+	TransaksiID    int       `json:"transaksiId"`
+	NomorTransaksi string    `json:"nomorTransaksi"`
+	Metode         string    `json:"metode"`
+	Jumlah         int       `json:"jumlah"`
+	Referensi      string    `json:"referensi"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// BayarPiutangRequest represents a (partial) repayment by a customer. Without
+// PiutangID the amount is applied to the oldest open credit sales first.
+type BayarPiutangRequest struct {
+	PelangganID  int    `json:"pelangganId"`
+	PiutangID    int    `json:"piutangId"` // Opsional, bayar nota tertentu
+	Metode       string `json:"metode"`
+	Jumlah       int    `json:"jumlah"`
+	Referensi    string `json:"referensi"`
+	KodeTerminal string `json:"kodeTerminal"`
+	StaffID      int    `json:"staffId"`
+	StaffNama    string `json:"staffNama"`
+}
+
+// BayarPiutangResponse represents the result of a repayment
+type BayarPiutangResponse struct {
+	PelangganID  int                  `json:"pelangganId"`
+	Dibayar      int                  `json:"dibayar"`
+	SaldoPiutang int                  `json:"saldoPiutang"` // Sisa piutang pelanggan setelah pembayaran
+	Pembayaran   []*PembayaranPiutang `json:"pembayaran"`   // Alokasi pembayaran per nota
+}
+
+// PiutangPelanggan represents the receivable ledger of one customer
+type PiutangPelanggan struct {
+	Pelanggan    *Pelanggan           `json:"pelanggan"`
+	BatasKredit  int                  `json:"batasKredit"`
+	SaldoPiutang int                  `json:"saldoPiutang"`
+	SisaLimit    int                  `json:"sisaLimit"`
+	Piutang      []*Piutang           `json:"piutang"`
+	Pembayaran   []*PembayaranPiutang `json:"pembayaran"`
+}
+
+// UpdateBatasKreditRequest represents request to set a customer's credit limit
+type UpdateBatasKreditRequest struct {
+	PelangganID int `json:"pelangganId"`
+	BatasKredit int `json:"batasKredit"` // 0 = pelanggan tidak boleh kasbon
+}
+
+// AgingPiutang represents the outstanding receivable of a customer by age
+type AgingPiutang struct {
+	PelangganID   int    `json:"pelangganId"`
+	PelangganNama string `json:"pelangganNama"`
+	JumlahNota    int    `json:"jumlahNota"`
+	Hari0s30      int    `json:"hari0s30"`    // Umur 0-30 hari
+	Hari31s60     int    `json:"hari31s60"`   // Umur 31-60 hari
+	HariLebih60   int    `json:"hariLebih60"` // Umur lebih dari 60 hari
+	Total         int    `json:"total"`
+}
+
+// LaporanAgingPiutang represents the aging of all outstanding receivables
+type LaporanAgingPiutang struct {
+	Pelanggan   []*AgingPiutang `json:"pelanggan"`
+	Hari0s30    int             `json:"hari0s30"`
+	Hari31s60   int             `json:"hari31s60"`
+	HariLebih60 int             `json:"hariLebih60"`
+	Total       int             `json:"total"`
+	DibuatAt    time.Time       `json:"dibuatAt"`
+}
@@ -21,10 +21,82 @@ type Produk struct {
 	Deskripsi                   string    `json:"deskripsi"`
 	Gambar                      string    `json:"gambar"`
 	HariPemberitahuanKadaluarsa int       `json:"hariPemberitahuanKadaluarsa"` // Existing field
+	KodePajak                   string    `json:"kodePajak"`                   // Kosong = ikut kode pajak kategori
+	PLU                         string    `json:"plu"`                         // Kode PLU timbangan untuk label barcode berat/harga
+	StokMinimum                 float64   `json:"stokMinimum"`                 // Titik pesan ulang; 0 = dihitung dari penjualan harian x lead time
+	StokMaksimum                float64   `json:"stokMaksimum"`                // Stok target setelah dipesan; 0 = dihitung dari penjualan harian
+	LeadTimeHari                int       `json:"leadTimeHari"`                // Lama pengiriman supplier dalam hari
+	SupplierID                  int       `json:"supplierId"`                  // Supplier utama untuk saran pembelian; 0 = supplier penerimaan terakhir
+	KebijakanBatch              string    `json:"kebijakanBatch"`              // Urutan pengambilan batch; kosong = ikut kategori
 	CreatedAt                   time.Time `json:"createdAt"`
 	UpdatedAt                   time.Time `json:"updatedAt"`
+
+	// Satuan tambahan (pak, karton, ...); stok selalu dicatat dalam Satuan dasar
+	SatuanLain []*ProdukSatuan `json:"satuanLain,omitempty"`
+
+	// Varian dari produk induk; 0 jika produk berdiri sendiri
+	IndukID       int               `json:"indukId"`
+	AtributVarian map[string]string `json:"atributVarian,omitempty"` // Nilai tiap atribut induk, mis. {"Ukuran": "1L"}
+
+	// Isi paket (hamper, bundel); jika ada, Stok dihitung dari stok komponen
+	Komponen []*ProdukKomponen `json:"komponen,omitempty"`
+}
+
+// ProdukSatuan represents an additional unit of measure of a product, e.g. a pack or carton
+type ProdukSatuan struct {
+	ID        int       `json:"id"`
+	ProdukID  int       `json:"produkId"`
+	Nama      string    `json:"nama"`
+	Konversi  float64   `json:"konversi"` // Jumlah satuan dasar dalam satu satuan ini, mis. 40
+	Barcode   string    `json:"barcode"`
+	HargaJual int       `json:"hargaJual"` // 0 = harga jual produk x konversi
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SimpanProdukSatuanRequest replaces all additional units of a product
+type SimpanProdukSatuanRequest struct {
+	ProdukID int            `json:"produkId"`
+	Satuan   []ProdukSatuan `json:"satuan"`
+}
+
+// ProdukKomponen represents one line of the bill of materials of a kit product.
+// Selling one kit (in its base unit) deducts Qty base units of the component.
+type ProdukKomponen struct {
+	ID             int       `json:"id"`
+	PaketID        int       `json:"paketId"`
+	KomponenID     int       `json:"komponenId"`
+	KomponenNama   string    `json:"komponenNama"`
+	KomponenSatuan string    `json:"komponenSatuan"`
+	Qty            float64   `json:"qty"`
+	Stok           float64   `json:"stok"`      // Stok komponen saat ini
+	HargaBeli      int       `json:"hargaBeli"` // Harga pokok komponen per satuan dasar
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
 
+// SimpanProdukKomponenRequest replaces the bill of materials of a kit product;
+// an empty list turns the kit back into an ordinary product
+type SimpanProdukKomponenRequest struct {
+	ProdukID int              `json:"produkId"`
+	Komponen []ProdukKomponen `json:"komponen"`
+}
+
+// ProdukBarcode represents the product and unit a scanned barcode belongs to
+type ProdukBarcode struct {
+	Produk    *Produk       `json:"produk"`
+	Satuan    *ProdukSatuan `json:"satuan,omitempty"` // Kosong jika barcode milik satuan dasar
+	Label     *LabelTimbang `json:"label,omitempty"`  // Berat/harga dari label timbangan
+	HargaJual int           `json:"hargaJual"`        // Harga jual per satuan yang dipindai (per kg untuk label timbangan)
+}
+
+// Kebijakan pengambilan batch saat stok berkurang
+const (
+	KebijakanBatchFIFO                 = "fifo"                   // Batch yang masuk lebih dulu keluar lebih dulu
+	KebijakanBatchFEFO                 = "fefo"                   // Batch yang kadaluarsa lebih dulu keluar lebih dulu
+	KebijakanBatchFEFOLewatiKadaluarsa = "fefo_lewati_kadaluarsa" // FEFO, batch kadaluarsa tidak dijual dan hanya dapat dihapus sebagai kerugian
+)
+
 // Batch represents a stock batch with expiry tracking
 type Batch struct {
 	ID                string    `json:"id"`                // Unique batch ID (UUID)
@@ -39,6 +111,25 @@ type Batch struct {
 	Keterangan        string    `json:"keterangan"`        // Notes
 	CreatedAt         time.Time `json:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt"`
+
+	SupplierID int `json:"supplierId"` // Supplier master data ID, 0 = tanpa supplier
+
+	// Penarikan (recall) oleh supplier; stok batch yang ditarik sudah dihapus dan tidak dapat dijual
+	DitarikAt       *time.Time `json:"ditarikAt,omitempty"`
+	AlasanPenarikan string     `json:"alasanPenarikan,omitempty"`
+	DitarikOleh     string     `json:"ditarikOleh,omitempty"`
+
+	HargaBeli int        `json:"hargaBeli"`           // Harga beli per satuan dasar saat diterima, 0 = ikut HPP produk
+	DihapusAt *time.Time `json:"dihapusAt,omitempty"` // Stok kadaluarsa sudah dihapus; batch disimpan sebagai arsip
+}
+
+// HasilProsesKadaluarsa summarizes a run of the expired batch job
+type HasilProsesKadaluarsa struct {
+	Waktu         time.Time `json:"waktu"`
+	HapusOtomatis bool      `json:"hapusOtomatis"`
+	JumlahBatch   int       `json:"jumlahBatch"`   // Batch kadaluarsa yang stoknya dihapus
+	QtyDihapus    float64   `json:"qtyDihapus"`    // Total stok yang dihapus
+	NilaiKerugian int       `json:"nilaiKerugian"` // Total nilai kerugian pada harga beli batch
 }
 
 // Keranjang represents items scanned to be added to inventory
@@ -76,17 +167,21 @@ type ScanBarcodeResponse struct {
 	Message string          `json:"message"`
 	Produk  *Produk         `json:"produk,omitempty"`
 	Item    *KeranjangItem  `json:"item,omitempty"`
+	Satuan  *ProdukSatuan   `json:"satuan,omitempty"` // Satuan yang dipindai, jumlah keranjang sudah dikonversi ke satuan dasar
+	Label   *LabelTimbang   `json:"label,omitempty"`  // Berat/harga dari label timbangan
 }
 
 // Kategori represents a product category
 type Kategori struct {
-	ID           int       `json:"id"`
-	Nama         string    `json:"nama"`
-	Deskripsi    string    `json:"deskripsi"`
-	Icon         string    `json:"icon"`
-	JumlahProduk int       `json:"jumlahProduk"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID             int       `json:"id"`
+	Nama           string    `json:"nama"`
+	Deskripsi      string    `json:"deskripsi"`
+	Icon           string    `json:"icon"`
+	KodePajak      string    `json:"kodePajak"`      // Kosong = ikut kode pajak default
+	KebijakanBatch string    `json:"kebijakanBatch"` // Urutan pengambilan batch produk; kosong = FIFO
+	JumlahProduk   int       `json:"jumlahProduk"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
 
 
@@ -101,5 +196,12 @@ type UpdateStokRequest struct {
 	NilaiKerugian  int     `json:"nilaiKerugian"`  // Loss value in rupiah (calculated from qty * harga_beli)
 	MasaSimpanHari int     `json:"masaSimpanHari"` // For batch creation during restock
 	Supplier       string  `json:"supplier"`       // Supplier for this batch
+	IdempotencyKey string  `json:"idempotencyKey"` // Prevents applying the same adjustment twice on retry
+
+	SupplierID int `json:"supplierId"` // Supplier master data ID; takes precedence over the free-text Supplier
+
+	HargaBeli int `json:"hargaBeli"` // Harga beli per satuan dasar dari stok yang masuk; 0 = HPP produk tidak berubah
+
+	IdempotencyHash string `json:"-"` // Hash of the original payload, stored with the key by the service
 }
  
\ No newline at end of file
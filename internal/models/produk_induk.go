@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ProdukInduk groups products that are variants of the same item, e.g. sizes
+// or flavours. Each variant stays a Produk with its own SKU, barcode, price
+// and stock; the parent only holds the shared name, category and the names of
+// the attributes that tell the variants apart.
+type ProdukInduk struct {
+	ID        int       `json:"id"`
+	Nama      string    `json:"nama"`
+	Kategori  string    `json:"kategori"` // Dipakai juga sebagai kategori setiap varian
+	Deskripsi string    `json:"deskripsi"`
+	Gambar    string    `json:"gambar"`
+	Atribut   []string  `json:"atribut"` // Nama atribut varian, mis. ["Ukuran", "Rasa"]
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Ringkasan varian yang masih aktif
+	JumlahVarian int     `json:"jumlahVarian"`
+	TotalStok    float64 `json:"totalStok"`
+	HargaMin     int     `json:"hargaMin"`
+	HargaMaks    int     `json:"hargaMaks"`
+
+	Varian []*Produk `json:"varian,omitempty"` // Diisi saat satu induk diambil
+}
+
+// SimpanProdukIndukRequest creates or edits a parent product with its variant
+// matrix. Variants with an ID are existing products that are edited or attached
+// to the parent, variants without an ID are created. Variants of the parent that
+// are left out are detached and become standalone products again.
+type SimpanProdukIndukRequest struct {
+	ID        int       `json:"id"` // 0 = induk baru
+	Nama      string    `json:"nama"`
+	Kategori  string    `json:"kategori"`
+	Deskripsi string    `json:"deskripsi"`
+	Gambar    string    `json:"gambar"`
+	Atribut   []string  `json:"atribut"`
+	Varian    []*Produk `json:"varian"` // AtributVarian harus berisi nilai untuk setiap atribut
+}
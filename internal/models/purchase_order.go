@@ -0,0 +1,143 @@
+package models
+
+import "time"
+
+// Status purchase order
+const (
+	StatusPODraft            = "draft"
+	StatusPODikirim          = "dikirim"           // Sudah dikirim ke supplier, menunggu barang
+	StatusPODiterimaSebagian = "diterima_sebagian" // Sebagian barang sudah diterima
+	StatusPODiterima         = "diterima"          // Semua barang sudah diterima sesuai pesanan
+	StatusPODitutup          = "ditutup"           // Ditutup sebelum semua barang diterima
+)
+
+// JenisPerubahanPembelian is the stok_history change type of a goods receipt
+const JenisPerubahanPembelian = "pembelian"
+
+// PurchaseOrder represents an order to a supplier
+type PurchaseOrder struct {
+	ID                int                  `json:"id"`
+	Nomor             string               `json:"nomor"`
+	SupplierID        int                  `json:"supplierId"`
+	NamaSupplier      string               `json:"namaSupplier"`
+	Status            string               `json:"status"`
+	TanggalDiharapkan *time.Time           `json:"tanggalDiharapkan,omitempty"` // Perkiraan tanggal barang datang
+	Catatan           string               `json:"catatan"`
+	TotalEstimasi     int                  `json:"totalEstimasi"` // Total qty pesan x harga estimasi
+	TotalDiterima     int                  `json:"totalDiterima"` // Total nilai barang yang sudah diterima dengan harga aktual
+	DibuatOleh        string               `json:"dibuatOleh"`
+	DikirimAt         *time.Time           `json:"dikirimAt,omitempty"`
+	DitutupAt         *time.Time           `json:"ditutupAt,omitempty"`
+	CreatedAt         time.Time            `json:"createdAt"`
+	UpdatedAt         time.Time            `json:"updatedAt"`
+	Items             []*PurchaseOrderItem `json:"items,omitempty"`
+	Penerimaan        []*PenerimaanBarang  `json:"penerimaan,omitempty"`
+}
+
+// PurchaseOrderItem represents an ordered product and how much of it was received.
+// A positive SelisihQty is over-delivery, a negative one under-delivery.
+type PurchaseOrderItem struct {
+	ID            int     `json:"id"`
+	POID          int     `json:"poId"`
+	ProdukID      int     `json:"produkId"`
+	NamaProduk    string  `json:"namaProduk"`
+	QtyPesan      float64 `json:"qtyPesan"`
+	HargaEstimasi int     `json:"hargaEstimasi"` // Harga beli per satuan yang diperkirakan
+	Subtotal      int     `json:"subtotal"`      // Qty pesan x harga estimasi
+	QtyDiterima   float64 `json:"qtyDiterima"`
+	NilaiDiterima int     `json:"nilaiDiterima"` // Qty diterima x harga beli aktual
+	SelisihQty    float64 `json:"selisihQty"`    // Qty diterima - qty pesan
+	SelisihNilai  int     `json:"selisihNilai"`  // Nilai diterima - qty diterima x harga estimasi
+}
+
+// PenerimaanBarang represents a goods receipt against a purchase order
+type PenerimaanBarang struct {
+	ID        int                     `json:"id"`
+	Nomor     string                  `json:"nomor"`
+	POID      int                     `json:"poId"`
+	Penerima  string                  `json:"penerima"`
+	Catatan   string                  `json:"catatan"`
+	CreatedAt time.Time               `json:"createdAt"`
+	Items     []*PenerimaanBarangItem `json:"items,omitempty"`
+}
+
+// PenerimaanBarangItem represents the received quantity of one purchase order line
+type PenerimaanBarangItem struct {
+	ID             int     `json:"id"`
+	PenerimaanID   int     `json:"penerimaanId"`
+	POItemID       int     `json:"poItemId"`
+	ProdukID       int     `json:"produkId"`
+	NamaProduk     string  `json:"namaProduk"`
+	Qty            float64 `json:"qty"`
+	HargaBeli      int     `json:"hargaBeli"`      // Harga beli aktual per satuan
+	MasaSimpanHari int     `json:"masaSimpanHari"` // 0 = tidak dibuat batch
+	BatchID        string  `json:"batchId,omitempty"`
+}
+
+// SimpanPurchaseOrderRequest creates a draft purchase order or replaces its lines
+type SimpanPurchaseOrderRequest struct {
+	ID                int                        `json:"id"` // 0 = PO baru
+	SupplierID        int                        `json:"supplierId"`
+	TanggalDiharapkan *time.Time                 `json:"tanggalDiharapkan"`
+	Catatan           string                     `json:"catatan"`
+	DibuatOleh        string                     `json:"dibuatOleh"`
+	Items             []PurchaseOrderItemRequest `json:"items"`
+}
+
+// PurchaseOrderItemRequest represents an ordered line
+type PurchaseOrderItemRequest struct {
+	ProdukID      int     `json:"produkId"`
+	QtyPesan      float64 `json:"qtyPesan"`
+	HargaEstimasi int     `json:"hargaEstimasi"` // 0 = harga beli produk saat ini
+}
+
+// TerimaBarangRequest records the goods received for a purchase order
+type TerimaBarangRequest struct {
+	POID     int                       `json:"poId"`
+	Penerima string                    `json:"penerima"`
+	Catatan  string                    `json:"catatan"`
+	Items    []TerimaBarangItemRequest `json:"items"`
+}
+
+// TerimaBarangItemRequest represents the received quantity of one purchase order line
+type TerimaBarangItemRequest struct {
+	POItemID       int     `json:"poItemId"`
+	Qty            float64 `json:"qty"`
+	HargaBeli      int     `json:"hargaBeli"`      // 0 = harga estimasi PO
+	MasaSimpanHari int     `json:"masaSimpanHari"` // 0 = masa simpan produk
+}
+
+// SaranPembelianRequest configures the purchase suggestion
+type SaranPembelianRequest struct {
+	HariAnalisis int `json:"hariAnalisis"` // Periode penjualan untuk menghitung penjualan harian, 0 = 30 hari
+	HariCakupan  int `json:"hariCakupan"`  // Stok yang ingin dicukupi setelah barang datang, 0 = 14 hari
+	SupplierID   int `json:"supplierId"`   // 0 = semua supplier
+}
+
+// SaranPembelianSupplier groups the suggested order lines of one supplier;
+// SupplierID 0 collects products whose supplier is not known yet
+type SaranPembelianSupplier struct {
+	SupplierID    int                   `json:"supplierId"`
+	NamaSupplier  string                `json:"namaSupplier"`
+	TotalEstimasi int                   `json:"totalEstimasi"`
+	Items         []*SaranPembelianItem `json:"items"`
+}
+
+// SaranPembelianItem is a suggested order quantity for a product that reached its reorder point
+type SaranPembelianItem struct {
+	ProdukID           int     `json:"produkId"`
+	SKU                string  `json:"sku"`
+	NamaProduk         string  `json:"namaProduk"`
+	Satuan             string  `json:"satuan"`
+	Stok               float64 `json:"stok"`
+	QtyDalamPesanan    float64 `json:"qtyDalamPesanan"` // Sisa PO yang sudah dikirim dan belum diterima
+	PenjualanHarian    float64 `json:"penjualanHarian"` // Rata-rata terjual per hari dalam satuan dasar
+	LeadTimeHari       int     `json:"leadTimeHari"`
+	TitikPesan         float64 `json:"titikPesan"` // Stok minimum, atau penjualan harian x lead time
+	StokTarget         float64 `json:"stokTarget"` // Stok maksimum, atau titik pesan + penjualan harian x hari cakupan
+	QtyDisarankan      float64 `json:"qtyDisarankan"`
+	HargaBeli          int     `json:"hargaBeli"`
+	Subtotal           int     `json:"subtotal"`
+	MasaSimpanHari     int     `json:"masaSimpanHari"`
+	DibatasiMasaSimpan bool    `json:"dibatasiMasaSimpan"` // Stok target dikurangi agar tidak melebihi yang terjual sebelum kadaluarsa
+}
@@ -12,20 +12,45 @@ type Return struct {
 	Type                  string    `json:"type"`   // "refund" or "exchange"
 	ReplacementProductID  int       `json:"replacement_product_id,omitempty"`
 	RefundAmount          int       `json:"refund_amount"`
-	RefundMethod          string    `json:"refund_method,omitempty"` // "tunai", "transfer"
+	RefundMethod          string    `json:"refund_method,omitempty"` // "tunai", "transfer", "kartu_hadiah"
 	RefundStatus          string    `json:"refund_status"`            // "pending", "completed", "cancelled"
 	Notes                 string    `json:"notes,omitempty"`
+	ShiftID               int       `json:"shift_id,omitempty"` // Shift kasir tempat refund dicatat
+	PotongPiutang         int       `json:"potong_piutang"`     // Bagian refund yang mengurangi sisa kasbon, tidak dibayarkan
 	CreatedAt             time.Time `json:"createdAt"`
 	UpdatedAt             time.Time `json:"updatedAt"`
 }
 
 // ReturnItem represents a product item in a return
 type ReturnItem struct {
-	ID        int       `json:"id"`
-	ReturnID  int       `json:"return_id"`
-	ProductID int       `json:"product_id"`
-	Quantity  int       `json:"quantity"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID              int       `json:"id"`
+	ReturnID        int       `json:"return_id"`
+	ProductID       int       `json:"product_id"`
+	TransaksiItemID int       `json:"transaksi_item_id"` // Baris transaksi asal; 0 untuk return lama yang belum ditautkan
+	Quantity        int       `json:"quantity"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// ReturnBaru is a validated return with every change it makes, written by the
+// repository in one database transaction
+type ReturnBaru struct {
+	Return          *Return
+	Items           []*ReturnBaruItem
+	KembalikanStok  bool         // false untuk barang rusak, stok tidak kembali ke rak
+	QtyPengganti    float64      // Qty produk pengganti dalam satuan dasar, untuk tukar barang
+	StatusTransaksi string       // Status transaksi asal setelah return
+	PelangganID     int          // Pelanggan yang poin dan total belanjanya dikurangi; 0 jika tidak ada
+	PoinDikurangi   int          // Poin yang ditarik dari pelanggan karena refund
+	KartuHadiah     *KartuHadiah // Store credit untuk bagian refund yang dibayar; nil jika tidak diterbitkan
+	IdempotencyKey  string       // Disimpan dalam transaksi database yang sama dengan return
+	IdempotencyHash string
+}
+
+// ReturnBaruItem is a returned sale line with the stock it puts back
+type ReturnBaruItem struct {
+	Item     *ReturnItem
+	QtyDasar float64           // Qty yang kembali dalam satuan dasar produk
+	Komponen []*ProdukKomponen // Komponen paket yang stoknya kembali; kosong jika bukan paket
 }
 
 // ReturnDetail represents complete return with items
@@ -51,12 +76,17 @@ type CreateReturnRequest struct {
 	Type                 string                 `json:"type"`
 	ReplacementProductID int                    `json:"replacement_product_id,omitempty"`
 	ReturnDate           string                 `json:"return_date"`
-	RefundMethod         string                 `json:"refund_method,omitempty"` // "tunai", "transfer"
+	RefundMethod         string                 `json:"refund_method,omitempty"` // "tunai", "transfer", "kartu_hadiah" (store credit)
 	Notes                string                 `json:"notes,omitempty"`
+	KodeTerminal         string                 `json:"kode_terminal,omitempty"`   // Terminal kasir yang mengeluarkan refund tunai
+	IdempotencyKey       string                 `json:"idempotency_key,omitempty"` // Mencegah return ganda saat client retry
+	IdempotencyHash      string                 `json:"-"`                         // Hash payload asli, diisi service untuk disimpan bersama key
+	KartuHadiah          *KartuHadiah           `json:"-"`                         // Diisi service jika refund diterbitkan sebagai kartu hadiah
 }
 
 // ReturnProductRequest represents product in create return request
 type ReturnProductRequest struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID       int `json:"product_id"`
+	TransaksiItemID int `json:"transaksi_item_id,omitempty"` // Wajib jika produk dijual di lebih dari satu baris (mis. pcs dan dus)
+	Quantity        int `json:"quantity"`                    // Dalam satuan baris transaksi
 }
@@ -65,6 +65,31 @@ type DiscountTypeBreakdown struct {
 	Persentase  float64 `json:"persentase"` // Percentage of total discount
 }
 
+// PromoDiscountBreakdown represents discount given per applied promo
+type PromoDiscountBreakdown struct {
+	PromoID     int    `json:"promoId"`
+	NamaPromo   string `json:"namaPromo"`
+	KodePromo   string `json:"kodePromo"`
+	TotalDiskon int    `json:"totalDiskon"`
+	Jumlah      int    `json:"jumlah"` // Count of transactions
+}
+
+// MarkdownAnalysis represents the near-expiry markdown given on sold items
+type MarkdownAnalysis struct {
+	TotalMarkdown   int                  `json:"totalMarkdown"`
+	JumlahTransaksi int                  `json:"jumlahTransaksi"` // Transactions with at least one marked down item
+	JumlahItem      int                  `json:"jumlahItem"`      // Marked down transaction lines
+	Produk          []*MarkdownBreakdown `json:"produk"`
+}
+
+// MarkdownBreakdown represents the near-expiry markdown given per product
+type MarkdownBreakdown struct {
+	ProdukID      int    `json:"produkId"`
+	NamaProduk    string `json:"namaProduk"`
+	TotalMarkdown int    `json:"totalMarkdown"`
+	JumlahItem    int    `json:"jumlahItem"` // Marked down transaction lines
+}
+
 // PaymentMethodBreakdown represents breakdown by payment method
 type PaymentMethodBreakdown struct {
 	Method     string  `json:"method"` // "tunai", "qris", "debit", "kredit"
@@ -73,6 +98,50 @@ type PaymentMethodBreakdown struct {
 	Persentase float64 `json:"persentase"` // Percentage of total transactions
 }
 
+// TaxBreakdown represents DPP and PPN per tax code
+type TaxBreakdown struct {
+	KodePajak  string  `json:"kodePajak"` // "PPN11", "PPN12", "BEBAS"
+	TarifPajak float64 `json:"tarifPajak"`
+	TotalDPP   int     `json:"totalDpp"`
+	TotalPajak int     `json:"totalPajak"`
+	Jumlah     int     `json:"jumlah"` // Count of items
+
+	// Bagian yang dikembalikan lewat refund, sudah dikurangkan dari total
+	ReturDPP   int `json:"returDpp"`
+	ReturPajak int `json:"returPajak"`
+}
+
+// PajakRetur represents the DPP and PPN given back through refunds for a tax code
+type PajakRetur struct {
+	KodePajak  string
+	TarifPajak float64
+	DPP        int
+	Pajak      int
+}
+
+// TaxSummary represents the PPN collected in a period
+type TaxSummary struct {
+	TotalDPP            int             `json:"totalDpp"`
+	TotalPajak          int             `json:"totalPajak"`
+	TransaksiInklusif   int             `json:"transaksiInklusif"`  // Count of transactions priced tax-inclusive
+	TransaksiEksklusif  int             `json:"transaksiEksklusif"` // Count of transactions priced tax-exclusive
+	TransaksiTanpaPajak int             `json:"transaksiTanpaPajak"`
+	Breakdown           []*TaxBreakdown `json:"breakdown"`
+
+	// Bagian yang dikembalikan lewat refund, sudah dikurangkan dari total
+	ReturDPP   int `json:"returDpp"`
+	ReturPajak int `json:"returPajak"`
+}
+
+// PembulatanSummary represents the cumulative cash rounding in a period, so the
+// sum of item sales, discounts and PPN reconciles with the collected totals
+type PembulatanSummary struct {
+	TotalPembulatan      int `json:"totalPembulatan"`      // Net rounding (positive = collected more than calculated)
+	TotalPembulatanAtas  int `json:"totalPembulatanAtas"`  // Sum of rounding up
+	TotalPembulatanBawah int `json:"totalPembulatanBawah"` // Sum of rounding down (negative)
+	TransaksiDibulatkan  int `json:"transaksiDibulatkan"`  // Count of rounded transactions
+}
+
 // LossBreakdownItem represents loss breakdown by type
 type LossBreakdownItem struct {
 	Type       string  `json:"type"`       // "expired", "damaged", "lost", "other"
@@ -102,8 +171,12 @@ type ComprehensiveSalesReport struct {
 	TopProducts            []*TopProductData                        `json:"topProducts"`
 	DiscountAnalysis       *DiscountAnalysis                        `json:"discountAnalysis"`
 	DiscountTypeBreakdown  []*DiscountTypeBreakdown                 `json:"discountTypeBreakdown"`
+	PromoBreakdown         []*PromoDiscountBreakdown                `json:"promoBreakdown"`
+	MarkdownAnalysis       *MarkdownAnalysis                        `json:"markdownAnalysis"` // Terpisah dari diskon promo dan manual
 	PaymentMethodBreakdown []*PaymentMethodBreakdown                `json:"paymentMethodBreakdown"`
 	LossAnalysis           *LossAnalysisData                        `json:"lossAnalysis"`
+	TaxSummary             *TaxSummary                              `json:"taxSummary"`
+	PembulatanSummary      *PembulatanSummary                       `json:"pembulatanSummary"`
 	StartDate              time.Time                                `json:"startDate"`
 	EndDate                time.Time                                `json:"endDate"`
 	GeneratedAt            time.Time                                `json:"generatedAt"`
@@ -20,4 +20,121 @@ type UpdatePoinSettingsRequest struct {
 	Level3MinPoints         int `json:"level3MinPoints"`
 	Level2MinSpending       int `json:"level2MinSpending"` // Legacy
 	Level3MinSpending       int `json:"level3MinSpending"` // Legacy
-}
\ No newline at end of file
+}
+
+// Format tanggal dan periode reset yang didukung untuk penomoran transaksi
+const (
+	FormatTanggalYYYYMMDD = "YYYYMMDD"
+	FormatTanggalYYMMDD   = "YYMMDD"
+	FormatTanggalYYYYMM   = "YYYYMM"
+	FormatTanggalYYMM     = "YYMM"
+
+	ResetCounterHarian  = "harian"
+	ResetCounterBulanan = "bulanan"
+)
+
+// NomorTransaksiSettings represents the transaction numbering scheme,
+// e.g. TRX-T01-20261018-0001 (prefix, kode terminal, tanggal, counter)
+type NomorTransaksiSettings struct {
+	ID             int    `json:"id"`
+	Prefix         string `json:"prefix"`         // Awalan nomor, mis. "TRX"
+	KodeTerminal   string `json:"kodeTerminal"`   // Kode terminal default jika request tidak mengirim kode terminal
+	FormatTanggal  string `json:"formatTanggal"`  // "YYYYMMDD", "YYMMDD", "YYYYMM" atau "YYMM"
+	PanjangCounter int    `json:"panjangCounter"` // Jumlah digit counter (zero-padded)
+	ResetCounter   string `json:"resetCounter"`   // "harian" atau "bulanan"
+}
+
+type UpdateNomorTransaksiSettingsRequest struct {
+	Prefix         string `json:"prefix"`
+	KodeTerminal   string `json:"kodeTerminal"`
+	FormatTanggal  string `json:"formatTanggal"`
+	PanjangCounter int    `json:"panjangCounter"`
+	ResetCounter   string `json:"resetCounter"`
+}
+
+// Kode pajak dan mode harga yang didukung untuk PPN
+const (
+	KodePajakPPN11 = "PPN11" // PPN 11%
+	KodePajakPPN12 = "PPN12" // PPN 12%
+	KodePajakBebas = "BEBAS" // Dibebaskan dari PPN
+
+	ModeHargaInklusif  = "inklusif"  // Harga jual sudah termasuk PPN
+	ModeHargaEksklusif = "eksklusif" // PPN ditambahkan di atas harga jual
+)
+
+// PajakSettings represents the PPN (VAT) configuration of the store
+type PajakSettings struct {
+	ID               int    `json:"id"`
+	Aktif            bool   `json:"aktif"`            // PPN hanya dihitung jika toko berstatus PKP
+	ModeHarga        string `json:"modeHarga"`        // "inklusif" atau "eksklusif"
+	KodePajakDefault string `json:"kodePajakDefault"` // Kode pajak untuk produk & kategori tanpa kode pajak
+	NPWP             string `json:"npwp"`             // NPWP toko, dicetak di struk
+}
+
+type UpdatePajakSettingsRequest struct {
+	Aktif            bool   `json:"aktif"`
+	ModeHarga        string `json:"modeHarga"`
+	KodePajakDefault string `json:"kodePajakDefault"`
+	NPWP             string `json:"npwp"`
+}
+
+// HargaSettings represents the limits for manual price overrides and line discounts
+type HargaSettings struct {
+	ID                  int     `json:"id"`
+	BatasOverridePersen float64 `json:"batasOverridePersen"` // Selisih harga (persen dari harga jual) yang boleh tanpa persetujuan admin
+}
+
+// UpdateHargaSettingsRequest represents request to update the price override limits
+type UpdateHargaSettingsRequest struct {
+	BatasOverridePersen float64 `json:"batasOverridePersen"`
+}
+
+// Mode pembulatan total transaksi
+const (
+	ModePembulatanTerdekat = "terdekat" // Dibulatkan ke kelipatan terdekat
+	ModePembulatanBawah    = "bawah"    // Selalu dibulatkan ke bawah
+	ModePembulatanAtas     = "atas"     // Selalu dibulatkan ke atas
+)
+
+// PembulatanSettings represents the cash rounding policy applied to transaction totals
+type PembulatanSettings struct {
+	ID         int    `json:"id"`
+	Aktif      bool   `json:"aktif"`
+	Mode       string `json:"mode"`       // "terdekat", "bawah" atau "atas"
+	Kelipatan  int    `json:"kelipatan"`  // Total dibulatkan ke kelipatan rupiah ini, mis. 100 atau 500
+	HanyaTunai bool   `json:"hanyaTunai"` // Hanya dibulatkan jika seluruh pembayaran tunai
+}
+
+// UpdatePembulatanSettingsRequest represents request to update the cash rounding policy
+type UpdatePembulatanSettingsRequest struct {
+	Aktif      bool   `json:"aktif"`
+	Mode       string `json:"mode"`
+	Kelipatan  int    `json:"kelipatan"`
+	HanyaTunai bool   `json:"hanyaTunai"`
+}
+
+// KartuHadiahSettings represents the validity rules of gift cards and store credit
+type KartuHadiahSettings struct {
+	ID                   int `json:"id"`
+	MasaBerlakuHari      int `json:"masaBerlakuHari"`      // Masa berlaku kartu yang dijual, 0 = tidak kedaluwarsa
+	MasaBerlakuReturHari int `json:"masaBerlakuReturHari"` // Masa berlaku store credit dari retur, 0 = tidak kedaluwarsa
+}
+
+// UpdateKartuHadiahSettingsRequest represents request to update the gift card validity rules
+type UpdateKartuHadiahSettingsRequest struct {
+	MasaBerlakuHari      int `json:"masaBerlakuHari"`
+	MasaBerlakuReturHari int `json:"masaBerlakuReturHari"`
+}
+
+// BatchKadaluarsaSettings represents the schedule of the expired batch job
+type BatchKadaluarsaSettings struct {
+	ID            int  `json:"id"`
+	HapusOtomatis bool `json:"hapusOtomatis"` // Stok batch kadaluarsa otomatis dihapus sebagai kerugian
+	IntervalJam   int  `json:"intervalJam"`   // Jarak antar pemeriksaan batch kadaluarsa
+}
+
+// UpdateBatchKadaluarsaSettingsRequest represents request to update the expired batch job schedule
+type UpdateBatchKadaluarsaSettingsRequest struct {
+	HapusOtomatis bool `json:"hapusOtomatis"`
+	IntervalJam   int  `json:"intervalJam"`
+}
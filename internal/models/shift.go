@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// Status shift dan jenis mutasi kas yang didukung
+const (
+	StatusShiftBuka  = "buka"
+	StatusShiftTutup = "tutup"
+
+	MutasiKasMasuk  = "masuk"  // Tambahan kas, mis. penukaran uang kecil
+	MutasiKasKeluar = "keluar" // Setoran (drop) atau kas kecil
+
+	MetodeTunai = "tunai" // Metode pembayaran/refund yang memengaruhi kas laci
+)
+
+// ShiftKasir represents a cashier session on a terminal, from opening float to cash count
+type ShiftKasir struct {
+	ID              int        `json:"id"`
+	KodeTerminal    string     `json:"kodeTerminal"`
+	StaffID         *int       `json:"staffId"`
+	StaffNama       string     `json:"staffNama"`
+	KasAwal         int        `json:"kasAwal"`       // Modal kas awal di laci
+	KasSeharusnya   int        `json:"kasSeharusnya"` // Kas yang seharusnya ada di laci saat shift ditutup
+	KasDihitung     *int       `json:"kasDihitung"`   // Kas hasil hitung saat shift ditutup
+	Selisih         int        `json:"selisih"`       // Kas dihitung - kas seharusnya (negatif = kurang)
+	Status          string     `json:"status"`        // "buka" atau "tutup"
+	Catatan         string     `json:"catatan"`
+	DibukaAt        time.Time  `json:"dibukaAt"`
+	DitutupAt       *time.Time `json:"ditutupAt,omitempty"`
+	DitutupOlehID   *int       `json:"ditutupOlehId,omitempty"`
+	DitutupOlehNama string     `json:"ditutupOlehNama,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// ShiftKasMutasi represents cash put into or taken out of the drawer during a shift
+type ShiftKasMutasi struct {
+	ID         int       `json:"id"`
+	ShiftID    int       `json:"shiftId"`
+	Jenis      string    `json:"jenis"` // "masuk" atau "keluar"
+	Jumlah     int       `json:"jumlah"`
+	Keterangan string    `json:"keterangan"`
+	StaffID    *int      `json:"staffId"`
+	StaffNama  string    `json:"staffNama"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// BukaShiftRequest represents a request to open a shift
+type BukaShiftRequest struct {
+	KodeTerminal string `json:"kodeTerminal"` // Kosong = kode terminal default
+	StaffID      int    `json:"staffId"`
+	StaffNama    string `json:"staffNama"`
+	KasAwal      int    `json:"kasAwal"`
+	Catatan      string `json:"catatan"`
+}
+
+// MutasiKasRequest represents a cash in/out entry on the active shift
+type MutasiKasRequest struct {
+	KodeTerminal string `json:"kodeTerminal"`
+	Jenis        string `json:"jenis"` // "masuk" atau "keluar"
+	Jumlah       int    `json:"jumlah"`
+	Keterangan   string `json:"keterangan"`
+	StaffID      int    `json:"staffId"`
+	StaffNama    string `json:"staffNama"`
+}
+
+// TutupShiftRequest represents a request to close the active shift with the counted cash
+type TutupShiftRequest struct {
+	KodeTerminal string `json:"kodeTerminal"`
+	KasDihitung  int    `json:"kasDihitung"`
+	Catatan      string `json:"catatan"`
+	StaffID      int    `json:"staffId"`
+	StaffNama    string `json:"staffNama"`
+}
+
+// ShiftPembayaranSummary represents sales per payment method in a shift
+type ShiftPembayaranSummary struct {
+	Metode string `json:"metode"`
+	Nama   string `json:"nama"`   // Nama metode dari daftar metode pembayaran
+	Jumlah int    `json:"jumlah"` // Jumlah pembayaran
+	Total  int    `json:"total"`
+}
+
+// LaporanShift is the X report (mid-shift) or Z report (at close) of a shift
+type LaporanShift struct {
+	Jenis           string                    `json:"jenis"` // "X" atau "Z"
+	Shift           *ShiftKasir               `json:"shift"`
+	JumlahTransaksi int                       `json:"jumlahTransaksi"`
+	TotalPenjualan  int                       `json:"totalPenjualan"`
+	TotalDiskon     int                       `json:"totalDiskon"`
+	TotalPajak      int                       `json:"totalPajak"`
+	JumlahVoid      int                       `json:"jumlahVoid"`
+	TotalVoid       int                       `json:"totalVoid"`
+	Pembayaran      []*ShiftPembayaranSummary `json:"pembayaran"`
+	PenjualanTunai  int                       `json:"penjualanTunai"` // Pembayaran tunai dikurangi kembalian
+	RefundTunai     int                       `json:"refundTunai"`
+	JumlahRefund    int                       `json:"jumlahRefund"`
+	KasMasuk        int                       `json:"kasMasuk"`
+	KasKeluar       int                       `json:"kasKeluar"`
+	MutasiKas       []*ShiftKasMutasi         `json:"mutasiKas"`
+	KasSeharusnya   int                       `json:"kasSeharusnya"`
+	KasDihitung     *int                      `json:"kasDihitung,omitempty"` // Hanya pada laporan Z
+	Selisih         int                       `json:"selisih"`
+	DibuatAt        time.Time                 `json:"dibuatAt"`
+
+	// Penjualan kartu hadiah: kas diterima, tetapi bukan omzet
+	JumlahKartuHadiah int `json:"jumlahKartuHadiah"`
+	TotalKartuHadiah  int `json:"totalKartuHadiah"`
+
+	// Uang tunai dikembalikan untuk void penjualan dari shift sebelumnya
+	VoidTunai int `json:"voidTunai"`
+}
+
+// PrintLaporanShiftRequest represents a request to print an X or Z report
+type PrintLaporanShiftRequest struct {
+	PrinterName string `json:"printerName"`
+	ShiftID     int    `json:"shiftId"`
+}
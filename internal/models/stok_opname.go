@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Status stok opname
+const (
+	StatusOpnameBerjalan   = "berjalan"   // Sedang dihitung, stok sistem sudah dibekukan
+	StatusOpnameDisetujui  = "disetujui"  // Selisih sudah diposting ke stok
+	StatusOpnameDibatalkan = "dibatalkan" // Dibatalkan tanpa mengubah stok
+)
+
+// Tipe selisih stok opname pada stok_history
+const (
+	TipeKerugianHilang    = "hilang"    // Stok fisik kurang dari stok sistem
+	TipeKerugianKelebihan = "kelebihan" // Stok fisik lebih dari stok sistem
+)
+
+// StokOpname represents a physical inventory count session
+type StokOpname struct {
+	ID            int        `json:"id"`
+	Nomor         string     `json:"nomor"`
+	Kategori      string     `json:"kategori"` // Kosong = semua kategori
+	Status        string     `json:"status"`
+	Catatan       string     `json:"catatan"`
+	DibuatOleh    string     `json:"dibuatOleh"`
+	DisetujuiOleh string     `json:"disetujuiOleh"`
+	SelesaiAt     *time.Time `json:"selesaiAt,omitempty"` // Waktu disetujui atau dibatalkan
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+
+	JumlahProduk   int `json:"jumlahProduk"`
+	JumlahDihitung int `json:"jumlahDihitung"`
+	NilaiKurang    int `json:"nilaiKurang"` // Nilai HPP stok yang kurang dari stok sistem
+	NilaiLebih     int `json:"nilaiLebih"`  // Nilai HPP stok yang lebih dari stok sistem
+
+	Items []*StokOpnameItem `json:"items,omitempty"`
+}
+
+// StokOpnameItem represents the frozen system quantity and counted quantity of one product.
+// A negative SelisihQty is a shortage, a positive one a surplus.
+type StokOpnameItem struct {
+	ID           int        `json:"id"`
+	OpnameID     int        `json:"opnameId"`
+	ProdukID     int        `json:"produkId"`
+	SKU          string     `json:"sku"`
+	NamaProduk   string     `json:"namaProduk"`
+	Satuan       string     `json:"satuan"`
+	StokSistem   float64    `json:"stokSistem"` // Stok sistem saat opname dimulai
+	HargaPokok   int        `json:"hargaPokok"` // HPP saat opname dimulai
+	StokFisik    *float64   `json:"stokFisik"`  // Kosong = belum dihitung
+	SelisihQty   float64    `json:"selisihQty"` // Stok fisik - stok sistem
+	SelisihNilai int        `json:"selisihNilai"`
+	DihitungAt   *time.Time `json:"dihitungAt,omitempty"`
+}
+
+// MulaiOpnameRequest starts a stock opname
+type MulaiOpnameRequest struct {
+	Kategori   string `json:"kategori"` // Kosong = semua kategori
+	Catatan    string `json:"catatan"`
+	DibuatOleh string `json:"dibuatOleh"`
+}
+
+// HitungOpnameRequest records the counted quantity of a product, either by
+// product ID (the quantity replaces the count) or by scanning a barcode (the
+// quantity is added to the count, in the unit of the scanned barcode)
+type HitungOpnameRequest struct {
+	OpnameID int     `json:"opnameId"`
+	ProdukID int     `json:"produkId"`
+	Barcode  string  `json:"barcode"`
+	Qty      float64 `json:"qty"` // Untuk scan barcode, 0 = 1 satuan (label timbangan: berat pada label)
+}
+
+// SetujuiOpnameRequest approves a stock opname and posts its variances
+type SetujuiOpnameRequest struct {
+	OpnameID      int    `json:"opnameId"`
+	DisetujuiOleh string `json:"disetujuiOleh"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Supplier represents a supplier in the master data; batches reference it by ID
+type Supplier struct {
+	ID         int       `json:"id"`
+	Nama       string    `json:"nama"`
+	Kontak     string    `json:"kontak"` // Nama kontak, telepon atau email
+	Alamat     string    `json:"alamat"`
+	NPWP       string    `json:"npwp"`
+	TerminHari int       `json:"terminHari"` // Termin pembayaran dalam hari, 0 = tunai
+	Aktif      bool      `json:"aktif"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+
+	// Supplier duplikat yang sudah digabung: tidak tampil lagi, namanya mengarah ke supplier tujuan
+	DigabungKeID *int       `json:"digabungKeId,omitempty"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
+}
+
+// GabungSupplierRequest merges a duplicate supplier into the supplier that is kept
+type GabungSupplierRequest struct {
+	DuplikatID int `json:"duplikatId"` // Supplier yang digabung lalu dihapus
+	TujuanID   int `json:"tujuanId"`   // Supplier yang dipertahankan
+}
+
+// LaporanSupplier summarizes the stock received from a supplier and what was lost of it.
+// Values use the current purchase price of each product.
+type LaporanSupplier struct {
+	SupplierID      int     `json:"supplierId"`
+	NamaSupplier    string  `json:"namaSupplier"`
+	JumlahBatch     int     `json:"jumlahBatch"` // Batch yang diterima dalam periode
+	QtyDiterima     float64 `json:"qtyDiterima"`
+	NilaiDiterima   int     `json:"nilaiDiterima"`
+	QtyKadaluarsa   float64 `json:"qtyKadaluarsa"` // Sisa stok batch periode ini yang sudah lewat tanggal kadaluarsa
+	NilaiKadaluarsa int     `json:"nilaiKadaluarsa"`
+	QtyDihapus      float64 `json:"qtyDihapus"` // Stok yang dihapus (rusak, hilang, kadaluarsa) dalam periode
+	NilaiDihapus    int     `json:"nilaiDihapus"`
+}
@@ -4,27 +4,36 @@ import "time"
 
 // Transaksi represents a transaction header
 type Transaksi struct {
-	ID              int       `json:"id"`
-	NomorTransaksi  string    `json:"nomorTransaksi"`
-	Tanggal         time.Time `json:"tanggal"`
-	PelangganID     int       `json:"pelangganId"`
-	PelangganNama   string    `json:"pelangganNama"`
-	PelangganTelp   string    `json:"pelangganTelp"`
-	Subtotal        int       `json:"subtotal"`
-	DiskonPromo     int       `json:"diskonPromo"`
-	DiskonPelanggan int       `json:"diskonPelanggan"`
-	PoinDitukar     int       `json:"poinDitukar"` // Jumlah poin yang ditukar
-	DiskonPoin      int       `json:"diskonPoin"`  // Nilai rupiah dari poin yang ditukar
-	Diskon          int       `json:"diskon"`
-	Total           int       `json:"total"`
-	TotalBayar      int       `json:"totalBayar"`
-	Kembalian       int       `json:"kembalian"`
-	Status          string    `json:"status"`
-	Catatan         string    `json:"catatan"`
-	Kasir           string    `json:"kasir"`     // Legacy field - nama kasir
-	StaffID         *int      `json:"staffId"`   // ID staff yang melakukan transaksi (nullable untuk backward compatibility)
-	StaffNama       string    `json:"staffNama"` // Nama staff (denormalized untuk performa)
-	CreatedAt       time.Time `json:"createdAt"`
+	ID              int        `json:"id"`
+	NomorTransaksi  string     `json:"nomorTransaksi"`
+	Tanggal         time.Time  `json:"tanggal"`
+	PelangganID     int        `json:"pelangganId"`
+	PelangganNama   string     `json:"pelangganNama"`
+	PelangganTelp   string     `json:"pelangganTelp"`
+	Subtotal        int        `json:"subtotal"`
+	DiskonPromo     int        `json:"diskonPromo"`
+	PromoID         *int       `json:"promoId"` // Promo yang diterapkan (nullable)
+	DiskonPelanggan int        `json:"diskonPelanggan"`
+	PoinDitukar     int        `json:"poinDitukar"` // Jumlah poin yang ditukar
+	DiskonPoin      int        `json:"diskonPoin"`  // Nilai rupiah dari poin yang ditukar
+	Diskon          int        `json:"diskon"`
+	Total           int        `json:"total"`
+	TotalBayar      int        `json:"totalBayar"`
+	Kembalian       int        `json:"kembalian"`
+	Status          string     `json:"status"`
+	Catatan         string     `json:"catatan"`
+	Kasir           string     `json:"kasir"`                  // Legacy field - nama kasir
+	StaffID         *int       `json:"staffId"`                // ID staff yang melakukan transaksi (nullable untuk backward compatibility)
+	StaffNama       string     `json:"staffNama"`              // Nama staff (denormalized untuk performa)
+	VoidAlasan      string     `json:"voidAlasan,omitempty"`   // Alasan pembatalan (hanya untuk status void)
+	VoidOlehNama    string     `json:"voidOlehNama,omitempty"` // Nama admin yang menyetujui pembatalan
+	VoidAt          *time.Time `json:"voidAt,omitempty"`       // Waktu pembatalan
+	ModePajak       string     `json:"modePajak"`              // "inklusif", "eksklusif", atau kosong jika PPN tidak aktif
+	TotalDPP        int        `json:"totalDpp"`               // Dasar pengenaan pajak setelah diskon
+	TotalPajak      int        `json:"totalPajak"`             // Total PPN
+	ShiftID         *int       `json:"shiftId"`                // Shift kasir saat transaksi dibuat (nullable untuk transaksi lama)
+	Pembulatan      int        `json:"pembulatan"`             // Selisih pembulatan total (positif = dibulatkan ke atas)
+	CreatedAt       time.Time  `json:"createdAt"`
 }
 
 // TransaksiItem represents a line item in a transaction
@@ -39,7 +48,30 @@ type TransaksiItem struct {
 	Jumlah         int       `json:"jumlah"`      // Quantity (untuk backward compatibility)
 	BeratGram      float64   `json:"beratGram"`   // Berat dalam gram (0 jika dijual per quantity)
 	Subtotal       int       `json:"subtotal"`
+	KodePajak      string    `json:"kodePajak"`
+	TarifPajak     float64   `json:"tarifPajak"` // Persen, mis. 11
+	DPP            int       `json:"dpp"`        // Dasar pengenaan pajak setelah alokasi diskon
+	Pajak          int       `json:"pajak"`
+	HargaAsli      int       `json:"hargaAsli"`                // Harga jual produk saat transaksi
+	HargaOverride  *int      `json:"hargaOverride,omitempty"`  // Harga manual jika kasir mengubah harga
+	DiskonTipe     string    `json:"diskonTipe,omitempty"`     // "persen" atau "nominal"
+	DiskonNilai    float64   `json:"diskonNilai,omitempty"`    // Persen atau rupiah sesuai DiskonTipe
+	DiskonItem     int       `json:"diskonItem"`               // Diskon baris dalam rupiah (sudah dikurangkan dari subtotal)
+	AlasanOverride string    `json:"alasanOverride,omitempty"` // Alasan perubahan harga / diskon baris
+	ApproverID     *int      `json:"approverId,omitempty"`     // Admin yang menyetujui harga / diskon baris
+	ApproverNama   string    `json:"approverNama,omitempty"`   // Nama admin yang menyetujui
 	CreatedAt      time.Time `json:"createdAt"`
+
+	// Satuan jual baris; Jumlah dalam satuan ini, stok berkurang Jumlah x Konversi satuan dasar
+	Satuan   string  `json:"satuan,omitempty"`
+	Konversi float64 `json:"konversi"`
+
+	// HPP rata-rata bergerak per satuan dasar (per kg untuk curah) saat barang dijual
+	HargaPokok int `json:"hargaPokok"`
+
+	// Markdown unit dari batch hampir kadaluarsa (sudah dikurangkan dari subtotal)
+	DiskonMarkdown     int    `json:"diskonMarkdown"`
+	KeteranganMarkdown string `json:"keteranganMarkdown,omitempty"` // Mis. "Markdown 20% x 2"
 }
 
 // Pembayaran represents a payment method used in a transaction
@@ -67,15 +99,29 @@ type CreateTransaksiRequest struct {
 	Items           []TransaksiItemRequest `json:"items"`
 	Pembayaran      []PembayaranRequest    `json:"pembayaran"`
 	PromoKode       string                 `json:"promoKode"`
+	PromoID         int                    `json:"-"`               // Diisi service setelah promo divalidasi ulang
 	PoinDitukar     int                    `json:"poinDitukar"`     // Jumlah poin yang ingin ditukar
 	Diskon          int                    `json:"diskon"`          // Total diskon
 	DiskonPromo     int                    `json:"diskonPromo"`     // Diskon dari promo
 	DiskonPelanggan int                    `json:"diskonPelanggan"` // Diskon dari level pelanggan
+	DiskonPoin      int                    `json:"-"`               // Diisi service dari poin yang ditukar
+	ModePajak       string                 `json:"-"`               // Diisi service dari pengaturan pajak
+	TotalDPP        int                    `json:"-"`               // Diisi service dari perhitungan pajak
+	TotalPajak      int                    `json:"-"`               // Diisi service dari perhitungan pajak
+	ShiftID         int                    `json:"-"`               // Diisi service dari shift kasir yang aktif
+	Pembulatan      int                    `json:"-"`               // Diisi service dari pengaturan pembulatan
 	Catatan         string                 `json:"catatan"`
 	Kasir           string                 `json:"kasir"`
-	StaffID         int                    `json:"staffId"`   // ID staff yang melakukan transaksi
-	StaffNama       string                 `json:"staffNama"` // Nama staff
+	StaffID         int                    `json:"staffId"`        // ID staff yang melakukan transaksi
+	StaffNama       string                 `json:"staffNama"`      // Nama staff
+	KodeTerminal    string                 `json:"kodeTerminal"`   // Kode terminal kasir untuk penomoran (opsional)
+	IdempotencyKey  string                 `json:"idempotencyKey"` // Kunci unik dari client untuk mencegah transaksi ganda saat retry
+	IdempotencyHash string                 `json:"-"`              // Diisi service dari hash payload, disimpan bersama transaksi
 	CreatedAt       time.Time              `json:"createdAt"`
+
+	// Persetujuan admin untuk perubahan harga / diskon baris di luar batas
+	ApproverUsername string `json:"approverUsername"`
+	ApproverPassword string `json:"approverPassword"`
 }
 
 // TransaksiItemRequest represents item in create transaction request
@@ -84,8 +130,39 @@ type TransaksiItemRequest struct {
 	Jumlah      int     `json:"jumlah"`      // Untuk backward compatibility (default 1)
 	HargaSatuan int     `json:"hargaSatuan"` // Harga per 1000 gram
 	BeratGram   float64 `json:"beratGram"`   // Berat yang dibeli dalam gram
+	KodePajak   string  `json:"-"`           // Diisi service dari perhitungan pajak
+	TarifPajak  float64 `json:"-"`           // Diisi service dari perhitungan pajak
+	DPP         int     `json:"-"`           // Diisi service dari perhitungan pajak
+	Pajak       int     `json:"-"`           // Diisi service dari perhitungan pajak
+
+	DiskonTipe     string  `json:"diskonTipe"`     // Diskon baris: "persen", "nominal" atau kosong
+	DiskonNilai    float64 `json:"diskonNilai"`    // Persen (0-100) atau rupiah untuk seluruh baris
+	AlasanOverride string  `json:"alasanOverride"` // Wajib jika harga diubah atau perlu persetujuan admin
+	HargaAsli      int     `json:"-"`              // Diisi service dari harga jual produk
+	DiskonItem     int     `json:"-"`              // Diisi service dari diskon baris
+	ApproverID     int     `json:"-"`              // Diisi service dari admin yang menyetujui
+	ApproverNama   string  `json:"-"`              // Diisi service dari admin yang menyetujui
+
+	SatuanID int     `json:"satuanId"` // Satuan tambahan produk, 0 = satuan dasar
+	Satuan   string  `json:"-"`        // Diisi service dari satuan yang dipilih
+	Konversi float64 `json:"-"`        // Diisi service dari satuan yang dipilih (1 untuk satuan dasar)
+
+	DiskonMarkdown     int    `json:"-"` // Diisi service dari aturan markdown batch hampir kadaluarsa
+	KeteranganMarkdown string `json:"-"` // Diisi service dari aturan markdown batch hampir kadaluarsa
 }
 
+// Tipe diskon baris yang didukung
+const (
+	DiskonTipePersen  = "persen"
+	DiskonTipeNominal = "nominal"
+)
+
+// Jenis transaksi; hanya penjualan barang yang dihitung sebagai omzet
+const (
+	JenisTransaksiPenjualan   = "penjualan"
+	JenisTransaksiKartuHadiah = "kartu_hadiah" // Saldo kartu yang dijual adalah kewajiban toko, bukan omzet
+)
+
 // PembayaranRequest represents payment in create transaction request
 type PembayaranRequest struct {
 	Metode    string `json:"metode"`
@@ -93,6 +170,15 @@ type PembayaranRequest struct {
 	Referensi string `json:"referensi"`
 }
 
+// VoidTransaksiRequest represents request to void (cancel) a completed transaction
+type VoidTransaksiRequest struct {
+	TransaksiID      int    `json:"transaksiId"`
+	Alasan           string `json:"alasan"`           // Alasan pembatalan (wajib)
+	ApproverUsername string `json:"approverUsername"` // Username admin yang menyetujui
+	ApproverPassword string `json:"approverPassword"` // Password admin yang menyetujui
+	KodeTerminal     string `json:"kodeTerminal"`     // Terminal kasir tempat void dilakukan
+}
+
 // TransaksiResponse represents response after creating transaction
 type TransaksiResponse struct {
 	Success   bool             `json:"success"`
@@ -110,6 +196,7 @@ type StokHistory struct {
 	Keterangan     string    `json:"keterangan"`
 	TipeKerugian   string    `json:"tipeKerugian"`  // "kadaluarsa", "rusak", "hilang", "other" (only for pengurangan)
 	NilaiKerugian  int       `json:"nilaiKerugian"` // Loss value in rupiah
+	HargaBeli      int       `json:"hargaBeli"`     // Harga beli per satuan dari stok yang masuk, 0 = tidak dicatat
 	CreatedAt      time.Time `json:"createdAt"`
 }
 
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// TransaksiParkir represents a held (parked) sale that can be resumed later
+type TransaksiParkir struct {
+	ID            int                    `json:"id"`
+	Label         string                 `json:"label"`
+	StaffID       *int                   `json:"staffId"`
+	StaffNama     string                 `json:"staffNama"`
+	KodeTerminal  string                 `json:"kodeTerminal"`
+	Draft         CreateTransaksiRequest `json:"draft"`         // Draft transaksi yang akan dilanjutkan
+	JumlahItem    int                    `json:"jumlahItem"`    // Jumlah baris item di draft
+	ReservasiStok bool                   `json:"reservasiStok"` // Stok ditahan selama transaksi diparkir
+	Status        string                 `json:"status"`        // "parkir", "dilanjutkan", "dibatalkan", "kadaluarsa"
+	ExpiresAt     time.Time              `json:"expiresAt"`
+	ResumedAt     *time.Time             `json:"resumedAt,omitempty"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	UpdatedAt     time.Time              `json:"updatedAt"`
+}
+
+// TransaksiParkirItem represents stock soft-reserved by a parked sale
+type TransaksiParkirItem struct {
+	ProdukID int     `json:"produkId"`
+	Qty      float64 `json:"qty"` // Jumlah unit, atau kg untuk produk timbang
+}
+
+// ParkirTransaksiRequest represents a request to park a sale
+type ParkirTransaksiRequest struct {
+	Label         string                 `json:"label"`
+	Draft         CreateTransaksiRequest `json:"draft"`
+	ReservasiStok bool                   `json:"reservasiStok"` // Tahan stok item selama diparkir (opsional)
+}
+
+// TransaksiParkirFilter filters the list of parked sales
+type TransaksiParkirFilter struct {
+	StaffID      int    `json:"staffId"`
+	KodeTerminal string `json:"kodeTerminal"`
+}
@@ -115,3 +115,37 @@ type ComprehensiveStaffReport struct {
 	PercentChange        float64 `json:"percentChange"`
 	StaffReports         []*StaffReportWithTrend `json:"staffReports"`
 }
+
+// OverrideHargaItem represents a sold line whose price was changed manually or discounted by the cashier
+type OverrideHargaItem struct {
+	TransaksiID    int       `json:"transaksiId"`
+	NomorTransaksi string    `json:"nomorTransaksi"`
+	Tanggal        time.Time `json:"tanggal"`
+	StaffID        int       `json:"staffId"`
+	StaffNama      string    `json:"staffNama"`
+	ProdukNama     string    `json:"produkNama"`
+	HargaAsli      int       `json:"hargaAsli"`               // Harga jual produk saat transaksi
+	HargaSatuan    int       `json:"hargaSatuan"`             // Harga yang dipakai kasir
+	HargaOverride  *int      `json:"hargaOverride,omitempty"` // Nil jika harga tidak diubah (hanya diskon baris)
+	Jumlah         int       `json:"jumlah"`
+	BeratGram      float64   `json:"beratGram"`
+	DiskonTipe     string    `json:"diskonTipe,omitempty"`
+	DiskonNilai    float64   `json:"diskonNilai,omitempty"`
+	DiskonItem     int       `json:"diskonItem"`
+	Subtotal       int       `json:"subtotal"`
+	Selisih        int       `json:"selisih"` // Harga asli x jumlah dikurangi subtotal (positif = lebih murah)
+	AlasanOverride string    `json:"alasanOverride"`
+	ApproverID     *int      `json:"approverId,omitempty"`
+	ApproverNama   string    `json:"approverNama,omitempty"`
+}
+
+// LaporanOverrideHargaStaff represents the price overrides and line discounts given by one staff
+type LaporanOverrideHargaStaff struct {
+	StaffID           int                  `json:"staffId"`
+	StaffNama         string               `json:"staffNama"`
+	JumlahOverride    int                  `json:"jumlahOverride"`    // Baris dengan harga diubah manual
+	JumlahDiskonBaris int                  `json:"jumlahDiskonBaris"` // Baris dengan diskon baris
+	JumlahDisetujui   int                  `json:"jumlahDisetujui"`   // Baris yang memerlukan persetujuan admin
+	TotalSelisih      int                  `json:"totalSelisih"`
+	Items             []*OverrideHargaItem `json:"items"`
+}
@@ -19,8 +19,9 @@ func NewAnalyticsRepository() *AnalyticsRepository {
 	}
 }
 
-// GetTopProducts retrieves top selling products within date range
-func (r *AnalyticsRepository) GetTopProducts(startDate, endDate string, limit int) ([]*models.TopProductsResponse, error) {
+// GetTopProducts retrieves top selling products within date range. With perInduk
+// the variants of a parent product are rolled up into one row for the parent.
+func (r *AnalyticsRepository) GetTopProducts(startDate, endDate string, limit int, perInduk bool) ([]*models.TopProductsResponse, error) {
 	if r.db == nil {
 		return []*models.TopProductsResponse{}, nil
 	}
@@ -28,21 +29,53 @@ func (r *AnalyticsRepository) GetTopProducts(startDate, endDate string, limit in
 	query := `
 		SELECT
 			ti.produk_id,
+			0 as induk_id,
 			ti.produk_sku,
 			ti.produk_nama,
 			COALESCE(ti.produk_kategori, 'Uncategorized') as category,
 			SUM(ti.jumlah) as total_qty,
 			SUM(ti.subtotal) as total_revenue,
 			COUNT(DISTINCT ti.transaksi_id) as times_sold,
-			AVG(ti.harga_satuan) as average_price
+			CAST(ROUND(AVG(ti.harga_satuan)) AS INTEGER) as average_price,
+			1 as variant_count
 		FROM transaksi_item ti
 		JOIN transaksi t ON ti.transaksi_id = t.id
 		WHERE DATE(t.tanggal) BETWEEN DATE(?) AND DATE(?)
-		  AND t.status IN ('selesai', 'partial_return')
+		  AND t.status IN ('selesai', 'partial_return') AND t.jenis = 'penjualan'
 		GROUP BY ti.produk_id, ti.produk_sku, ti.produk_nama, ti.produk_kategori
 		ORDER BY total_qty DESC
 		LIMIT ?
 	`
+	if perInduk {
+		// Varian dikelompokkan ke induk yang berlaku sekarang, produk tanpa induk tetap per produk
+		query = `
+		SELECT
+			CASE WHEN pi.id IS NULL THEN ti.produk_id ELSE 0 END as product_id,
+			COALESCE(pi.id, 0) as induk_id,
+			CASE WHEN pi.id IS NULL THEN ti.produk_sku ELSE '' END as product_sku,
+			COALESCE(pi.nama, ti.produk_nama) as product_name,
+			COALESCE(NULLIF(pi.kategori, ''), ti.produk_kategori, 'Uncategorized') as category,
+			SUM(ti.jumlah) as total_qty,
+			SUM(ti.subtotal) as total_revenue,
+			COUNT(DISTINCT ti.transaksi_id) as times_sold,
+			CAST(ROUND(AVG(ti.harga_satuan)) AS INTEGER) as average_price,
+			COUNT(DISTINCT ti.produk_id) as variant_count
+		FROM transaksi_item ti
+		JOIN transaksi t ON ti.transaksi_id = t.id
+		LEFT JOIN produk p ON p.id = ti.produk_id
+		LEFT JOIN produk_induk pi ON pi.id = p.induk_id
+		WHERE DATE(t.tanggal) BETWEEN DATE(?) AND DATE(?)
+		  AND t.status IN ('selesai', 'partial_return') AND t.jenis = 'penjualan'
+		GROUP BY
+			CASE WHEN pi.id IS NULL THEN ti.produk_id ELSE 0 END,
+			COALESCE(pi.id, 0),
+			CASE WHEN pi.id IS NULL THEN ti.produk_sku ELSE '' END,
+			COALESCE(pi.nama, ti.produk_nama),
+			COALESCE(NULLIF(pi.kategori, ''), ti.produk_kategori, 'Uncategorized')
+		ORDER BY total_qty DESC
+		LIMIT ?
+	`
+	}
 
 	rows, err := r.db.Query(query, startDate, endDate, limit)
 	if err != nil {
@@ -55,6 +88,7 @@ func (r *AnalyticsRepository) GetTopProducts(startDate, endDate string, limit in
 		var p models.TopProductsResponse
 		err := rows.Scan(
 			&p.ProductID,
+			&p.IndukID,
 			&p.ProductSKU,
 			&p.ProductName,
 			&p.Category,
@@ -62,6 +96,7 @@ func (r *AnalyticsRepository) GetTopProducts(startDate, endDate string, limit in
 			&p.TotalRevenue,
 			&p.TimesSold,
 			&p.AveragePrice,
+			&p.VariantCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan product: %w", err)
@@ -84,15 +119,18 @@ func (r *AnalyticsRepository) GetPaymentMethodBreakdown(startDate, endDate strin
 
 	query := `
 		SELECT
-			p.metode as method,
+			LOWER(p.metode) as kode,
+			COALESCE(mp.nama, LOWER(p.metode)) as method,
 			SUM(p.jumlah) as total_amount,
 			COUNT(*) as count,
 			AVG(p.jumlah) as average_value
 		FROM pembayaran p
 		JOIN transaksi t ON p.transaksi_id = t.id
+		LEFT JOIN metode_pembayaran mp ON mp.kode = LOWER(p.metode)
 		WHERE DATE(t.tanggal) BETWEEN DATE(?) AND DATE(?)
-		  AND t.status IN ('selesai', 'partial_return')
-		GROUP BY p.metode
+		  AND t.status IN ('selesai', 'partial_return') AND t.jenis = 'penjualan'
+		  AND p.piutang_id IS NULL
+		GROUP BY LOWER(p.metode), mp.nama
 		ORDER BY total_amount DESC
 	`
 
@@ -108,6 +146,7 @@ func (r *AnalyticsRepository) GetPaymentMethodBreakdown(startDate, endDate strin
 	for rows.Next() {
 		var p models.PaymentBreakdownResponse
 		err := rows.Scan(
+			&p.Kode,
 			&p.Method,
 			&p.TotalAmount,
 			&p.Count,
@@ -151,7 +190,7 @@ func (r *AnalyticsRepository) GetSalesTrend(startDate, endDate string) ([]*model
 			), 0) as total_items
 		FROM transaksi t
 		WHERE DATE(t.tanggal) BETWEEN DATE(?) AND DATE(?)
-		  AND t.status IN ('selesai', 'partial_return')
+		  AND t.status IN ('selesai', 'partial_return') AND t.jenis = 'penjualan'
 		GROUP BY DATE(t.tanggal)
 		ORDER BY date ASC
 	`
@@ -185,8 +224,9 @@ func (r *AnalyticsRepository) GetSalesTrend(startDate, endDate string) ([]*model
 	return trends, nil
 }
 
-// GetCategoryBreakdown retrieves sales by category
-func (r *AnalyticsRepository) GetCategoryBreakdown(startDate, endDate string) ([]*models.CategoryBreakdownResponse, error) {
+// GetCategoryBreakdown retrieves sales by category. With perInduk the sales of a
+// variant are counted under the category of its parent product.
+func (r *AnalyticsRepository) GetCategoryBreakdown(startDate, endDate string, perInduk bool) ([]*models.CategoryBreakdownResponse, error) {
 	if r.db == nil {
 		return []*models.CategoryBreakdownResponse{}, nil
 	}
@@ -200,10 +240,27 @@ func (r *AnalyticsRepository) GetCategoryBreakdown(startDate, endDate string) ([
 		FROM transaksi_item ti
 		JOIN transaksi t ON ti.transaksi_id = t.id
 		WHERE DATE(t.tanggal) BETWEEN DATE(?) AND DATE(?)
-		  AND t.status IN ('selesai', 'partial_return')
+		  AND t.status IN ('selesai', 'partial_return') AND t.jenis = 'penjualan'
 		GROUP BY ti.produk_kategori
 		ORDER BY total_revenue DESC
 	`
+	if perInduk {
+		query = `
+		SELECT
+			COALESCE(NULLIF(pi.kategori, ''), ti.produk_kategori, 'Uncategorized') as category,
+			SUM(ti.jumlah) as total_qty,
+			SUM(ti.subtotal) as total_revenue,
+			COUNT(DISTINCT ti.transaksi_id) as trans_count
+		FROM transaksi_item ti
+		JOIN transaksi t ON ti.transaksi_id = t.id
+		LEFT JOIN produk p ON p.id = ti.produk_id
+		LEFT JOIN produk_induk pi ON pi.id = p.induk_id
+		WHERE DATE(t.tanggal) BETWEEN DATE(?) AND DATE(?)
+		  AND t.status IN ('selesai', 'partial_return') AND t.jenis = 'penjualan'
+		GROUP BY COALESCE(NULLIF(pi.kategori, ''), ti.produk_kategori, 'Uncategorized')
+		ORDER BY total_revenue DESC
+	`
+	}
 
 	rows, err := r.db.Query(query, startDate, endDate)
 	if err != nil {
@@ -257,7 +314,7 @@ func (r *AnalyticsRepository) GetHourlySales(startDate, endDate string) ([]*mode
 			SUM(total) as total_sales
 		FROM transaksi
 		WHERE DATE(tanggal) BETWEEN DATE(?) AND DATE(?)
-		  AND status IN ('selesai', 'partial_return')
+		  AND status IN ('selesai', 'partial_return') AND jenis = 'penjualan'
 		GROUP BY hour, day_of_week
 		ORDER BY hour, day_of_week
 	`
@@ -300,7 +357,7 @@ func (r *AnalyticsRepository) GetTotalDiscount(startDate, endDate string) (int,
 		SELECT COALESCE(SUM(diskon), 0)
 		FROM transaksi
 		WHERE DATE(tanggal) BETWEEN DATE(?) AND DATE(?)
-		  AND status IN ('selesai', 'partial_return')
+		  AND status IN ('selesai', 'partial_return') AND jenis = 'penjualan'
 	`
 
 	var totalDiscount int
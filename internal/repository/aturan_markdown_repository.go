@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// AturanMarkdownRepository handles database operations for near-expiry markdown rules
+type AturanMarkdownRepository struct{}
+
+// NewAturanMarkdownRepository creates a new repository instance
+func NewAturanMarkdownRepository() *AturanMarkdownRepository {
+	return &AturanMarkdownRepository{}
+}
+
+const aturanMarkdownColumns = `id, nama, hari_sebelum, persen, aktif, created_at, updated_at`
+
+// Create creates a new markdown rule
+func (r *AturanMarkdownRepository) Create(aturan *models.AturanMarkdown) error {
+	query := `
+		INSERT INTO aturan_markdown (nama, hari_sebelum, persen, aktif, created_at, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
+	`
+
+	var id int64
+	err := database.QueryRow(query,
+		aturan.Nama,
+		aturan.HariSebelum,
+		aturan.Persen,
+		boolToInt(aturan.Aktif),
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create aturan markdown: %w", err)
+	}
+
+	aturan.ID = int(id)
+	return nil
+}
+
+// GetAll retrieves all markdown rules, closest to expiry first
+func (r *AturanMarkdownRepository) GetAll() ([]*models.AturanMarkdown, error) {
+	return r.query(`SELECT ` + aturanMarkdownColumns + ` FROM aturan_markdown ORDER BY hari_sebelum ASC, persen DESC`)
+}
+
+// GetAktif retrieves the active markdown rules, closest to expiry first
+func (r *AturanMarkdownRepository) GetAktif() ([]*models.AturanMarkdown, error) {
+	return r.query(`SELECT ` + aturanMarkdownColumns + ` FROM aturan_markdown WHERE aktif = 1 ORDER BY hari_sebelum ASC, persen DESC`)
+}
+
+// GetByID retrieves a markdown rule by ID
+func (r *AturanMarkdownRepository) GetByID(id int) (*models.AturanMarkdown, error) {
+	query := `SELECT ` + aturanMarkdownColumns + ` FROM aturan_markdown WHERE id = ?`
+
+	aturan, err := scanAturanMarkdown(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return aturan, err
+}
+
+// Update updates a markdown rule
+func (r *AturanMarkdownRepository) Update(aturan *models.AturanMarkdown) error {
+	query := `
+		UPDATE aturan_markdown SET
+			nama = ?, hari_sebelum = ?, persen = ?, aktif = ?
+		WHERE id = ?
+	`
+
+	result, err := database.Exec(query,
+		aturan.Nama,
+		aturan.HariSebelum,
+		aturan.Persen,
+		boolToInt(aturan.Aktif),
+		aturan.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update aturan markdown: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("aturan markdown tidak ditemukan")
+	}
+
+	return nil
+}
+
+// Delete removes a markdown rule
+func (r *AturanMarkdownRepository) Delete(id int) error {
+	result, err := database.Exec(`DELETE FROM aturan_markdown WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete aturan markdown: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("aturan markdown tidak ditemukan")
+	}
+
+	return nil
+}
+
+// query runs a rule query selected with aturanMarkdownColumns
+func (r *AturanMarkdownRepository) query(query string) ([]*models.AturanMarkdown, error) {
+	rows, err := database.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aturan markdown: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.AturanMarkdown{}
+	for rows.Next() {
+		aturan, err := scanAturanMarkdown(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, aturan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate aturan markdown: %w", err)
+	}
+
+	return list, nil
+}
+
+// scanAturanMarkdown scans a rule row selected with aturanMarkdownColumns
+func scanAturanMarkdown(row rowScanner) (*models.AturanMarkdown, error) {
+	var a models.AturanMarkdown
+	var aktif int
+
+	err := row.Scan(
+		&a.ID,
+		&a.Nama,
+		&a.HariSebelum,
+		&a.Persen,
+		&aktif,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan aturan markdown: %w", err)
+	}
+
+	a.Aktif = aktif == 1
+
+	return &a, nil
+}
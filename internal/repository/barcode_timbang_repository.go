@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// BarcodeTimbangRepository handles database operations for scale label barcode rules
+type BarcodeTimbangRepository struct{}
+
+// NewBarcodeTimbangRepository creates a new repository instance
+func NewBarcodeTimbangRepository() *BarcodeTimbangRepository {
+	return &BarcodeTimbangRepository{}
+}
+
+const aturanBarcodeTimbangColumns = `
+	id, nama, prefix, plu_dari, plu_sampai, nilai_dari, nilai_sampai, tipe_nilai,
+	COALESCE(desimal, 0), aktif, created_at, updated_at
+`
+
+// Create creates a new scale label rule
+func (r *BarcodeTimbangRepository) Create(aturan *models.AturanBarcodeTimbang) error {
+	query := `
+		INSERT INTO aturan_barcode_timbang (
+			nama, prefix, plu_dari, plu_sampai, nilai_dari, nilai_sampai, tipe_nilai, desimal, aktif,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
+	`
+
+	var id int64
+	err := database.QueryRow(query,
+		aturan.Nama,
+		aturan.Prefix,
+		aturan.PLUDari,
+		aturan.PLUSampai,
+		aturan.NilaiDari,
+		aturan.NilaiSampai,
+		aturan.TipeNilai,
+		aturan.Desimal,
+		boolToInt(aturan.Aktif),
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create aturan barcode timbang: %w", err)
+	}
+
+	aturan.ID = int(id)
+	return nil
+}
+
+// GetAll retrieves all scale label rules ordered by prefix
+func (r *BarcodeTimbangRepository) GetAll() ([]*models.AturanBarcodeTimbang, error) {
+	query := `SELECT ` + aturanBarcodeTimbangColumns + ` FROM aturan_barcode_timbang ORDER BY prefix ASC`
+
+	rows, err := database.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aturan barcode timbang: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.AturanBarcodeTimbang{}
+	for rows.Next() {
+		aturan, err := scanAturanBarcodeTimbang(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, aturan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate aturan barcode timbang: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetByID retrieves a scale label rule by ID
+func (r *BarcodeTimbangRepository) GetByID(id int) (*models.AturanBarcodeTimbang, error) {
+	query := `SELECT ` + aturanBarcodeTimbangColumns + ` FROM aturan_barcode_timbang WHERE id = ?`
+
+	aturan, err := scanAturanBarcodeTimbang(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return aturan, err
+}
+
+// GetByPrefix retrieves the scale label rule for a prefix
+func (r *BarcodeTimbangRepository) GetByPrefix(prefix string) (*models.AturanBarcodeTimbang, error) {
+	query := `SELECT ` + aturanBarcodeTimbangColumns + ` FROM aturan_barcode_timbang WHERE prefix = ?`
+
+	aturan, err := scanAturanBarcodeTimbang(database.QueryRow(query, prefix))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return aturan, err
+}
+
+// Update updates a scale label rule
+func (r *BarcodeTimbangRepository) Update(aturan *models.AturanBarcodeTimbang) error {
+	query := `
+		UPDATE aturan_barcode_timbang SET
+			nama = ?, prefix = ?, plu_dari = ?, plu_sampai = ?, nilai_dari = ?, nilai_sampai = ?,
+			tipe_nilai = ?, desimal = ?, aktif = ?
+		WHERE id = ?
+	`
+
+	_, err := database.Exec(query,
+		aturan.Nama,
+		aturan.Prefix,
+		aturan.PLUDari,
+		aturan.PLUSampai,
+		aturan.NilaiDari,
+		aturan.NilaiSampai,
+		aturan.TipeNilai,
+		aturan.Desimal,
+		boolToInt(aturan.Aktif),
+		aturan.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update aturan barcode timbang: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a scale label rule
+func (r *BarcodeTimbangRepository) Delete(id int) error {
+	result, err := database.Exec(`DELETE FROM aturan_barcode_timbang WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete aturan barcode timbang: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("aturan barcode timbang tidak ditemukan")
+	}
+
+	return nil
+}
+
+// scanAturanBarcodeTimbang scans a rule row selected with aturanBarcodeTimbangColumns
+func scanAturanBarcodeTimbang(row rowScanner) (*models.AturanBarcodeTimbang, error) {
+	var a models.AturanBarcodeTimbang
+	var aktif int
+
+	err := row.Scan(
+		&a.ID,
+		&a.Nama,
+		&a.Prefix,
+		&a.PLUDari,
+		&a.PLUSampai,
+		&a.NilaiDari,
+		&a.NilaiSampai,
+		&a.TipeNilai,
+		&a.Desimal,
+		&aktif,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan aturan barcode timbang: %w", err)
+	}
+
+	a.Aktif = aktif == 1
+
+	return &a, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
 	"ritel-app/internal/database"
@@ -44,8 +45,8 @@ func (r *BatchRepository) CreateBatch(batch *models.Batch) error {
 		INSERT INTO batch (
 			id, produk_id, qty, qty_tersisa, tanggal_restok,
 			masa_simpan_hari, tanggal_kadaluarsa, status,
-			supplier, keterangan
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			supplier, supplier_id, keterangan, harga_beli
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := r.db.Exec(
@@ -59,7 +60,9 @@ func (r *BatchRepository) CreateBatch(batch *models.Batch) error {
 		batch.TanggalKadaluarsa,
 		batch.Status,
 		batch.Supplier,
+		sql.NullInt64{Int64: int64(batch.SupplierID), Valid: batch.SupplierID > 0},
 		batch.Keterangan,
+		batch.HargaBeli,
 	)
 
 	if err != nil {
@@ -69,12 +72,82 @@ func (r *BatchRepository) CreateBatch(batch *models.Batch) error {
 	return nil
 }
 
+// tambahBatch records incoming stock as a batch within tx. Like CreateBatchFromRestok
+// it merges into an open batch restocked the same day with the same shelf life and
+// supplier, at the average cost of its remaining and incoming stock; otherwise a new
+// batch is created. The caller updates the product stock itself.
+func tambahBatch(tx *sql.Tx, batch *models.Batch, now time.Time) error {
+	qty := batch.Qty
+	existing := &models.Batch{}
+	findQuery := database.TranslateQuery(`
+		SELECT id, qty, qty_tersisa, COALESCE(harga_beli, 0)
+		FROM batch
+		WHERE produk_id = ?
+		  AND DATE(tanggal_restok) = DATE(?)
+		  AND masa_simpan_hari = ?
+		  AND COALESCE(supplier_id, 0) = ?
+		  AND qty_tersisa > 0
+		  AND ditarik_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`)
+	err := tx.QueryRow(findQuery, batch.ProdukID, now.Format("2006-01-02"), batch.MasaSimpanHari, batch.SupplierID).Scan(
+		&existing.ID, &existing.Qty, &existing.QtyTersisa, &existing.HargaBeli)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to find batch: %w", err)
+	}
+
+	if err == nil {
+		hargaBeli := batch.HargaBeli
+		if existing.HargaBeli > 0 {
+			hargaBeli = int(math.Round((existing.QtyTersisa*float64(existing.HargaBeli) + qty*float64(batch.HargaBeli)) /
+				(existing.QtyTersisa + qty)))
+		}
+		updateQuery := database.TranslateQuery(`
+			UPDATE batch SET qty = qty + ?, qty_tersisa = qty_tersisa + ?, harga_beli = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`)
+		if _, err := tx.Exec(updateQuery, qty, qty, hargaBeli, existing.ID); err != nil {
+			return fmt.Errorf("failed to update existing batch: %w", err)
+		}
+		batch.ID = existing.ID
+		batch.Qty = existing.Qty + qty
+		batch.QtyTersisa = existing.QtyTersisa + qty
+		batch.HargaBeli = hargaBeli
+		return nil
+	}
+
+	if batch.ID == "" {
+		batch.ID = uuid.New().String()
+	}
+	batch.TanggalRestok = now
+	batch.TanggalKadaluarsa = now.AddDate(0, 0, batch.MasaSimpanHari)
+	batch.Status = statusBatch(batch.TanggalKadaluarsa)
+	batch.QtyTersisa = qty
+
+	insertQuery := database.TranslateQuery(`
+		INSERT INTO batch (
+			id, produk_id, qty, qty_tersisa, tanggal_restok,
+			masa_simpan_hari, tanggal_kadaluarsa, status,
+			supplier, supplier_id, keterangan, harga_beli
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if _, err := tx.Exec(insertQuery, batch.ID, batch.ProdukID, batch.Qty, batch.QtyTersisa, batch.TanggalRestok,
+		batch.MasaSimpanHari, batch.TanggalKadaluarsa, batch.Status, batch.Supplier,
+		sql.NullInt64{Int64: int64(batch.SupplierID), Valid: batch.SupplierID > 0}, batch.Keterangan, batch.HargaBeli); err != nil {
+		return fmt.Errorf("failed to create batch: %w", err)
+	}
+	return nil
+}
+
 // GetBatchByID retrieves a batch by ID
 func (r *BatchRepository) GetBatchByID(id string) (*models.Batch, error) {
 	query := `
 		SELECT id, produk_id, qty, qty_tersisa, tanggal_restok,
 		       masa_simpan_hari, tanggal_kadaluarsa, status,
-		       supplier, keterangan, created_at, updated_at
+		       supplier, COALESCE(supplier_id, 0), keterangan, created_at, updated_at,
+		       ditarik_at, COALESCE(alasan_penarikan, ''), COALESCE(ditarik_oleh, ''),
+		       COALESCE(harga_beli, 0), dihapus_at
 		FROM batch
 		WHERE id = ?
 	`
@@ -90,9 +163,15 @@ func (r *BatchRepository) GetBatchByID(id string) (*models.Batch, error) {
 		&batch.TanggalKadaluarsa,
 		&batch.Status,
 		&batch.Supplier,
+		&batch.SupplierID,
 		&batch.Keterangan,
 		&batch.CreatedAt,
 		&batch.UpdatedAt,
+		&batch.DitarikAt,
+		&batch.AlasanPenarikan,
+		&batch.DitarikOleh,
+		&batch.HargaBeli,
+		&batch.DihapusAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -116,7 +195,9 @@ func (r *BatchRepository) GetBatchesByProdukID(produkID int) ([]*models.Batch, e
 	query := `
 		SELECT id, produk_id, qty, qty_tersisa, tanggal_restok,
 		       masa_simpan_hari, tanggal_kadaluarsa, status,
-		       supplier, keterangan, created_at, updated_at
+		       supplier, COALESCE(supplier_id, 0), keterangan, created_at, updated_at,
+		       ditarik_at, COALESCE(alasan_penarikan, ''), COALESCE(ditarik_oleh, ''),
+		       COALESCE(harga_beli, 0), dihapus_at
 		FROM batch
 		WHERE produk_id = ? AND qty_tersisa > 0
 		ORDER BY tanggal_restok ASC, created_at ASC
@@ -142,17 +223,25 @@ func (r *BatchRepository) GetBatchesByProdukID(produkID int) ([]*models.Batch, e
 			&batch.TanggalKadaluarsa,
 			&batch.Status,
 			&batch.Supplier,
+			&batch.SupplierID,
 			&batch.Keterangan,
 			&batch.CreatedAt,
 			&batch.UpdatedAt,
+			&batch.DitarikAt,
+			&batch.AlasanPenarikan,
+			&batch.DitarikOleh,
+			&batch.HargaBeli,
+			&batch.DihapusAt,
 		)
 		if err != nil {
 			log.Printf("[BATCH REPO] ❌ Error scanning batch row for produk_id=%d: %v", produkID, err)
 			return nil, fmt.Errorf("failed to scan batch: %w", err)
 		}
 
-		// Update status based on current date
-		batch.Status = r.calculateBatchStatus(batch.TanggalKadaluarsa)
+		// Update status based on current date; archived batches stay expired
+		if batch.DihapusAt == nil {
+			batch.Status = r.calculateBatchStatus(batch.TanggalKadaluarsa)
+		}
 		batches = append(batches, batch)
 	}
 
@@ -160,12 +249,426 @@ func (r *BatchRepository) GetBatchesByProdukID(produkID int) ([]*models.Batch, e
 	return batches, nil
 }
 
+// kebijakanBatchQuery resolves the batch consumption policy of a product: its own,
+// else its category's, else the default passed as the second argument
+const kebijakanBatchQuery = `
+	SELECT COALESCE(NULLIF(p.kebijakan_batch, ''), NULLIF(k.kebijakan_batch, ''), ?)
+	FROM produk p
+	LEFT JOIN kategori k ON k.nama = p.kategori
+	WHERE p.id = ?
+`
+
+// batchKonsumsiQuery selects the given columns of the batches of a product (first
+// argument) with remaining stock, in the order the policy takes them. When expired
+// batches are skipped, today's date (second argument) must be passed.
+func batchKonsumsiQuery(kolom, kebijakan string, lewatiKadaluarsa bool) string {
+	query := `SELECT ` + kolom + ` FROM batch WHERE produk_id = ? AND qty_tersisa > 0 AND ditarik_at IS NULL`
+	if lewatiKadaluarsa {
+		query += ` AND DATE(tanggal_kadaluarsa) >= DATE(?)`
+	}
+	if kebijakan == models.KebijakanBatchFIFO {
+		return query + ` ORDER BY tanggal_restok ASC, created_at ASC`
+	}
+	return query + ` ORDER BY tanggal_kadaluarsa ASC, tanggal_restok ASC, created_at ASC`
+}
+
+// qtyKadaluarsaQuery sums the remaining stock of a product (first argument) in
+// batches that expired before today (second argument)
+const qtyKadaluarsaQuery = `
+	SELECT COALESCE(SUM(qty_tersisa), 0) FROM batch
+	WHERE produk_id = ? AND qty_tersisa > 0 AND DATE(tanggal_kadaluarsa) < DATE(?)
+`
+
+// GetKebijakanBatch returns the batch consumption policy that applies to a product
+func (r *BatchRepository) GetKebijakanBatch(produkID int) (string, error) {
+	var kebijakan string
+	err := database.QueryRow(kebijakanBatchQuery, models.KebijakanBatchFIFO, produkID).Scan(&kebijakan)
+	if err == sql.ErrNoRows {
+		return models.KebijakanBatchFIFO, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get batch policy: %w", err)
+	}
+	return kebijakan, nil
+}
+
+// GetBatchesKonsumsi retrieves the batches of a product with remaining stock in the order
+// the consumption policy takes them, optionally without batches that have expired
+func (r *BatchRepository) GetBatchesKonsumsi(produkID int, kebijakan string, lewatiKadaluarsa bool) ([]*models.Batch, error) {
+	query := batchKonsumsiQuery(`
+		id, produk_id, qty, qty_tersisa, tanggal_restok,
+		masa_simpan_hari, tanggal_kadaluarsa, status,
+		supplier, COALESCE(supplier_id, 0), keterangan, created_at, updated_at,
+		ditarik_at, COALESCE(alasan_penarikan, ''), COALESCE(ditarik_oleh, ''),
+		COALESCE(harga_beli, 0), dihapus_at
+	`, kebijakan, lewatiKadaluarsa)
+	args := []interface{}{produkID}
+	if lewatiKadaluarsa {
+		args = append(args, time.Now().Format("2006-01-02"))
+	}
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*models.Batch
+	for rows.Next() {
+		batch := &models.Batch{}
+		err := rows.Scan(
+			&batch.ID,
+			&batch.ProdukID,
+			&batch.Qty,
+			&batch.QtyTersisa,
+			&batch.TanggalRestok,
+			&batch.MasaSimpanHari,
+			&batch.TanggalKadaluarsa,
+			&batch.Status,
+			&batch.Supplier,
+			&batch.SupplierID,
+			&batch.Keterangan,
+			&batch.CreatedAt,
+			&batch.UpdatedAt,
+			&batch.DitarikAt,
+			&batch.AlasanPenarikan,
+			&batch.DitarikOleh,
+			&batch.HargaBeli,
+			&batch.DihapusAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch: %w", err)
+		}
+
+		batch.Status = r.calculateBatchStatus(batch.TanggalKadaluarsa)
+		batches = append(batches, batch)
+	}
+
+	return batches, rows.Err()
+}
+
+// GetQtyKadaluarsa returns the remaining stock of a product in batches that have expired
+func (r *BatchRepository) GetQtyKadaluarsa(produkID int) (float64, error) {
+	var qty float64
+	if err := database.QueryRow(qtyKadaluarsaQuery, produkID, time.Now().Format("2006-01-02")).Scan(&qty); err != nil {
+		return 0, fmt.Errorf("failed to get expired batch stock: %w", err)
+	}
+	return qty, nil
+}
+
+// kembalikanStokReturn restores the product stock of returned goods and puts them
+// back into the batches the sale took them from, so product stock, batch quantities
+// and the return's batch trace cannot disagree. Returns the quantity that could be
+// matched to a batch.
+func kembalikanStokReturn(tx *sql.Tx, returnID, transaksiID, produkID int, qty float64, keterangan string, now time.Time) (float64, error) {
+	var stokSebelum float64
+	var hargaBeli int
+	produkQuery := database.TranslateQuery(`SELECT stok, COALESCE(harga_beli, 0) FROM produk WHERE id = ? AND deleted_at IS NULL`)
+	err := tx.QueryRow(produkQuery, produkID).Scan(&stokSebelum, &hargaBeli)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("produk %d tidak ditemukan", produkID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get product stock: %w", err)
+	}
+
+	stokSesudah := stokSebelum + qty
+	updateQuery := database.TranslateQuery(`UPDATE produk SET stok = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	if _, err := tx.Exec(updateQuery, stokSesudah, produkID); err != nil {
+		return 0, fmt.Errorf("failed to update product stock: %w", err)
+	}
+
+	historyQuery := database.TranslateQuery(`
+		INSERT INTO stok_history (
+			produk_id, stok_sebelum, stok_sesudah, perubahan, jenis_perubahan, keterangan,
+			tipe_kerugian, nilai_kerugian, harga_beli, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if _, err := tx.Exec(historyQuery, produkID, stokSebelum, stokSesudah, qty, "return", keterangan,
+		"", 0, hargaBeli, now); err != nil {
+		return 0, fmt.Errorf("failed to create stock history: %w", err)
+	}
+
+	kembali, err := kembalikanAlokasi(tx, returnID, transaksiID, produkID, qty, now)
+	if err != nil {
+		return 0, err
+	}
+	if kembali < qty {
+		log.Printf("[BATCH REPO] %.2f of %.2f returned units of produk %d had no batch allocation to return to", qty-kembali, qty, produkID)
+	}
+
+	return kembali, nil
+}
+
+// kembalikanAlokasi records returned quantity of a sold product against the batches
+// the sale took it from, most recently taken first and net of earlier returns. The
+// quantity goes back into each batch, except for recalled batches where it is written
+// off right away, since recalled stock must not be sold again. Returns the quantity
+// that could be matched to a batch.
+func kembalikanAlokasi(tx *sql.Tx, returnID, transaksiID, produkID int, qty float64, now time.Time) (float64, error) {
+	query := database.TranslateQuery(`
+		SELECT a.batch_id, SUM(a.qty),
+		       COALESCE((SELECT SUM(r.qty) FROM return_item_batch r
+		                 WHERE r.transaksi_id = a.transaksi_id AND r.batch_id = a.batch_id), 0),
+		       MAX(b.qty) - MAX(b.qty_tersisa),
+		       MAX(CASE WHEN b.ditarik_at IS NULL THEN 0 ELSE 1 END)
+		FROM transaksi_item_batch a
+		JOIN batch b ON b.id = a.batch_id
+		WHERE a.transaksi_id = ? AND a.produk_id = ?
+		GROUP BY a.transaksi_id, a.batch_id
+		ORDER BY MAX(a.id) DESC
+	`)
+
+	rows, err := tx.Query(query, transaksiID, produkID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get batch allocations: %w", err)
+	}
+
+	type alokasi struct {
+		batchID string
+		qty     float64
+		ditarik bool
+	}
+	var list []alokasi
+	for rows.Next() {
+		var batchID string
+		var dialokasikan, dikembalikan, ruang float64
+		var ditarik int
+		if err := rows.Scan(&batchID, &dialokasikan, &dikembalikan, &ruang, &ditarik); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan batch allocation: %w", err)
+		}
+		a := alokasi{batchID: batchID, qty: dialokasikan - dikembalikan, ditarik: ditarik == 1}
+		if !a.ditarik {
+			a.qty = math.Min(a.qty, ruang)
+		}
+		list = append(list, a)
+	}
+	rows.Close()
+
+	insertQuery := database.TranslateQuery(`
+		INSERT INTO return_item_batch (return_id, transaksi_id, produk_id, batch_id, qty, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	restoreQuery := database.TranslateQuery(`UPDATE batch SET qty_tersisa = qty_tersisa + ? WHERE id = ?`)
+
+	sisa := qty
+	for _, a := range list {
+		if sisa <= 0 {
+			break
+		}
+		kembali := math.Min(sisa, a.qty)
+		if kembali <= 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(insertQuery, returnID, transaksiID, produkID, a.batchID, kembali, now); err != nil {
+			return 0, fmt.Errorf("failed to record returned batch quantity: %w", err)
+		}
+		if a.ditarik {
+			if _, err := hapusStokBatch(tx, a.batchID, produkID, kembali, models.TipeKerugianDitarik, "Return dari batch yang ditarik", now); err != nil {
+				return 0, err
+			}
+		} else if _, err := tx.Exec(restoreQuery, kembali, a.batchID); err != nil {
+			return 0, fmt.Errorf("failed to restore batch %s: %w", a.batchID, err)
+		}
+		sisa -= kembali
+	}
+
+	return qty - sisa, nil
+}
+
+// Tarik recalls a batch: it is marked as recalled so it is never sold or restocked
+// into again, and its remaining stock is written off from the product stock as a
+// "ditarik" loss. Returns the quantity that was written off.
+func (r *BatchRepository) Tarik(batchID, alasan, ditarikOleh string) (float64, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var produkID int
+	var sisa float64
+	var ditarikAt sql.NullTime
+	batchQuery := database.TranslateQuery(`SELECT produk_id, qty_tersisa, ditarik_at FROM batch WHERE id = ?`)
+	err = tx.QueryRow(batchQuery, batchID).Scan(&produkID, &sisa, &ditarikAt)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("batch tidak ditemukan")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get batch: %w", err)
+	}
+	if ditarikAt.Valid {
+		return 0, fmt.Errorf("batch sudah ditarik pada %s", ditarikAt.Time.Format("2006-01-02 15:04"))
+	}
+
+	now := time.Now()
+	updateQuery := database.TranslateQuery(`
+		UPDATE batch SET ditarik_at = ?, alasan_penarikan = ?, ditarik_oleh = ?, qty_tersisa = 0, updated_at = ?
+		WHERE id = ? AND ditarik_at IS NULL
+	`)
+	if _, err := tx.Exec(updateQuery, now, alasan, ditarikOleh, now, batchID); err != nil {
+		return 0, fmt.Errorf("failed to recall batch: %w", err)
+	}
+
+	if sisa > 0 {
+		if _, err := hapusStokBatch(tx, batchID, produkID, sisa, models.TipeKerugianDitarik, "Penarikan batch: "+alasan, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit batch recall: %w", err)
+	}
+
+	return sisa, nil
+}
+
+// hapusStokBatch writes off stock of a batch from the product stock, with a stock
+// history loss valued at the batch cost (the product cost for batches without one)
+// and a batch write-off record. Returns the loss value.
+func hapusStokBatch(tx *sql.Tx, batchID string, produkID int, qty float64, tipeKerugian, keterangan string, now time.Time) (int, error) {
+	var stokSebelum float64
+	var hargaBeli int
+	produkQuery := database.TranslateQuery(`
+		SELECT p.stok, COALESCE(NULLIF(b.harga_beli, 0), p.harga_beli, 0)
+		FROM batch b
+		JOIN produk p ON p.id = b.produk_id
+		WHERE b.id = ?
+	`)
+	if err := tx.QueryRow(produkQuery, batchID).Scan(&stokSebelum, &hargaBeli); err != nil {
+		return 0, fmt.Errorf("failed to get product stock: %w", err)
+	}
+
+	stokSesudah := math.Max(stokSebelum-qty, 0)
+	nilaiKerugian := int(math.Round(qty * float64(hargaBeli)))
+	updateQuery := database.TranslateQuery(`UPDATE produk SET stok = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	if _, err := tx.Exec(updateQuery, stokSesudah, produkID); err != nil {
+		return 0, fmt.Errorf("failed to update product stock: %w", err)
+	}
+
+	historyQuery := database.TranslateQuery(`
+		INSERT INTO stok_history (
+			produk_id, stok_sebelum, stok_sesudah, perubahan, jenis_perubahan, keterangan,
+			tipe_kerugian, nilai_kerugian, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if _, err := tx.Exec(historyQuery, produkID, stokSebelum, stokSesudah, stokSesudah-stokSebelum, "pengurangan", keterangan,
+		tipeKerugian, nilaiKerugian, now); err != nil {
+		return 0, fmt.Errorf("failed to create stock history: %w", err)
+	}
+
+	penghapusanQuery := database.TranslateQuery(`
+		INSERT INTO batch_penghapusan (batch_id, produk_id, qty, tipe_kerugian, keterangan, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if _, err := tx.Exec(penghapusanQuery, batchID, produkID, qty, tipeKerugian, keterangan, now); err != nil {
+		return 0, fmt.Errorf("failed to record batch write-off: %w", err)
+	}
+
+	return nilaiKerugian, nil
+}
+
+// hapusBatchKonsumsi writes off stock of a product from its batches in the order
+// of its batch policy, expired batches included, with a batch write-off record for
+// each batch. The product stock is left to the caller. Returns the quantity that
+// could be taken from batches.
+func hapusBatchKonsumsi(tx *sql.Tx, produkID int, qty float64, tipeKerugian, keterangan string, now time.Time) (float64, error) {
+	var kebijakan string
+	if err := tx.QueryRow(database.TranslateQuery(kebijakanBatchQuery), models.KebijakanBatchFIFO, produkID).Scan(&kebijakan); err != nil {
+		return 0, fmt.Errorf("failed to get batch policy: %w", err)
+	}
+
+	rows, err := tx.Query(database.TranslateQuery(batchKonsumsiQuery(`id, qty_tersisa`, kebijakan, false)), produkID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get batches: %w", err)
+	}
+	type batchStok struct {
+		id         string
+		qtyTersisa float64
+	}
+	var batches []batchStok
+	for rows.Next() {
+		var b batchStok
+		if err := rows.Scan(&b.id, &b.qtyTersisa); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan batch: %w", err)
+		}
+		batches = append(batches, b)
+	}
+	rows.Close()
+
+	updateQuery := database.TranslateQuery(`UPDATE batch SET qty_tersisa = qty_tersisa - ?, updated_at = ? WHERE id = ?`)
+	penghapusanQuery := database.TranslateQuery(`
+		INSERT INTO batch_penghapusan (batch_id, produk_id, qty, tipe_kerugian, keterangan, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	sisa := qty
+	for _, b := range batches {
+		if sisa <= 0 {
+			break
+		}
+		hapus := math.Min(sisa, b.qtyTersisa)
+		if _, err := tx.Exec(updateQuery, hapus, now, b.id); err != nil {
+			return 0, fmt.Errorf("failed to update batch %s: %w", b.id, err)
+		}
+		if _, err := tx.Exec(penghapusanQuery, b.id, produkID, hapus, tipeKerugian, keterangan, now); err != nil {
+			return 0, fmt.Errorf("failed to record batch write-off: %w", err)
+		}
+		sisa -= hapus
+	}
+
+	return qty - sisa, nil
+}
+
+// GetPenarikan lists the sales that took stock from a batch with the registered
+// customer of each sale, net of returns; voided sales are left out because their
+// stock came back to the store (and was written off if the batch was recalled)
+func (r *BatchRepository) GetPenarikan(batchID string) ([]*models.PenarikanTransaksi, error) {
+	query := `
+		SELECT t.id, t.nomor_transaksi, t.tanggal, t.status,
+		       SUM(a.qty),
+		       COALESCE((SELECT SUM(r.qty) FROM return_item_batch r
+		                 WHERE r.transaksi_id = t.id AND r.batch_id = a.batch_id), 0),
+		       COALESCE(t.pelanggan_id, 0), COALESCE(pl.nama, ''), COALESCE(pl.telepon, ''), COALESCE(pl.email, '')
+		FROM transaksi_item_batch a
+		JOIN transaksi t ON t.id = a.transaksi_id
+		LEFT JOIN pelanggan pl ON pl.id = t.pelanggan_id
+		WHERE a.batch_id = ? AND t.status != 'void'
+		GROUP BY t.id, t.nomor_transaksi, t.tanggal, t.status, t.pelanggan_id, pl.nama, pl.telepon, pl.email, a.batch_id
+		ORDER BY t.tanggal ASC, t.id ASC
+	`
+
+	rows, err := database.Query(query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch sales: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.PenarikanTransaksi{}
+	for rows.Next() {
+		var t models.PenarikanTransaksi
+		var terjual float64
+		if err := rows.Scan(&t.TransaksiID, &t.NomorTransaksi, &t.Tanggal, &t.Status, &terjual, &t.QtyDikembalikan,
+			&t.PelangganID, &t.NamaPelanggan, &t.Telepon, &t.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan batch sale: %w", err)
+		}
+		t.Qty = terjual - t.QtyDikembalikan
+		list = append(list, &t)
+	}
+
+	return list, rows.Err()
+}
+
 // GetAllBatches retrieves all batches (for admin view)
 func (r *BatchRepository) GetAllBatches() ([]*models.Batch, error) {
 	query := `
 		SELECT id, produk_id, qty, qty_tersisa, tanggal_restok,
 		       masa_simpan_hari, tanggal_kadaluarsa, status,
-		       supplier, keterangan, created_at, updated_at
+		       supplier, COALESCE(supplier_id, 0), keterangan, created_at, updated_at,
+		       ditarik_at, COALESCE(alasan_penarikan, ''), COALESCE(ditarik_oleh, ''),
+		       COALESCE(harga_beli, 0), dihapus_at
 		FROM batch
 		ORDER BY tanggal_restok DESC, created_at DESC
 	`
@@ -189,16 +692,24 @@ func (r *BatchRepository) GetAllBatches() ([]*models.Batch, error) {
 			&batch.TanggalKadaluarsa,
 			&batch.Status,
 			&batch.Supplier,
+			&batch.SupplierID,
 			&batch.Keterangan,
 			&batch.CreatedAt,
 			&batch.UpdatedAt,
+			&batch.DitarikAt,
+			&batch.AlasanPenarikan,
+			&batch.DitarikOleh,
+			&batch.HargaBeli,
+			&batch.DihapusAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan batch: %w", err)
 		}
 
-		// Update status based on current date
-		batch.Status = r.calculateBatchStatus(batch.TanggalKadaluarsa)
+		// Update status based on current date; archived batches stay expired
+		if batch.DihapusAt == nil {
+			batch.Status = r.calculateBatchStatus(batch.TanggalKadaluarsa)
+		}
 		batches = append(batches, batch)
 	}
 
@@ -226,7 +737,7 @@ func (r *BatchRepository) GetExpiringBatches(daysThreshold int) ([]*models.Batch
 			SELECT
 				b.id, b.produk_id, b.qty, b.qty_tersisa, b.tanggal_restok,
 				b.masa_simpan_hari, b.tanggal_kadaluarsa, b.status,
-				b.supplier, b.keterangan, b.created_at, b.updated_at,
+				b.supplier, COALESCE(b.supplier_id, 0), b.keterangan, b.created_at, b.updated_at,
 				p.hari_pemberitahuan_kadaluarsa,
 				p.nama as produk_nama,
 				(DATE(b.tanggal_kadaluarsa) - CURRENT_DATE) as days_diff
@@ -242,7 +753,7 @@ func (r *BatchRepository) GetExpiringBatches(daysThreshold int) ([]*models.Batch
 			SELECT
 				b.id, b.produk_id, b.qty, b.qty_tersisa, b.tanggal_restok,
 				b.masa_simpan_hari, b.tanggal_kadaluarsa, b.status,
-				b.supplier, b.keterangan, b.created_at, b.updated_at,
+				b.supplier, COALESCE(b.supplier_id, 0), b.keterangan, b.created_at, b.updated_at,
 				p.hari_pemberitahuan_kadaluarsa,
 				p.nama as produk_nama,
 				CAST(julianday(b.tanggal_kadaluarsa) - julianday('now') AS INTEGER) as days_diff
@@ -277,6 +788,7 @@ func (r *BatchRepository) GetExpiringBatches(daysThreshold int) ([]*models.Batch
 			&batch.TanggalKadaluarsa,
 			&batch.Status,
 			&batch.Supplier,
+			&batch.SupplierID,
 			&batch.Keterangan,
 			&batch.CreatedAt,
 			&batch.UpdatedAt,
@@ -290,8 +802,10 @@ func (r *BatchRepository) GetExpiringBatches(daysThreshold int) ([]*models.Batch
 
 		// DEBUG LOGGING
 
-		// Update status based on current date
-		batch.Status = r.calculateBatchStatus(batch.TanggalKadaluarsa)
+		// Update status based on current date; archived batches stay expired
+		if batch.DihapusAt == nil {
+			batch.Status = r.calculateBatchStatus(batch.TanggalKadaluarsa)
+		}
 		batches = append(batches, batch)
 	}
 
@@ -336,13 +850,90 @@ func (r *BatchRepository) UpdateBatchStatus(batchID string, status string) error
 	return nil
 }
 
-// DeleteBatch deletes a batch (soft delete by setting qty_tersisa to 0)
-func (r *BatchRepository) DeleteBatch(batchID string) error {
-	query := `UPDATE batch SET qty_tersisa = 0, status = 'expired' WHERE id = ?`
+// HapusKadaluarsa writes off the remaining stock of a batch as an expiry loss: the
+// product stock is reduced, the loss is posted to the stock history at the batch cost
+// and the batch is kept as an archived expired record. Returns the quantity and
+// value written off.
+func (r *BatchRepository) HapusKadaluarsa(batchID, keterangan string) (float64, int, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	_, err := r.db.Exec(query, batchID)
+	var produkID int
+	var sisa float64
+	var ditarikAt sql.NullTime
+	batchQuery := database.TranslateQuery(`SELECT produk_id, qty_tersisa, ditarik_at FROM batch WHERE id = ?`)
+	err = tx.QueryRow(batchQuery, batchID).Scan(&produkID, &sisa, &ditarikAt)
+	if err == sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("batch tidak ditemukan")
+	}
 	if err != nil {
-		return fmt.Errorf("failed to delete batch: %w", err)
+		return 0, 0, fmt.Errorf("failed to get batch: %w", err)
+	}
+	if ditarikAt.Valid {
+		return 0, 0, fmt.Errorf("batch sudah ditarik dan stoknya sudah dihapus")
+	}
+
+	now := time.Now()
+	var nilai int
+	if sisa > 0 {
+		if nilai, err = hapusStokBatch(tx, batchID, produkID, sisa, models.TipeKerugianKadaluarsa, keterangan, now); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	updateQuery := database.TranslateQuery(`
+		UPDATE batch SET qty_tersisa = 0, status = 'expired', dihapus_at = ?, updated_at = ?
+		WHERE id = ?
+	`)
+	if _, err := tx.Exec(updateQuery, now, now, batchID); err != nil {
+		return 0, 0, fmt.Errorf("failed to archive batch: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit expired batch write-off: %w", err)
+	}
+
+	return sisa, nilai, nil
+}
+
+// GetKadaluarsaBelumDihapus returns the IDs of expired batches that still hold stock
+func (r *BatchRepository) GetKadaluarsaBelumDihapus() ([]string, error) {
+	query := `
+		SELECT id FROM batch
+		WHERE qty_tersisa > 0 AND ditarik_at IS NULL AND tanggal_kadaluarsa < ?
+		ORDER BY tanggal_kadaluarsa ASC
+	`
+
+	rows, err := database.Query(query, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired batches: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan expired batch: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// CreatePenghapusan records stock written off from a batch
+func (r *BatchRepository) CreatePenghapusan(batchID string, produkID int, qty float64, tipeKerugian, keterangan string) error {
+	query := `
+		INSERT INTO batch_penghapusan (batch_id, produk_id, qty, tipe_kerugian, keterangan, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	if _, err := database.Exec(query, batchID, produkID, qty, tipeKerugian, keterangan, time.Now()); err != nil {
+		return fmt.Errorf("failed to record batch write-off: %w", err)
 	}
 
 	return nil
@@ -356,6 +947,9 @@ func (r *BatchRepository) UpdateAllBatchStatuses() error {
 	}
 
 	for _, batch := range batches {
+		if batch.DihapusAt != nil {
+			continue
+		}
 		newStatus := r.calculateBatchStatus(batch.TanggalKadaluarsa)
 		if newStatus != batch.Status {
 			err := r.UpdateBatchStatus(batch.ID, newStatus)
@@ -370,6 +964,11 @@ func (r *BatchRepository) UpdateAllBatchStatuses() error {
 
 // calculateBatchStatus determines batch status based on expiry date
 func (r *BatchRepository) calculateBatchStatus(expiryDate time.Time) string {
+	return statusBatch(expiryDate)
+}
+
+// statusBatch determines the status of a batch from its expiry date
+func statusBatch(expiryDate time.Time) string {
 	now := time.Now()
 	daysUntilExpiry := int(expiryDate.Sub(now).Hours() / 24)
 
@@ -386,12 +985,15 @@ func (r *BatchRepository) FindBatchByDateAndShelfLife(produkID int, date string,
 	query := `
 		SELECT id, produk_id, qty, qty_tersisa, tanggal_restok,
 		       masa_simpan_hari, tanggal_kadaluarsa, status,
-		       supplier, keterangan, created_at, updated_at
+		       supplier, COALESCE(supplier_id, 0), keterangan, created_at, updated_at,
+		       ditarik_at, COALESCE(alasan_penarikan, ''), COALESCE(ditarik_oleh, ''),
+		       COALESCE(harga_beli, 0), dihapus_at
 		FROM batch
 		WHERE produk_id = ?
 		  AND DATE(tanggal_restok) = DATE(?)
 		  AND masa_simpan_hari = ?
 		  AND qty_tersisa > 0
+		  AND ditarik_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
@@ -407,9 +1009,15 @@ func (r *BatchRepository) FindBatchByDateAndShelfLife(produkID int, date string,
 		&batch.TanggalKadaluarsa,
 		&batch.Status,
 		&batch.Supplier,
+		&batch.SupplierID,
 		&batch.Keterangan,
 		&batch.CreatedAt,
 		&batch.UpdatedAt,
+		&batch.DitarikAt,
+		&batch.AlasanPenarikan,
+		&batch.DitarikOleh,
+		&batch.HargaBeli,
+		&batch.DihapusAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -430,7 +1038,9 @@ func (r *BatchRepository) UpdateBatch(batch *models.Batch) error {
 		SET qty = ?,
 		    qty_tersisa = ?,
 		    supplier = ?,
+		    supplier_id = ?,
 		    keterangan = ?,
+		    harga_beli = ?,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
@@ -440,7 +1050,9 @@ func (r *BatchRepository) UpdateBatch(batch *models.Batch) error {
 		batch.Qty,
 		batch.QtyTersisa,
 		batch.Supplier,
+		sql.NullInt64{Int64: int64(batch.SupplierID), Valid: batch.SupplierID > 0},
 		batch.Keterangan,
+		batch.HargaBeli,
 		batch.ID,
 	)
 
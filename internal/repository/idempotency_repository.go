@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+	"time"
+)
+
+// ErrIdempotencyKeyExists is returned when a key stored inside a write transaction
+// was already completed by a concurrent request with the same key
+var ErrIdempotencyKeyExists = errors.New("idempotency key sudah dipakai request lain")
+
+// IdempotencyRepository handles database operations for idempotency keys
+type IdempotencyRepository struct{}
+
+// NewIdempotencyRepository creates a new repository instance
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{}
+}
+
+// simpanIdempotencyKey stores a completed key inside the transaction that performs
+// the request, so the key is committed if and only if the request is. A stale
+// "processing" key left by an earlier request is overwritten; a completed one
+// returns ErrIdempotencyKeyExists.
+func simpanIdempotencyKey(tx *sql.Tx, scope, key, requestHash string, resourceID int64, now time.Time) error {
+	query := database.TranslateQuery(`
+		INSERT INTO idempotency_key (scope, idempotency_key, request_hash, status, resource_id, created_at, completed_at)
+		VALUES (?, ?, ?, 'completed', ?, ?, ?)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE
+		SET request_hash = excluded.request_hash, status = excluded.status, resource_id = excluded.resource_id,
+			created_at = excluded.created_at, completed_at = excluded.completed_at
+		WHERE idempotency_key.status = 'processing'
+	`)
+
+	result, err := tx.Exec(query, scope, key, requestHash, resourceID, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// Get retrieves a stored idempotency key
+func (r *IdempotencyRepository) Get(scope, key string) (*models.IdempotencyRecord, error) {
+	query := `
+		SELECT scope, idempotency_key, request_hash, status, response, COALESCE(resource_id, 0), created_at, completed_at
+		FROM idempotency_key
+		WHERE scope = ? AND idempotency_key = ?
+	`
+
+	var record models.IdempotencyRecord
+	var response sql.NullString
+	var completedAt sql.NullTime
+	err := database.QueryRow(query, scope, key).Scan(
+		&record.Scope,
+		&record.Key,
+		&record.RequestHash,
+		&record.Status,
+		&response,
+		&record.ResourceID,
+		&record.CreatedAt,
+		&completedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	record.Response = response.String
+	if completedAt.Valid {
+		record.CompletedAt = &completedAt.Time
+	}
+
+	return &record, nil
+}
+
+// Complete stores the original response so retries can replay it
+func (r *IdempotencyRepository) Complete(scope, key, response string) error {
+	query := `
+		UPDATE idempotency_key
+		SET status = 'completed', response = ?, completed_at = COALESCE(completed_at, ?)
+		WHERE scope = ? AND idempotency_key = ?
+	`
+
+	result, err := database.Exec(query, response, time.Now(), scope, key)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("idempotency key %s tidak ditemukan", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,461 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// KartuHadiahRepository handles database operations for gift cards and store credit
+type KartuHadiahRepository struct {
+	db            *sql.DB
+	transaksiRepo *TransaksiRepository
+	settingsRepo  *SettingsRepository
+}
+
+// NewKartuHadiahRepository creates a new repository instance
+func NewKartuHadiahRepository() *KartuHadiahRepository {
+	return &KartuHadiahRepository{
+		db:            database.DB,
+		transaksiRepo: NewTransaksiRepository(),
+		settingsRepo:  NewSettingsRepository(),
+	}
+}
+
+const kartuHadiahColumns = `
+	id, kode, sumber, saldo_awal, saldo, status, pelanggan_id, transaksi_id, return_id,
+	expired_at, created_at, updated_at
+`
+
+// GetAll retrieves gift cards, newest first, optionally filtered by status
+func (r *KartuHadiahRepository) GetAll(status string) ([]*models.KartuHadiah, error) {
+	query := `SELECT ` + kartuHadiahColumns + ` FROM kartu_hadiah`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kartu hadiah: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.KartuHadiah{}
+	for rows.Next() {
+		kartu, err := scanKartuHadiah(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, kartu)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate kartu hadiah: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetByKode retrieves a gift card by its code
+func (r *KartuHadiahRepository) GetByKode(kode string) (*models.KartuHadiah, error) {
+	query := `SELECT ` + kartuHadiahColumns + ` FROM kartu_hadiah WHERE kode = ?`
+
+	kartu, err := scanKartuHadiah(database.QueryRow(query, strings.ToUpper(strings.TrimSpace(kode))))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return kartu, err
+}
+
+// GetMutasi retrieves the balance history of a gift card, oldest first
+func (r *KartuHadiahRepository) GetMutasi(kartuID int) ([]*models.KartuHadiahMutasi, error) {
+	query := `
+		SELECT m.id, m.kartu_id, m.tipe, m.jumlah, m.saldo_sesudah, m.transaksi_id,
+		       COALESCE(t.nomor_transaksi, ''), m.return_id, COALESCE(m.keterangan, ''), m.created_at
+		FROM kartu_hadiah_mutasi m
+		LEFT JOIN transaksi t ON t.id = m.transaksi_id
+		WHERE m.kartu_id = ?
+		ORDER BY m.created_at ASC, m.id ASC
+	`
+
+	rows, err := database.Query(query, kartuID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mutasi kartu hadiah: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.KartuHadiahMutasi{}
+	for rows.Next() {
+		var m models.KartuHadiahMutasi
+		var transaksiID, returnID sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.KartuID, &m.Tipe, &m.Jumlah, &m.SaldoSesudah, &transaksiID,
+			&m.NomorTransaksi, &returnID, &m.Keterangan, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mutasi kartu hadiah: %w", err)
+		}
+		m.TransaksiID = nullIntPtr(transaksiID)
+		m.ReturnID = nullIntPtr(returnID)
+		list = append(list, &m)
+	}
+
+	return list, nil
+}
+
+// Jual records the sale of a gift card as a "kartu_hadiah" transaction with a single
+// non-stock line and issues the card in the same database transaction. The card
+// balance is owed to the holder, so the sale is kept out of sales and revenue.
+func (r *KartuHadiahRepository) Jual(kartu *models.KartuHadiah, req *models.JualKartuHadiahRequest) (int, error) {
+	if r.db == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	nomorSettings, err := r.settingsRepo.GetNomorTransaksiSettings()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction numbering settings: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	nomorTransaksi, err := r.transaksiRepo.GenerateNomorTransaksi(tx, nomorSettings, req.KodeTerminal, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate transaction number: %w", err)
+	}
+
+	totalBayar := 0
+	for _, payment := range req.Pembayaran {
+		totalBayar += payment.Jumlah
+	}
+	kembalian := totalBayar - req.Nominal
+	if kembalian < 0 {
+		return 0, fmt.Errorf("pembayaran tidak mencukupi")
+	}
+
+	var shiftID sql.NullInt64
+	if req.ShiftID > 0 {
+		shiftID = sql.NullInt64{Int64: int64(req.ShiftID), Valid: true}
+	}
+
+	headerQuery := database.TranslateQuery(`INSERT INTO transaksi (
+		nomor_transaksi, pelanggan_id, pelanggan_nama, pelanggan_telp,
+		subtotal, diskon, total, total_bayar, kembalian,
+		status, jenis, catatan, kasir, staff_id, staff_nama, shift_id, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	var transaksiID int64
+	err = tx.QueryRow(headerQuery,
+		nomorTransaksi, req.PelangganID, req.PelangganNama, req.PelangganTelp,
+		req.Nominal, 0, req.Nominal, totalBayar, kembalian,
+		"selesai", models.JenisTransaksiKartuHadiah, "Penjualan kartu hadiah "+kartu.Kode, req.Kasir, req.StaffID, req.StaffNama, shiftID, now,
+	).Scan(&transaksiID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	// Kartu hadiah bukan barang persediaan, jadi baris item tidak memiliki produk
+	itemQuery := database.TranslateQuery(`INSERT INTO transaksi_item (
+		transaksi_id, produk_id, produk_sku, produk_nama, produk_kategori,
+		harga_satuan, jumlah, beratgram, subtotal, kode_pajak, harga_asli, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if _, err := tx.Exec(itemQuery,
+		transaksiID, nil, "KARTU-HADIAH", "Kartu Hadiah "+kartu.Kode, "Kartu Hadiah",
+		req.Nominal, 1, 0, req.Nominal, models.KodePajakBebas, req.Nominal, now,
+	); err != nil {
+		return 0, fmt.Errorf("failed to insert transaction item: %w", err)
+	}
+
+	paymentQuery := database.TranslateQuery(`INSERT INTO pembayaran (transaksi_id, metode, jumlah, referensi, shift_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`)
+	for _, payment := range req.Pembayaran {
+		if _, err := tx.Exec(paymentQuery, transaksiID, payment.Metode, payment.Jumlah, payment.Referensi, shiftID, now); err != nil {
+			return 0, fmt.Errorf("failed to insert payment: %w", err)
+		}
+	}
+
+	id := int(transaksiID)
+	kartu.TransaksiID = &id
+	if err := insertKartuHadiah(tx, kartu, "Dijual pada transaksi "+nomorTransaksi, now); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return id, nil
+}
+
+// Kedaluwarsakan forfeits the remaining balance of active cards whose expiry
+// date has passed. It returns the number of cards and the forfeited balance.
+func (r *KartuHadiahRepository) Kedaluwarsakan(now time.Time) (int, int, error) {
+	if r.db == nil {
+		return 0, 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	selectQuery := database.TranslateQuery(`SELECT id, saldo FROM kartu_hadiah
+		WHERE status = ? AND expired_at IS NOT NULL AND expired_at <= ?`)
+	rows, err := tx.Query(selectQuery, models.StatusKartuHadiahAktif, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get expired kartu hadiah: %w", err)
+	}
+
+	type expiredKartu struct {
+		id    int
+		saldo int
+	}
+	var expired []expiredKartu
+	for rows.Next() {
+		var k expiredKartu
+		if err := rows.Scan(&k.id, &k.saldo); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan expired kartu hadiah: %w", err)
+		}
+		expired = append(expired, k)
+	}
+	rows.Close()
+
+	updateQuery := database.TranslateQuery(`UPDATE kartu_hadiah SET saldo = 0, status = ? WHERE id = ?`)
+	total := 0
+	for _, k := range expired {
+		if _, err := tx.Exec(updateQuery, models.StatusKartuHadiahKedaluwarsa, k.id); err != nil {
+			return 0, 0, fmt.Errorf("failed to expire kartu hadiah: %w", err)
+		}
+		if err := insertMutasiKartuHadiah(tx, k.id, models.MutasiKartuHadiahKedaluwarsa, -k.saldo, 0, nil, nil, "Saldo hangus karena kedaluwarsa", now); err != nil {
+			return 0, 0, err
+		}
+		total += k.saldo
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(expired), total, nil
+}
+
+// pakaiKartuHadiah redeems part of a gift card balance as payment of a sale. The
+// balance is checked and deducted inside the sale's database transaction.
+func pakaiKartuHadiah(tx *sql.Tx, kode string, jumlah int, transaksiID int64, now time.Time) error {
+	kode = strings.ToUpper(strings.TrimSpace(kode))
+
+	var id, saldo int
+	var status string
+	var expiredAt sql.NullTime
+	query := database.TranslateQuery(`SELECT id, saldo, status, expired_at FROM kartu_hadiah WHERE kode = ?`)
+	err := tx.QueryRow(query, kode).Scan(&id, &saldo, &status, &expiredAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("kartu hadiah %s tidak ditemukan", kode)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get kartu hadiah: %w", err)
+	}
+
+	if status == models.StatusKartuHadiahAktif && expiredAt.Valid && !now.Before(expiredAt.Time) {
+		status = models.StatusKartuHadiahKedaluwarsa
+	}
+	if status != models.StatusKartuHadiahAktif {
+		return fmt.Errorf("kartu hadiah %s tidak dapat dipakai (status: %s)", kode, status)
+	}
+	if saldo < jumlah {
+		return fmt.Errorf("saldo kartu hadiah %s tidak mencukupi (saldo: Rp %d)", kode, saldo)
+	}
+
+	status = models.StatusKartuHadiahAktif
+	if saldo == jumlah {
+		status = models.StatusKartuHadiahHabis
+	}
+	updateQuery := database.TranslateQuery(`UPDATE kartu_hadiah SET saldo = saldo - ?, status = ? WHERE id = ? AND saldo >= ?`)
+	result, err := tx.Exec(updateQuery, jumlah, status, id, jumlah)
+	if err != nil {
+		return fmt.Errorf("failed to update kartu hadiah: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("saldo kartu hadiah %s sudah berubah, silakan coba lagi", kode)
+	}
+
+	trxID := int(transaksiID)
+	return insertMutasiKartuHadiah(tx, id, models.MutasiKartuHadiahPakai, -jumlah, saldo-jumlah, &trxID, nil, "", now)
+}
+
+// kembalikanKartuHadiah restores the gift card balances redeemed by a voided sale
+func kembalikanKartuHadiah(tx *sql.Tx, transaksiID int, nomorTransaksi string, now time.Time) error {
+	query := database.TranslateQuery(`SELECT kartu_id, SUM(jumlah) FROM kartu_hadiah_mutasi
+		WHERE transaksi_id = ? AND tipe = ? GROUP BY kartu_id`)
+	rows, err := tx.Query(query, transaksiID, models.MutasiKartuHadiahPakai)
+	if err != nil {
+		return fmt.Errorf("failed to get kartu hadiah redemptions: %w", err)
+	}
+
+	redeemed := make(map[int]int)
+	for rows.Next() {
+		var kartuID, jumlah int
+		if err := rows.Scan(&kartuID, &jumlah); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan kartu hadiah redemption: %w", err)
+		}
+		redeemed[kartuID] = -jumlah
+	}
+	rows.Close()
+
+	// Kartu yang habis karena transaksi ini aktif kembali; kartu kedaluwarsa tetap kedaluwarsa
+	updateQuery := database.TranslateQuery(`UPDATE kartu_hadiah
+		SET saldo = saldo + ?, status = CASE WHEN status = ? THEN ? ELSE status END
+		WHERE id = ?`)
+	saldoQuery := database.TranslateQuery(`SELECT saldo FROM kartu_hadiah WHERE id = ?`)
+	for kartuID, jumlah := range redeemed {
+		if _, err := tx.Exec(updateQuery, jumlah, models.StatusKartuHadiahHabis, models.StatusKartuHadiahAktif, kartuID); err != nil {
+			return fmt.Errorf("failed to restore kartu hadiah: %w", err)
+		}
+		var saldo int
+		if err := tx.QueryRow(saldoQuery, kartuID).Scan(&saldo); err != nil {
+			return fmt.Errorf("failed to get kartu hadiah saldo: %w", err)
+		}
+		if err := insertMutasiKartuHadiah(tx, kartuID, models.MutasiKartuHadiahKembali, jumlah, saldo, &transaksiID, nil,
+			"Void transaksi "+nomorTransaksi, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batalkanKartuHadiah cancels the gift card sold by a voided sale. A card that
+// has already been used cannot be cancelled, so its sale cannot be voided.
+func batalkanKartuHadiah(tx *sql.Tx, transaksiID int, nomorTransaksi string, now time.Time) error {
+	var id, saldoAwal, saldo int
+	var kode, status string
+	query := database.TranslateQuery(`SELECT id, kode, saldo_awal, saldo, status FROM kartu_hadiah WHERE transaksi_id = ?`)
+	err := tx.QueryRow(query, transaksiID).Scan(&id, &kode, &saldoAwal, &saldo, &status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get kartu hadiah: %w", err)
+	}
+	if status != models.StatusKartuHadiahAktif || saldo != saldoAwal {
+		return fmt.Errorf("kartu hadiah %s dari transaksi %s sudah dipakai atau tidak aktif, tidak dapat dibatalkan", kode, nomorTransaksi)
+	}
+
+	updateQuery := database.TranslateQuery(`UPDATE kartu_hadiah SET saldo = 0, status = ? WHERE id = ?`)
+	if _, err := tx.Exec(updateQuery, models.StatusKartuHadiahBatal, id); err != nil {
+		return fmt.Errorf("failed to cancel kartu hadiah: %w", err)
+	}
+
+	return insertMutasiKartuHadiah(tx, id, models.MutasiKartuHadiahBatal, -saldo, 0, &transaksiID, nil,
+		"Void transaksi "+nomorTransaksi, now)
+}
+
+// insertKartuHadiah stores a new gift card together with its issue entry
+func insertKartuHadiah(tx *sql.Tx, kartu *models.KartuHadiah, keterangan string, now time.Time) error {
+	query := database.TranslateQuery(`INSERT INTO kartu_hadiah (
+		kode, sumber, saldo_awal, saldo, status, pelanggan_id, transaksi_id, return_id,
+		expired_at, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	var expiredAt sql.NullTime
+	if kartu.ExpiredAt != nil {
+		expiredAt = sql.NullTime{Time: *kartu.ExpiredAt, Valid: true}
+	}
+
+	var id int64
+	err := tx.QueryRow(query,
+		kartu.Kode, kartu.Sumber, kartu.SaldoAwal, kartu.SaldoAwal, models.StatusKartuHadiahAktif,
+		intPtrValue(kartu.PelangganID), intPtrValue(kartu.TransaksiID), intPtrValue(kartu.ReturnID),
+		expiredAt, now, now,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create kartu hadiah: %w", err)
+	}
+
+	kartu.ID = int(id)
+	kartu.Saldo = kartu.SaldoAwal
+	kartu.Status = models.StatusKartuHadiahAktif
+	kartu.CreatedAt = now
+	kartu.UpdatedAt = now
+
+	return insertMutasiKartuHadiah(tx, kartu.ID, models.MutasiKartuHadiahTerbit, kartu.SaldoAwal, kartu.SaldoAwal,
+		kartu.TransaksiID, kartu.ReturnID, keterangan, now)
+}
+
+// insertMutasiKartuHadiah records a balance change of a gift card
+func insertMutasiKartuHadiah(tx *sql.Tx, kartuID int, tipe string, jumlah, saldoSesudah int, transaksiID, returnID *int, keterangan string, now time.Time) error {
+	query := database.TranslateQuery(`INSERT INTO kartu_hadiah_mutasi (
+		kartu_id, tipe, jumlah, saldo_sesudah, transaksi_id, return_id, keterangan, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+
+	if _, err := tx.Exec(query, kartuID, tipe, jumlah, saldoSesudah,
+		intPtrValue(transaksiID), intPtrValue(returnID), keterangan, now); err != nil {
+		return fmt.Errorf("failed to record mutasi kartu hadiah: %w", err)
+	}
+
+	return nil
+}
+
+// scanKartuHadiah scans a gift card row selected with kartuHadiahColumns
+func scanKartuHadiah(row rowScanner) (*models.KartuHadiah, error) {
+	var k models.KartuHadiah
+	var pelangganID, transaksiID, returnID sql.NullInt64
+	var expiredAt sql.NullTime
+
+	err := row.Scan(
+		&k.ID,
+		&k.Kode,
+		&k.Sumber,
+		&k.SaldoAwal,
+		&k.Saldo,
+		&k.Status,
+		&pelangganID,
+		&transaksiID,
+		&returnID,
+		&expiredAt,
+		&k.CreatedAt,
+		&k.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan kartu hadiah: %w", err)
+	}
+
+	k.PelangganID = nullIntPtr(pelangganID)
+	k.TransaksiID = nullIntPtr(transaksiID)
+	k.ReturnID = nullIntPtr(returnID)
+	if expiredAt.Valid {
+		k.ExpiredAt = &expiredAt.Time
+	}
+
+	return &k, nil
+}
+
+// nullIntPtr converts a nullable column to *int
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
+
+// intPtrValue converts *int to a nullable query argument
+func intPtrValue(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
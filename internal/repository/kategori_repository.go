@@ -18,8 +18,8 @@ func NewKategoriRepository() *KategoriRepository {
 // Create creates a new kategori
 func (r *KategoriRepository) Create(kategori *models.Kategori) error {
 	query := `
-		INSERT INTO kategori (nama, deskripsi, icon, created_at, updated_at)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
+		INSERT INTO kategori (nama, deskripsi, icon, kode_pajak, kebijakan_batch, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
 	`
 
 	var id int64
@@ -27,6 +27,8 @@ func (r *KategoriRepository) Create(kategori *models.Kategori) error {
 		kategori.Nama,
 		kategori.Deskripsi,
 		kategori.Icon,
+		kategori.KodePajak,
+		kategori.KebijakanBatch,
 	).Scan(&id)
 	if err != nil {
 		return fmt.Errorf("failed to create kategori: %w", err)
@@ -44,6 +46,8 @@ func (r *KategoriRepository) GetAll() ([]*models.Kategori, error) {
 			k.nama,
 			k.deskripsi,
 			k.icon,
+			COALESCE(k.kode_pajak, '') as kode_pajak,
+			COALESCE(k.kebijakan_batch, '') as kebijakan_batch,
 			COUNT(p.id) as jumlah_produk,
 			k.created_at,
 			k.updated_at
@@ -67,6 +71,8 @@ func (r *KategoriRepository) GetAll() ([]*models.Kategori, error) {
 			&k.Nama,
 			&k.Deskripsi,
 			&k.Icon,
+			&k.KodePajak,
+			&k.KebijakanBatch,
 			&k.JumlahProduk,
 			&k.CreatedAt,
 			&k.UpdatedAt,
@@ -88,6 +94,8 @@ func (r *KategoriRepository) GetByID(id int) (*models.Kategori, error) {
 			k.nama,
 			k.deskripsi,
 			k.icon,
+			COALESCE(k.kode_pajak, '') as kode_pajak,
+			COALESCE(k.kebijakan_batch, '') as kebijakan_batch,
 			COUNT(p.id) as jumlah_produk,
 			k.created_at,
 			k.updated_at
@@ -103,6 +111,8 @@ func (r *KategoriRepository) GetByID(id int) (*models.Kategori, error) {
 		&k.Nama,
 		&k.Deskripsi,
 		&k.Icon,
+		&k.KodePajak,
+		&k.KebijakanBatch,
 		&k.JumlahProduk,
 		&k.CreatedAt,
 		&k.UpdatedAt,
@@ -126,6 +136,8 @@ func (r *KategoriRepository) GetByNama(nama string) (*models.Kategori, error) {
 			k.nama,
 			k.deskripsi,
 			k.icon,
+			COALESCE(k.kode_pajak, '') as kode_pajak,
+			COALESCE(k.kebijakan_batch, '') as kebijakan_batch,
 			COUNT(p.id) as jumlah_produk,
 			k.created_at,
 			k.updated_at
@@ -141,6 +153,8 @@ func (r *KategoriRepository) GetByNama(nama string) (*models.Kategori, error) {
 		&k.Nama,
 		&k.Deskripsi,
 		&k.Icon,
+		&k.KodePajak,
+		&k.KebijakanBatch,
 		&k.JumlahProduk,
 		&k.CreatedAt,
 		&k.UpdatedAt,
@@ -160,7 +174,7 @@ func (r *KategoriRepository) GetByNama(nama string) (*models.Kategori, error) {
 func (r *KategoriRepository) Update(kategori *models.Kategori) error {
 	query := `
 		UPDATE kategori
-		SET nama = ?, deskripsi = ?, icon = ?
+		SET nama = ?, deskripsi = ?, icon = ?, kode_pajak = ?, kebijakan_batch = ?
 		WHERE id = ?
 	`
 
@@ -168,6 +182,8 @@ func (r *KategoriRepository) Update(kategori *models.Kategori) error {
 		kategori.Nama,
 		kategori.Deskripsi,
 		kategori.Icon,
+		kategori.KodePajak,
+		kategori.KebijakanBatch,
 		kategori.ID,
 	)
 	if err != nil {
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// MetodePembayaranRepository handles database operations for the payment method registry
+type MetodePembayaranRepository struct{}
+
+// NewMetodePembayaranRepository creates a new repository instance
+func NewMetodePembayaranRepository() *MetodePembayaranRepository {
+	return &MetodePembayaranRepository{}
+}
+
+const metodePembayaranColumns = `
+	id, kode, nama, aktif, boleh_kembalian, wajib_referensi,
+	COALESCE(biaya_persen, 0), COALESCE(urutan, 0), created_at, updated_at
+`
+
+// Create creates a new payment method
+func (r *MetodePembayaranRepository) Create(metode *models.MetodePembayaran) error {
+	query := `
+		INSERT INTO metode_pembayaran (
+			kode, nama, aktif, boleh_kembalian, wajib_referensi, biaya_persen, urutan,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
+	`
+
+	var id int64
+	err := database.QueryRow(query,
+		metode.Kode,
+		metode.Nama,
+		boolToInt(metode.Aktif),
+		boolToInt(metode.BolehKembalian),
+		boolToInt(metode.WajibReferensi),
+		metode.BiayaPersen,
+		metode.Urutan,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create metode pembayaran: %w", err)
+	}
+
+	metode.ID = int(id)
+	return nil
+}
+
+// GetAll retrieves all payment methods in display order. The default methods
+// are created on first use.
+func (r *MetodePembayaranRepository) GetAll() ([]*models.MetodePembayaran, error) {
+	query := `SELECT ` + metodePembayaranColumns + ` FROM metode_pembayaran ORDER BY urutan ASC, id ASC`
+
+	rows, err := database.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metode pembayaran: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*models.MetodePembayaran
+	for rows.Next() {
+		metode, err := scanMetodePembayaran(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, metode)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate metode pembayaran: %w", err)
+	}
+
+	if len(list) == 0 {
+		if err := r.createDefaultMetodePembayaran(); err != nil {
+			return nil, err
+		}
+		return r.GetAll()
+	}
+
+	return list, nil
+}
+
+// GetByID retrieves a payment method by ID
+func (r *MetodePembayaranRepository) GetByID(id int) (*models.MetodePembayaran, error) {
+	query := `SELECT ` + metodePembayaranColumns + ` FROM metode_pembayaran WHERE id = ?`
+
+	metode, err := scanMetodePembayaran(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return metode, err
+}
+
+// GetByKode retrieves a payment method by its code
+func (r *MetodePembayaranRepository) GetByKode(kode string) (*models.MetodePembayaran, error) {
+	query := `SELECT ` + metodePembayaranColumns + ` FROM metode_pembayaran WHERE kode = ?`
+
+	metode, err := scanMetodePembayaran(database.QueryRow(query, kode))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return metode, err
+}
+
+// Update updates a payment method. The code is immutable because it is stored
+// on recorded payments.
+func (r *MetodePembayaranRepository) Update(metode *models.MetodePembayaran) error {
+	query := `
+		UPDATE metode_pembayaran SET
+			nama = ?, aktif = ?, boleh_kembalian = ?, wajib_referensi = ?, biaya_persen = ?, urutan = ?
+		WHERE id = ?
+	`
+
+	_, err := database.Exec(query,
+		metode.Nama,
+		boolToInt(metode.Aktif),
+		boolToInt(metode.BolehKembalian),
+		boolToInt(metode.WajibReferensi),
+		metode.BiayaPersen,
+		metode.Urutan,
+		metode.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update metode pembayaran: %w", err)
+	}
+
+	return nil
+}
+
+// createDefaultMetodePembayaran seeds the methods the cashier screen has always offered.
+// Only cash may give change; references stay optional until an admin requires them.
+// Kasbon is only accepted for customers with a credit limit; gift cards are
+// identified by their code in the reference field.
+func (r *MetodePembayaranRepository) createDefaultMetodePembayaran() error {
+	defaults := []*models.MetodePembayaran{
+		{Kode: "tunai", Nama: "Tunai", Aktif: true, BolehKembalian: true, Urutan: 1},
+		{Kode: "qris", Nama: "QRIS", Aktif: true, Urutan: 2},
+		{Kode: "debit", Nama: "Kartu Debit", Aktif: true, Urutan: 3},
+		{Kode: "kredit", Nama: "Kartu Kredit", Aktif: true, Urutan: 4},
+		{Kode: "transfer", Nama: "Transfer Bank", Aktif: true, Urutan: 5},
+		{Kode: models.MetodeKasbon, Nama: "Kasbon (Piutang)", Aktif: true, Urutan: 6},
+		{Kode: models.MetodeKartuHadiah, Nama: "Kartu Hadiah", Aktif: true, WajibReferensi: true, Urutan: 7},
+	}
+
+	for _, metode := range defaults {
+		if err := r.Create(metode); err != nil {
+			return fmt.Errorf("failed to create default metode pembayaran: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// scanMetodePembayaran scans a payment method row selected with metodePembayaranColumns
+func scanMetodePembayaran(row rowScanner) (*models.MetodePembayaran, error) {
+	var m models.MetodePembayaran
+	var aktif, bolehKembalian, wajibReferensi int
+
+	err := row.Scan(
+		&m.ID,
+		&m.Kode,
+		&m.Nama,
+		&aktif,
+		&bolehKembalian,
+		&wajibReferensi,
+		&m.BiayaPersen,
+		&m.Urutan,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan metode pembayaran: %w", err)
+	}
+
+	m.Aktif = aktif == 1
+	m.BolehKembalian = bolehKembalian == 1
+	m.WajibReferensi = wajibReferensi == 1
+
+	return &m, nil
+}
@@ -53,7 +53,7 @@ func (r *PelangganRepository) GetAll() ([]*models.Pelanggan, error) {
 	query := `
 		SELECT
 			id, nama, telepon, email, alamat, level, tipe, poin, diskon_persen,
-			total_transaksi, total_belanja, created_at, updated_at
+			total_transaksi, total_belanja, COALESCE(batas_kredit, 0), created_at, updated_at
 		FROM pelanggan
 		WHERE deleted_at IS NULL
 		ORDER BY nama ASC
@@ -83,6 +83,7 @@ func (r *PelangganRepository) GetAll() ([]*models.Pelanggan, error) {
 			&diskonPersen,
 			&p.TotalTransaksi,
 			&p.TotalBelanja,
+			&p.BatasKredit,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		)
@@ -116,7 +117,7 @@ func (r *PelangganRepository) GetByID(id int) (*models.Pelanggan, error) {
 	query := `
 		SELECT
 			id, nama, telepon, email, alamat, level, tipe, poin, diskon_persen,
-			total_transaksi, total_belanja, created_at, updated_at
+			total_transaksi, total_belanja, COALESCE(batas_kredit, 0), created_at, updated_at
 		FROM pelanggan
 		WHERE id = ? AND deleted_at IS NULL
 	`
@@ -137,6 +138,7 @@ func (r *PelangganRepository) GetByID(id int) (*models.Pelanggan, error) {
 		&diskonPersen,
 		&p.TotalTransaksi,
 		&p.TotalBelanja,
+		&p.BatasKredit,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -171,7 +173,7 @@ func (r *PelangganRepository) GetByTelepon(telepon string) (*models.Pelanggan, e
 	query := `
 		SELECT
 			id, nama, telepon, email, alamat, level, tipe, poin, diskon_persen,
-			total_transaksi, total_belanja, created_at, updated_at
+			total_transaksi, total_belanja, COALESCE(batas_kredit, 0), created_at, updated_at
 		FROM pelanggan
 		WHERE telepon = ? AND deleted_at IS NULL
 	`
@@ -192,6 +194,7 @@ func (r *PelangganRepository) GetByTelepon(telepon string) (*models.Pelanggan, e
 		&diskonPersen,
 		&p.TotalTransaksi,
 		&p.TotalBelanja,
+		&p.BatasKredit,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -288,6 +291,31 @@ func (r *PelangganRepository) UpdatePoin(id int, poin int) error {
 	return nil
 }
 
+// UpdateBatasKredit updates the credit (kasbon) limit of a pelanggan
+func (r *PelangganRepository) UpdateBatasKredit(id int, batasKredit int) error {
+	query := `
+		UPDATE pelanggan
+		SET batas_kredit = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	result, err := database.Exec(query, batasKredit, id)
+	if err != nil {
+		return fmt.Errorf("failed to update batas kredit: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pelanggan not found")
+	}
+
+	return nil
+}
+
 // AddPoin adds points to pelanggan
 func (r *PelangganRepository) AddPoin(id int, poin int) error {
 	query := `
@@ -419,7 +447,7 @@ func (r *PelangganRepository) GetDeleted() ([]*models.Pelanggan, error) {
 	query := `
 		SELECT
 			id, nama, telepon, email, alamat, level, tipe, poin,
-			total_transaksi, total_belanja, created_at, updated_at
+			total_transaksi, total_belanja, COALESCE(batas_kredit, 0), created_at, updated_at
 		FROM pelanggan
 		WHERE deleted_at IS NOT NULL
 		ORDER BY deleted_at DESC
@@ -448,6 +476,7 @@ func (r *PelangganRepository) GetDeleted() ([]*models.Pelanggan, error) {
 			&p.Poin,
 			&p.TotalTransaksi,
 			&p.TotalBelanja,
+			&p.BatasKredit,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		)
@@ -478,7 +507,7 @@ func (r *PelangganRepository) GetByTipe(tipe string) ([]*models.Pelanggan, error
 	query := `
 		SELECT
 			id, nama, telepon, email, alamat, level, tipe, poin,
-			total_transaksi, total_belanja, created_at, updated_at
+			total_transaksi, total_belanja, COALESCE(batas_kredit, 0), created_at, updated_at
 		FROM pelanggan
 		WHERE tipe = ? AND deleted_at IS NULL
 		ORDER BY nama ASC
@@ -507,6 +536,7 @@ func (r *PelangganRepository) GetByTipe(tipe string) ([]*models.Pelanggan, error
 			&p.Poin,
 			&p.TotalTransaksi,
 			&p.TotalBelanja,
+			&p.BatasKredit,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		)
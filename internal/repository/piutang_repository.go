@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+	"time"
+)
+
+// PiutangRepository handles database operations for customer receivables (kasbon)
+type PiutangRepository struct {
+	db *sql.DB
+}
+
+// NewPiutangRepository creates a new repository instance
+func NewPiutangRepository() *PiutangRepository {
+	return &PiutangRepository{
+		db: database.DB,
+	}
+}
+
+const piutangColumns = `
+	pt.id, pt.transaksi_id, t.nomor_transaksi, pt.pelanggan_id, COALESCE(p.nama, t.pelanggan_nama),
+	pt.jumlah, pt.sisa, pt.status, pt.lunas_at, pt.created_at, pt.updated_at
+`
+
+const piutangJoins = `
+	FROM piutang pt
+	JOIN transaksi t ON t.id = pt.transaksi_id
+	LEFT JOIN pelanggan p ON p.id = pt.pelanggan_id
+`
+
+// GetAll retrieves receivables, oldest first, optionally filtered by customer and status
+func (r *PiutangRepository) GetAll(pelangganID int, status string) ([]*models.Piutang, error) {
+	query := `SELECT ` + piutangColumns + piutangJoins + ` WHERE 1 = 1`
+	var args []interface{}
+	if pelangganID > 0 {
+		query += ` AND pt.pelanggan_id = ?`
+		args = append(args, pelangganID)
+	}
+	if status != "" {
+		query += ` AND pt.status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY pt.created_at ASC, pt.id ASC`
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query piutang: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.Piutang{}
+	for rows.Next() {
+		piutang, err := scanPiutang(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, piutang)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate piutang: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetByID retrieves a receivable by ID
+func (r *PiutangRepository) GetByID(id int) (*models.Piutang, error) {
+	query := `SELECT ` + piutangColumns + piutangJoins + ` WHERE pt.id = ?`
+
+	piutang, err := scanPiutang(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return piutang, err
+}
+
+// GetSaldoByPelanggan returns the total outstanding receivable of a customer
+func (r *PiutangRepository) GetSaldoByPelanggan(pelangganID int) (int, error) {
+	query := `SELECT COALESCE(SUM(sisa), 0) FROM piutang WHERE pelanggan_id = ? AND status = ?`
+
+	var saldo int
+	if err := database.QueryRow(query, pelangganID, models.StatusPiutangBelumLunas).Scan(&saldo); err != nil {
+		return 0, fmt.Errorf("failed to get saldo piutang: %w", err)
+	}
+
+	return saldo, nil
+}
+
+// GetSisaByTransaksi returns the outstanding kasbon of a credit sale
+func (r *PiutangRepository) GetSisaByTransaksi(transaksiID int) (int, error) {
+	query := `SELECT COALESCE(SUM(sisa), 0) FROM piutang WHERE transaksi_id = ? AND status = ?`
+
+	var sisa int
+	if err := database.QueryRow(query, transaksiID, models.StatusPiutangBelumLunas).Scan(&sisa); err != nil {
+		return 0, fmt.Errorf("failed to get sisa piutang: %w", err)
+	}
+
+	return sisa, nil
+}
+
+// GetPembayaranByPelanggan retrieves the repayments of a customer, newest first
+func (r *PiutangRepository) GetPembayaranByPelanggan(pelangganID int) ([]*models.PembayaranPiutang, error) {
+	query := `
+		SELECT pb.id, pb.piutang_id, pb.transaksi_id, t.nomor_transaksi, pb.metode, pb.jumlah,
+		       COALESCE(pb.referensi, ''), pb.created_at
+		FROM pembayaran pb
+		JOIN piutang pt ON pt.id = pb.piutang_id
+		JOIN transaksi t ON t.id = pb.transaksi_id
+		WHERE pt.pelanggan_id = ?
+		ORDER BY pb.created_at DESC, pb.id DESC
+	`
+
+	rows, err := database.Query(query, pelangganID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pembayaran piutang: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.PembayaranPiutang{}
+	for rows.Next() {
+		var pb models.PembayaranPiutang
+		if err := rows.Scan(&pb.ID, &pb.PiutangID, &pb.TransaksiID, &pb.NomorTransaksi,
+			&pb.Metode, &pb.Jumlah, &pb.Referensi, &pb.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pembayaran piutang: %w", err)
+		}
+		list = append(list, &pb)
+	}
+
+	return list, nil
+}
+
+// Bayar records a repayment of a customer. The amount is applied to the given
+// receivable, or to the oldest open receivables first when piutangID is 0. Each
+// allocation is stored as a pembayaran row of the original credit sale.
+func (r *PiutangRepository) Bayar(pelangganID, piutangID int, metode string, jumlah int, referensi string, shiftID int, now time.Time) ([]*models.PembayaranPiutang, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	openQuery := `
+		SELECT pt.id, pt.transaksi_id, t.nomor_transaksi, pt.sisa
+		FROM piutang pt
+		JOIN transaksi t ON t.id = pt.transaksi_id
+		WHERE pt.pelanggan_id = ? AND pt.status = ?`
+	args := []interface{}{pelangganID, models.StatusPiutangBelumLunas}
+	if piutangID > 0 {
+		openQuery += ` AND pt.id = ?`
+		args = append(args, piutangID)
+	}
+	openQuery += ` ORDER BY pt.created_at ASC, pt.id ASC`
+
+	rows, err := tx.Query(database.TranslateQuery(openQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open piutang: %w", err)
+	}
+
+	type openPiutang struct {
+		id             int
+		transaksiID    int
+		nomorTransaksi string
+		sisa           int
+	}
+	var open []openPiutang
+	totalSisa := 0
+	for rows.Next() {
+		var o openPiutang
+		if err := rows.Scan(&o.id, &o.transaksiID, &o.nomorTransaksi, &o.sisa); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan open piutang: %w", err)
+		}
+		open = append(open, o)
+		totalSisa += o.sisa
+	}
+	rows.Close()
+
+	if len(open) == 0 {
+		if piutangID > 0 {
+			return nil, fmt.Errorf("piutang tidak ditemukan atau sudah lunas")
+		}
+		return nil, fmt.Errorf("pelanggan tidak memiliki piutang")
+	}
+	if jumlah > totalSisa {
+		return nil, fmt.Errorf("pembayaran (Rp %d) melebihi sisa piutang (Rp %d)", jumlah, totalSisa)
+	}
+
+	var shift sql.NullInt64
+	if shiftID > 0 {
+		shift = sql.NullInt64{Int64: int64(shiftID), Valid: true}
+	}
+
+	updateQuery := database.TranslateQuery(`UPDATE piutang
+		SET sisa = sisa - ?, status = ?, lunas_at = ?
+		WHERE id = ? AND sisa >= ?`)
+	paymentQuery := database.TranslateQuery(`INSERT INTO pembayaran (
+		transaksi_id, metode, jumlah, referensi, shift_id, piutang_id, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	var alokasi []*models.PembayaranPiutang
+	sisaBayar := jumlah
+	for _, o := range open {
+		if sisaBayar <= 0 {
+			break
+		}
+
+		bayar := sisaBayar
+		if bayar > o.sisa {
+			bayar = o.sisa
+		}
+
+		status := models.StatusPiutangBelumLunas
+		var lunasAt sql.NullTime
+		if bayar == o.sisa {
+			status = models.StatusPiutangLunas
+			lunasAt = sql.NullTime{Time: now, Valid: true}
+		}
+
+		result, err := tx.Exec(updateQuery, bayar, status, lunasAt, o.id, bayar)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update piutang: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return nil, fmt.Errorf("sisa piutang %s sudah berubah, silakan coba lagi", o.nomorTransaksi)
+		}
+
+		var paymentID int64
+		if err := tx.QueryRow(paymentQuery, o.transaksiID, metode, bayar, referensi, shift, o.id, now).Scan(&paymentID); err != nil {
+			return nil, fmt.Errorf("failed to insert pembayaran piutang: %w", err)
+		}
+
+		alokasi = append(alokasi, &models.PembayaranPiutang{
+			ID:             int(paymentID),
+			PiutangID:      o.id,
+			TransaksiID:    o.transaksiID,
+			NomorTransaksi: o.nomorTransaksi,
+			Metode:         metode,
+			Jumlah:         bayar,
+			Referensi:      referensi,
+			CreatedAt:      now,
+		})
+		sisaBayar -= bayar
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return alokasi, nil
+}
+
+// createPiutang records the kasbon part of a sale as a receivable, enforcing the
+// customer's credit limit inside the sale's database transaction
+func createPiutang(tx *sql.Tx, transaksiID int64, pelangganID int, jumlah int, now time.Time) error {
+	var batasKredit int
+	limitQuery := database.TranslateQuery(`SELECT COALESCE(batas_kredit, 0) FROM pelanggan WHERE id = ? AND deleted_at IS NULL`)
+	err := tx.QueryRow(limitQuery, pelangganID).Scan(&batasKredit)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("kasbon hanya untuk pelanggan terdaftar")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get batas kredit: %w", err)
+	}
+
+	var saldo int
+	saldoQuery := database.TranslateQuery(`SELECT COALESCE(SUM(sisa), 0) FROM piutang WHERE pelanggan_id = ? AND status = ?`)
+	if err := tx.QueryRow(saldoQuery, pelangganID, models.StatusPiutangBelumLunas).Scan(&saldo); err != nil {
+		return fmt.Errorf("failed to get saldo piutang: %w", err)
+	}
+	if saldo+jumlah > batasKredit {
+		return fmt.Errorf("kasbon melebihi batas kredit pelanggan (sisa limit: Rp %d)", batasKredit-saldo)
+	}
+
+	insertQuery := database.TranslateQuery(`INSERT INTO piutang (
+		transaksi_id, pelanggan_id, jumlah, sisa, status, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if _, err := tx.Exec(insertQuery, transaksiID, pelangganID, jumlah, jumlah, models.StatusPiutangBelumLunas, now, now); err != nil {
+		return fmt.Errorf("failed to create piutang: %w", err)
+	}
+
+	return nil
+}
+
+// batalkanPiutang cancels the receivable of a voided credit sale. A sale that
+// has already been (partly) repaid cannot be voided.
+func batalkanPiutang(tx *sql.Tx, transaksiID int, nomorTransaksi string) error {
+	var id, jumlah, sisa int
+	query := database.TranslateQuery(`SELECT id, jumlah, sisa FROM piutang WHERE transaksi_id = ? AND status != ?`)
+	err := tx.QueryRow(query, transaksiID, models.StatusPiutangBatal).Scan(&id, &jumlah, &sisa)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get piutang: %w", err)
+	}
+	if sisa != jumlah {
+		return fmt.Errorf("kasbon transaksi %s sudah (sebagian) dibayar, tidak dapat dibatalkan", nomorTransaksi)
+	}
+
+	updateQuery := database.TranslateQuery(`UPDATE piutang SET sisa = 0, status = ? WHERE id = ?`)
+	if _, err := tx.Exec(updateQuery, models.StatusPiutangBatal, id); err != nil {
+		return fmt.Errorf("failed to cancel piutang: %w", err)
+	}
+
+	return nil
+}
+
+// kurangiPiutangRetur deducts returned goods from the outstanding kasbon of a credit
+// sale. The receivable keeps its original date, so its age is unchanged, and it is
+// settled once nothing is left to pay.
+func kurangiPiutangRetur(tx *sql.Tx, transaksiID int, nomorTransaksi string, jumlah int, now time.Time) error {
+	query := database.TranslateQuery(`UPDATE piutang
+		SET sisa = sisa - ?,
+			status = CASE WHEN sisa = ? THEN ? ELSE status END,
+			lunas_at = CASE WHEN sisa = ? THEN ? ELSE lunas_at END,
+			updated_at = ?
+		WHERE transaksi_id = ? AND status = ? AND sisa >= ?`)
+
+	result, err := tx.Exec(query, jumlah, jumlah, models.StatusPiutangLunas, jumlah, now, now,
+		transaksiID, models.StatusPiutangBelumLunas, jumlah)
+	if err != nil {
+		return fmt.Errorf("failed to update piutang: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("sisa kasbon transaksi %s sudah berubah, silakan coba lagi", nomorTransaksi)
+	}
+
+	return nil
+}
+
+// scanPiutang scans a receivable row selected with piutangColumns
+func scanPiutang(row rowScanner) (*models.Piutang, error) {
+	var p models.Piutang
+	var lunasAt sql.NullTime
+
+	err := row.Scan(
+		&p.ID,
+		&p.TransaksiID,
+		&p.NomorTransaksi,
+		&p.PelangganID,
+		&p.PelangganNama,
+		&p.Jumlah,
+		&p.Sisa,
+		&p.Status,
+		&lunasAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan piutang: %w", err)
+	}
+
+	if lunasAt.Valid {
+		p.LunasAt = &lunasAt.Time
+	}
+	p.UmurHari = int(time.Since(p.CreatedAt).Hours() / 24)
+
+	return &p, nil
+}
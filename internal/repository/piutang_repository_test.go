@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openPiutangDB opens an in-memory database with one open kasbon of Rp 100.000
+// on transaction 1, created 45 days before now
+func openPiutangDB(t *testing.T, now time.Time) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE piutang (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		transaksi_id INTEGER NOT NULL,
+		pelanggan_id INTEGER NOT NULL,
+		jumlah INTEGER NOT NULL,
+		sisa INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		lunas_at DATETIME,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`)
+	require.NoError(t, err)
+
+	dibuat := now.AddDate(0, 0, -45)
+	_, err = db.Exec(`INSERT INTO piutang (transaksi_id, pelanggan_id, jumlah, sisa, status, created_at, updated_at)
+		VALUES (1, 7, 100000, 100000, ?, ?, ?)`, models.StatusPiutangBelumLunas, dibuat, dibuat)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestKurangiPiutangRetur(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		potong     []int
+		wantErr    bool
+		wantSisa   int
+		wantStatus string
+		wantLunas  bool
+	}{
+		{"retur sebagian mengurangi sisa", []int{30000}, false, 70000, models.StatusPiutangBelumLunas, false},
+		{"dua retur berturut-turut", []int{30000, 20000}, false, 50000, models.StatusPiutangBelumLunas, false},
+		{"retur seluruh sisa melunasi kasbon", []int{100000}, false, 0, models.StatusPiutangLunas, true},
+		{"retur melebihi sisa ditolak", []int{120000}, true, 100000, models.StatusPiutangBelumLunas, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openPiutangDB(t, now)
+
+			var createdAt time.Time
+			require.NoError(t, db.QueryRow(`SELECT created_at FROM piutang WHERE transaksi_id = 1`).Scan(&createdAt))
+
+			var err error
+			for _, jumlah := range tt.potong {
+				tx, beginErr := db.Begin()
+				require.NoError(t, beginErr)
+				err = kurangiPiutangRetur(tx, 1, "TRX-T01-20260903-0001", jumlah, now)
+				if err != nil {
+					tx.Rollback()
+					break
+				}
+				require.NoError(t, tx.Commit())
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var sisa, jumlah int
+			var status string
+			var lunasAt sql.NullTime
+			var createdAfter time.Time
+			require.NoError(t, db.QueryRow(`SELECT jumlah, sisa, status, lunas_at, created_at FROM piutang WHERE transaksi_id = 1`).
+				Scan(&jumlah, &sisa, &status, &lunasAt, &createdAfter))
+
+			assert.Equal(t, 100000, jumlah, "nilai kasbon awal tidak berubah")
+			assert.Equal(t, tt.wantSisa, sisa)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantLunas, lunasAt.Valid)
+			// Umur piutang dihitung dari created_at, jadi retur tidak boleh menggesernya
+			assert.True(t, createdAt.Equal(createdAfter), "tanggal kasbon tidak berubah")
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// ProdukIndukRepository handles database operations for parent products of variants
+type ProdukIndukRepository struct{}
+
+// NewProdukIndukRepository creates a new repository instance
+func NewProdukIndukRepository() *ProdukIndukRepository {
+	return &ProdukIndukRepository{}
+}
+
+// produkIndukQuery selects parent products with a summary of their active variants
+const produkIndukQuery = `
+	SELECT pi.id, pi.nama, COALESCE(pi.kategori, ''), COALESCE(pi.deskripsi, ''), COALESCE(pi.gambar, ''),
+	       COALESCE(pi.atribut, '[]'), pi.created_at, pi.updated_at,
+	       COUNT(p.id), COALESCE(SUM(p.stok), 0), COALESCE(MIN(p.harga_jual), 0), COALESCE(MAX(p.harga_jual), 0)
+	FROM produk_induk pi
+	LEFT JOIN produk p ON p.induk_id = pi.id AND p.deleted_at IS NULL
+`
+
+const produkIndukGroupBy = `
+	GROUP BY pi.id, pi.nama, pi.kategori, pi.deskripsi, pi.gambar, pi.atribut, pi.created_at, pi.updated_at
+`
+
+// Create creates a new parent product
+func (r *ProdukIndukRepository) Create(induk *models.ProdukInduk) error {
+	atribut, err := json.Marshal(induk.Atribut)
+	if err != nil {
+		return fmt.Errorf("failed to encode variant attributes: %w", err)
+	}
+
+	query := `
+		INSERT INTO produk_induk (nama, kategori, deskripsi, gambar, atribut, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
+	`
+
+	var id int64
+	err = database.QueryRow(query,
+		induk.Nama,
+		induk.Kategori,
+		induk.Deskripsi,
+		induk.Gambar,
+		string(atribut),
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create produk induk: %w", err)
+	}
+
+	induk.ID = int(id)
+	return nil
+}
+
+// GetAll retrieves all parent products with the stock and price range of their variants
+func (r *ProdukIndukRepository) GetAll() ([]*models.ProdukInduk, error) {
+	rows, err := database.Query(produkIndukQuery + produkIndukGroupBy + ` ORDER BY pi.nama`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query produk induk: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.ProdukInduk{}
+	for rows.Next() {
+		induk, err := scanProdukInduk(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, induk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate produk induk: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetByID retrieves a parent product by ID with the summary of its variants
+func (r *ProdukIndukRepository) GetByID(id int) (*models.ProdukInduk, error) {
+	query := produkIndukQuery + ` WHERE pi.id = ?` + produkIndukGroupBy
+
+	induk, err := scanProdukInduk(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return induk, err
+}
+
+// Update updates a parent product
+func (r *ProdukIndukRepository) Update(induk *models.ProdukInduk) error {
+	atribut, err := json.Marshal(induk.Atribut)
+	if err != nil {
+		return fmt.Errorf("failed to encode variant attributes: %w", err)
+	}
+
+	query := `
+		UPDATE produk_induk SET
+			nama = ?, kategori = ?, deskripsi = ?, gambar = ?, atribut = ?
+		WHERE id = ?
+	`
+
+	result, err := database.Exec(query,
+		induk.Nama,
+		induk.Kategori,
+		induk.Deskripsi,
+		induk.Gambar,
+		string(atribut),
+		induk.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update produk induk: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("produk induk tidak ditemukan")
+	}
+
+	return nil
+}
+
+// Delete removes a parent product; its variants, including deleted ones, become standalone products
+func (r *ProdukIndukRepository) Delete(id int) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	lepasQuery := database.TranslateQuery(`UPDATE produk SET induk_id = NULL, atribut_varian = '' WHERE induk_id = ?`)
+	if _, err := tx.Exec(lepasQuery, id); err != nil {
+		return fmt.Errorf("failed to detach product variants: %w", err)
+	}
+
+	result, err := tx.Exec(database.TranslateQuery(`DELETE FROM produk_induk WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete produk induk: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("produk induk tidak ditemukan")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// scanProdukInduk scans a parent product row selected with produkIndukQuery
+func scanProdukInduk(row rowScanner) (*models.ProdukInduk, error) {
+	var induk models.ProdukInduk
+	var atribut string
+
+	err := row.Scan(
+		&induk.ID,
+		&induk.Nama,
+		&induk.Kategori,
+		&induk.Deskripsi,
+		&induk.Gambar,
+		&atribut,
+		&induk.CreatedAt,
+		&induk.UpdatedAt,
+		&induk.JumlahVarian,
+		&induk.TotalStok,
+		&induk.HargaMin,
+		&induk.HargaMaks,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan produk induk: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(atribut), &induk.Atribut); err != nil {
+		return nil, fmt.Errorf("failed to decode variant attributes of produk induk %d: %w", induk.ID, err)
+	}
+	if induk.Atribut == nil {
+		induk.Atribut = []string{}
+	}
+
+	return &induk, nil
+}
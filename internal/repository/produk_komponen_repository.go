@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// ProdukKomponenRepository handles database operations for the bill of materials of kit products
+type ProdukKomponenRepository struct {
+	db *sql.DB
+}
+
+// NewProdukKomponenRepository creates a new repository instance
+func NewProdukKomponenRepository() *ProdukKomponenRepository {
+	return &ProdukKomponenRepository{db: database.DB}
+}
+
+const produkKomponenColumns = `
+	k.id, k.paket_id, k.komponen_id, p.nama, COALESCE(p.satuan, ''), k.qty, p.stok, COALESCE(p.harga_beli, 0),
+	k.created_at, k.updated_at
+`
+
+// GetAll retrieves the components of all kits that are not deleted
+func (r *ProdukKomponenRepository) GetAll() ([]*models.ProdukKomponen, error) {
+	query := `SELECT ` + produkKomponenColumns + `
+		FROM produk_komponen k
+		JOIN produk p ON p.id = k.komponen_id
+		JOIN produk paket ON paket.id = k.paket_id
+		WHERE paket.deleted_at IS NULL
+		ORDER BY k.paket_id, k.id`
+
+	rows, err := database.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query produk komponen: %w", err)
+	}
+	defer rows.Close()
+
+	return scanProdukKomponenRows(rows)
+}
+
+// GetByPaket retrieves the components of a kit in the order they were added
+func (r *ProdukKomponenRepository) GetByPaket(paketID int) ([]*models.ProdukKomponen, error) {
+	query := `SELECT ` + produkKomponenColumns + `
+		FROM produk_komponen k
+		JOIN produk p ON p.id = k.komponen_id
+		WHERE k.paket_id = ?
+		ORDER BY k.id`
+
+	rows, err := database.Query(query, paketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query produk komponen: %w", err)
+	}
+	defer rows.Close()
+
+	return scanProdukKomponenRows(rows)
+}
+
+// CountPaketByKomponen returns how many kits that are not deleted contain a product
+func (r *ProdukKomponenRepository) CountPaketByKomponen(komponenID int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM produk_komponen k
+		JOIN produk paket ON paket.id = k.paket_id
+		WHERE k.komponen_id = ? AND paket.deleted_at IS NULL
+	`
+
+	var count int
+	if err := database.QueryRow(query, komponenID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count kits of product: %w", err)
+	}
+
+	return count, nil
+}
+
+// Simpan replaces the components of a kit. Components are matched by product so
+// a component that is kept keeps its ID; components missing from the list are removed.
+func (r *ProdukKomponenRepository) Simpan(paketID int, list []models.ProdukKomponen) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existingQuery := database.TranslateQuery(`SELECT id, komponen_id FROM produk_komponen WHERE paket_id = ?`)
+	rows, err := tx.Query(existingQuery, paketID)
+	if err != nil {
+		return fmt.Errorf("failed to query produk komponen: %w", err)
+	}
+	existing := make(map[int]int)
+	for rows.Next() {
+		var id, komponenID int
+		if err := rows.Scan(&id, &komponenID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan produk komponen: %w", err)
+		}
+		existing[komponenID] = id
+	}
+	rows.Close()
+
+	keep := make(map[int]bool)
+	for _, komponen := range list {
+		if id, ok := existing[komponen.KomponenID]; ok {
+			keep[id] = true
+		}
+	}
+	deleteQuery := database.TranslateQuery(`DELETE FROM produk_komponen WHERE id = ?`)
+	for _, id := range existing {
+		if keep[id] {
+			continue
+		}
+		if _, err := tx.Exec(deleteQuery, id); err != nil {
+			return fmt.Errorf("failed to delete produk komponen: %w", err)
+		}
+	}
+
+	updateQuery := database.TranslateQuery(`UPDATE produk_komponen SET qty = ? WHERE id = ?`)
+	insertQuery := database.TranslateQuery(`INSERT INTO produk_komponen (paket_id, komponen_id, qty, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`)
+	now := time.Now()
+	for _, komponen := range list {
+		if id, ok := existing[komponen.KomponenID]; ok {
+			if _, err := tx.Exec(updateQuery, komponen.Qty, id); err != nil {
+				return fmt.Errorf("failed to update produk komponen %d: %w", komponen.KomponenID, err)
+			}
+			continue
+		}
+		if _, err := tx.Exec(insertQuery, paketID, komponen.KomponenID, komponen.Qty, now, now); err != nil {
+			return fmt.Errorf("failed to insert produk komponen %d: %w", komponen.KomponenID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit produk komponen: %w", err)
+	}
+
+	return nil
+}
+
+func scanProdukKomponenRows(rows *sql.Rows) ([]*models.ProdukKomponen, error) {
+	list := []*models.ProdukKomponen{}
+	for rows.Next() {
+		var komponen models.ProdukKomponen
+		if err := rows.Scan(&komponen.ID, &komponen.PaketID, &komponen.KomponenID, &komponen.KomponenNama,
+			&komponen.KomponenSatuan, &komponen.Qty, &komponen.Stok, &komponen.HargaBeli,
+			&komponen.CreatedAt, &komponen.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan produk komponen: %w", err)
+		}
+		list = append(list, &komponen)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate produk komponen: %w", err)
+	}
+
+	return list, nil
+}
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"ritel-app/internal/database"
 	"ritel-app/internal/models"
+	"strings"
 	"time"
 )
 
@@ -23,8 +26,9 @@ func (r *ProdukRepository) Create(produk *models.Produk) error {
 		INSERT INTO produk (
 			sku, barcode, nama, kategori, berat, harga_beli, harga_jual,
 			stok, satuan, jenis_produk, kadaluarsa, tanggal_masuk, deskripsi, gambar,
-			hari_pemberitahuan_kadaluarsa, masa_simpan_hari
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+			hari_pemberitahuan_kadaluarsa, masa_simpan_hari, kode_pajak, plu,
+			stok_minimum, stok_maksimum, lead_time_hari, supplier_id, kebijakan_batch
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
 	`
 
 	var id int64
@@ -46,6 +50,13 @@ func (r *ProdukRepository) Create(produk *models.Produk) error {
 		produk.Gambar,
 		produk.HariPemberitahuanKadaluarsa,
 		produk.MasaSimpanHari,
+		produk.KodePajak,
+		produk.PLU,
+		produk.StokMinimum,
+		produk.StokMaksimum,
+		produk.LeadTimeHari,
+		sql.NullInt64{Int64: int64(produk.SupplierID), Valid: produk.SupplierID > 0},
+		produk.KebijakanBatch,
 	).Scan(&id)
 
 	if err != nil {
@@ -64,14 +75,17 @@ func (r *ProdukRepository) GetByBarcode(barcode string) (*models.Produk, error)
 	query := `
 		SELECT id, sku, barcode, nama, kategori, berat, harga_beli, harga_jual,
 		       stok, satuan, jenis_produk, kadaluarsa, tanggal_masuk, deskripsi, gambar,
-		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari,
+		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari, kode_pajak, plu,
+		       stok_minimum, stok_maksimum, lead_time_hari, COALESCE(supplier_id, 0), COALESCE(kebijakan_batch, ''),
+		       COALESCE(induk_id, 0), COALESCE(atribut_varian, ''),
 		       created_at, updated_at
 		FROM produk
 		WHERE barcode = ? AND deleted_at IS NULL
 	`
 
 	produk := &models.Produk{}
-	var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk sql.NullString
+	var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk, kodePajak, plu sql.NullString
+	var atributVarian string
 
 	err := database.QueryRow(query, barcode).Scan(
 		&produk.ID,
@@ -91,6 +105,15 @@ func (r *ProdukRepository) GetByBarcode(barcode string) (*models.Produk, error)
 		&gambar,
 		&produk.HariPemberitahuanKadaluarsa,
 		&produk.MasaSimpanHari,
+		&kodePajak,
+		&plu,
+		&produk.StokMinimum,
+		&produk.StokMaksimum,
+		&produk.LeadTimeHari,
+		&produk.SupplierID,
+		&produk.KebijakanBatch,
+		&produk.IndukID,
+		&atributVarian,
 		&produk.CreatedAt,
 		&produk.UpdatedAt,
 	)
@@ -119,23 +142,49 @@ func (r *ProdukRepository) GetByBarcode(barcode string) (*models.Produk, error)
 	if gambar.Valid {
 		produk.Gambar = gambar.String
 	}
+	if kodePajak.Valid {
+		produk.KodePajak = kodePajak.String
+	}
+	if plu.Valid {
+		produk.PLU = plu.String
+	}
+	produk.AtributVarian = decodeAtributVarian(atributVarian)
 
 	return produk, nil
 }
 
+// GetByPLU retrieves a product by its scale PLU code, ignoring leading zeros (excluding soft-deleted)
+func (r *ProdukRepository) GetByPLU(plu string) (*models.Produk, error) {
+	query := `SELECT id FROM produk WHERE plu <> '' AND LTRIM(plu, '0') = ? AND deleted_at IS NULL`
+
+	var id int
+	err := database.QueryRow(query, strings.TrimLeft(plu, "0")).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product by PLU: %w", err)
+	}
+
+	return r.GetByID(id)
+}
+
 // GetBySKU retrieves a product by SKU (excluding soft-deleted)
 func (r *ProdukRepository) GetBySKU(sku string) (*models.Produk, error) {
 	query := `
 		SELECT id, sku, barcode, nama, kategori, berat, harga_beli, harga_jual,
 		       stok, satuan, jenis_produk, kadaluarsa, tanggal_masuk, deskripsi, gambar,
-		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari,
+		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari, kode_pajak, plu,
+		       stok_minimum, stok_maksimum, lead_time_hari, COALESCE(supplier_id, 0), COALESCE(kebijakan_batch, ''),
+		       COALESCE(induk_id, 0), COALESCE(atribut_varian, ''),
 		       created_at, updated_at
 		FROM produk
 		WHERE sku = ? AND deleted_at IS NULL
 	`
 
 	produk := &models.Produk{}
-	var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk sql.NullString
+	var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk, kodePajak, plu sql.NullString
+	var atributVarian string
 
 	err := database.QueryRow(query, sku).Scan(
 		&produk.ID,
@@ -155,6 +204,15 @@ func (r *ProdukRepository) GetBySKU(sku string) (*models.Produk, error) {
 		&gambar,
 		&produk.HariPemberitahuanKadaluarsa,
 		&produk.MasaSimpanHari,
+		&kodePajak,
+		&plu,
+		&produk.StokMinimum,
+		&produk.StokMaksimum,
+		&produk.LeadTimeHari,
+		&produk.SupplierID,
+		&produk.KebijakanBatch,
+		&produk.IndukID,
+		&atributVarian,
 		&produk.CreatedAt,
 		&produk.UpdatedAt,
 	)
@@ -183,6 +241,13 @@ func (r *ProdukRepository) GetBySKU(sku string) (*models.Produk, error) {
 	if gambar.Valid {
 		produk.Gambar = gambar.String
 	}
+	if kodePajak.Valid {
+		produk.KodePajak = kodePajak.String
+	}
+	if plu.Valid {
+		produk.PLU = plu.String
+	}
+	produk.AtributVarian = decodeAtributVarian(atributVarian)
 
 	return produk, nil
 }
@@ -192,7 +257,9 @@ func (r *ProdukRepository) GetAll() ([]*models.Produk, error) {
 	query := `
 		SELECT id, sku, barcode, nama, kategori, berat, harga_beli, harga_jual,
 		       stok, satuan, jenis_produk, kadaluarsa, tanggal_masuk, deskripsi, gambar,
-		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari,
+		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari, kode_pajak, plu,
+		       stok_minimum, stok_maksimum, lead_time_hari, COALESCE(supplier_id, 0), COALESCE(kebijakan_batch, ''),
+		       COALESCE(induk_id, 0), COALESCE(atribut_varian, ''),
 		       created_at, updated_at
 		FROM produk
 		WHERE deleted_at IS NULL
@@ -209,7 +276,8 @@ func (r *ProdukRepository) GetAll() ([]*models.Produk, error) {
 
 	for rows.Next() {
 		produk := &models.Produk{}
-		var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk sql.NullString
+		var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk, kodePajak, plu sql.NullString
+		var atributVarian string
 
 		err := rows.Scan(
 			&produk.ID,
@@ -229,6 +297,15 @@ func (r *ProdukRepository) GetAll() ([]*models.Produk, error) {
 			&gambar,
 			&produk.HariPemberitahuanKadaluarsa,
 			&produk.MasaSimpanHari,
+			&kodePajak,
+			&plu,
+			&produk.StokMinimum,
+			&produk.StokMaksimum,
+			&produk.LeadTimeHari,
+			&produk.SupplierID,
+			&produk.KebijakanBatch,
+			&produk.IndukID,
+			&atributVarian,
 			&produk.CreatedAt,
 			&produk.UpdatedAt,
 		)
@@ -254,6 +331,13 @@ func (r *ProdukRepository) GetAll() ([]*models.Produk, error) {
 		if gambar.Valid {
 			produk.Gambar = gambar.String
 		}
+		if kodePajak.Valid {
+			produk.KodePajak = kodePajak.String
+		}
+		if plu.Valid {
+			produk.PLU = plu.String
+		}
+		produk.AtributVarian = decodeAtributVarian(atributVarian)
 
 		products = append(products, produk)
 	}
@@ -265,8 +349,8 @@ func (r *ProdukRepository) CreateStokHistory(history *models.StokHistory) error
 	query := `
         INSERT INTO stok_history (
             produk_id, stok_sebelum, stok_sesudah, perubahan,
-            jenis_perubahan, keterangan, tipe_kerugian, nilai_kerugian
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+            jenis_perubahan, keterangan, tipe_kerugian, nilai_kerugian, harga_beli
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 
 	_, err := database.Exec(
@@ -279,6 +363,7 @@ func (r *ProdukRepository) CreateStokHistory(history *models.StokHistory) error
 		history.Keterangan,
 		history.TipeKerugian,
 		history.NilaiKerugian,
+		history.HargaBeli,
 	)
 
 	if err != nil {
@@ -293,14 +378,17 @@ func (r *ProdukRepository) GetByID(id int) (*models.Produk, error) {
 	query := `
         SELECT id, sku, barcode, nama, kategori, berat, harga_beli, harga_jual,
                stok, satuan, jenis_produk, kadaluarsa, tanggal_masuk, deskripsi, gambar,
-               hari_pemberitahuan_kadaluarsa, masa_simpan_hari,
+               hari_pemberitahuan_kadaluarsa, masa_simpan_hari, kode_pajak, plu,
+               stok_minimum, stok_maksimum, lead_time_hari, COALESCE(supplier_id, 0), COALESCE(kebijakan_batch, ''),
+               COALESCE(induk_id, 0), COALESCE(atribut_varian, ''),
                created_at, updated_at
         FROM produk
         WHERE id = ? AND deleted_at IS NULL
     `
 
 	produk := &models.Produk{}
-	var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk sql.NullString
+	var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk, kodePajak, plu sql.NullString
+	var atributVarian string
 
 	err := database.QueryRow(query, id).Scan(
 		&produk.ID,
@@ -320,6 +408,15 @@ func (r *ProdukRepository) GetByID(id int) (*models.Produk, error) {
 		&gambar,
 		&produk.HariPemberitahuanKadaluarsa,
 		&produk.MasaSimpanHari,
+		&kodePajak,
+		&plu,
+		&produk.StokMinimum,
+		&produk.StokMaksimum,
+		&produk.LeadTimeHari,
+		&produk.SupplierID,
+		&produk.KebijakanBatch,
+		&produk.IndukID,
+		&atributVarian,
 		&produk.CreatedAt,
 		&produk.UpdatedAt,
 	)
@@ -348,6 +445,13 @@ func (r *ProdukRepository) GetByID(id int) (*models.Produk, error) {
 	if gambar.Valid {
 		produk.Gambar = gambar.String
 	}
+	if kodePajak.Valid {
+		produk.KodePajak = kodePajak.String
+	}
+	if plu.Valid {
+		produk.PLU = plu.String
+	}
+	produk.AtributVarian = decodeAtributVarian(atributVarian)
 
 	return produk, nil
 }
@@ -357,7 +461,8 @@ func (r *ProdukRepository) GetStokHistory(produkID int) ([]*models.StokHistory,
 
 	query := `
         SELECT id, produk_id, stok_sebelum, stok_sesudah, perubahan,
-               jenis_perubahan, keterangan, tipe_kerugian, nilai_kerugian, created_at
+               jenis_perubahan, keterangan, tipe_kerugian, nilai_kerugian,
+               COALESCE(harga_beli, 0), created_at
         FROM stok_history
         WHERE produk_id = ?
         ORDER BY created_at DESC
@@ -386,6 +491,7 @@ func (r *ProdukRepository) GetStokHistory(produkID int) ([]*models.StokHistory,
 			&h.Keterangan,
 			&tipeKerugian,
 			&nilaiKerugianRaw,
+			&h.HargaBeli,
 			&h.CreatedAt,
 		)
 		if err != nil {
@@ -429,7 +535,8 @@ func (r *ProdukRepository) Update(produk *models.Produk) error {
 			berat = ?, harga_beli = ?, harga_jual = ?,
 			stok = ?, satuan = ?, jenis_produk = ?, kadaluarsa = ?,
 			tanggal_masuk = ?, deskripsi = ?, gambar = ?,
-			hari_pemberitahuan_kadaluarsa = ?, masa_simpan_hari = ?,
+			hari_pemberitahuan_kadaluarsa = ?, masa_simpan_hari = ?, kode_pajak = ?, plu = ?,
+			stok_minimum = ?, stok_maksimum = ?, lead_time_hari = ?, supplier_id = ?, kebijakan_batch = ?,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
@@ -452,6 +559,13 @@ func (r *ProdukRepository) Update(produk *models.Produk) error {
 		produk.Gambar,
 		produk.HariPemberitahuanKadaluarsa,
 		produk.MasaSimpanHari,
+		produk.KodePajak,
+		produk.PLU,
+		produk.StokMinimum,
+		produk.StokMaksimum,
+		produk.LeadTimeHari,
+		sql.NullInt64{Int64: int64(produk.SupplierID), Valid: produk.SupplierID > 0},
+		produk.KebijakanBatch,
 		produk.ID,
 	)
 
@@ -537,7 +651,9 @@ func (r *ProdukRepository) GetDeleted() ([]*models.Produk, error) {
 	query := `
 		SELECT id, sku, barcode, nama, kategori, berat, harga_beli, harga_jual,
 		       stok, satuan, jenis_produk, kadaluarsa, tanggal_masuk, deskripsi, gambar,
-		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari,
+		       hari_pemberitahuan_kadaluarsa, masa_simpan_hari, kode_pajak, plu,
+		       stok_minimum, stok_maksimum, lead_time_hari, COALESCE(supplier_id, 0), COALESCE(kebijakan_batch, ''),
+		       COALESCE(induk_id, 0), COALESCE(atribut_varian, ''),
 		       created_at, updated_at
 		FROM produk
 		WHERE deleted_at IS NOT NULL
@@ -553,7 +669,8 @@ func (r *ProdukRepository) GetDeleted() ([]*models.Produk, error) {
 	var produks []*models.Produk
 	for rows.Next() {
 		produk := &models.Produk{}
-		var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk sql.NullString
+		var barcodeNull, kadaluarsa, tanggalMasuk, gambar, jenisProduk, kodePajak, plu sql.NullString
+		var atributVarian string
 
 		err := rows.Scan(
 			&produk.ID,
@@ -573,6 +690,15 @@ func (r *ProdukRepository) GetDeleted() ([]*models.Produk, error) {
 			&gambar,
 			&produk.HariPemberitahuanKadaluarsa,
 			&produk.MasaSimpanHari,
+			&kodePajak,
+			&plu,
+			&produk.StokMinimum,
+			&produk.StokMaksimum,
+			&produk.LeadTimeHari,
+			&produk.SupplierID,
+			&produk.KebijakanBatch,
+			&produk.IndukID,
+			&atributVarian,
 			&produk.CreatedAt,
 			&produk.UpdatedAt,
 		)
@@ -593,6 +719,13 @@ func (r *ProdukRepository) GetDeleted() ([]*models.Produk, error) {
 		if gambar.Valid {
 			produk.Gambar = gambar.String
 		}
+		if kodePajak.Valid {
+			produk.KodePajak = kodePajak.String
+		}
+		if plu.Valid {
+			produk.PLU = plu.String
+		}
+		produk.AtributVarian = decodeAtributVarian(atributVarian)
 		if jenisProduk.Valid {
 			produk.JenisProduk = jenisProduk.String
 		}
@@ -614,6 +747,63 @@ func (r *ProdukRepository) UpdateStok(id int, stok float64) error {
 	return nil
 }
 
+// UpdateStokMasuk sets the stock after receiving qty units bought at hargaBeli each
+// and recalculates the moving-average cost (HPP) from the stock and cost of produk
+// before the receipt. Returns the new HPP.
+func (r *ProdukRepository) UpdateStokMasuk(produk *models.Produk, qty float64, hargaBeli int) (int, error) {
+	hpp := hitungHPPRataRata(produk.Stok, produk.HargaBeli, qty, hargaBeli)
+	query := `UPDATE produk SET stok = ?, harga_beli = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+
+	_, err := database.Exec(query, produk.Stok+qty, hpp, produk.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update stock and cost: %w", err)
+	}
+
+	return hpp, nil
+}
+
+// UpdateStokIdempoten sets the stock of produk after an adjustment and stores the
+// adjustment's idempotency key in the same transaction, so a retry either finds the
+// key or finds the stock unchanged. Incoming stock bought at hargaBeli updates the
+// moving-average cost like UpdateStokMasuk. Returns the HPP after the change.
+func (r *ProdukRepository) UpdateStokIdempoten(produk *models.Produk, stok float64, hargaBeli int, scope, key, requestHash string) (int, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	hpp := hitungHPPRataRata(produk.Stok, produk.HargaBeli, stok-produk.Stok, hargaBeli)
+	query := database.TranslateQuery(`UPDATE produk SET stok = ?, harga_beli = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	if _, err := tx.Exec(query, stok, hpp, produk.ID); err != nil {
+		return 0, fmt.Errorf("failed to update stock: %w", err)
+	}
+
+	if err := simpanIdempotencyKey(tx, scope, key, requestHash, int64(produk.ID), time.Now()); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit stock update: %w", err)
+	}
+
+	return hpp, nil
+}
+
+// hitungHPPRataRata returns the weighted moving-average cost after receiving qty
+// units bought at hargaBeli each on top of stok units that cost hpp each.
+// Empty or negative stock carries no cost, so the receipt price becomes the HPP.
+// A receipt without a purchase price (0) leaves the HPP unchanged.
+func hitungHPPRataRata(stok float64, hpp int, qty float64, hargaBeli int) int {
+	if qty <= 0 || hargaBeli <= 0 {
+		return hpp
+	}
+	if stok <= 0 {
+		return hargaBeli
+	}
+	return int(math.Round((stok*float64(hpp) + qty*float64(hargaBeli)) / (stok + qty)))
+}
+
 // UpdateStokIncrement updates stock by increment/decrement
 func (r *ProdukRepository) UpdateStokIncrement(id int, perubahan float64) error {
 	query := `UPDATE produk SET stok = stok + ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
@@ -625,3 +815,81 @@ func (r *ProdukRepository) UpdateStokIncrement(id int, perubahan float64) error
 
 	return nil
 }
+
+// SetVarian links a product to a parent product with its attribute values, or
+// makes it a standalone product again when indukID is 0
+func (r *ProdukRepository) SetVarian(produkID, indukID int, atribut map[string]string) error {
+	atributJSON := ""
+	if indukID > 0 && len(atribut) > 0 {
+		data, err := json.Marshal(atribut)
+		if err != nil {
+			return fmt.Errorf("failed to encode variant attributes: %w", err)
+		}
+		atributJSON = string(data)
+	}
+
+	query := `UPDATE produk SET induk_id = ?, atribut_varian = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL`
+	result, err := database.Exec(query, sql.NullInt64{Int64: int64(indukID), Valid: indukID > 0}, atributJSON, produkID)
+	if err != nil {
+		return fmt.Errorf("failed to update product variant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("produk dengan ID %d tidak ditemukan", produkID)
+	}
+
+	return nil
+}
+
+// GetByInduk retrieves the variants of a parent product (excluding soft-deleted)
+func (r *ProdukRepository) GetByInduk(indukID int) ([]*models.Produk, error) {
+	rows, err := database.Query(`SELECT id FROM produk WHERE induk_id = ? AND deleted_at IS NULL ORDER BY id`, indukID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product variants: %w", err)
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan product variant: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate product variants: %w", err)
+	}
+
+	varian := make([]*models.Produk, 0, len(ids))
+	for _, id := range ids {
+		produk, err := r.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if produk != nil {
+			varian = append(varian, produk)
+		}
+	}
+
+	return varian, nil
+}
+
+// decodeAtributVarian decodes the attribute values stored on a variant; products
+// that are not a variant have no attributes
+func decodeAtributVarian(data string) map[string]string {
+	if data == "" {
+		return nil
+	}
+	var atribut map[string]string
+	if err := json.Unmarshal([]byte(data), &atribut); err != nil {
+		log.Printf("[REPO] Warning: invalid variant attributes %q: %v", data, err)
+		return nil
+	}
+	return atribut
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// ProdukSatuanRepository handles database operations for additional product units of measure
+type ProdukSatuanRepository struct {
+	db *sql.DB
+}
+
+// NewProdukSatuanRepository creates a new repository instance
+func NewProdukSatuanRepository() *ProdukSatuanRepository {
+	return &ProdukSatuanRepository{db: database.DB}
+}
+
+const produkSatuanColumns = `
+	s.id, s.produk_id, s.nama, s.konversi, s.barcode, COALESCE(s.harga_jual, 0), s.created_at, s.updated_at
+`
+
+// GetAll retrieves the units of all products that are not deleted
+func (r *ProdukSatuanRepository) GetAll() ([]*models.ProdukSatuan, error) {
+	query := `SELECT ` + produkSatuanColumns + `
+		FROM produk_satuan s
+		JOIN produk p ON p.id = s.produk_id
+		WHERE p.deleted_at IS NULL
+		ORDER BY s.produk_id, s.konversi, s.id`
+
+	rows, err := database.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query produk satuan: %w", err)
+	}
+	defer rows.Close()
+
+	return scanProdukSatuanRows(rows)
+}
+
+// GetByProduk retrieves the units of a product, smallest conversion first
+func (r *ProdukSatuanRepository) GetByProduk(produkID int) ([]*models.ProdukSatuan, error) {
+	query := `SELECT ` + produkSatuanColumns + `
+		FROM produk_satuan s
+		WHERE s.produk_id = ?
+		ORDER BY s.konversi, s.id`
+
+	rows, err := database.Query(query, produkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query produk satuan: %w", err)
+	}
+	defer rows.Close()
+
+	return scanProdukSatuanRows(rows)
+}
+
+// GetByID retrieves a unit by ID
+func (r *ProdukSatuanRepository) GetByID(id int) (*models.ProdukSatuan, error) {
+	query := `SELECT ` + produkSatuanColumns + ` FROM produk_satuan s WHERE s.id = ?`
+
+	satuan, err := scanProdukSatuan(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return satuan, err
+}
+
+// GetByBarcode retrieves the unit a barcode is assigned to (excluding soft-deleted products)
+func (r *ProdukSatuanRepository) GetByBarcode(barcode string) (*models.ProdukSatuan, error) {
+	query := `SELECT ` + produkSatuanColumns + `
+		FROM produk_satuan s
+		JOIN produk p ON p.id = s.produk_id
+		WHERE s.barcode = ? AND p.deleted_at IS NULL`
+
+	satuan, err := scanProdukSatuan(database.QueryRow(query, barcode))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return satuan, err
+}
+
+// Simpan replaces the units of a product. Units are matched by name so a unit
+// that is kept keeps its ID; units missing from the list are removed.
+func (r *ProdukSatuanRepository) Simpan(produkID int, list []models.ProdukSatuan) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existingQuery := database.TranslateQuery(`SELECT id, nama FROM produk_satuan WHERE produk_id = ?`)
+	rows, err := tx.Query(existingQuery, produkID)
+	if err != nil {
+		return fmt.Errorf("failed to query produk satuan: %w", err)
+	}
+	existing := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var nama string
+		if err := rows.Scan(&id, &nama); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan produk satuan: %w", err)
+		}
+		existing[strings.ToLower(nama)] = id
+	}
+	rows.Close()
+
+	// Remove units that are no longer listed first, so their barcodes can be reused
+	keep := make(map[int]bool)
+	for _, satuan := range list {
+		if id, ok := existing[strings.ToLower(satuan.Nama)]; ok {
+			keep[id] = true
+		}
+	}
+	deleteQuery := database.TranslateQuery(`DELETE FROM produk_satuan WHERE id = ?`)
+	for _, id := range existing {
+		if keep[id] {
+			continue
+		}
+		if _, err := tx.Exec(deleteQuery, id); err != nil {
+			return fmt.Errorf("failed to delete produk satuan: %w", err)
+		}
+	}
+
+	// Clear barcodes of kept units before reassigning, so two units can swap barcodes
+	clearQuery := database.TranslateQuery(`UPDATE produk_satuan SET barcode = NULL WHERE id = ?`)
+	for id := range keep {
+		if _, err := tx.Exec(clearQuery, id); err != nil {
+			return fmt.Errorf("failed to update produk satuan: %w", err)
+		}
+	}
+
+	updateQuery := database.TranslateQuery(`UPDATE produk_satuan
+		SET nama = ?, konversi = ?, barcode = ?, harga_jual = ? WHERE id = ?`)
+	insertQuery := database.TranslateQuery(`INSERT INTO produk_satuan (produk_id, nama, konversi, barcode, harga_jual, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	now := time.Now()
+	for _, satuan := range list {
+		var barcode sql.NullString
+		if satuan.Barcode != "" {
+			barcode = sql.NullString{String: satuan.Barcode, Valid: true}
+		}
+
+		if id, ok := existing[strings.ToLower(satuan.Nama)]; ok {
+			if _, err := tx.Exec(updateQuery, satuan.Nama, satuan.Konversi, barcode, satuan.HargaJual, id); err != nil {
+				return fmt.Errorf("failed to update produk satuan %s: %w", satuan.Nama, err)
+			}
+			continue
+		}
+		if _, err := tx.Exec(insertQuery, produkID, satuan.Nama, satuan.Konversi, barcode, satuan.HargaJual, now, now); err != nil {
+			return fmt.Errorf("failed to insert produk satuan %s: %w", satuan.Nama, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit produk satuan: %w", err)
+	}
+
+	return nil
+}
+
+func scanProdukSatuanRows(rows *sql.Rows) ([]*models.ProdukSatuan, error) {
+	list := []*models.ProdukSatuan{}
+	for rows.Next() {
+		satuan, err := scanProdukSatuan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan produk satuan: %w", err)
+		}
+		list = append(list, satuan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate produk satuan: %w", err)
+	}
+
+	return list, nil
+}
+
+func scanProdukSatuan(row rowScanner) (*models.ProdukSatuan, error) {
+	var satuan models.ProdukSatuan
+	var barcode sql.NullString
+
+	if err := row.Scan(&satuan.ID, &satuan.ProdukID, &satuan.Nama, &satuan.Konversi, &barcode,
+		&satuan.HargaJual, &satuan.CreatedAt, &satuan.UpdatedAt); err != nil {
+		return nil, err
+	}
+	satuan.Barcode = barcode.String
+
+	return &satuan, nil
+}
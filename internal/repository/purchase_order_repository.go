@@ -0,0 +1,587 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// PurchaseOrderRepository handles database operations for purchase orders and goods receipts
+type PurchaseOrderRepository struct{}
+
+// NewPurchaseOrderRepository creates a new repository instance
+func NewPurchaseOrderRepository() *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{}
+}
+
+const purchaseOrderColumns = `
+	po.id, po.nomor, po.supplier_id, COALESCE(s.nama, ''), po.status, po.tanggal_diharapkan,
+	COALESCE(po.catatan, ''), COALESCE(po.total_estimasi, 0),
+	COALESCE((SELECT SUM(i.nilai_diterima) FROM purchase_order_item i WHERE i.po_id = po.id), 0),
+	COALESCE(po.dibuat_oleh, ''), po.dikirim_at, po.ditutup_at, po.created_at, po.updated_at
+`
+
+const purchaseOrderFrom = ` FROM purchase_order po LEFT JOIN supplier s ON s.id = po.supplier_id`
+
+// GetAll retrieves purchase orders without lines, newest first, optionally filtered by status
+func (r *PurchaseOrderRepository) GetAll(status string) ([]*models.PurchaseOrder, error) {
+	query := `SELECT ` + purchaseOrderColumns + purchaseOrderFrom
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE po.status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY po.created_at DESC, po.id DESC`
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purchase order: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.PurchaseOrder{}
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, po)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate purchase order: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetByID retrieves a purchase order with its lines and goods receipts
+func (r *PurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	query := `SELECT ` + purchaseOrderColumns + purchaseOrderFrom + ` WHERE po.id = ?`
+
+	po, err := scanPurchaseOrder(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if po.Items, err = r.getItems(po.ID); err != nil {
+		return nil, err
+	}
+	if po.Penerimaan, err = r.getPenerimaan(po.ID); err != nil {
+		return nil, err
+	}
+
+	return po, nil
+}
+
+// Create saves a new draft purchase order with its lines and assigns its number
+func (r *PurchaseOrderRepository) Create(po *models.PurchaseOrder) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	nomor, err := generateNomorDokumen(tx, "purchase_order", "PO", now)
+	if err != nil {
+		return err
+	}
+
+	query := database.TranslateQuery(`
+		INSERT INTO purchase_order (
+			nomor, supplier_id, status, tanggal_diharapkan, catatan, total_estimasi, dibuat_oleh,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`)
+
+	var id int64
+	if err := tx.QueryRow(query, nomor, po.SupplierID, models.StatusPODraft, timePtrValue(po.TanggalDiharapkan),
+		po.Catatan, po.TotalEstimasi, po.DibuatOleh, now, now).Scan(&id); err != nil {
+		return fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	if err := insertPurchaseOrderItems(tx, int(id), po.Items); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purchase order: %w", err)
+	}
+
+	po.ID = int(id)
+	po.Nomor = nomor
+	po.Status = models.StatusPODraft
+	po.CreatedAt = now
+	po.UpdatedAt = now
+	return nil
+}
+
+// UpdateDraft replaces the header fields and lines of a purchase order that is still a draft
+func (r *PurchaseOrderRepository) UpdateDraft(po *models.PurchaseOrder) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := database.TranslateQuery(`
+		UPDATE purchase_order
+		SET supplier_id = ?, tanggal_diharapkan = ?, catatan = ?, total_estimasi = ?
+		WHERE id = ? AND status = ?
+	`)
+	result, err := tx.Exec(query, po.SupplierID, timePtrValue(po.TanggalDiharapkan), po.Catatan, po.TotalEstimasi,
+		po.ID, models.StatusPODraft)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("hanya purchase order berstatus draft yang dapat diubah")
+	}
+
+	if _, err := tx.Exec(database.TranslateQuery(`DELETE FROM purchase_order_item WHERE po_id = ?`), po.ID); err != nil {
+		return fmt.Errorf("failed to delete purchase order items: %w", err)
+	}
+	if err := insertPurchaseOrderItems(tx, po.ID, po.Items); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purchase order: %w", err)
+	}
+
+	return nil
+}
+
+// Kirim marks a draft purchase order as sent to the supplier
+func (r *PurchaseOrderRepository) Kirim(id int) error {
+	query := `UPDATE purchase_order SET status = ?, dikirim_at = ? WHERE id = ? AND status = ?`
+
+	result, err := database.Exec(query, models.StatusPODikirim, time.Now(), id, models.StatusPODraft)
+	if err != nil {
+		return fmt.Errorf("failed to send purchase order: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("hanya purchase order berstatus draft yang dapat dikirim")
+	}
+
+	return nil
+}
+
+// Tutup closes a purchase order that is not yet fully received; goods still
+// outstanding are no longer expected
+func (r *PurchaseOrderRepository) Tutup(id int) error {
+	query := `UPDATE purchase_order SET status = ?, ditutup_at = ? WHERE id = ? AND status IN (?, ?, ?)`
+
+	result, err := database.Exec(query, models.StatusPODitutup, time.Now(), id,
+		models.StatusPODraft, models.StatusPODikirim, models.StatusPODiterimaSebagian)
+	if err != nil {
+		return fmt.Errorf("failed to close purchase order: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("purchase order sudah diterima lengkap atau sudah ditutup")
+	}
+
+	return nil
+}
+
+// DeleteDraft removes a purchase order that is still a draft
+func (r *PurchaseOrderRepository) DeleteDraft(id int) error {
+	result, err := database.Exec(`DELETE FROM purchase_order WHERE id = ? AND status = ?`, id, models.StatusPODraft)
+	if err != nil {
+		return fmt.Errorf("failed to delete purchase order: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("hanya purchase order berstatus draft yang dapat dihapus")
+	}
+
+	return nil
+}
+
+// Terima records a goods receipt: the received quantity and actual cost of each
+// line, the stock increase with a "pembelian" stock history entry, the batch of
+// lines with a shelf life, the product's moving-average cost, and the new status
+// of the purchase order, all in one database transaction
+func (r *PurchaseOrderRepository) Terima(po *models.PurchaseOrder, penerimaan *models.PenerimaanBarang) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	nomor, err := generateNomorDokumen(tx, "penerimaan_barang", "PNB", now)
+	if err != nil {
+		return err
+	}
+
+	headerQuery := database.TranslateQuery(`
+		INSERT INTO penerimaan_barang (nomor, po_id, penerima, catatan, created_at)
+		VALUES (?, ?, ?, ?, ?) RETURNING id
+	`)
+	var penerimaanID int64
+	if err := tx.QueryRow(headerQuery, nomor, po.ID, penerimaan.Penerima, penerimaan.Catatan, now).Scan(&penerimaanID); err != nil {
+		return fmt.Errorf("failed to create goods receipt: %w", err)
+	}
+
+	itemQuery := database.TranslateQuery(`
+		INSERT INTO penerimaan_barang_item (penerimaan_id, po_item_id, produk_id, qty, harga_beli, masa_simpan_hari, batch_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`)
+	poItemQuery := database.TranslateQuery(`
+		UPDATE purchase_order_item SET qty_diterima = qty_diterima + ?, nilai_diterima = nilai_diterima + ?
+		WHERE id = ? AND po_id = ?
+	`)
+	stokQuery := database.TranslateQuery(`SELECT stok, harga_beli FROM produk WHERE id = ?`)
+	updateStokQuery := database.TranslateQuery(`UPDATE produk SET stok = stok + ?, harga_beli = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	historyQuery := database.TranslateQuery(`
+		INSERT INTO stok_history (produk_id, stok_sebelum, stok_sesudah, perubahan, jenis_perubahan, keterangan, harga_beli, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	keterangan := fmt.Sprintf("Penerimaan %s dari %s (%s)", nomor, po.NamaSupplier, po.Nomor)
+
+	for _, item := range penerimaan.Items {
+		// HPP produk dihitung ulang sebagai rata-rata bergerak dengan harga beli aktual
+		var stokSebelum float64
+		var hpp int
+		if err := tx.QueryRow(stokQuery, item.ProdukID).Scan(&stokSebelum, &hpp); err != nil {
+			return fmt.Errorf("failed to get stock of product %d: %w", item.ProdukID, err)
+		}
+
+		var batchID sql.NullString
+		if item.MasaSimpanHari > 0 {
+			batch := &models.Batch{
+				ProdukID:       item.ProdukID,
+				Qty:            item.Qty,
+				MasaSimpanHari: item.MasaSimpanHari,
+				Supplier:       po.NamaSupplier,
+				SupplierID:     po.SupplierID,
+				Keterangan:     fmt.Sprintf("Penerimaan %s (%s)", nomor, po.Nomor),
+				HargaBeli:      item.HargaBeli,
+			}
+			if batch.HargaBeli <= 0 {
+				batch.HargaBeli = hpp
+			}
+			if err := tambahBatch(tx, batch, now); err != nil {
+				return fmt.Errorf("failed to create batch for %s: %w", item.NamaProduk, err)
+			}
+			item.BatchID = batch.ID
+			batchID = sql.NullString{String: batch.ID, Valid: true}
+		}
+
+		var itemID int64
+		if err := tx.QueryRow(itemQuery, penerimaanID, item.POItemID, item.ProdukID, item.Qty, item.HargaBeli,
+			item.MasaSimpanHari, batchID).Scan(&itemID); err != nil {
+			return fmt.Errorf("failed to create goods receipt item: %w", err)
+		}
+		item.ID = int(itemID)
+		item.PenerimaanID = int(penerimaanID)
+
+		nilai := int(item.Qty * float64(item.HargaBeli))
+		if _, err := tx.Exec(poItemQuery, item.Qty, nilai, item.POItemID, po.ID); err != nil {
+			return fmt.Errorf("failed to update purchase order item: %w", err)
+		}
+
+		hpp = hitungHPPRataRata(stokSebelum, hpp, item.Qty, item.HargaBeli)
+		if _, err := tx.Exec(updateStokQuery, item.Qty, hpp, item.ProdukID); err != nil {
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+		if _, err := tx.Exec(historyQuery, item.ProdukID, stokSebelum, stokSebelum+item.Qty, item.Qty,
+			models.JenisPerubahanPembelian, keterangan, item.HargaBeli, now); err != nil {
+			return fmt.Errorf("failed to create stock history: %w", err)
+		}
+	}
+
+	// Lengkap jika semua baris sudah diterima minimal sebanyak yang dipesan
+	var kurang int
+	kurangQuery := database.TranslateQuery(`SELECT COUNT(*) FROM purchase_order_item WHERE po_id = ? AND qty_diterima < qty_pesan`)
+	if err := tx.QueryRow(kurangQuery, po.ID).Scan(&kurang); err != nil {
+		return fmt.Errorf("failed to check received quantity: %w", err)
+	}
+	status := models.StatusPODiterima
+	if kurang > 0 {
+		status = models.StatusPODiterimaSebagian
+	}
+
+	statusQuery := database.TranslateQuery(`UPDATE purchase_order SET status = ? WHERE id = ? AND status IN (?, ?)`)
+	result, err := tx.Exec(statusQuery, status, po.ID, models.StatusPODikirim, models.StatusPODiterimaSebagian)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order status: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("purchase order %s tidak lagi menunggu penerimaan barang", po.Nomor)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit goods receipt: %w", err)
+	}
+
+	penerimaan.ID = int(penerimaanID)
+	penerimaan.Nomor = nomor
+	penerimaan.POID = po.ID
+	penerimaan.CreatedAt = now
+	po.Status = status
+	return nil
+}
+
+// GetQtyDalamPesanan returns per product the quantity ordered on sent purchase
+// orders that has not been received yet
+func (r *PurchaseOrderRepository) GetQtyDalamPesanan() (map[int]float64, error) {
+	query := `
+		SELECT i.produk_id, SUM(i.qty_pesan - i.qty_diterima)
+		FROM purchase_order_item i
+		JOIN purchase_order po ON po.id = i.po_id
+		WHERE po.status IN (?, ?) AND i.qty_diterima < i.qty_pesan
+		GROUP BY i.produk_id
+	`
+
+	rows, err := database.Query(query, models.StatusPODikirim, models.StatusPODiterimaSebagian)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quantity on order: %w", err)
+	}
+	defer rows.Close()
+
+	dipesan := make(map[int]float64)
+	for rows.Next() {
+		var produkID int
+		var qty float64
+		if err := rows.Scan(&produkID, &qty); err != nil {
+			return nil, fmt.Errorf("failed to scan quantity on order: %w", err)
+		}
+		dipesan[produkID] = qty
+	}
+
+	return dipesan, rows.Err()
+}
+
+// GetSupplierTerakhir returns per product the supplier it was last ordered or
+// received from, used for products without a main supplier
+func (r *PurchaseOrderRepository) GetSupplierTerakhir() (map[int]int, error) {
+	query := `
+		SELECT produk_id, supplier_id FROM (
+			SELECT i.produk_id, po.supplier_id, po.created_at AS waktu
+			FROM purchase_order_item i
+			JOIN purchase_order po ON po.id = i.po_id
+			WHERE po.status != ?
+			UNION ALL
+			SELECT produk_id, supplier_id, created_at AS waktu
+			FROM batch
+			WHERE supplier_id IS NOT NULL
+		) riwayat
+		ORDER BY waktu ASC
+	`
+
+	rows, err := database.Query(query, models.StatusPODraft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last supplier per product: %w", err)
+	}
+	defer rows.Close()
+
+	supplier := make(map[int]int)
+	for rows.Next() {
+		var produkID, supplierID int
+		if err := rows.Scan(&produkID, &supplierID); err != nil {
+			return nil, fmt.Errorf("failed to scan last supplier: %w", err)
+		}
+		// Urut dari yang terlama, sehingga supplier terakhir menimpa yang sebelumnya
+		supplier[produkID] = supplierID
+	}
+
+	return supplier, rows.Err()
+}
+
+func (r *PurchaseOrderRepository) getItems(poID int) ([]*models.PurchaseOrderItem, error) {
+	query := `
+		SELECT i.id, i.po_id, i.produk_id, COALESCE(p.nama, ''), i.qty_pesan, i.harga_estimasi,
+		       i.qty_diterima, i.nilai_diterima
+		FROM purchase_order_item i
+		LEFT JOIN produk p ON p.id = i.produk_id
+		WHERE i.po_id = ?
+		ORDER BY i.id ASC
+	`
+
+	rows, err := database.Query(query, poID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purchase order items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*models.PurchaseOrderItem{}
+	for rows.Next() {
+		var item models.PurchaseOrderItem
+		if err := rows.Scan(&item.ID, &item.POID, &item.ProdukID, &item.NamaProduk, &item.QtyPesan,
+			&item.HargaEstimasi, &item.QtyDiterima, &item.NilaiDiterima); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase order item: %w", err)
+		}
+		item.Subtotal = int(item.QtyPesan * float64(item.HargaEstimasi))
+		item.SelisihQty = item.QtyDiterima - item.QtyPesan
+		item.SelisihNilai = item.NilaiDiterima - int(item.QtyDiterima*float64(item.HargaEstimasi))
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate purchase order items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *PurchaseOrderRepository) getPenerimaan(poID int) ([]*models.PenerimaanBarang, error) {
+	query := `
+		SELECT id, nomor, po_id, COALESCE(penerima, ''), COALESCE(catatan, ''), created_at
+		FROM penerimaan_barang
+		WHERE po_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := database.Query(query, poID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goods receipts: %w", err)
+	}
+
+	list := []*models.PenerimaanBarang{}
+	byID := make(map[int]*models.PenerimaanBarang)
+	for rows.Next() {
+		var p models.PenerimaanBarang
+		if err := rows.Scan(&p.ID, &p.Nomor, &p.POID, &p.Penerima, &p.Catatan, &p.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan goods receipt: %w", err)
+		}
+		p.Items = []*models.PenerimaanBarangItem{}
+		list = append(list, &p)
+		byID[p.ID] = &p
+	}
+	rows.Close()
+
+	itemQuery := `
+		SELECT i.id, i.penerimaan_id, i.po_item_id, i.produk_id, COALESCE(p.nama, ''), i.qty, i.harga_beli,
+		       COALESCE(i.masa_simpan_hari, 0), COALESCE(i.batch_id, '')
+		FROM penerimaan_barang_item i
+		JOIN penerimaan_barang pb ON pb.id = i.penerimaan_id
+		LEFT JOIN produk p ON p.id = i.produk_id
+		WHERE pb.po_id = ?
+		ORDER BY i.id ASC
+	`
+	rows, err = database.Query(itemQuery, poID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goods receipt items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.PenerimaanBarangItem
+		if err := rows.Scan(&item.ID, &item.PenerimaanID, &item.POItemID, &item.ProdukID, &item.NamaProduk,
+			&item.Qty, &item.HargaBeli, &item.MasaSimpanHari, &item.BatchID); err != nil {
+			return nil, fmt.Errorf("failed to scan goods receipt item: %w", err)
+		}
+		if p, ok := byID[item.PenerimaanID]; ok {
+			p.Items = append(p.Items, &item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate goods receipt items: %w", err)
+	}
+
+	return list, nil
+}
+
+func insertPurchaseOrderItems(tx *sql.Tx, poID int, items []*models.PurchaseOrderItem) error {
+	query := database.TranslateQuery(`
+		INSERT INTO purchase_order_item (po_id, produk_id, qty_pesan, harga_estimasi, qty_diterima, nilai_diterima)
+		VALUES (?, ?, ?, ?, 0, 0) RETURNING id
+	`)
+
+	for _, item := range items {
+		var id int64
+		if err := tx.QueryRow(query, poID, item.ProdukID, item.QtyPesan, item.HargaEstimasi).Scan(&id); err != nil {
+			return fmt.Errorf("failed to create purchase order item: %w", err)
+		}
+		item.ID = int(id)
+		item.POID = poID
+	}
+
+	return nil
+}
+
+// generateNomorDokumen generates the next document number of the day, e.g.
+// PO-20261018-0001. The number column is unique, so two documents created at
+// the same moment cannot both be saved with the same number.
+func generateNomorDokumen(tx *sql.Tx, table, prefix string, waktu time.Time) (string, error) {
+	awalan := fmt.Sprintf("%s-%s-", prefix, waktu.Format("20060102"))
+
+	var jumlah int
+	query := database.TranslateQuery(`SELECT COUNT(*) FROM ` + table + ` WHERE nomor LIKE ?`)
+	if err := tx.QueryRow(query, awalan+"%").Scan(&jumlah); err != nil {
+		return "", fmt.Errorf("failed to generate document number: %w", err)
+	}
+
+	return fmt.Sprintf("%s%04d", awalan, jumlah+1), nil
+}
+
+// timePtrValue converts *time.Time to a nullable query argument
+func timePtrValue(v *time.Time) sql.NullTime {
+	if v == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *v, Valid: true}
+}
+
+func scanPurchaseOrder(row rowScanner) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	var tanggalDiharapkan, dikirimAt, ditutupAt sql.NullTime
+
+	err := row.Scan(
+		&po.ID,
+		&po.Nomor,
+		&po.SupplierID,
+		&po.NamaSupplier,
+		&po.Status,
+		&tanggalDiharapkan,
+		&po.Catatan,
+		&po.TotalEstimasi,
+		&po.TotalDiterima,
+		&po.DibuatOleh,
+		&dikirimAt,
+		&ditutupAt,
+		&po.CreatedAt,
+		&po.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan purchase order: %w", err)
+	}
+
+	if tanggalDiharapkan.Valid {
+		po.TanggalDiharapkan = &tanggalDiharapkan.Time
+	}
+	if dikirimAt.Valid {
+		po.DikirimAt = &dikirimAt.Time
+	}
+	if ditutupAt.Valid {
+		po.DitutupAt = &ditutupAt.Time
+	}
+
+	return &po, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"math"
 	"ritel-app/internal/database"
 	"ritel-app/internal/models"
 	"time"
@@ -16,16 +17,30 @@ func NewReturnRepository() *ReturnRepository {
 	return &ReturnRepository{}
 }
 
-// Create creates a new return transaction
-func (r *ReturnRepository) Create(returnData *models.Return) error {
-	query := `
+// Create creates a return with every change it makes in one database transaction:
+// the return and its items, the stock and batches of the returned goods (or of the
+// components of returned kits), the replacement product of an exchange, the status
+// of the sale, the customer's points, the part of the refund that offsets the sale's
+// kasbon, the store credit issued for the paid part and the request's idempotency
+// key. A failure leaves nothing behind.
+func (r *ReturnRepository) Create(baru *models.ReturnBaru) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	returnData := baru.Return
+	now := time.Now()
+
+	query := database.TranslateQuery(`
 		INSERT INTO returns (
 			transaksi_id, no_transaksi, return_date, reason, type,
 			replacement_product_id, refund_amount, refund_method, refund_status, notes,
-			created_at, updated_at
+			shift_id, potong_piutang, created_at, updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
-	`
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
+	`)
 
 	var replacementProductID interface{}
 	if returnData.ReplacementProductID > 0 {
@@ -34,8 +49,13 @@ func (r *ReturnRepository) Create(returnData *models.Return) error {
 		replacementProductID = nil
 	}
 
+	var shiftID interface{}
+	if returnData.ShiftID > 0 {
+		shiftID = returnData.ShiftID
+	}
+
 	var id int64
-	err := database.QueryRow(query,
+	err = tx.QueryRow(query,
 		returnData.TransaksiID,
 		returnData.NoTransaksi,
 		returnData.ReturnDate,
@@ -46,47 +66,189 @@ func (r *ReturnRepository) Create(returnData *models.Return) error {
 		returnData.RefundMethod,
 		returnData.RefundStatus,
 		returnData.Notes,
+		shiftID,
+		returnData.PotongPiutang,
 	).Scan(&id)
 	if err != nil {
 		return fmt.Errorf("failed to create return: %w", err)
 	}
+	returnID := int(id)
+	returnData.ID = returnID
+
+	itemQuery := database.TranslateQuery(`
+		INSERT INTO return_items (return_id, product_id, transaksi_item_id, quantity, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP) RETURNING id
+	`)
+	for _, b := range baru.Items {
+		item := b.Item
+		item.ReturnID = returnID
+
+		var itemID int64
+		if err := tx.QueryRow(itemQuery, returnID, item.ProductID, item.TransaksiItemID, item.Quantity).Scan(&itemID); err != nil {
+			return fmt.Errorf("failed to create return item: %w", err)
+		}
+		item.ID = int(itemID)
+
+		if err := kembalikanStokItemReturn(tx, returnData, b, baru.KembalikanStok, now); err != nil {
+			return err
+		}
+	}
+
+	// The replacement of an exchange leaves stock like a sale, without a sale line
+	if returnData.Type == "exchange" && returnData.ReplacementProductID > 0 {
+		keterangan := fmt.Sprintf("Tukar barang untuk return %s", returnData.NoTransaksi)
+		if err := kurangiStokPengganti(tx, returnData.ReplacementProductID, baru.QtyPengganti, keterangan, now); err != nil {
+			return err
+		}
+	}
+
+	statusQuery := database.TranslateQuery(`UPDATE transaksi SET status = ? WHERE id = ?`)
+	if _, err := tx.Exec(statusQuery, baru.StatusTransaksi, returnData.TransaksiID); err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+
+	if baru.PelangganID > 0 {
+		if err := kurangiPoinRetur(tx, baru.PelangganID, baru.PoinDikurangi, returnData.RefundAmount, now); err != nil {
+			return err
+		}
+	}
+
+	if returnData.PotongPiutang > 0 {
+		if err := kurangiPiutangRetur(tx, returnData.TransaksiID, returnData.NoTransaksi, returnData.PotongPiutang, now); err != nil {
+			return err
+		}
+	}
+
+	if baru.KartuHadiah != nil {
+		baru.KartuHadiah.ReturnID = &returnID
+		if err := insertKartuHadiah(tx, baru.KartuHadiah, "Refund retur transaksi "+returnData.NoTransaksi, now); err != nil {
+			return err
+		}
+	}
+
+	// Idempotency key is committed together with the return, so a retry either
+	// finds the return or finds nothing
+	if baru.IdempotencyKey != "" {
+		if err := simpanIdempotencyKey(tx, "return", baru.IdempotencyKey, baru.IdempotencyHash, id, now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit return: %w", err)
+	}
 
-	returnData.ID = int(id)
 	return nil
 }
 
-// CreateReturnItem creates a return item
-func (r *ReturnRepository) CreateReturnItem(item *models.ReturnItem) error {
-	query := `
-		INSERT INTO return_items (return_id, product_id, quantity, created_at)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP) RETURNING id
-	`
+// kembalikanStokItemReturn puts the stock of a returned line back: into the batches
+// the sale took it from, or into those of the kit components deducted at sale time.
+// Damaged goods only get a stock history entry, their stock does not come back.
+func kembalikanStokItemReturn(tx *sql.Tx, returnData *models.Return, b *models.ReturnBaruItem, kembalikanStok bool, now time.Time) error {
+	produkID := b.Item.ProductID
+	if !kembalikanStok {
+		var stok float64
+		stokQuery := database.TranslateQuery(`SELECT stok FROM produk WHERE id = ?`)
+		err := tx.QueryRow(stokQuery, produkID).Scan(&stok)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get product stock: %w", err)
+		}
 
-	var id int64
-	err := database.QueryRow(query,
-		item.ReturnID,
-		item.ProductID,
-		item.Quantity,
-	).Scan(&id)
+		historyQuery := database.TranslateQuery(`
+			INSERT INTO stok_history (produk_id, stok_sebelum, stok_sesudah, perubahan, jenis_perubahan, keterangan, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`)
+		keterangan := fmt.Sprintf("Return barang rusak dari transaksi %s (stok tidak dikembalikan)", returnData.NoTransaksi)
+		if _, err := tx.Exec(historyQuery, produkID, stok, stok, 0, "return_damaged", keterangan, now); err != nil {
+			return fmt.Errorf("failed to create stock history: %w", err)
+		}
+		return nil
+	}
+
+	// A kit holds no stock; the components deducted at sale time go back instead
+	if len(b.Komponen) > 0 {
+		keterangan := fmt.Sprintf("Return paket dari transaksi %s - alasan: %s", returnData.NoTransaksi, returnData.Reason)
+		for _, k := range b.Komponen {
+			if _, err := kembalikanStokReturn(tx, returnData.ID, returnData.TransaksiID, k.KomponenID, b.QtyDasar*k.Qty, keterangan, now); err != nil {
+				return fmt.Errorf("failed to restore stock of component %s: %w", k.KomponenNama, err)
+			}
+		}
+		return nil
+	}
+
+	keterangan := fmt.Sprintf("Return dari transaksi %s - alasan: %s", returnData.NoTransaksi, returnData.Reason)
+	if _, err := kembalikanStokReturn(tx, returnData.ID, returnData.TransaksiID, produkID, b.QtyDasar, keterangan, now); err != nil {
+		return fmt.Errorf("failed to restore stock: %w", err)
+	}
+	return nil
+}
+
+// kurangiStokPengganti takes the replacement product of an exchange out of stock and
+// out of its batches in the order of its batch policy, checked like a sale
+func kurangiStokPengganti(tx *sql.Tx, produkID int, qty float64, keterangan string, now time.Time) error {
+	k := &stokKeluar{produkID: produkID, qty: qty}
+	produkQuery := database.TranslateQuery(`SELECT nama, stok FROM produk WHERE id = ? AND deleted_at IS NULL`)
+	err := tx.QueryRow(produkQuery, produkID).Scan(&k.nama, &k.stok)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("replacement product not found")
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create return item: %w", err)
+		return fmt.Errorf("failed to get replacement product: %w", err)
+	}
+
+	if err := cekStokKeluar(tx, k, now); err != nil {
+		return err
+	}
+	if err := kurangiStokKeluar(tx, "", 0, 0, k, now); err != nil {
+		return err
+	}
+
+	historyQuery := database.TranslateQuery(`
+		INSERT INTO stok_history (produk_id, stok_sebelum, stok_sesudah, perubahan, jenis_perubahan, keterangan, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if _, err := tx.Exec(historyQuery, produkID, k.stok, k.stok-qty, -qty, "exchange", keterangan, now); err != nil {
+		return fmt.Errorf("failed to create stock history: %w", err)
 	}
 
-	item.ID = int(id)
 	return nil
 }
 
-// GetReturnedQuantity gets the total quantity already returned for a product in a transaction
-func (r *ReturnRepository) GetReturnedQuantity(transaksiID int, productID int) (int, error) {
+// kurangiPoinRetur takes the points and spending of a refunded return back from the
+// customer; neither goes below zero
+func kurangiPoinRetur(tx *sql.Tx, pelangganID, poin, refund int, now time.Time) error {
+	query := database.TranslateQuery(`
+		UPDATE pelanggan
+		SET poin = CASE WHEN poin < ? THEN 0 ELSE poin - ? END,
+			total_belanja = CASE WHEN total_belanja < ? THEN 0 ELSE total_belanja - ? END,
+			updated_at = ?
+		WHERE id = ?
+	`)
+
+	if _, err := tx.Exec(query, poin, poin, refund, refund, now, pelangganID); err != nil {
+		return fmt.Errorf("failed to update customer points: %w", err)
+	}
+
+	return nil
+}
+
+// GetReturnedQuantity gets the total quantity already returned from a line of a
+// transaction. Returns recorded before lines were tracked count against every
+// line of their product.
+func (r *ReturnRepository) GetReturnedQuantity(transaksiID, transaksiItemID, productID int) (int, error) {
 	query := `
 		SELECT COALESCE(SUM(ri.quantity), 0)
 		FROM return_items ri
 		INNER JOIN returns r ON ri.return_id = r.id
 		WHERE r.transaksi_id = ? AND ri.product_id = ?
+		AND (ri.transaksi_item_id = ? OR ri.transaksi_item_id IS NULL)
 	`
 
 	var totalReturned int
-	err := database.QueryRow(query, transaksiID, productID).Scan(&totalReturned)
+	err := database.QueryRow(query, transaksiID, productID, transaksiItemID).Scan(&totalReturned)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get returned quantity: %w", err)
 	}
@@ -94,16 +256,6 @@ func (r *ReturnRepository) GetReturnedQuantity(transaksiID int, productID int) (
 	return totalReturned, nil
 }
 
-// UpdateTransactionStatus updates the status of a transaction
-func (r *ReturnRepository) UpdateTransactionStatus(transaksiID int, status string) error {
-	query := `UPDATE transaksi SET status = ? WHERE id = ?`
-	_, err := database.Exec(query, status, transaksiID)
-	if err != nil {
-		return fmt.Errorf("failed to update transaction status: %w", err)
-	}
-	return nil
-}
-
 // UpdateRefundStatus updates the refund status of a return
 func (r *ReturnRepository) UpdateRefundStatus(returnID int, status string) error {
 	query := `UPDATE returns SET refund_status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
@@ -117,7 +269,7 @@ func (r *ReturnRepository) UpdateRefundStatus(returnID int, status string) error
 // GetAllReturnedItemsByTransaksi gets all returned items for a transaction (for status calculation)
 func (r *ReturnRepository) GetAllReturnedItemsByTransaksi(transaksiID int) ([]*models.ReturnItem, error) {
 	query := `
-		SELECT ri.id, ri.return_id, ri.product_id, ri.quantity, ri.created_at
+		SELECT ri.id, ri.return_id, ri.product_id, COALESCE(ri.transaksi_item_id, 0), ri.quantity, ri.created_at
 		FROM return_items ri
 		INNER JOIN returns r ON ri.return_id = r.id
 		WHERE r.transaksi_id = ?
@@ -138,6 +290,7 @@ func (r *ReturnRepository) GetAllReturnedItemsByTransaksi(transaksiID int) ([]*m
 			&item.ID,
 			&item.ReturnID,
 			&item.ProductID,
+			&item.TransaksiItemID,
 			&item.Quantity,
 			&createdAtStr,
 		)
@@ -163,7 +316,7 @@ func (r *ReturnRepository) GetAll() ([]*models.Return, error) {
 			COALESCE(replacement_product_id, 0),
 			COALESCE(refund_amount, 0), COALESCE(refund_method, ''),
 			COALESCE(refund_status, 'pending'), COALESCE(notes, ''),
-			created_at, updated_at
+			COALESCE(shift_id, 0), COALESCE(potong_piutang, 0), created_at, updated_at
 		FROM returns
 		ORDER BY return_date DESC
 	`
@@ -192,6 +345,8 @@ func (r *ReturnRepository) GetAll() ([]*models.Return, error) {
 			&ret.RefundMethod,
 			&ret.RefundStatus,
 			&ret.Notes,
+			&ret.ShiftID,
+			&ret.PotongPiutang,
 			&createdAtStr,
 			&updatedAtStr,
 		)
@@ -224,7 +379,7 @@ func (r *ReturnRepository) GetByID(id int) (*models.Return, error) {
 			COALESCE(replacement_product_id, 0),
 			COALESCE(refund_amount, 0), COALESCE(refund_method, ''),
 			COALESCE(refund_status, 'pending'), COALESCE(notes, ''),
-			created_at, updated_at
+			COALESCE(shift_id, 0), COALESCE(potong_piutang, 0), created_at, updated_at
 		FROM returns
 		WHERE id = ?
 	`
@@ -245,6 +400,8 @@ func (r *ReturnRepository) GetByID(id int) (*models.Return, error) {
 		&ret.RefundMethod,
 		&ret.RefundStatus,
 		&ret.Notes,
+		&ret.ShiftID,
+		&ret.PotongPiutang,
 		&createdAtStr,
 		&updatedAtStr,
 	)
@@ -307,6 +464,65 @@ func (r *ReturnRepository) GetReturnItemsByReturnID(returnID int) ([]*models.Ret
 	return products, nil
 }
 
+// GetPajakReturByDateRange sums per tax code the DPP and PPN of the sale lines
+// refunded in a date range. Each line contributes its stored DPP and PPN in
+// proportion to the quantity returned; returns from before the sale line was
+// recorded are matched to the first line of the product in the sale. Exchanges
+// and cancelled refunds do not give back tax.
+func (r *ReturnRepository) GetPajakReturByDateRange(startDate, endDate time.Time) ([]*models.PajakRetur, error) {
+	query := `
+		SELECT ti.kode_pajak, COALESCE(ti.tarif_pajak, 0), COALESCE(ti.dpp, 0), COALESCE(ti.pajak, 0),
+			ti.jumlah, ri.quantity
+		FROM return_items ri
+		JOIN returns r ON r.id = ri.return_id
+		JOIN transaksi_item ti ON ti.id = CASE
+			WHEN COALESCE(ri.transaksi_item_id, 0) > 0 THEN ri.transaksi_item_id
+			ELSE (SELECT MIN(x.id) FROM transaksi_item x WHERE x.transaksi_id = r.transaksi_id AND x.produk_id = ri.product_id)
+		END
+		WHERE r.type = 'refund' AND COALESCE(r.refund_status, '') != 'cancelled'
+			AND r.return_date >= ? AND r.return_date <= ?
+			AND COALESCE(ti.kode_pajak, '') != ''
+	`
+
+	rows, err := database.Query(query, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get returned tax: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.PajakRetur, 0)
+	perKode := make(map[string]*models.PajakRetur)
+	for rows.Next() {
+		var kodePajak string
+		var tarifPajak float64
+		var dpp, pajak, jumlah, qty int
+		if err := rows.Scan(&kodePajak, &tarifPajak, &dpp, &pajak, &jumlah, &qty); err != nil {
+			return nil, fmt.Errorf("failed to scan returned tax: %w", err)
+		}
+
+		// Bagian DPP dan PPN baris sesuai qty yang dikembalikan
+		porsi := 1.0
+		if jumlah > 0 && qty < jumlah {
+			porsi = float64(qty) / float64(jumlah)
+		}
+
+		key := fmt.Sprintf("%s|%g", kodePajak, tarifPajak)
+		retur, ok := perKode[key]
+		if !ok {
+			retur = &models.PajakRetur{KodePajak: kodePajak, TarifPajak: tarifPajak}
+			perKode[key] = retur
+			result = append(result, retur)
+		}
+		retur.DPP += int(math.Round(float64(dpp) * porsi))
+		retur.Pajak += int(math.Round(float64(pajak) * porsi))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate returned tax: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetTotalRefundByDateRange calculates total refund amount in a date range
 func (r *ReturnRepository) GetTotalRefundByDateRange(startDate, endDate time.Time) (int, error) {
 	query := `
@@ -332,7 +548,7 @@ func (r *ReturnRepository) GetReturnsByDateRange(startDate, endDate time.Time) (
 			COALESCE(replacement_product_id, 0),
 			COALESCE(refund_amount, 0), COALESCE(refund_method, ''),
 			COALESCE(refund_status, 'pending'), COALESCE(notes, ''),
-			created_at, updated_at
+			COALESCE(shift_id, 0), COALESCE(potong_piutang, 0), created_at, updated_at
 		FROM returns
 		WHERE return_date >= ? AND return_date <= ?
 		ORDER BY return_date DESC
@@ -362,6 +578,8 @@ func (r *ReturnRepository) GetReturnsByDateRange(startDate, endDate time.Time) (
 			&ret.RefundMethod,
 			&ret.RefundStatus,
 			&ret.Notes,
+			&ret.ShiftID,
+			&ret.PotongPiutang,
 			&createdAtStr,
 			&updatedAtStr,
 		)
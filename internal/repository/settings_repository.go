@@ -133,3 +133,589 @@ func (r *SettingsRepository) createDefaultSettings() (*models.PoinSettings, erro
 
 	return defaultSettings, nil
 }
+
+// GetNomorTransaksiSettings retrieves the transaction numbering scheme
+func (r *SettingsRepository) GetNomorTransaksiSettings() (*models.NomorTransaksiSettings, error) {
+	query := `
+		SELECT id, prefix, kode_terminal, format_tanggal, panjang_counter, reset_counter
+		FROM nomor_transaksi_settings
+		WHERE id = 1
+	`
+
+	var settings models.NomorTransaksiSettings
+	err := database.QueryRow(query).Scan(
+		&settings.ID,
+		&settings.Prefix,
+		&settings.KodeTerminal,
+		&settings.FormatTanggal,
+		&settings.PanjangCounter,
+		&settings.ResetCounter,
+	)
+
+	if err == sql.ErrNoRows {
+		// Return default settings if not found
+		return r.createDefaultNomorTransaksiSettings()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nomor transaksi settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// UpdateNomorTransaksiSettings updates the transaction numbering scheme
+func (r *SettingsRepository) UpdateNomorTransaksiSettings(settings *models.NomorTransaksiSettings) error {
+	query := `
+		UPDATE nomor_transaksi_settings
+		SET
+			prefix = ?,
+			kode_terminal = ?,
+			format_tanggal = ?,
+			panjang_counter = ?,
+			reset_counter = ?
+		WHERE id = 1
+	`
+
+	result, err := database.Exec(query,
+		settings.Prefix,
+		settings.KodeTerminal,
+		settings.FormatTanggal,
+		settings.PanjangCounter,
+		settings.ResetCounter,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update nomor transaksi settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// If no rows were updated, create default settings
+		_, err := r.createDefaultNomorTransaksiSettings()
+		if err != nil {
+			return fmt.Errorf("failed to create default nomor transaksi settings: %w", err)
+		}
+		// Try update again
+		return r.UpdateNomorTransaksiSettings(settings)
+	}
+
+	return nil
+}
+
+// createDefaultNomorTransaksiSettings creates the default numbering scheme (TRX-T01-YYYYMMDD-0001)
+func (r *SettingsRepository) createDefaultNomorTransaksiSettings() (*models.NomorTransaksiSettings, error) {
+	defaultSettings := &models.NomorTransaksiSettings{
+		ID:             1,
+		Prefix:         "TRX",
+		KodeTerminal:   "T01",
+		FormatTanggal:  models.FormatTanggalYYYYMMDD,
+		PanjangCounter: 4,
+		ResetCounter:   models.ResetCounterHarian,
+	}
+
+	query := `
+		INSERT INTO nomor_transaksi_settings (
+			id, prefix, kode_terminal, format_tanggal, panjang_counter, reset_counter
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := database.Exec(query,
+		defaultSettings.ID,
+		defaultSettings.Prefix,
+		defaultSettings.KodeTerminal,
+		defaultSettings.FormatTanggal,
+		defaultSettings.PanjangCounter,
+		defaultSettings.ResetCounter,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default nomor transaksi settings: %w", err)
+	}
+
+	return defaultSettings, nil
+}
+
+// GetPajakSettings retrieves the PPN configuration
+func (r *SettingsRepository) GetPajakSettings() (*models.PajakSettings, error) {
+	query := `
+		SELECT id, aktif, mode_harga, kode_pajak_default, npwp
+		FROM pajak_settings
+		WHERE id = 1
+	`
+
+	var settings models.PajakSettings
+	var aktif int
+	var npwp sql.NullString
+	err := database.QueryRow(query).Scan(
+		&settings.ID,
+		&aktif,
+		&settings.ModeHarga,
+		&settings.KodePajakDefault,
+		&npwp,
+	)
+
+	if err == sql.ErrNoRows {
+		// Return default settings if not found
+		return r.createDefaultPajakSettings()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pajak settings: %w", err)
+	}
+
+	settings.Aktif = aktif == 1
+	if npwp.Valid {
+		settings.NPWP = npwp.String
+	}
+
+	return &settings, nil
+}
+
+// UpdatePajakSettings updates the PPN configuration
+func (r *SettingsRepository) UpdatePajakSettings(settings *models.PajakSettings) error {
+	query := `
+		UPDATE pajak_settings
+		SET
+			aktif = ?,
+			mode_harga = ?,
+			kode_pajak_default = ?,
+			npwp = ?
+		WHERE id = 1
+	`
+
+	result, err := database.Exec(query,
+		boolToInt(settings.Aktif),
+		settings.ModeHarga,
+		settings.KodePajakDefault,
+		settings.NPWP,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update pajak settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// If no rows were updated, create default settings
+		_, err := r.createDefaultPajakSettings()
+		if err != nil {
+			return fmt.Errorf("failed to create default pajak settings: %w", err)
+		}
+		// Try update again
+		return r.UpdatePajakSettings(settings)
+	}
+
+	return nil
+}
+
+// createDefaultPajakSettings creates the default PPN configuration (non-PKP, harga inklusif PPN 11%)
+func (r *SettingsRepository) createDefaultPajakSettings() (*models.PajakSettings, error) {
+	defaultSettings := &models.PajakSettings{
+		ID:               1,
+		Aktif:            false,
+		ModeHarga:        models.ModeHargaInklusif,
+		KodePajakDefault: models.KodePajakPPN11,
+	}
+
+	query := `
+		INSERT INTO pajak_settings (
+			id, aktif, mode_harga, kode_pajak_default, npwp
+		) VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := database.Exec(query,
+		defaultSettings.ID,
+		boolToInt(defaultSettings.Aktif),
+		defaultSettings.ModeHarga,
+		defaultSettings.KodePajakDefault,
+		defaultSettings.NPWP,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default pajak settings: %w", err)
+	}
+
+	return defaultSettings, nil
+}
+
+// GetHargaSettings retrieves the limits for manual price overrides and line discounts
+func (r *SettingsRepository) GetHargaSettings() (*models.HargaSettings, error) {
+	query := `
+		SELECT id, batas_override_persen
+		FROM harga_settings
+		WHERE id = 1
+	`
+
+	var settings models.HargaSettings
+	err := database.QueryRow(query).Scan(
+		&settings.ID,
+		&settings.BatasOverridePersen,
+	)
+
+	if err == sql.ErrNoRows {
+		// Return default settings if not found
+		return r.createDefaultHargaSettings()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harga settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// UpdateHargaSettings updates the limits for manual price overrides and line discounts
+func (r *SettingsRepository) UpdateHargaSettings(settings *models.HargaSettings) error {
+	query := `
+		UPDATE harga_settings
+		SET
+			batas_override_persen = ?
+		WHERE id = 1
+	`
+
+	result, err := database.Exec(query, settings.BatasOverridePersen)
+
+	if err != nil {
+		return fmt.Errorf("failed to update harga settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// If no rows were updated, create default settings
+		_, err := r.createDefaultHargaSettings()
+		if err != nil {
+			return fmt.Errorf("failed to create default harga settings: %w", err)
+		}
+		// Try update again
+		return r.UpdateHargaSettings(settings)
+	}
+
+	return nil
+}
+
+// createDefaultHargaSettings creates the default limits (selisih harga maksimal 10% tanpa persetujuan admin)
+func (r *SettingsRepository) createDefaultHargaSettings() (*models.HargaSettings, error) {
+	defaultSettings := &models.HargaSettings{
+		ID:                  1,
+		BatasOverridePersen: 10,
+	}
+
+	query := `
+		INSERT INTO harga_settings (
+			id, batas_override_persen
+		) VALUES (?, ?)
+	`
+
+	_, err := database.Exec(query,
+		defaultSettings.ID,
+		defaultSettings.BatasOverridePersen,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default harga settings: %w", err)
+	}
+
+	return defaultSettings, nil
+}
+
+// GetPembulatanSettings retrieves the cash rounding policy
+func (r *SettingsRepository) GetPembulatanSettings() (*models.PembulatanSettings, error) {
+	query := `
+		SELECT id, aktif, mode, kelipatan, hanya_tunai
+		FROM pembulatan_settings
+		WHERE id = 1
+	`
+
+	var settings models.PembulatanSettings
+	var aktif, hanyaTunai int
+	err := database.QueryRow(query).Scan(
+		&settings.ID,
+		&aktif,
+		&settings.Mode,
+		&settings.Kelipatan,
+		&hanyaTunai,
+	)
+
+	if err == sql.ErrNoRows {
+		// Return default settings if not found
+		return r.createDefaultPembulatanSettings()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pembulatan settings: %w", err)
+	}
+
+	settings.Aktif = aktif == 1
+	settings.HanyaTunai = hanyaTunai == 1
+
+	return &settings, nil
+}
+
+// UpdatePembulatanSettings updates the cash rounding policy
+func (r *SettingsRepository) UpdatePembulatanSettings(settings *models.PembulatanSettings) error {
+	query := `
+		UPDATE pembulatan_settings
+		SET
+			aktif = ?,
+			mode = ?,
+			kelipatan = ?,
+			hanya_tunai = ?
+		WHERE id = 1
+	`
+
+	result, err := database.Exec(query,
+		boolToInt(settings.Aktif),
+		settings.Mode,
+		settings.Kelipatan,
+		boolToInt(settings.HanyaTunai),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update pembulatan settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// If no rows were updated, create default settings
+		_, err := r.createDefaultPembulatanSettings()
+		if err != nil {
+			return fmt.Errorf("failed to create default pembulatan settings: %w", err)
+		}
+		// Try update again
+		return r.UpdatePembulatanSettings(settings)
+	}
+
+	return nil
+}
+
+// createDefaultPembulatanSettings creates the default rounding policy (nonaktif, terdekat Rp 100, hanya tunai)
+func (r *SettingsRepository) createDefaultPembulatanSettings() (*models.PembulatanSettings, error) {
+	defaultSettings := &models.PembulatanSettings{
+		ID:         1,
+		Aktif:      false,
+		Mode:       models.ModePembulatanTerdekat,
+		Kelipatan:  100,
+		HanyaTunai: true,
+	}
+
+	query := `
+		INSERT INTO pembulatan_settings (
+			id, aktif, mode, kelipatan, hanya_tunai
+		) VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := database.Exec(query,
+		defaultSettings.ID,
+		boolToInt(defaultSettings.Aktif),
+		defaultSettings.Mode,
+		defaultSettings.Kelipatan,
+		boolToInt(defaultSettings.HanyaTunai),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default pembulatan settings: %w", err)
+	}
+
+	return defaultSettings, nil
+}
+
+// GetKartuHadiahSettings retrieves the validity rules of gift cards and store credit
+func (r *SettingsRepository) GetKartuHadiahSettings() (*models.KartuHadiahSettings, error) {
+	query := `
+		SELECT id, masa_berlaku_hari, masa_berlaku_retur_hari
+		FROM kartu_hadiah_settings
+		WHERE id = 1
+	`
+
+	var settings models.KartuHadiahSettings
+	err := database.QueryRow(query).Scan(
+		&settings.ID,
+		&settings.MasaBerlakuHari,
+		&settings.MasaBerlakuReturHari,
+	)
+
+	if err == sql.ErrNoRows {
+		// Return default settings if not found
+		return r.createDefaultKartuHadiahSettings()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kartu hadiah settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// UpdateKartuHadiahSettings updates the validity rules of gift cards and store credit
+func (r *SettingsRepository) UpdateKartuHadiahSettings(settings *models.KartuHadiahSettings) error {
+	query := `
+		UPDATE kartu_hadiah_settings
+		SET
+			masa_berlaku_hari = ?,
+			masa_berlaku_retur_hari = ?
+		WHERE id = 1
+	`
+
+	result, err := database.Exec(query,
+		settings.MasaBerlakuHari,
+		settings.MasaBerlakuReturHari,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update kartu hadiah settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// If no rows were updated, create default settings
+		_, err := r.createDefaultKartuHadiahSettings()
+		if err != nil {
+			return fmt.Errorf("failed to create default kartu hadiah settings: %w", err)
+		}
+		// Try update again
+		return r.UpdateKartuHadiahSettings(settings)
+	}
+
+	return nil
+}
+
+// createDefaultKartuHadiahSettings creates the default validity rules (kartu 365 hari, store credit 180 hari)
+func (r *SettingsRepository) createDefaultKartuHadiahSettings() (*models.KartuHadiahSettings, error) {
+	defaultSettings := &models.KartuHadiahSettings{
+		ID:                   1,
+		MasaBerlakuHari:      365,
+		MasaBerlakuReturHari: 180,
+	}
+
+	query := `
+		INSERT INTO kartu_hadiah_settings (
+			id, masa_berlaku_hari, masa_berlaku_retur_hari
+		) VALUES (?, ?, ?)
+	`
+
+	_, err := database.Exec(query,
+		defaultSettings.ID,
+		defaultSettings.MasaBerlakuHari,
+		defaultSettings.MasaBerlakuReturHari,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default kartu hadiah settings: %w", err)
+	}
+
+	return defaultSettings, nil
+}
+
+// GetBatchKadaluarsaSettings retrieves the schedule of the expired batch job
+func (r *SettingsRepository) GetBatchKadaluarsaSettings() (*models.BatchKadaluarsaSettings, error) {
+	query := `
+		SELECT id, hapus_otomatis, interval_jam
+		FROM batch_kadaluarsa_settings
+		WHERE id = 1
+	`
+
+	var settings models.BatchKadaluarsaSettings
+	var hapusOtomatis int
+	err := database.QueryRow(query).Scan(
+		&settings.ID,
+		&hapusOtomatis,
+		&settings.IntervalJam,
+	)
+
+	if err == sql.ErrNoRows {
+		// Return default settings if not found
+		return r.createDefaultBatchKadaluarsaSettings()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch kadaluarsa settings: %w", err)
+	}
+
+	settings.HapusOtomatis = hapusOtomatis == 1
+	return &settings, nil
+}
+
+// UpdateBatchKadaluarsaSettings updates the schedule of the expired batch job
+func (r *SettingsRepository) UpdateBatchKadaluarsaSettings(settings *models.BatchKadaluarsaSettings) error {
+	query := `
+		UPDATE batch_kadaluarsa_settings
+		SET
+			hapus_otomatis = ?,
+			interval_jam = ?
+		WHERE id = 1
+	`
+
+	result, err := database.Exec(query,
+		boolToInt(settings.HapusOtomatis),
+		settings.IntervalJam,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update batch kadaluarsa settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// If no rows were updated, create default settings
+		_, err := r.createDefaultBatchKadaluarsaSettings()
+		if err != nil {
+			return fmt.Errorf("failed to create default batch kadaluarsa settings: %w", err)
+		}
+		// Try update again
+		return r.UpdateBatchKadaluarsaSettings(settings)
+	}
+
+	return nil
+}
+
+// createDefaultBatchKadaluarsaSettings creates the default schedule (tiap 6 jam, tanpa hapus otomatis)
+func (r *SettingsRepository) createDefaultBatchKadaluarsaSettings() (*models.BatchKadaluarsaSettings, error) {
+	defaultSettings := &models.BatchKadaluarsaSettings{
+		ID:            1,
+		HapusOtomatis: false,
+		IntervalJam:   6,
+	}
+
+	query := `
+		INSERT INTO batch_kadaluarsa_settings (
+			id, hapus_otomatis, interval_jam
+		) VALUES (?, ?, ?)
+	`
+
+	_, err := database.Exec(query,
+		defaultSettings.ID,
+		boolToInt(defaultSettings.HapusOtomatis),
+		defaultSettings.IntervalJam,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default batch kadaluarsa settings: %w", err)
+	}
+
+	return defaultSettings, nil
+}
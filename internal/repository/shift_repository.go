@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+	"time"
+)
+
+// ShiftRepository handles database operations for cashier shifts
+type ShiftRepository struct{}
+
+// NewShiftRepository creates a new repository instance
+func NewShiftRepository() *ShiftRepository {
+	return &ShiftRepository{}
+}
+
+const shiftColumns = `id, kode_terminal, staff_id, staff_nama, kas_awal, kas_seharusnya, kas_dihitung,
+		selisih, status, catatan, dibuka_at, ditutup_at, ditutup_oleh_id, ditutup_oleh_nama,
+		created_at, updated_at`
+
+// Create opens a new shift. Only one shift can be open per terminal; the
+// partial unique index on kode_terminal rejects a second open shift.
+func (r *ShiftRepository) Create(shift *models.ShiftKasir) error {
+	query := `
+		INSERT INTO shift_kasir (
+			kode_terminal, staff_id, staff_nama, kas_awal, status, catatan, dibuka_at,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`
+
+	now := time.Now()
+	var staffID sql.NullInt64
+	if shift.StaffID != nil {
+		staffID = sql.NullInt64{Int64: int64(*shift.StaffID), Valid: true}
+	}
+
+	var id int64
+	err := database.QueryRow(query,
+		shift.KodeTerminal, staffID, shift.StaffNama, shift.KasAwal, models.StatusShiftBuka,
+		shift.Catatan, shift.DibukaAt, now, now,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to open shift: %w", err)
+	}
+
+	shift.ID = int(id)
+	shift.Status = models.StatusShiftBuka
+	shift.CreatedAt = now
+	shift.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves a shift by ID
+func (r *ShiftRepository) GetByID(id int) (*models.ShiftKasir, error) {
+	query := `SELECT ` + shiftColumns + ` FROM shift_kasir WHERE id = ?`
+
+	shift, err := scanShiftKasir(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+// GetActiveByTerminal retrieves the open shift of a terminal, or nil when no shift is open
+func (r *ShiftRepository) GetActiveByTerminal(kodeTerminal string) (*models.ShiftKasir, error) {
+	query := `SELECT ` + shiftColumns + ` FROM shift_kasir WHERE kode_terminal = ? AND status = ?`
+
+	shift, err := scanShiftKasir(database.QueryRow(query, kodeTerminal, models.StatusShiftBuka))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+// GetAll retrieves shifts, newest first, optionally filtered by terminal
+func (r *ShiftRepository) GetAll(kodeTerminal string, limit, offset int) ([]*models.ShiftKasir, error) {
+	query := `SELECT ` + shiftColumns + ` FROM shift_kasir`
+	var args []interface{}
+	if kodeTerminal != "" {
+		query += ` WHERE kode_terminal = ?`
+		args = append(args, kodeTerminal)
+	}
+	query += ` ORDER BY dibuka_at DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shifts: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*models.ShiftKasir
+	for rows.Next() {
+		shift, err := scanShiftKasir(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shift: %w", err)
+		}
+		list = append(list, shift)
+	}
+
+	return list, nil
+}
+
+// Close closes an open shift with the counted cash. The shift is marked closed
+// before its totals are aggregated, and its expected cash and variance are
+// computed from the closed shift in the same database transaction, so the Z
+// report covers everything booked on the shift up to closing. KasSeharusnya and
+// Selisih of shift are set. It returns false when the shift was already closed.
+func (r *ShiftRepository) Close(shift *models.ShiftKasir) (bool, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	closeQuery := database.TranslateQuery(`
+		UPDATE shift_kasir SET
+			kas_dihitung = ?, status = ?, catatan = ?, ditutup_at = ?, ditutup_oleh_id = ?, ditutup_oleh_nama = ?
+		WHERE id = ? AND status = ?
+	`)
+
+	var ditutupOlehID sql.NullInt64
+	if shift.DitutupOlehID != nil {
+		ditutupOlehID = sql.NullInt64{Int64: int64(*shift.DitutupOlehID), Valid: true}
+	}
+
+	result, err := tx.Exec(closeQuery,
+		shift.KasDihitung, models.StatusShiftTutup, shift.Catatan, shift.DitutupAt, ditutupOlehID, shift.DitutupOlehNama,
+		shift.ID, models.StatusShiftBuka,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to close shift: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	laporan, err := laporanShift(tx, shift)
+	if err != nil {
+		return false, err
+	}
+	shift.KasSeharusnya = laporan.KasSeharusnya
+	shift.Selisih = 0
+	if shift.KasDihitung != nil {
+		shift.Selisih = *shift.KasDihitung - laporan.KasSeharusnya
+	}
+
+	kasQuery := database.TranslateQuery(`UPDATE shift_kasir SET kas_seharusnya = ?, selisih = ? WHERE id = ?`)
+	if _, err := tx.Exec(kasQuery, shift.KasSeharusnya, shift.Selisih, shift.ID); err != nil {
+		return false, fmt.Errorf("failed to record expected cash: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit shift close: %w", err)
+	}
+
+	return true, nil
+}
+
+// CreateMutasi records cash put into or taken out of the drawer
+func (r *ShiftRepository) CreateMutasi(mutasi *models.ShiftKasMutasi) error {
+	query := `
+		INSERT INTO shift_kas_mutasi (shift_id, jenis, jumlah, keterangan, staff_id, staff_nama, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`
+
+	var staffID sql.NullInt64
+	if mutasi.StaffID != nil {
+		staffID = sql.NullInt64{Int64: int64(*mutasi.StaffID), Valid: true}
+	}
+
+	var id int64
+	err := database.QueryRow(query,
+		mutasi.ShiftID, mutasi.Jenis, mutasi.Jumlah, mutasi.Keterangan, staffID, mutasi.StaffNama, mutasi.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to record cash movement: %w", err)
+	}
+
+	mutasi.ID = int(id)
+	return nil
+}
+
+// GetMutasiByShift retrieves the cash movements of a shift
+func (r *ShiftRepository) GetMutasiByShift(shiftID int) ([]*models.ShiftKasMutasi, error) {
+	query := `
+		SELECT id, shift_id, jenis, jumlah, keterangan, staff_id, staff_nama, created_at
+		FROM shift_kas_mutasi
+		WHERE shift_id = ?
+		ORDER BY created_at ASC
+	`
+
+	rows, err := database.Query(query, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cash movements: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]*models.ShiftKasMutasi, 0)
+	for rows.Next() {
+		var m models.ShiftKasMutasi
+		var keterangan, staffNama sql.NullString
+		var staffID sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.ShiftID, &m.Jenis, &m.Jumlah, &keterangan, &staffID, &staffNama, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cash movement: %w", err)
+		}
+		m.Keterangan = keterangan.String
+		m.StaffNama = staffNama.String
+		if staffID.Valid {
+			id := int(staffID.Int64)
+			m.StaffID = &id
+		}
+		list = append(list, &m)
+	}
+
+	return list, nil
+}
+
+// shiftQueryer runs the report queries of a shift on the database or within a transaction
+type shiftQueryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// GetLaporan aggregates the sales, payments, cash refunds and cash movements
+// linked to a shift, and its expected cash:
+// kas awal + penjualan tunai - refund tunai - void tunai + kas masuk - kas keluar.
+// A void counts in the shift where it was done. Sales voided within their own
+// shift are reported separately and do not count towards the expected cash;
+// sales voided in a later shift stay in their own shift, and the cash paid back
+// counts as void tunai of the shift that voided them. Gift card sales bring in
+// cash but are not sales, so they are reported separately from the sales totals.
+func (r *ShiftRepository) GetLaporan(shift *models.ShiftKasir) (*models.LaporanShift, error) {
+	return laporanShift(database.DB, shift)
+}
+
+func laporanShift(q shiftQueryer, shift *models.ShiftKasir) (*models.LaporanShift, error) {
+	laporan := &models.LaporanShift{
+		Pembayaran: make([]*models.ShiftPembayaranSummary, 0),
+	}
+
+	// Penjualan (tanpa transaksi yang di-void di shift ini); kembalian termasuk dari penjualan kartu hadiah
+	var totalKembalian int
+	salesQuery := database.TranslateQuery(`
+		SELECT
+			COALESCE(SUM(CASE WHEN jenis = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN jenis = ? THEN total ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN jenis = ? THEN diskon ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN jenis = ? THEN total_pajak ELSE 0 END), 0),
+			COALESCE(SUM(kembalian), 0),
+			COALESCE(SUM(CASE WHEN jenis = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN jenis = ? THEN total ELSE 0 END), 0)
+		FROM transaksi
+		WHERE shift_id = ? AND (status != 'void' OR void_shift_id != shift_id)
+	`)
+	penjualan, kartuHadiah := models.JenisTransaksiPenjualan, models.JenisTransaksiKartuHadiah
+	err := q.QueryRow(salesQuery, penjualan, penjualan, penjualan, penjualan, kartuHadiah, kartuHadiah, shift.ID).Scan(
+		&laporan.JumlahTransaksi, &laporan.TotalPenjualan, &laporan.TotalDiskon,
+		&laporan.TotalPajak, &totalKembalian, &laporan.JumlahKartuHadiah, &laporan.TotalKartuHadiah,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift sales: %w", err)
+	}
+
+	// Transaksi void yang dilakukan di shift ini; void lama tanpa shift dihitung di shift penjualannya
+	voidQuery := database.TranslateQuery(`
+		SELECT COUNT(*), COALESCE(SUM(total), 0) FROM transaksi
+		WHERE status = 'void' AND COALESCE(void_shift_id, shift_id) = ?
+	`)
+	if err := q.QueryRow(voidQuery, shift.ID).Scan(&laporan.JumlahVoid, &laporan.TotalVoid); err != nil {
+		return nil, fmt.Errorf("failed to get shift voids: %w", err)
+	}
+
+	// Uang tunai yang dikembalikan untuk void penjualan dari shift lain
+	voidTunaiQuery := database.TranslateQuery(`
+		SELECT COALESCE(SUM(
+			(SELECT COALESCE(SUM(p.jumlah), 0) FROM pembayaran p WHERE p.transaksi_id = t.id AND LOWER(p.metode) = ?)
+			- COALESCE(t.kembalian, 0)), 0)
+		FROM transaksi t
+		WHERE t.status = 'void' AND t.void_shift_id = ? AND COALESCE(t.shift_id, 0) != t.void_shift_id
+	`)
+	if err := q.QueryRow(voidTunaiQuery, models.MetodeTunai, shift.ID).Scan(&laporan.VoidTunai); err != nil {
+		return nil, fmt.Errorf("failed to get shift void cash: %w", err)
+	}
+
+	// Pembayaran per metode
+	paymentQuery := database.TranslateQuery(`
+		SELECT LOWER(p.metode), COALESCE(mp.nama, LOWER(p.metode)), COUNT(*), COALESCE(SUM(p.jumlah), 0)
+		FROM pembayaran p
+		JOIN transaksi t ON t.id = p.transaksi_id
+		LEFT JOIN metode_pembayaran mp ON mp.kode = LOWER(p.metode)
+		WHERE p.shift_id = ? AND (t.status != 'void' OR t.void_shift_id != p.shift_id)
+		GROUP BY LOWER(p.metode), mp.nama
+		ORDER BY LOWER(p.metode)
+	`)
+	rows, err := q.Query(paymentQuery, shift.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift payments: %w", err)
+	}
+	defer rows.Close()
+
+	pembayaranTunai := 0
+	for rows.Next() {
+		var p models.ShiftPembayaranSummary
+		if err := rows.Scan(&p.Metode, &p.Nama, &p.Jumlah, &p.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan shift payment: %w", err)
+		}
+		if p.Metode == models.MetodeTunai {
+			pembayaranTunai = p.Total
+		}
+		laporan.Pembayaran = append(laporan.Pembayaran, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate shift payments: %w", err)
+	}
+	rows.Close()
+	// Kembalian selalu diberikan dalam bentuk tunai
+	laporan.PenjualanTunai = pembayaranTunai - totalKembalian
+
+	// Refund
+	refundQuery := database.TranslateQuery(`
+		SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN type = 'refund' AND LOWER(refund_method) = ? THEN refund_amount - COALESCE(potong_piutang, 0) ELSE 0 END), 0)
+		FROM returns
+		WHERE shift_id = ? AND COALESCE(refund_status, '') != 'cancelled'
+	`)
+	if err := q.QueryRow(refundQuery, models.MetodeTunai, shift.ID).Scan(&laporan.JumlahRefund, &laporan.RefundTunai); err != nil {
+		return nil, fmt.Errorf("failed to get shift refunds: %w", err)
+	}
+
+	// Kas masuk/keluar
+	mutasiQuery := database.TranslateQuery(`
+		SELECT
+			COALESCE(SUM(CASE WHEN jenis = ? THEN jumlah ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN jenis = ? THEN jumlah ELSE 0 END), 0)
+		FROM shift_kas_mutasi
+		WHERE shift_id = ?
+	`)
+	err = q.QueryRow(mutasiQuery, models.MutasiKasMasuk, models.MutasiKasKeluar, shift.ID).
+		Scan(&laporan.KasMasuk, &laporan.KasKeluar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift cash movements: %w", err)
+	}
+
+	laporan.KasSeharusnya = shift.KasAwal + laporan.PenjualanTunai - laporan.RefundTunai - laporan.VoidTunai +
+		laporan.KasMasuk - laporan.KasKeluar
+	return laporan, nil
+}
+
+func scanShiftKasir(row rowScanner) (*models.ShiftKasir, error) {
+	var shift models.ShiftKasir
+	var staffID, kasDihitung, ditutupOlehID sql.NullInt64
+	var staffNama, catatan, ditutupOlehNama sql.NullString
+	var ditutupAt sql.NullTime
+
+	err := row.Scan(
+		&shift.ID, &shift.KodeTerminal, &staffID, &staffNama, &shift.KasAwal, &shift.KasSeharusnya, &kasDihitung,
+		&shift.Selisih, &shift.Status, &catatan, &shift.DibukaAt, &ditutupAt, &ditutupOlehID, &ditutupOlehNama,
+		&shift.CreatedAt, &shift.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if staffID.Valid {
+		id := int(staffID.Int64)
+		shift.StaffID = &id
+	}
+	if kasDihitung.Valid {
+		kas := int(kasDihitung.Int64)
+		shift.KasDihitung = &kas
+	}
+	if ditutupOlehID.Valid {
+		id := int(ditutupOlehID.Int64)
+		shift.DitutupOlehID = &id
+	}
+	if ditutupAt.Valid {
+		shift.DitutupAt = &ditutupAt.Time
+	}
+	shift.StaffNama = staffNama.String
+	shift.Catatan = catatan.String
+	shift.DitutupOlehNama = ditutupOlehNama.String
+
+	return &shift, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openShiftDB opens an in-memory database with the tables a shift report reads
+// and makes it the database used by the package-level helpers
+func openShiftDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	lama := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = lama
+		db.Close()
+	})
+
+	for _, ddl := range []string{
+		`CREATE TABLE shift_kasir (
+			id INTEGER PRIMARY KEY, kode_terminal TEXT, kas_awal INTEGER, kas_seharusnya INTEGER DEFAULT 0,
+			kas_dihitung INTEGER, selisih INTEGER DEFAULT 0, status TEXT, catatan TEXT, ditutup_at DATETIME,
+			ditutup_oleh_id INTEGER, ditutup_oleh_nama TEXT
+		)`,
+		`CREATE TABLE transaksi (
+			id INTEGER PRIMARY KEY, shift_id INTEGER, status TEXT, jenis TEXT DEFAULT 'penjualan', total INTEGER,
+			diskon INTEGER DEFAULT 0, total_pajak INTEGER DEFAULT 0, kembalian INTEGER DEFAULT 0, void_shift_id INTEGER
+		)`,
+		`CREATE TABLE pembayaran (id INTEGER PRIMARY KEY, transaksi_id INTEGER, shift_id INTEGER, metode TEXT, jumlah INTEGER)`,
+		`CREATE TABLE metode_pembayaran (id INTEGER PRIMARY KEY, kode TEXT, nama TEXT)`,
+		`CREATE TABLE returns (
+			id INTEGER PRIMARY KEY, shift_id INTEGER, type TEXT, refund_method TEXT, refund_amount INTEGER,
+			potong_piutang INTEGER, refund_status TEXT
+		)`,
+		`CREATE TABLE shift_kas_mutasi (id INTEGER PRIMARY KEY, shift_id INTEGER, jenis TEXT, jumlah INTEGER)`,
+	} {
+		_, err := db.Exec(ddl)
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func TestLaporanShiftVoidDiShiftBerikutnya(t *testing.T) {
+	db := openShiftDB(t)
+	for _, q := range []string{
+		`INSERT INTO shift_kasir (id, kode_terminal, kas_awal, status) VALUES (1, 'K1', 100000, 'buka'), (2, 'K1', 100000, 'buka')`,
+		// Shift 1: penjualan 50.000 dibayar tunai 60.000, dan penjualan 20.000 yang di-void di shift yang sama
+		`INSERT INTO transaksi (id, shift_id, status, total, kembalian, void_shift_id) VALUES
+			(1, 1, 'void', 50000, 10000, 2),
+			(2, 1, 'void', 20000, 0, 1),
+			(3, 2, 'selesai', 30000, 0, NULL)`,
+		`INSERT INTO pembayaran (transaksi_id, shift_id, metode, jumlah) VALUES
+			(1, 1, 'tunai', 60000), (2, 1, 'tunai', 20000), (3, 2, 'tunai', 30000)`,
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+
+	repo := NewShiftRepository()
+	kasDihitung := 150000
+	now := time.Now()
+	shift := &models.ShiftKasir{ID: 1, KasAwal: 100000, KasDihitung: &kasDihitung, DitutupAt: &now}
+	closed, err := repo.Close(shift)
+	require.NoError(t, err)
+	require.True(t, closed)
+	assert.Equal(t, 150000, shift.KasSeharusnya, "void di shift 2 tidak mengubah kas shift 1")
+	assert.Zero(t, shift.Selisih)
+
+	var kasSeharusnya int
+	require.NoError(t, db.QueryRow(`SELECT kas_seharusnya FROM shift_kasir WHERE id = 1`).Scan(&kasSeharusnya))
+	assert.Equal(t, 150000, kasSeharusnya)
+
+	closed, err = repo.Close(shift)
+	require.NoError(t, err)
+	assert.False(t, closed, "shift yang sudah ditutup tidak ditutup lagi")
+
+	laporan, err := repo.GetLaporan(shift)
+	require.NoError(t, err)
+	assert.Equal(t, 1, laporan.JumlahTransaksi)
+	assert.Equal(t, 50000, laporan.TotalPenjualan)
+	assert.Equal(t, 1, laporan.JumlahVoid)
+	assert.Equal(t, 20000, laporan.TotalVoid)
+
+	laporan, err = repo.GetLaporan(&models.ShiftKasir{ID: 2, KasAwal: 100000})
+	require.NoError(t, err)
+	assert.Equal(t, 1, laporan.JumlahTransaksi)
+	assert.Equal(t, 1, laporan.JumlahVoid)
+	assert.Equal(t, 50000, laporan.TotalVoid)
+	assert.Equal(t, 50000, laporan.VoidTunai)
+	assert.Equal(t, 80000, laporan.KasSeharusnya, "uang void dikembalikan dari laci shift 2")
+}
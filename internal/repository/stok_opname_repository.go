@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// StokOpnameRepository handles database operations for stock opname (physical inventory count) sessions
+type StokOpnameRepository struct{}
+
+// NewStokOpnameRepository creates a new repository instance
+func NewStokOpnameRepository() *StokOpnameRepository {
+	return &StokOpnameRepository{}
+}
+
+const stokOpnameColumns = `
+	o.id, o.nomor, COALESCE(o.kategori, ''), o.status, COALESCE(o.catatan, ''), COALESCE(o.dibuat_oleh, ''),
+	COALESCE(o.disetujui_oleh, ''), o.selesai_at, o.created_at, o.updated_at,
+	(SELECT COUNT(*) FROM stok_opname_item i WHERE i.opname_id = o.id),
+	(SELECT COUNT(*) FROM stok_opname_item i WHERE i.opname_id = o.id AND i.stok_fisik IS NOT NULL),
+	CAST(COALESCE((SELECT SUM((i.stok_sistem - i.stok_fisik) * i.harga_pokok) FROM stok_opname_item i
+		WHERE i.opname_id = o.id AND i.stok_fisik < i.stok_sistem), 0) AS INTEGER),
+	CAST(COALESCE((SELECT SUM((i.stok_fisik - i.stok_sistem) * i.harga_pokok) FROM stok_opname_item i
+		WHERE i.opname_id = o.id AND i.stok_fisik > i.stok_sistem), 0) AS INTEGER)
+`
+
+const stokOpnameItemColumns = `
+	i.id, i.opname_id, i.produk_id, COALESCE(p.sku, ''), COALESCE(p.nama, ''), COALESCE(p.satuan, ''),
+	i.stok_sistem, i.harga_pokok, i.stok_fisik, i.dihitung_at
+`
+
+const stokOpnameItemFrom = ` FROM stok_opname_item i LEFT JOIN produk p ON p.id = i.produk_id`
+
+// Mulai starts a stock opname and freezes the current stock and cost of every
+// product (of one category, if given) as the system quantity to count against.
+// Kits are left out, their components are counted instead. Two running sessions
+// may not cover the same products.
+func (r *StokOpnameRepository) Mulai(opname *models.StokOpname) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var berjalan int
+	checkQuery := database.TranslateQuery(`
+		SELECT COUNT(*) FROM stok_opname
+		WHERE status = ? AND (kategori = '' OR kategori = ? OR ? = '')
+	`)
+	if err := tx.QueryRow(checkQuery, models.StatusOpnameBerjalan, opname.Kategori, opname.Kategori).Scan(&berjalan); err != nil {
+		return fmt.Errorf("failed to check running stock opname: %w", err)
+	}
+	if berjalan > 0 {
+		return fmt.Errorf("masih ada stok opname yang berjalan untuk produk yang sama, setujui atau batalkan terlebih dahulu")
+	}
+
+	now := time.Now()
+	nomor, err := generateNomorDokumen(tx, "stok_opname", "SO", now)
+	if err != nil {
+		return err
+	}
+
+	headerQuery := database.TranslateQuery(`
+		INSERT INTO stok_opname (nomor, kategori, status, catatan, dibuat_oleh, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`)
+	var id int64
+	if err := tx.QueryRow(headerQuery, nomor, opname.Kategori, models.StatusOpnameBerjalan, opname.Catatan,
+		opname.DibuatOleh, now, now).Scan(&id); err != nil {
+		return fmt.Errorf("failed to create stock opname: %w", err)
+	}
+
+	snapshotQuery := database.TranslateQuery(`
+		INSERT INTO stok_opname_item (opname_id, produk_id, stok_sistem, harga_pokok)
+		SELECT ?, id, stok, COALESCE(harga_beli, 0)
+		FROM produk
+		WHERE deleted_at IS NULL AND (? = '' OR kategori = ?)
+		  AND id NOT IN (SELECT paket_id FROM produk_komponen)
+	`)
+	result, err := tx.Exec(snapshotQuery, id, opname.Kategori, opname.Kategori)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot stock: %w", err)
+	}
+	jumlah, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if jumlah == 0 {
+		return fmt.Errorf("tidak ada produk untuk dihitung")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock opname: %w", err)
+	}
+
+	opname.ID = int(id)
+	opname.Nomor = nomor
+	opname.Status = models.StatusOpnameBerjalan
+	opname.JumlahProduk = int(jumlah)
+	opname.CreatedAt = now
+	opname.UpdatedAt = now
+	return nil
+}
+
+// GetAll retrieves stock opname sessions without their items, newest first, optionally filtered by status
+func (r *StokOpnameRepository) GetAll(status string) ([]*models.StokOpname, error) {
+	query := `SELECT ` + stokOpnameColumns + ` FROM stok_opname o`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE o.status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY o.created_at DESC, o.id DESC`
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock opname: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.StokOpname{}
+	for rows.Next() {
+		opname, err := scanStokOpname(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, opname)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stock opname: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetByID retrieves a stock opname session with its items and variances
+func (r *StokOpnameRepository) GetByID(id int) (*models.StokOpname, error) {
+	query := `SELECT ` + stokOpnameColumns + ` FROM stok_opname o WHERE o.id = ?`
+
+	opname, err := scanStokOpname(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + stokOpnameItemColumns + stokOpnameItemFrom + ` WHERE i.opname_id = ? ORDER BY p.nama ASC, i.id ASC`
+	rows, err := database.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock opname items: %w", err)
+	}
+	defer rows.Close()
+
+	opname.Items = []*models.StokOpnameItem{}
+	for rows.Next() {
+		item, err := scanStokOpnameItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		opname.Items = append(opname.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stock opname items: %w", err)
+	}
+
+	return opname, nil
+}
+
+// GetItem retrieves the count of one product in a stock opname
+func (r *StokOpnameRepository) GetItem(opnameID, produkID int) (*models.StokOpnameItem, error) {
+	query := `SELECT ` + stokOpnameItemColumns + stokOpnameItemFrom + ` WHERE i.opname_id = ? AND i.produk_id = ?`
+
+	item, err := scanStokOpnameItem(database.QueryRow(query, opnameID, produkID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return item, err
+}
+
+// CatatHitung records the counted quantity of a product in a running stock
+// opname; with tambah the quantity is added to what was counted so far
+func (r *StokOpnameRepository) CatatHitung(opnameID, produkID int, qty float64, tambah bool) error {
+	set := `stok_fisik = ?`
+	if tambah {
+		set = `stok_fisik = COALESCE(stok_fisik, 0) + ?`
+	}
+	query := `
+		UPDATE stok_opname_item SET ` + set + `, dihitung_at = ?
+		WHERE opname_id = ? AND produk_id = ?
+		  AND EXISTS (SELECT 1 FROM stok_opname o WHERE o.id = stok_opname_item.opname_id AND o.status = ?)
+	`
+
+	result, err := database.Exec(query, qty, time.Now(), opnameID, produkID, models.StatusOpnameBerjalan)
+	if err != nil {
+		return fmt.Errorf("failed to record counted stock: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("produk tidak termasuk dalam stok opname yang sedang berjalan")
+	}
+
+	return nil
+}
+
+// Setujui approves a running stock opname and posts the variance of every
+// counted product to its current stock with a stock history entry: shortages
+// as "hilang" losses and surpluses as "kelebihan". Sales made while counting
+// are kept, because the variance is measured against the frozen system stock.
+// The batches follow the same change in the same database transaction:
+// shortages are written off from batches in the order of the batch policy and
+// surpluses of products with a shelf life are added as a batch.
+func (r *StokOpnameRepository) Setujui(opname *models.StokOpname, disetujuiOleh string) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	statusQuery := database.TranslateQuery(`
+		UPDATE stok_opname SET status = ?, disetujui_oleh = ?, selesai_at = ? WHERE id = ? AND status = ?
+	`)
+	result, err := tx.Exec(statusQuery, models.StatusOpnameDisetujui, disetujuiOleh, now, opname.ID, models.StatusOpnameBerjalan)
+	if err != nil {
+		return fmt.Errorf("failed to approve stock opname: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("stok opname %s sudah tidak berjalan", opname.Nomor)
+	}
+
+	stokQuery := database.TranslateQuery(`SELECT stok, COALESCE(masa_simpan_hari, 0) FROM produk WHERE id = ?`)
+	updateStokQuery := database.TranslateQuery(`UPDATE produk SET stok = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	historyQuery := database.TranslateQuery(`
+		INSERT INTO stok_history (
+			produk_id, stok_sebelum, stok_sesudah, perubahan, jenis_perubahan, keterangan,
+			tipe_kerugian, nilai_kerugian, harga_beli, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+
+	for _, item := range opname.Items {
+		if item.StokFisik == nil || item.SelisihQty == 0 {
+			continue
+		}
+
+		var stokSebelum float64
+		var masaSimpan int
+		if err := tx.QueryRow(stokQuery, item.ProdukID).Scan(&stokSebelum, &masaSimpan); err != nil {
+			return fmt.Errorf("failed to get stock of %s: %w", item.NamaProduk, err)
+		}
+		stokSesudah := math.Max(stokSebelum+item.SelisihQty, 0)
+		perubahan := stokSesudah - stokSebelum
+		if perubahan == 0 {
+			continue
+		}
+		if _, err := tx.Exec(updateStokQuery, stokSesudah, item.ProdukID); err != nil {
+			return fmt.Errorf("failed to update stock of %s: %w", item.NamaProduk, err)
+		}
+
+		jenis, tipe := "pengurangan", models.TipeKerugianHilang
+		nilaiKerugian, hargaBeli := int(math.Round(-perubahan*float64(item.HargaPokok))), 0
+		if perubahan > 0 {
+			jenis, tipe = "penambahan", models.TipeKerugianKelebihan
+			nilaiKerugian, hargaBeli = 0, item.HargaPokok
+		}
+		keterangan := fmt.Sprintf("Stok opname %s (sistem %.2f, fisik %.2f)", opname.Nomor, item.StokSistem, *item.StokFisik)
+		if _, err := tx.Exec(historyQuery, item.ProdukID, stokSebelum, stokSesudah, perubahan, jenis, keterangan,
+			tipe, nilaiKerugian, hargaBeli, now); err != nil {
+			return fmt.Errorf("failed to create stock history: %w", err)
+		}
+
+		// Batch disesuaikan dengan perubahan stok yang sama, setelah dibatasi di 0
+		keteranganBatch := fmt.Sprintf("Stok opname %s", opname.Nomor)
+		if perubahan < 0 {
+			if _, err := hapusBatchKonsumsi(tx, item.ProdukID, -perubahan, tipe, keteranganBatch, now); err != nil {
+				return err
+			}
+		} else if masaSimpan > 0 {
+			batch := &models.Batch{
+				ProdukID:       item.ProdukID,
+				Qty:            perubahan,
+				MasaSimpanHari: masaSimpan,
+				Keterangan:     keteranganBatch,
+				HargaBeli:      item.HargaPokok,
+			}
+			if err := tambahBatch(tx, batch, now); err != nil {
+				return fmt.Errorf("failed to create batch for %s: %w", item.NamaProduk, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock opname: %w", err)
+	}
+
+	opname.Status = models.StatusOpnameDisetujui
+	opname.DisetujuiOleh = disetujuiOleh
+	opname.SelesaiAt = &now
+	return nil
+}
+
+// Batalkan cancels a running stock opname without changing any stock
+func (r *StokOpnameRepository) Batalkan(id int) error {
+	query := `UPDATE stok_opname SET status = ?, selesai_at = ? WHERE id = ? AND status = ?`
+
+	result, err := database.Exec(query, models.StatusOpnameDibatalkan, time.Now(), id, models.StatusOpnameBerjalan)
+	if err != nil {
+		return fmt.Errorf("failed to cancel stock opname: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("hanya stok opname yang sedang berjalan yang dapat dibatalkan")
+	}
+
+	return nil
+}
+
+// scanStokOpname scans a stock opname row selected with stokOpnameColumns
+func scanStokOpname(row rowScanner) (*models.StokOpname, error) {
+	var o models.StokOpname
+	var selesaiAt sql.NullTime
+
+	err := row.Scan(
+		&o.ID,
+		&o.Nomor,
+		&o.Kategori,
+		&o.Status,
+		&o.Catatan,
+		&o.DibuatOleh,
+		&o.DisetujuiOleh,
+		&selesaiAt,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+		&o.JumlahProduk,
+		&o.JumlahDihitung,
+		&o.NilaiKurang,
+		&o.NilaiLebih,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan stock opname: %w", err)
+	}
+
+	if selesaiAt.Valid {
+		o.SelesaiAt = &selesaiAt.Time
+	}
+
+	return &o, nil
+}
+
+// scanStokOpnameItem scans a stock opname item row selected with stokOpnameItemColumns
+// and computes its variance when it has been counted
+func scanStokOpnameItem(row rowScanner) (*models.StokOpnameItem, error) {
+	var item models.StokOpnameItem
+	var stokFisik sql.NullFloat64
+	var dihitungAt sql.NullTime
+
+	err := row.Scan(
+		&item.ID,
+		&item.OpnameID,
+		&item.ProdukID,
+		&item.SKU,
+		&item.NamaProduk,
+		&item.Satuan,
+		&item.StokSistem,
+		&item.HargaPokok,
+		&stokFisik,
+		&dihitungAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan stock opname item: %w", err)
+	}
+
+	if stokFisik.Valid {
+		item.StokFisik = &stokFisik.Float64
+		item.SelisihQty = stokFisik.Float64 - item.StokSistem
+		item.SelisihNilai = int(math.Round(item.SelisihQty * float64(item.HargaPokok)))
+	}
+	if dihitungAt.Valid {
+		item.DihitungAt = &dihitungAt.Time
+	}
+
+	return &item, nil
+}
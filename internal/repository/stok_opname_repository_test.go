@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openOpnameDB opens an in-memory database with the tables a stock opname touches
+// and makes it the database used by the package-level helpers
+func openOpnameDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	lama := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = lama
+		db.Close()
+	})
+
+	for _, ddl := range []string{
+		`CREATE TABLE stok_opname (
+			id INTEGER PRIMARY KEY, nomor TEXT, kategori TEXT, status TEXT, catatan TEXT, dibuat_oleh TEXT,
+			disetujui_oleh TEXT, selesai_at DATETIME, created_at DATETIME, updated_at DATETIME
+		)`,
+		`CREATE TABLE stok_opname_item (
+			id INTEGER PRIMARY KEY, opname_id INTEGER, produk_id INTEGER, stok_sistem REAL, harga_pokok INTEGER,
+			stok_fisik REAL, dihitung_at DATETIME
+		)`,
+		`CREATE TABLE kategori (id INTEGER PRIMARY KEY, nama TEXT, kebijakan_batch TEXT)`,
+		`CREATE TABLE produk_komponen (id INTEGER PRIMARY KEY, paket_id INTEGER, komponen_id INTEGER, qty REAL)`,
+		`CREATE TABLE produk (
+			id INTEGER PRIMARY KEY, nama TEXT, kategori TEXT, stok REAL, harga_beli INTEGER,
+			masa_simpan_hari INTEGER, kebijakan_batch TEXT, deleted_at DATETIME, updated_at DATETIME
+		)`,
+		`CREATE TABLE batch (
+			id TEXT PRIMARY KEY, produk_id INTEGER, qty REAL, qty_tersisa REAL, tanggal_restok DATETIME,
+			masa_simpan_hari INTEGER, tanggal_kadaluarsa DATETIME, status TEXT, supplier TEXT, supplier_id INTEGER,
+			keterangan TEXT, harga_beli INTEGER, ditarik_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME
+		)`,
+		`CREATE TABLE batch_penghapusan (
+			id INTEGER PRIMARY KEY, batch_id TEXT, produk_id INTEGER, qty REAL,
+			tipe_kerugian TEXT, keterangan TEXT, created_at DATETIME
+		)`,
+		`CREATE TABLE stok_history (
+			id INTEGER PRIMARY KEY, produk_id INTEGER, stok_sebelum REAL, stok_sesudah REAL, perubahan REAL,
+			jenis_perubahan TEXT, keterangan TEXT, tipe_kerugian TEXT, nilai_kerugian INTEGER, harga_beli INTEGER,
+			created_at DATETIME
+		)`,
+	} {
+		_, err := db.Exec(ddl)
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func TestSetujuiBatchSamaDenganStok(t *testing.T) {
+	db := openOpnameDB(t)
+	for _, q := range []string{
+		`INSERT INTO stok_opname (id, nomor, status) VALUES (1, 'SO-20261018-0001', 'berjalan')`,
+		// Kurang 3: dihapus dari batch tertua lebih dulu
+		`INSERT INTO produk (id, nama, stok, harga_beli, masa_simpan_hari) VALUES (1, 'Roti', 10, 5000, 3)`,
+		`INSERT INTO batch (id, produk_id, qty, qty_tersisa, tanggal_restok, masa_simpan_hari)
+			VALUES ('B1', 1, 4, 4, '2026-10-10', 3), ('B2', 1, 6, 6, '2026-10-12', 3)`,
+		// Terjual selama opname: selisih -5 dibatasi menjadi -2 agar stok tidak negatif
+		`INSERT INTO produk (id, nama, stok, harga_beli, masa_simpan_hari) VALUES (2, 'Susu', 2, 8000, 7)`,
+		`INSERT INTO batch (id, produk_id, qty, qty_tersisa, tanggal_restok, masa_simpan_hari)
+			VALUES ('B3', 2, 5, 2, '2026-10-11', 7)`,
+		// Lebih 2: masuk sebagai batch baru
+		`INSERT INTO produk (id, nama, stok, harga_beli, masa_simpan_hari) VALUES (3, 'Keju', 3, 20000, 30)`,
+		`INSERT INTO batch (id, produk_id, qty, qty_tersisa, tanggal_restok, masa_simpan_hari)
+			VALUES ('B4', 3, 3, 3, '2026-10-01', 30)`,
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+
+	fisik := func(v float64) *float64 { return &v }
+	opname := &models.StokOpname{
+		ID:    1,
+		Nomor: "SO-20261018-0001",
+		Items: []*models.StokOpnameItem{
+			{ProdukID: 1, NamaProduk: "Roti", StokSistem: 10, HargaPokok: 5000, StokFisik: fisik(7), SelisihQty: -3},
+			{ProdukID: 2, NamaProduk: "Susu", StokSistem: 5, HargaPokok: 8000, StokFisik: fisik(0), SelisihQty: -5},
+			{ProdukID: 3, NamaProduk: "Keju", StokSistem: 3, HargaPokok: 20000, StokFisik: fisik(5), SelisihQty: 2},
+		},
+	}
+	require.NoError(t, (&StokOpnameRepository{}).Setujui(opname, "Admin"))
+
+	for _, tt := range []struct {
+		produkID int
+		want     float64
+	}{
+		{1, 7},
+		{2, 0},
+		{3, 5},
+	} {
+		var stok, batch float64
+		require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = ?`, tt.produkID).Scan(&stok))
+		require.NoError(t, db.QueryRow(`SELECT COALESCE(SUM(qty_tersisa), 0) FROM batch WHERE produk_id = ?`, tt.produkID).Scan(&batch))
+		assert.Equal(t, tt.want, stok, "stok produk %d", tt.produkID)
+		assert.Equal(t, stok, batch, "jumlah batch produk %d", tt.produkID)
+	}
+
+	var sisaB1, sisaB2 float64
+	require.NoError(t, db.QueryRow(`SELECT qty_tersisa FROM batch WHERE id = 'B1'`).Scan(&sisaB1))
+	require.NoError(t, db.QueryRow(`SELECT qty_tersisa FROM batch WHERE id = 'B2'`).Scan(&sisaB2))
+	assert.Equal(t, 1.0, sisaB1, "FIFO mengambil dari batch tertua")
+	assert.Equal(t, 6.0, sisaB2)
+
+	var dihapus float64
+	require.NoError(t, db.QueryRow(`SELECT SUM(qty) FROM batch_penghapusan WHERE produk_id = 2`).Scan(&dihapus))
+	assert.Equal(t, 2.0, dihapus, "penghapusan batch memakai selisih yang sudah dibatasi")
+}
+
+func TestMulaiTanpaPaket(t *testing.T) {
+	db := openOpnameDB(t)
+	for _, q := range []string{
+		`INSERT INTO produk (id, nama, stok, harga_beli) VALUES (1, 'Kopi', 10, 3000), (2, 'Gula', 20, 1000)`,
+		`INSERT INTO produk (id, nama, stok, harga_beli) VALUES (3, 'Paket Kopi Gula', 0, 4000)`,
+		`INSERT INTO produk_komponen (paket_id, komponen_id, qty) VALUES (3, 1, 1), (3, 2, 1)`,
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+
+	opname := &models.StokOpname{DibuatOleh: "Admin"}
+	require.NoError(t, (&StokOpnameRepository{}).Mulai(opname))
+	assert.Equal(t, 2, opname.JumlahProduk)
+
+	var paket int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM stok_opname_item WHERE produk_id = 3`).Scan(&paket))
+	assert.Zero(t, paket, "paket tidak ikut dihitung")
+}
@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+)
+
+// SupplierRepository handles database operations for supplier master data
+type SupplierRepository struct{}
+
+// NewSupplierRepository creates a new repository instance
+func NewSupplierRepository() *SupplierRepository {
+	return &SupplierRepository{}
+}
+
+const supplierColumns = `
+	id, nama, COALESCE(kontak, ''), COALESCE(alamat, ''), COALESCE(npwp, ''), COALESCE(termin_hari, 0),
+	aktif, created_at, updated_at, digabung_ke_id, deleted_at
+`
+
+// Create creates a new supplier
+func (r *SupplierRepository) Create(supplier *models.Supplier) error {
+	query := `
+		INSERT INTO supplier (nama, kontak, alamat, npwp, termin_hari, aktif, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id
+	`
+
+	var id int64
+	err := database.QueryRow(query,
+		supplier.Nama,
+		supplier.Kontak,
+		supplier.Alamat,
+		supplier.NPWP,
+		supplier.TerminHari,
+		boolToInt(supplier.Aktif),
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create supplier: %w", err)
+	}
+
+	supplier.ID = int(id)
+	return nil
+}
+
+// GetAll retrieves all suppliers ordered by name, without merged duplicates
+func (r *SupplierRepository) GetAll() ([]*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM supplier WHERE deleted_at IS NULL ORDER BY nama ASC`
+
+	rows, err := database.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query supplier: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.Supplier{}
+	for rows.Next() {
+		supplier, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, supplier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate supplier: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetByID retrieves a supplier by ID, including a merged duplicate
+func (r *SupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM supplier WHERE id = ?`
+
+	supplier, err := scanSupplier(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return supplier, err
+}
+
+// GetByNama retrieves a supplier by name, ignoring case and surrounding spaces.
+// A merged duplicate is returned too, so its name can be followed to the kept supplier.
+func (r *SupplierRepository) GetByNama(nama string) (*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM supplier WHERE LOWER(nama) = ?`
+
+	supplier, err := scanSupplier(database.QueryRow(query, strings.ToLower(strings.TrimSpace(nama))))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return supplier, err
+}
+
+// Update updates a supplier and the supplier name shown on its batches
+func (r *SupplierRepository) Update(supplier *models.Supplier) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := database.TranslateQuery(`
+		UPDATE supplier SET nama = ?, kontak = ?, alamat = ?, npwp = ?, termin_hari = ?, aktif = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`)
+	result, err := tx.Exec(query,
+		supplier.Nama,
+		supplier.Kontak,
+		supplier.Alamat,
+		supplier.NPWP,
+		supplier.TerminHari,
+		boolToInt(supplier.Aktif),
+		supplier.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update supplier: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("supplier tidak ditemukan")
+	}
+
+	batchQuery := database.TranslateQuery(`UPDATE batch SET supplier = ? WHERE supplier_id = ?`)
+	if _, err := tx.Exec(batchQuery, supplier.Nama, supplier.ID); err != nil {
+		return fmt.Errorf("failed to update batch supplier: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit supplier: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a supplier that is not referenced by any batch
+func (r *SupplierRepository) Delete(id int) error {
+	var jumlahBatch int
+	if err := database.QueryRow(`SELECT COUNT(*) FROM batch WHERE supplier_id = ?`, id).Scan(&jumlahBatch); err != nil {
+		return fmt.Errorf("failed to count supplier batches: %w", err)
+	}
+	if jumlahBatch > 0 {
+		return fmt.Errorf("supplier sudah memiliki %d batch, nonaktifkan supplier sebagai gantinya", jumlahBatch)
+	}
+
+	result, err := database.Exec(`DELETE FROM supplier WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete supplier: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("supplier tidak ditemukan")
+	}
+
+	return nil
+}
+
+// Gabung merges a duplicate supplier into the supplier that is kept, in one
+// database transaction: its batches (including the supplier name shown on them),
+// products and purchase orders are moved to the kept supplier, duplicates merged
+// into it earlier follow along, and it is deactivated and soft-deleted with a
+// link to the kept supplier.
+func (r *SupplierRepository) Gabung(duplikatID int, tujuan *models.Supplier) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Tandai duplikat lebih dulu, hanya jika keduanya belum digabung; dua penggabungan
+	// bersamaan tidak dapat saling menggabungkan
+	hapusQuery := database.TranslateQuery(`
+		UPDATE supplier SET aktif = 0, digabung_ke_id = ?, deleted_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+		  AND EXISTS (SELECT 1 FROM supplier t WHERE t.id = ? AND t.deleted_at IS NULL)
+	`)
+	result, err := tx.Exec(hapusQuery, tujuan.ID, time.Now(), duplikatID, tujuan.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete duplicate supplier: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("supplier sudah digabung oleh proses lain")
+	}
+
+	for _, q := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`UPDATE batch SET supplier_id = ?, supplier = ? WHERE supplier_id = ?`, []interface{}{tujuan.ID, tujuan.Nama, duplikatID}},
+		{`UPDATE produk SET supplier_id = ? WHERE supplier_id = ?`, []interface{}{tujuan.ID, duplikatID}},
+		{`UPDATE purchase_order SET supplier_id = ? WHERE supplier_id = ?`, []interface{}{tujuan.ID, duplikatID}},
+		{`UPDATE supplier SET digabung_ke_id = ? WHERE digabung_ke_id = ?`, []interface{}{tujuan.ID, duplikatID}},
+	} {
+		if _, err := tx.Exec(database.TranslateQuery(q.query), q.args...); err != nil {
+			return fmt.Errorf("failed to move supplier references: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit supplier merge: %w", err)
+	}
+
+	return nil
+}
+
+// GetLaporan summarizes per supplier the batches received between two dates
+// (YYYY-MM-DD) and the stock of its batches written off in that period.
+// Values use the current purchase price of each product.
+func (r *SupplierRepository) GetLaporan(startDate, endDate string) ([]*models.LaporanSupplier, error) {
+	perSupplier := make(map[int]*models.LaporanSupplier)
+
+	penerimaanQuery := `
+		SELECT s.id, s.nama, COUNT(b.id),
+		       COALESCE(SUM(b.qty), 0),
+		       CAST(COALESCE(SUM(b.qty * p.harga_beli), 0) AS INTEGER),
+		       COALESCE(SUM(CASE WHEN DATE(b.tanggal_kadaluarsa) < CURRENT_DATE THEN b.qty_tersisa ELSE 0 END), 0),
+		       CAST(COALESCE(SUM(CASE WHEN DATE(b.tanggal_kadaluarsa) < CURRENT_DATE THEN b.qty_tersisa * p.harga_beli ELSE 0 END), 0) AS INTEGER)
+		FROM batch b
+		JOIN supplier s ON s.id = b.supplier_id
+		JOIN produk p ON p.id = b.produk_id
+		WHERE DATE(b.tanggal_restok) BETWEEN DATE(?) AND DATE(?)
+		GROUP BY s.id, s.nama
+	`
+	rows, err := database.Query(penerimaanQuery, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query supplier receipts: %w", err)
+	}
+	for rows.Next() {
+		var l models.LaporanSupplier
+		if err := rows.Scan(&l.SupplierID, &l.NamaSupplier, &l.JumlahBatch, &l.QtyDiterima, &l.NilaiDiterima,
+			&l.QtyKadaluarsa, &l.NilaiKadaluarsa); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan supplier receipts: %w", err)
+		}
+		perSupplier[l.SupplierID] = &l
+	}
+	rows.Close()
+
+	penghapusanQuery := `
+		SELECT s.id, s.nama,
+		       COALESCE(SUM(h.qty), 0),
+		       CAST(COALESCE(SUM(h.qty * p.harga_beli), 0) AS INTEGER)
+		FROM batch_penghapusan h
+		JOIN batch b ON b.id = h.batch_id
+		JOIN supplier s ON s.id = b.supplier_id
+		JOIN produk p ON p.id = h.produk_id
+		WHERE DATE(h.created_at) BETWEEN DATE(?) AND DATE(?)
+		GROUP BY s.id, s.nama
+	`
+	rows, err = database.Query(penghapusanQuery, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query supplier write-offs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var nama string
+		var qty float64
+		var nilai int
+		if err := rows.Scan(&id, &nama, &qty, &nilai); err != nil {
+			return nil, fmt.Errorf("failed to scan supplier write-offs: %w", err)
+		}
+		l, ok := perSupplier[id]
+		if !ok {
+			l = &models.LaporanSupplier{SupplierID: id, NamaSupplier: nama}
+			perSupplier[id] = l
+		}
+		l.QtyDihapus = qty
+		l.NilaiDihapus = nilai
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate supplier write-offs: %w", err)
+	}
+
+	laporan := make([]*models.LaporanSupplier, 0, len(perSupplier))
+	for _, l := range perSupplier {
+		laporan = append(laporan, l)
+	}
+	sort.Slice(laporan, func(i, j int) bool {
+		if laporan[i].NilaiDiterima != laporan[j].NilaiDiterima {
+			return laporan[i].NilaiDiterima > laporan[j].NilaiDiterima
+		}
+		return laporan[i].NamaSupplier < laporan[j].NamaSupplier
+	})
+
+	return laporan, nil
+}
+
+// scanSupplier scans a supplier row selected with supplierColumns
+func scanSupplier(row rowScanner) (*models.Supplier, error) {
+	var s models.Supplier
+	var aktif int
+	var digabungKeID sql.NullInt64
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&s.ID,
+		&s.Nama,
+		&s.Kontak,
+		&s.Alamat,
+		&s.NPWP,
+		&s.TerminHari,
+		&aktif,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&digabungKeID,
+		&deletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan supplier: %w", err)
+	}
+
+	s.Aktif = aktif == 1
+	if digabungKeID.Valid {
+		id := int(digabungKeID.Int64)
+		s.DigabungKeID = &id
+	}
+	if deletedAt.Valid {
+		s.DeletedAt = &deletedAt.Time
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+	"strings"
+	"time"
+)
+
+// TransaksiParkirRepository handles database operations for parked sales
+type TransaksiParkirRepository struct {
+	db *sql.DB
+}
+
+// NewTransaksiParkirRepository creates a new repository instance
+func NewTransaksiParkirRepository() *TransaksiParkirRepository {
+	return &TransaksiParkirRepository{
+		db: database.DB,
+	}
+}
+
+// Create stores a parked sale. When reservations are given, availability is
+// checked against stock minus other active reservations in the same transaction.
+func (r *TransaksiParkirRepository) Create(parkir *models.TransaksiParkir, reservasi []models.TransaksiParkirItem) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	draft, err := json.Marshal(parkir.Draft)
+	if err != nil {
+		return fmt.Errorf("failed to encode parked sale draft: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	for _, item := range reservasi {
+		var nama string
+		var stok float64
+		produkQuery := database.TranslateQuery(`SELECT nama, stok FROM produk WHERE id = ?`)
+		if err := tx.QueryRow(produkQuery, item.ProdukID).Scan(&nama, &stok); err != nil {
+			return fmt.Errorf("failed to get product %d: %w", item.ProdukID, err)
+		}
+
+		reserved, err := reservedStok(tx, item.ProdukID, now)
+		if err != nil {
+			return err
+		}
+
+		if stok-reserved < item.Qty {
+			return fmt.Errorf("stok %s tidak mencukupi untuk ditahan (tersedia: %.2f, diminta: %.2f)",
+				nama, stok-reserved, item.Qty)
+		}
+	}
+
+	var staffID sql.NullInt64
+	if parkir.StaffID != nil {
+		staffID = sql.NullInt64{Int64: int64(*parkir.StaffID), Valid: true}
+	}
+
+	query := database.TranslateQuery(`INSERT INTO transaksi_parkir (
+		label, staff_id, staff_nama, kode_terminal, draft, jumlah_item,
+		reservasi_stok, status, expires_at, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	var id int64
+	err = tx.QueryRow(query,
+		parkir.Label, staffID, parkir.StaffNama, parkir.KodeTerminal, string(draft), parkir.JumlahItem,
+		boolToInt(parkir.ReservasiStok), "parkir", parkir.ExpiresAt, now, now,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to insert parked sale: %w", err)
+	}
+
+	itemQuery := database.TranslateQuery(`INSERT INTO transaksi_parkir_item (parkir_id, produk_id, qty) VALUES (?, ?, ?)`)
+	for _, item := range reservasi {
+		if _, err := tx.Exec(itemQuery, id, item.ProdukID, item.Qty); err != nil {
+			return fmt.Errorf("failed to reserve stock: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	parkir.ID = int(id)
+	parkir.Status = "parkir"
+	parkir.CreatedAt = now
+	parkir.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves a parked sale by ID
+func (r *TransaksiParkirRepository) GetByID(id int) (*models.TransaksiParkir, error) {
+	query := `
+		SELECT id, label, staff_id, staff_nama, kode_terminal, draft, jumlah_item,
+			reservasi_stok, status, expires_at, resumed_at, created_at, updated_at
+		FROM transaksi_parkir
+		WHERE id = ?
+	`
+
+	parkir, err := scanTransaksiParkir(database.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parked sale: %w", err)
+	}
+
+	return parkir, nil
+}
+
+// GetActive retrieves parked sales that have not expired, optionally filtered by staff and terminal
+func (r *TransaksiParkirRepository) GetActive(filter models.TransaksiParkirFilter) ([]*models.TransaksiParkir, error) {
+	conditions := []string{"status = 'parkir'", "expires_at > ?"}
+	args := []interface{}{time.Now()}
+
+	if filter.StaffID > 0 {
+		conditions = append(conditions, "staff_id = ?")
+		args = append(args, filter.StaffID)
+	}
+	if filter.KodeTerminal != "" {
+		conditions = append(conditions, "kode_terminal = ?")
+		args = append(args, filter.KodeTerminal)
+	}
+
+	query := `
+		SELECT id, label, staff_id, staff_nama, kode_terminal, draft, jumlah_item,
+			reservasi_stok, status, expires_at, resumed_at, created_at, updated_at
+		FROM transaksi_parkir
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at ASC
+	`
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parked sales: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*models.TransaksiParkir
+	for rows.Next() {
+		parkir, err := scanTransaksiParkir(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan parked sale: %w", err)
+		}
+		list = append(list, parkir)
+	}
+
+	return list, nil
+}
+
+// UpdateStatus moves an active parked sale to a final status. It returns false
+// when the sale was no longer parked, so it cannot be resumed twice.
+func (r *TransaksiParkirRepository) UpdateStatus(id int, status string) (bool, error) {
+	query := `UPDATE transaksi_parkir SET status = ?, resumed_at = ? WHERE id = ? AND status = 'parkir'`
+
+	var resumedAt interface{}
+	if status == "dilanjutkan" {
+		resumedAt = time.Now()
+	}
+
+	result, err := database.Exec(query, status, resumedAt, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update parked sale status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ExpireOld marks parked sales past their expiry time as expired, releasing their reserved stock
+func (r *TransaksiParkirRepository) ExpireOld() (int64, error) {
+	query := `UPDATE transaksi_parkir SET status = 'kadaluarsa' WHERE status = 'parkir' AND expires_at <= ?`
+
+	result, err := database.Exec(query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire parked sales: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// reservedStok returns the quantity of a product held by active parked sales
+func reservedStok(tx *sql.Tx, produkID int, now time.Time) (float64, error) {
+	query := database.TranslateQuery(`
+		SELECT COALESCE(SUM(i.qty), 0)
+		FROM transaksi_parkir_item i
+		JOIN transaksi_parkir p ON p.id = i.parkir_id
+		WHERE i.produk_id = ? AND p.status = 'parkir' AND p.expires_at > ?
+	`)
+
+	var reserved float64
+	if err := tx.QueryRow(query, produkID, now).Scan(&reserved); err != nil {
+		return 0, fmt.Errorf("failed to get reserved stock: %w", err)
+	}
+
+	return reserved, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransaksiParkir(row rowScanner) (*models.TransaksiParkir, error) {
+	var parkir models.TransaksiParkir
+	var staffID sql.NullInt64
+	var staffNama, kodeTerminal sql.NullString
+	var draft string
+	var reservasiStok int
+	var resumedAt sql.NullTime
+
+	err := row.Scan(
+		&parkir.ID, &parkir.Label, &staffID, &staffNama, &kodeTerminal, &draft, &parkir.JumlahItem,
+		&reservasiStok, &parkir.Status, &parkir.ExpiresAt, &resumedAt, &parkir.CreatedAt, &parkir.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if staffID.Valid {
+		id := int(staffID.Int64)
+		parkir.StaffID = &id
+	}
+	parkir.StaffNama = staffNama.String
+	parkir.KodeTerminal = kodeTerminal.String
+	parkir.ReservasiStok = reservasiStok == 1
+	if resumedAt.Valid {
+		parkir.ResumedAt = &resumedAt.Time
+	}
+
+	if err := json.Unmarshal([]byte(draft), &parkir.Draft); err != nil {
+		return nil, fmt.Errorf("failed to decode parked sale draft: %w", err)
+	}
+
+	return &parkir, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
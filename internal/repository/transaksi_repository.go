@@ -1,10 +1,9 @@
 package repository
 
 import (
-	"crypto/rand"
 	"database/sql"
 	"fmt"
-	"math/big"
+	"math"
 	"time"
 
 	"ritel-app/internal/database"
@@ -12,50 +11,78 @@ import (
 )
 
 type TransaksiRepository struct {
-	db        *sql.DB
-	batchRepo *BatchRepository
+	db           *sql.DB
+	batchRepo    *BatchRepository
+	settingsRepo *SettingsRepository
 }
 
 func NewTransaksiRepository() *TransaksiRepository {
 	return &TransaksiRepository{
-		db:        database.DB,
-		batchRepo: NewBatchRepository(),
+		db:           database.DB,
+		batchRepo:    NewBatchRepository(),
+		settingsRepo: NewSettingsRepository(),
 	}
 }
 
-// GenerateNomorTransaksi generates a unique transaction number with random digits
-func (r *TransaksiRepository) GenerateNomorTransaksi() (string, error) {
-	// Check if database connection is nil
-	if r.db == nil {
-		return "", fmt.Errorf("database connection is not initialized")
-	}
-
-	// Try to generate a unique number (max 10 attempts)
-	for attempt := 0; attempt < 10; attempt++ {
-		// Generate random 8-digit number using crypto/rand for better randomness
-		randomBig, err := rand.Int(rand.Reader, big.NewInt(90000000))
-		if err != nil {
-			return "", fmt.Errorf("failed to generate random number: %w", err)
-		}
-		randomNum := randomBig.Int64() + 10000000 // Generates 10000000-99999999
-		nomorTransaksi := fmt.Sprintf("TRX-%d", randomNum)
+// GenerateNomorTransaksi generates the next sequential transaction number for a
+// terminal, e.g. TRX-T01-20261018-0001. The counter row is incremented inside the
+// caller's transaction, so a rolled-back sale does not leave a gap in the sequence
+// and concurrent sales on the same terminal wait on the same counter row.
+//
+// The counter is keyed on the rendered part of the number before the counter, so
+// changing the prefix, date format or reset period can never issue a number twice.
+// A new counter row continues from the highest counter of the same reset period,
+// which keeps a monthly counter running when the number shows the day.
+func (r *TransaksiRepository) GenerateNomorTransaksi(tx *sql.Tx, settings *models.NomorTransaksiSettings, kodeTerminal string, waktu time.Time) (string, error) {
+	if kodeTerminal == "" {
+		kodeTerminal = settings.KodeTerminal
+	}
+
+	kunci := kunciCounter(settings.Prefix, settings.FormatTanggal, waktu)
+	periode := periodeCounter(settings.ResetCounter, waktu)
+
+	query := database.TranslateQuery(`INSERT INTO transaksi_counter (kode_terminal, periode, periode_reset, nilai, updated_at)
+		VALUES (?, ?, ?, COALESCE((SELECT MAX(nilai) FROM transaksi_counter WHERE kode_terminal = ? AND periode_reset = ?), 0) + 1, ?)
+		ON CONFLICT (kode_terminal, periode) DO UPDATE SET
+			nilai = transaksi_counter.nilai + 1,
+			updated_at = excluded.updated_at
+		RETURNING nilai`)
+
+	var nilai int
+	if err := tx.QueryRow(query, kodeTerminal, kunci, periode, kodeTerminal, periode, waktu).Scan(&nilai); err != nil {
+		return "", fmt.Errorf("failed to increment transaction counter: %w", err)
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%0*d",
+		settings.Prefix, kodeTerminal, formatTanggalNomor(settings.FormatTanggal, waktu), settings.PanjangCounter, nilai), nil
+}
 
-		// Check if this number already exists
-		var exists int
-		query := `SELECT COUNT(*) FROM transaksi WHERE nomor_transaksi = ?`
-		err = database.QueryRow(query, nomorTransaksi).Scan(&exists)
-		if err != nil {
-			return "", fmt.Errorf("failed to check transaction number uniqueness: %w", err)
-		}
+// kunciCounter returns the counter key of a terminal: the prefix and date part
+// rendered in the transaction number
+func kunciCounter(prefix, formatTanggal string, waktu time.Time) string {
+	return prefix + "-" + formatTanggalNomor(formatTanggal, waktu)
+}
 
-		// If number doesn't exist, use it
-		if exists == 0 {
-			return nomorTransaksi, nil
-		}
+// periodeCounter returns the reset period of the counter, so the counter restarts every day or month
+func periodeCounter(resetCounter string, waktu time.Time) string {
+	if resetCounter == models.ResetCounterBulanan {
+		return waktu.Format("200601")
 	}
+	return waktu.Format("20060102")
+}
 
-	// If we couldn't generate a unique number after 10 attempts, return error
-	return "", fmt.Errorf("failed to generate unique transaction number after 10 attempts")
+// formatTanggalNomor renders the date part of a transaction number
+func formatTanggalNomor(formatTanggal string, waktu time.Time) string {
+	switch formatTanggal {
+	case models.FormatTanggalYYMMDD:
+		return waktu.Format("060102")
+	case models.FormatTanggalYYYYMM:
+		return waktu.Format("200601")
+	case models.FormatTanggalYYMM:
+		return waktu.Format("0601")
+	default:
+		return waktu.Format("20060102")
+	}
 }
 
 // Create creates a new transaction with items and payments
@@ -65,6 +92,12 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 		return nil, fmt.Errorf("database connection is not initialized")
 	}
 
+	// Load numbering scheme before the transaction starts
+	nomorSettings, err := r.settingsRepo.GetNomorTransaksiSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction numbering settings: %w", err)
+	}
+
 	// Start transaction
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -72,8 +105,10 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 	}
 	defer tx.Rollback()
 
+	now := time.Now()
+
 	// Generate transaction number
-	nomorTransaksi, err := r.GenerateNomorTransaksi()
+	nomorTransaksi, err := r.GenerateNomorTransaksi(tx, nomorSettings, req.KodeTerminal, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate transaction number: %w", err)
 	}
@@ -89,10 +124,16 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 			// Perhitungan biasa untuk backward compatibility
 			itemSubtotal = item.HargaSatuan * item.Jumlah
 		}
-		subtotal += itemSubtotal
+		subtotal += itemSubtotal - item.DiskonItem - item.DiskonMarkdown
 	}
 
 	total := subtotal - req.Diskon
+	if req.ModePajak == models.ModeHargaEksklusif {
+		// PPN ditambahkan di atas harga jual
+		total += req.TotalPajak
+	}
+	// Pembulatan dihitung service setelah diskon, poin dan PPN
+	total += req.Pembulatan
 
 	// Calculate total payment
 	totalBayar := 0
@@ -112,22 +153,32 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 	// Insert transaksi header
 	query := `INSERT INTO transaksi (
 		nomor_transaksi, pelanggan_id, pelanggan_nama, pelanggan_telp,
-		subtotal, diskon_promo, diskon_pelanggan, poin_ditukar, diskon_poin, diskon, total, total_bayar, kembalian,
-		status, catatan, kasir, staff_id, staff_nama, created_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+		subtotal, diskon_promo, promo_id, diskon_pelanggan, poin_ditukar, diskon_poin, diskon, total, total_bayar, kembalian,
+		status, catatan, kasir, staff_id, staff_nama, mode_pajak, total_dpp, total_pajak, shift_id, pembulatan, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
 	query = database.TranslateQuery(query)
 
-	// Calculate diskon_poin from poin_ditukar (will be set in service layer)
+	// Points discount is calculated in service layer
 	poinDitukar := req.PoinDitukar
-	diskonPoin := 0 // Will be calculated in service layer
+	diskonPoin := req.DiskonPoin
 
-	now := time.Now()
+	// Promo ID is only set when the service validated a promo code
+	var promoID sql.NullInt64
+	if req.PromoID > 0 {
+		promoID = sql.NullInt64{Int64: int64(req.PromoID), Valid: true}
+	}
+
+	// Shift ID links the sale and its payments to the cashier session
+	var shiftID sql.NullInt64
+	if req.ShiftID > 0 {
+		shiftID = sql.NullInt64{Int64: int64(req.ShiftID), Valid: true}
+	}
 
 	var transaksiID int64
 	err = tx.QueryRow(query,
 		nomorTransaksi, req.PelangganID, req.PelangganNama, req.PelangganTelp,
-		subtotal, diskonPromo, diskonPelanggan, poinDitukar, diskonPoin, req.Diskon, total, totalBayar, kembalian,
-		"selesai", req.Catatan, req.Kasir, req.StaffID, req.StaffNama, now,
+		subtotal, diskonPromo, promoID, diskonPelanggan, poinDitukar, diskonPoin, req.Diskon, total, totalBayar, kembalian,
+		"selesai", req.Catatan, req.Kasir, req.StaffID, req.StaffNama, req.ModePajak, req.TotalDPP, req.TotalPajak, shiftID, req.Pembulatan, now,
 	).Scan(&transaksiID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert transaction: %w", err)
@@ -136,20 +187,45 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 	// Insert transaction items
 	itemQuery := `INSERT INTO transaksi_item (
 		transaksi_id, produk_id, produk_sku, produk_nama,
-		produk_kategori, harga_satuan, jumlah, beratgram, subtotal, created_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		produk_kategori, harga_satuan, jumlah, beratgram, subtotal,
+		kode_pajak, tarif_pajak, dpp, pajak,
+		harga_asli, harga_override, diskon_tipe, diskon_nilai, diskon_item,
+		alasan_override, approver_id, approver_nama, satuan, konversi, harga_pokok,
+		diskon_markdown, keterangan_markdown, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
 	itemQuery = database.TranslateQuery(itemQuery)
 
+	// Record which batch rows each item was taken from (needed for void/recall)
+	allocationQuery := database.TranslateQuery(`INSERT INTO transaksi_item_batch (
+		transaksi_id, transaksi_item_id, produk_id, batch_id, qty, created_at
+	) VALUES (?, ?, ?, ?, ?, ?)`)
+
+	// Bill of materials of a kit, read inside the transaction and frozen on the sale
+	komponenQuery := database.TranslateQuery(`SELECT k.komponen_id, p.nama, p.stok, COALESCE(p.harga_beli, 0), k.qty
+		FROM produk_komponen k
+		JOIN produk p ON p.id = k.komponen_id
+		WHERE k.paket_id = ?
+		ORDER BY k.id`)
+	komponenItemQuery := database.TranslateQuery(`INSERT INTO transaksi_item_komponen (
+		transaksi_id, transaksi_item_id, paket_id, komponen_id, komponen_nama, qty_per_paket, qty, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+
 	for _, item := range req.Items {
-		// Get product details
+		// Get product details; harga_beli is the moving-average cost frozen on the item
 		var produk models.Produk
-		productQuery := database.TranslateQuery(`SELECT sku, nama, kategori, stok FROM produk WHERE id = ?`)
+		productQuery := database.TranslateQuery(`SELECT sku, nama, kategori, stok, harga_beli FROM produk WHERE id = ?`)
 		err := tx.QueryRow(productQuery, item.ProdukID).
-			Scan(&produk.SKU, &produk.Nama, &produk.Kategori, &produk.Stok)
+			Scan(&produk.SKU, &produk.Nama, &produk.Kategori, &produk.Stok, &produk.HargaBeli)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get product: %w", err)
 		}
 
+		// Satuan dasar untuk baris tanpa satuan tambahan
+		konversi := item.Konversi
+		if konversi <= 0 {
+			konversi = 1
+		}
+
 		// Calculate stock to deduct FIRST (support berat or quantity)
 		var stockToDeduct float64
 		if item.BeratGram > 0 {
@@ -158,16 +234,36 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 			fmt.Printf("[DEBUG STOCK] Produk: %s, BeratGram: %.2f, StockToDeduct: %.3f kg, Stok Sekarang: %.2f kg\n",
 				produk.Nama, item.BeratGram, stockToDeduct, produk.Stok)
 		} else {
-			// Quantity biasa untuk backward compatibility
-			stockToDeduct = float64(item.Jumlah)
-			fmt.Printf("[DEBUG STOCK] Produk: %s, Quantity: %d, StockToDeduct: %.3f, Stok Sekarang: %.2f kg\n",
-				produk.Nama, item.Jumlah, stockToDeduct, produk.Stok)
+			// Quantity dalam satuan jual, stok dicatat dalam satuan dasar
+			stockToDeduct = float64(item.Jumlah) * konversi
+			fmt.Printf("[DEBUG STOCK] Produk: %s, Quantity: %d x %.3f, StockToDeduct: %.3f, Stok Sekarang: %.2f kg\n",
+				produk.Nama, item.Jumlah, konversi, stockToDeduct, produk.Stok)
+		}
+
+		// A kit holds no stock of its own; its components are deducted instead
+		komponen, err := komponenPaket(tx, komponenQuery, item.ProdukID, stockToDeduct)
+		if err != nil {
+			return nil, err
+		}
+		hargaPokok := produk.HargaBeli
+		keluar := []*stokKeluar{{produkID: item.ProdukID, nama: produk.Nama, stok: produk.Stok, qty: stockToDeduct}}
+		if len(komponen) > 0 {
+			keluar = komponen
+			var pokok float64
+			for _, k := range komponen {
+				pokok += float64(k.hargaBeli) * k.qtyPerPaket
+			}
+			hargaPokok = int(math.Round(pokok))
 		}
 
-		// Check stock availability with correct value
-		if produk.Stok < stockToDeduct {
-			return nil, fmt.Errorf("stok %s tidak mencukupi (tersedia: %.2f kg, diminta: %.2f kg)",
-				produk.Nama, produk.Stok, stockToDeduct)
+		// Check stock availability, reservations and batch policy of everything deducted
+		for _, k := range keluar {
+			if err := cekStokKeluar(tx, k, now); err != nil {
+				if len(komponen) > 0 {
+					return nil, fmt.Errorf("paket %s: %w", produk.Nama, err)
+				}
+				return nil, err
+			}
 		}
 
 		// Calculate item subtotal (support berat or quantity)
@@ -179,74 +275,85 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 			// Perhitungan biasa untuk backward compatibility
 			itemSubtotal = item.HargaSatuan * item.Jumlah
 		}
+		// Diskon baris sudah disetujui service dan markdown dihitung service, subtotal disimpan setelah keduanya
+		itemSubtotal -= item.DiskonItem + item.DiskonMarkdown
+
+		// Harga override hanya dicatat jika kasir mengubah harga jual
+		var hargaOverride, approverID sql.NullInt64
+		if item.HargaAsli > 0 && item.HargaSatuan != item.HargaAsli {
+			hargaOverride = sql.NullInt64{Int64: int64(item.HargaSatuan), Valid: true}
+		}
+		if item.ApproverID > 0 {
+			approverID = sql.NullInt64{Int64: int64(item.ApproverID), Valid: true}
+		}
 
 		// Insert item
-		_, err = tx.Exec(itemQuery,
+		var transaksiItemID int64
+		err = tx.QueryRow(itemQuery,
 			transaksiID, item.ProdukID, produk.SKU, produk.Nama,
 			produk.Kategori, item.HargaSatuan, item.Jumlah, item.BeratGram, itemSubtotal,
-			now,
-		)
+			item.KodePajak, item.TarifPajak, item.DPP, item.Pajak,
+			item.HargaAsli, hargaOverride, item.DiskonTipe, item.DiskonNilai, item.DiskonItem,
+			item.AlasanOverride, approverID, item.ApproverNama, item.Satuan, konversi, hargaPokok,
+			item.DiskonMarkdown, item.KeteranganMarkdown, now,
+		).Scan(&transaksiItemID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert transaction item: %w", err)
 		}
 
-		// Update product stock
-		updateStockQuery := database.TranslateQuery(`UPDATE produk SET stok = stok - ? WHERE id = ?`)
-		_, err = tx.Exec(updateStockQuery, stockToDeduct, item.ProdukID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to update product stock: %w", err)
-		}
-
-		// Update batch quantities using FIFO (deduct from oldest batches first)
-		// Get all available batches for this product (ordered by FIFO)
-		batchQuery := `
-			SELECT id, qty_tersisa FROM batch
-			WHERE produk_id = ? AND qty_tersisa > 0
-			ORDER BY tanggal_restok ASC, created_at ASC
-		`
-		batchQuery = database.TranslateQuery(batchQuery)
-		rows, err := tx.Query(batchQuery, item.ProdukID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get batches: %w", err)
-		}
-		defer rows.Close()
-
-		remainingQty := stockToDeduct
-		for rows.Next() && remainingQty > 0 {
-			var batchID string
-			var qtyTersisa float64
-			if err := rows.Scan(&batchID, &qtyTersisa); err != nil {
-				return nil, fmt.Errorf("failed to scan batch: %w", err)
+		// Update product stock and batch quantities; batch rows of kit components
+		// are recorded against the kit item so void and recall find them
+		for _, k := range keluar {
+			if err := kurangiStokKeluar(tx, allocationQuery, transaksiID, transaksiItemID, k, now); err != nil {
+				return nil, err
 			}
-
-			// Determine how much to take from this batch
-			qtyFromThisBatch := remainingQty
-			if qtyFromThisBatch > qtyTersisa {
-				qtyFromThisBatch = qtyTersisa
+			if len(komponen) == 0 {
+				continue
 			}
-
-			// Update batch quantity
-			updateBatchQuery := database.TranslateQuery(`UPDATE batch SET qty_tersisa = qty_tersisa - ? WHERE id = ?`)
-			_, err = tx.Exec(updateBatchQuery, qtyFromThisBatch, batchID)
+			_, err = tx.Exec(komponenItemQuery, transaksiID, transaksiItemID, item.ProdukID, k.produkID, k.nama, k.qtyPerPaket, k.qty, now)
 			if err != nil {
-				return nil, fmt.Errorf("failed to update batch %s: %w", batchID, err)
+				return nil, fmt.Errorf("failed to record kit component: %w", err)
 			}
-
-			remainingQty -= qtyFromThisBatch
 		}
-		rows.Close()
 	}
 
 	// Insert payments
-	paymentQuery := `INSERT INTO pembayaran (transaksi_id, metode, jumlah, referensi, created_at) VALUES (?, ?, ?, ?, ?)`
+	paymentQuery := `INSERT INTO pembayaran (transaksi_id, metode, jumlah, referensi, shift_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`
 	paymentQuery = database.TranslateQuery(paymentQuery)
 	for _, payment := range req.Pembayaran {
-		_, err = tx.Exec(paymentQuery, transaksiID, payment.Metode, payment.Jumlah, payment.Referensi, now)
+		_, err = tx.Exec(paymentQuery, transaksiID, payment.Metode, payment.Jumlah, payment.Referensi, shiftID, now)
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert payment: %w", err)
 		}
 	}
 
+	// Record the kasbon part of the sale as a receivable of the customer and
+	// redeem gift card payments (the card code is sent as the reference)
+	kasbon := 0
+	for _, payment := range req.Pembayaran {
+		switch payment.Metode {
+		case models.MetodeKasbon:
+			kasbon += payment.Jumlah
+		case models.MetodeKartuHadiah:
+			if err := pakaiKartuHadiah(tx, payment.Referensi, payment.Jumlah, transaksiID, now); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if kasbon > 0 {
+		if err := createPiutang(tx, transaksiID, req.PelangganID, kasbon, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Idempotency key is committed together with the sale, so a retry either
+	// finds the sale or finds nothing
+	if req.IdempotencyKey != "" {
+		if err := simpanIdempotencyKey(tx, "transaksi", req.IdempotencyKey, req.IdempotencyHash, transaksiID, now); err != nil {
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -256,6 +363,398 @@ func (r *TransaksiRepository) Create(req *models.CreateTransaksiRequest) (*model
 	return r.GetByID(int(transaksiID))
 }
 
+// kembalikanPoinPelanggan reverses the points of a voided sale: the reward it earned
+// is taken back and the points redeemed on it are returned. The balance never goes
+// below zero, since earned points may already have been spent.
+func kembalikanPoinPelanggan(tx *sql.Tx, pelangganID, poinReward, poinDitukar int, now time.Time) error {
+	query := database.TranslateQuery(`UPDATE pelanggan
+		SET poin = CASE WHEN poin - ? + ? < 0 THEN 0 ELSE poin - ? + ? END, updated_at = ?
+		WHERE id = ?`)
+
+	if _, err := tx.Exec(query, poinReward, poinDitukar, poinReward, poinDitukar, now, pelangganID); err != nil {
+		return fmt.Errorf("failed to reverse customer points: %w", err)
+	}
+
+	return nil
+}
+
+// stokKeluar is stock leaving a product for one sale line: the product itself,
+// or one component of a kit
+type stokKeluar struct {
+	produkID    int
+	nama        string
+	stok        float64
+	hargaBeli   int
+	qtyPerPaket float64 // Qty komponen per satuan dasar paket; 0 jika bukan komponen
+	qty         float64
+	kebijakan   string
+	lewati      bool
+}
+
+// komponenPaket returns the components of a kit with the quantity deducted for
+// qtyPaket kits, or nothing when the product is not a kit
+func komponenPaket(tx *sql.Tx, query string, paketID int, qtyPaket float64) ([]*stokKeluar, error) {
+	rows, err := tx.Query(query, paketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kit components: %w", err)
+	}
+	defer rows.Close()
+
+	var komponen []*stokKeluar
+	for rows.Next() {
+		k := &stokKeluar{}
+		if err := rows.Scan(&k.produkID, &k.nama, &k.stok, &k.hargaBeli, &k.qtyPerPaket); err != nil {
+			return nil, fmt.Errorf("failed to scan kit component: %w", err)
+		}
+		k.qty = k.qtyPerPaket * qtyPaket
+		komponen = append(komponen, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate kit components: %w", err)
+	}
+
+	return komponen, nil
+}
+
+// cekStokKeluar checks that the stock can be deducted: it must not be held by
+// parked sales or, when the batch policy skips them, lie in expired batches.
+// The batch policy is kept on k for kurangiStokKeluar.
+func cekStokKeluar(tx *sql.Tx, k *stokKeluar, now time.Time) error {
+	// Check stock availability with correct value
+	if k.stok < k.qty {
+		return fmt.Errorf("stok %s tidak mencukupi (tersedia: %.2f kg, diminta: %.2f kg)",
+			k.nama, k.stok, k.qty)
+	}
+
+	// Stock soft-reserved by parked sales is not available for other sales
+	reserved, err := reservedStok(tx, k.produkID, now)
+	if err != nil {
+		return err
+	}
+	if reserved > 0 && k.stok-reserved < k.qty {
+		return fmt.Errorf("stok %s tidak mencukupi, %.2f ditahan untuk transaksi parkir (tersedia: %.2f kg, diminta: %.2f kg)",
+			k.nama, reserved, k.stok-reserved, k.qty)
+	}
+
+	// Batch consumption policy of the product or its category
+	if err := tx.QueryRow(database.TranslateQuery(kebijakanBatchQuery), models.KebijakanBatchFIFO, k.produkID).Scan(&k.kebijakan); err != nil {
+		return fmt.Errorf("failed to get batch policy: %w", err)
+	}
+	k.lewati = k.kebijakan == models.KebijakanBatchFEFOLewatiKadaluarsa
+
+	// Stock in expired batches cannot be sold when the policy skips them
+	if k.lewati {
+		var kadaluarsa float64
+		if err := tx.QueryRow(database.TranslateQuery(qtyKadaluarsaQuery), k.produkID, now.Format("2006-01-02")).Scan(&kadaluarsa); err != nil {
+			return fmt.Errorf("failed to get expired batch stock: %w", err)
+		}
+		if kadaluarsa > 0 && k.stok-reserved-kadaluarsa < k.qty {
+			return fmt.Errorf("stok %s yang belum kadaluarsa tidak mencukupi, %.2f berada di batch kadaluarsa (tersedia: %.2f, diminta: %.2f)",
+				k.nama, kadaluarsa, k.stok-reserved-kadaluarsa, k.qty)
+		}
+	}
+
+	return nil
+}
+
+// kurangiStokKeluar deducts the stock of a product and takes it from its batches
+// in the order of the batch policy (FIFO or FEFO), recording each batch row used
+// with allocationQuery when it is set
+func kurangiStokKeluar(tx *sql.Tx, allocationQuery string, transaksiID, transaksiItemID int64, k *stokKeluar, now time.Time) error {
+	// Update product stock
+	updateStockQuery := database.TranslateQuery(`UPDATE produk SET stok = stok - ? WHERE id = ?`)
+	if _, err := tx.Exec(updateStockQuery, k.qty, k.produkID); err != nil {
+		return fmt.Errorf("failed to update product stock: %w", err)
+	}
+
+	// Update batch quantities in the order of the batch policy (FIFO or FEFO)
+	batchQuery := database.TranslateQuery(batchKonsumsiQuery(`id, qty_tersisa`, k.kebijakan, k.lewati))
+	batchArgs := []interface{}{k.produkID}
+	if k.lewati {
+		batchArgs = append(batchArgs, now.Format("2006-01-02"))
+	}
+	rows, err := tx.Query(batchQuery, batchArgs...)
+	if err != nil {
+		return fmt.Errorf("failed to get batches: %w", err)
+	}
+
+	// Read all candidate batches first so the cursor is closed before updating
+	type batchStock struct {
+		id         string
+		qtyTersisa float64
+	}
+	var batches []batchStock
+	for rows.Next() {
+		var b batchStock
+		if err := rows.Scan(&b.id, &b.qtyTersisa); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan batch: %w", err)
+		}
+		batches = append(batches, b)
+	}
+	rows.Close()
+
+	remainingQty := k.qty
+	for _, b := range batches {
+		if remainingQty <= 0 {
+			break
+		}
+
+		// Determine how much to take from this batch
+		qtyFromThisBatch := remainingQty
+		if qtyFromThisBatch > b.qtyTersisa {
+			qtyFromThisBatch = b.qtyTersisa
+		}
+
+		// Update batch quantity
+		updateBatchQuery := database.TranslateQuery(`UPDATE batch SET qty_tersisa = qty_tersisa - ? WHERE id = ?`)
+		if _, err := tx.Exec(updateBatchQuery, qtyFromThisBatch, b.id); err != nil {
+			return fmt.Errorf("failed to update batch %s: %w", b.id, err)
+		}
+
+		if allocationQuery != "" {
+			if _, err := tx.Exec(allocationQuery, transaksiID, transaksiItemID, k.produkID, b.id, qtyFromThisBatch, now); err != nil {
+				return fmt.Errorf("failed to record batch allocation: %w", err)
+			}
+		}
+
+		remainingQty -= qtyFromThisBatch
+	}
+
+	return nil
+}
+
+// Void cancels a completed transaction. Product stock and the exact batch rows
+// deducted at sale time are restored (quantity of recalled batches is written off
+// instead), the customer's points are reversed and the transaction is marked as
+// void, all in one database transaction. minTransaksiPoin
+// is the spend per reward point, used to take back the points the sale earned.
+// voidShiftID is the cashier shift the void is done in (0 when none is open);
+// the shift report counts the void there rather than in the shift of the sale.
+func (r *TransaksiRepository) Void(transaksiID int, alasan string, approver *models.User, minTransaksiPoin, voidShiftID int) error {
+	// Check if database connection is nil
+	if r.db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only completed sales without returns can be voided
+	var status, nomorTransaksi string
+	var pelangganID, total, poinDitukar int
+	statusQuery := database.TranslateQuery(`SELECT status, nomor_transaksi, COALESCE(pelanggan_id, 0), total, COALESCE(poin_ditukar, 0)
+		FROM transaksi WHERE id = ?`)
+	err = tx.QueryRow(statusQuery, transaksiID).Scan(&status, &nomorTransaksi, &pelangganID, &total, &poinDitukar)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("transaksi tidak ditemukan")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if status == "void" {
+		return fmt.Errorf("transaksi %s sudah dibatalkan", nomorTransaksi)
+	}
+	if status != "selesai" {
+		return fmt.Errorf("transaksi %s berstatus %s dan tidak dapat dibatalkan", nomorTransaksi, status)
+	}
+
+	// Mark the transaction as void first, conditional on its status. The update locks
+	// the row, so of two concurrent voids only one gets to restore stock and balances.
+	now := time.Now()
+	var shiftID sql.NullInt64
+	if voidShiftID > 0 {
+		shiftID = sql.NullInt64{Int64: int64(voidShiftID), Valid: true}
+	}
+	voidQuery := database.TranslateQuery(`UPDATE transaksi
+		SET status = 'void', void_alasan = ?, void_oleh_id = ?, void_oleh_nama = ?, void_at = ?, void_shift_id = ?
+		WHERE id = ? AND status = 'selesai'`)
+	result, err := tx.Exec(voidQuery, alasan, approver.ID, approver.NamaLengkap, now, shiftID, transaksiID)
+	if err != nil {
+		return fmt.Errorf("failed to mark transaction as void: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected != 1 {
+		return fmt.Errorf("transaksi %s sudah dibatalkan atau diubah oleh proses lain", nomorTransaksi)
+	}
+
+	// Get sold items
+	itemQuery := database.TranslateQuery(`SELECT id, produk_id, produk_nama, jumlah, beratgram, COALESCE(konversi, 1)
+		FROM transaksi_item WHERE transaksi_id = ?`)
+	rows, err := tx.Query(itemQuery, transaksiID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction items: %w", err)
+	}
+
+	type soldItem struct {
+		id         int
+		produkID   sql.NullInt64
+		produkNama string
+		jumlah     int
+		beratGram  float64
+		konversi   float64
+		qty        float64 // Stok yang dikembalikan dalam satuan dasar
+	}
+	var items []soldItem
+	for rows.Next() {
+		var it soldItem
+		if err := rows.Scan(&it.id, &it.produkID, &it.produkNama, &it.jumlah, &it.beratGram, &it.konversi); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan transaction item: %w", err)
+		}
+		it.qty = float64(it.jumlah) * it.konversi
+		if it.beratGram > 0 {
+			it.qty = it.beratGram / 1000.0
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+
+	// A kit sold is restored as the component quantities deducted at sale time
+	komponenQuery := database.TranslateQuery(`SELECT transaksi_item_id, komponen_id, komponen_nama, qty
+		FROM transaksi_item_komponen WHERE transaksi_id = ? ORDER BY id`)
+	komponenRows, err := tx.Query(komponenQuery, transaksiID)
+	if err != nil {
+		return fmt.Errorf("failed to get kit components: %w", err)
+	}
+	komponenPerItem := make(map[int][]soldItem)
+	for komponenRows.Next() {
+		var itemID int
+		var it soldItem
+		if err := komponenRows.Scan(&itemID, &it.produkID, &it.produkNama, &it.qty); err != nil {
+			komponenRows.Close()
+			return fmt.Errorf("failed to scan kit component: %w", err)
+		}
+		komponenPerItem[itemID] = append(komponenPerItem[itemID], it)
+	}
+	komponenRows.Close()
+
+	var restore []soldItem
+	for _, it := range items {
+		if komponen, ok := komponenPerItem[it.id]; ok {
+			restore = append(restore, komponen...)
+			continue
+		}
+		restore = append(restore, it)
+	}
+
+	keterangan := fmt.Sprintf("Void transaksi %s: %s", nomorTransaksi, alasan)
+
+	// Restore product stock
+	stokQuery := database.TranslateQuery(`SELECT stok FROM produk WHERE id = ?`)
+	updateStockQuery := database.TranslateQuery(`UPDATE produk SET stok = stok + ? WHERE id = ?`)
+	historyQuery := database.TranslateQuery(`INSERT INTO stok_history (
+		produk_id, stok_sebelum, stok_sesudah, perubahan, jenis_perubahan, keterangan, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	for _, it := range restore {
+		if !it.produkID.Valid {
+			// Product was hard-deleted, nothing to restore
+			continue
+		}
+		qty := it.qty
+
+		var stokSebelum float64
+		if err := tx.QueryRow(stokQuery, it.produkID.Int64).Scan(&stokSebelum); err != nil {
+			return fmt.Errorf("failed to get stock of %s: %w", it.produkNama, err)
+		}
+
+		if _, err := tx.Exec(updateStockQuery, qty, it.produkID.Int64); err != nil {
+			return fmt.Errorf("failed to restore stock of %s: %w", it.produkNama, err)
+		}
+
+		if _, err := tx.Exec(historyQuery, it.produkID.Int64, stokSebelum, stokSebelum+qty, qty, "void", keterangan, now); err != nil {
+			return fmt.Errorf("failed to create stock history: %w", err)
+		}
+	}
+
+	// Put quantity back into the exact batch rows recorded at sale time
+	allocQuery := database.TranslateQuery(`SELECT a.batch_id, MAX(a.produk_id), SUM(a.qty),
+			MAX(CASE WHEN b.ditarik_at IS NULL THEN 0 ELSE 1 END)
+		FROM transaksi_item_batch a
+		LEFT JOIN batch b ON b.id = a.batch_id
+		WHERE a.transaksi_id = ? GROUP BY a.batch_id`)
+	allocRows, err := tx.Query(allocQuery, transaksiID)
+	if err != nil {
+		return fmt.Errorf("failed to get batch allocations: %w", err)
+	}
+
+	type alokasiBatch struct {
+		produkID int
+		qty      float64
+		ditarik  bool
+	}
+	allocations := make(map[string]alokasiBatch)
+	for allocRows.Next() {
+		var batchID string
+		var a alokasiBatch
+		var ditarik int
+		if err := allocRows.Scan(&batchID, &a.produkID, &a.qty, &ditarik); err != nil {
+			allocRows.Close()
+			return fmt.Errorf("failed to scan batch allocation: %w", err)
+		}
+		a.ditarik = ditarik == 1
+		allocations[batchID] = a
+	}
+	allocRows.Close()
+
+	if len(allocations) == 0 && len(items) > 0 {
+		// Transactions created before allocations were recorded
+		fmt.Printf("[WARNING] Transaksi %s has no batch allocations, batch quantities not restored\n", nomorTransaksi)
+	}
+
+	updateBatchQuery := database.TranslateQuery(`UPDATE batch SET qty_tersisa = qty_tersisa + ? WHERE id = ?`)
+	for batchID, a := range allocations {
+		// Recalled stock must not be sold again; it is written off like a return from a recalled batch
+		if a.ditarik {
+			if _, err := hapusStokBatch(tx, batchID, a.produkID, a.qty, models.TipeKerugianDitarik, "Void dari batch yang ditarik", now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Batch may have been deleted in the meantime; product stock is already restored
+		if _, err := tx.Exec(updateBatchQuery, a.qty, batchID); err != nil {
+			return fmt.Errorf("failed to restore batch %s: %w", batchID, err)
+		}
+	}
+
+	// Cancel the receivable of a credit sale
+	if err := batalkanPiutang(tx, transaksiID, nomorTransaksi); err != nil {
+		return err
+	}
+
+	// Cancel a gift card sold by this sale and restore redeemed gift card balances
+	if err := batalkanKartuHadiah(tx, transaksiID, nomorTransaksi, now); err != nil {
+		return err
+	}
+	if err := kembalikanKartuHadiah(tx, transaksiID, nomorTransaksi, now); err != nil {
+		return err
+	}
+
+	// Take back the reward points earned by the sale and give back the redeemed points
+	if pelangganID > 0 {
+		poinReward := 0
+		if minTransaksiPoin > 0 && total >= minTransaksiPoin {
+			poinReward = total / minTransaksiPoin
+		}
+		if err := kembalikanPoinPelanggan(tx, pelangganID, poinReward, poinDitukar, now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // GetByID retrieves a complete transaction by ID
 func (r *TransaksiRepository) GetByNomorTransaksi(nomorTransaksi string) (*models.TransaksiDetail, error) {
 	// Check if database connection is nil
@@ -267,17 +766,24 @@ func (r *TransaksiRepository) GetByNomorTransaksi(nomorTransaksi string) (*model
 	query := `SELECT
 		id, nomor_transaksi, tanggal, pelanggan_id, pelanggan_nama, pelanggan_telp,
 		subtotal, diskon_promo, diskon_pelanggan, poin_ditukar, diskon_poin, diskon, total, total_bayar, kembalian,
-		status, catatan, kasir, created_at
+		status, catatan, kasir, void_alasan, void_oleh_nama, void_at, promo_id,
+		COALESCE(mode_pajak, ''), COALESCE(total_dpp, 0), COALESCE(total_pajak, 0), shift_id,
+		COALESCE(pembulatan, 0), created_at
 	FROM transaksi WHERE nomor_transaksi = ?`
 
 	transaksi := &models.Transaksi{}
+	var voidAlasan, voidOlehNama sql.NullString
+	var voidAt sql.NullTime
+	var promoID, shiftID sql.NullInt64
 	err := database.QueryRow(query, nomorTransaksi).Scan(
 		&transaksi.ID, &transaksi.NomorTransaksi, &transaksi.Tanggal,
 		&transaksi.PelangganID, &transaksi.PelangganNama, &transaksi.PelangganTelp,
 		&transaksi.Subtotal, &transaksi.DiskonPromo, &transaksi.DiskonPelanggan, &transaksi.PoinDitukar, &transaksi.DiskonPoin, &transaksi.Diskon, &transaksi.Total,
 		&transaksi.TotalBayar, &transaksi.Kembalian,
 		&transaksi.Status, &transaksi.Catatan, &transaksi.Kasir,
-		&transaksi.CreatedAt,
+		&voidAlasan, &voidOlehNama, &voidAt, &promoID,
+		&transaksi.ModePajak, &transaksi.TotalDPP, &transaksi.TotalPajak, &shiftID,
+		&transaksi.Pembulatan, &transaksi.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -286,10 +792,29 @@ func (r *TransaksiRepository) GetByNomorTransaksi(nomorTransaksi string) (*model
 		return nil, fmt.Errorf("failed to get transaction: %w", err)
 	}
 
+	transaksi.VoidAlasan = voidAlasan.String
+	transaksi.VoidOlehNama = voidOlehNama.String
+	if voidAt.Valid {
+		transaksi.VoidAt = &voidAt.Time
+	}
+	if promoID.Valid {
+		pid := int(promoID.Int64)
+		transaksi.PromoID = &pid
+	}
+	if shiftID.Valid {
+		sid := int(shiftID.Int64)
+		transaksi.ShiftID = &sid
+	}
+
 	// Get transaction items
 	itemQuery := `SELECT
 		id, transaksi_id, produk_id, produk_sku, produk_nama,
-		produk_kategori, harga_satuan, jumlah, beratgram, subtotal, created_at
+		produk_kategori, harga_satuan, jumlah, beratgram, subtotal,
+		COALESCE(kode_pajak, ''), COALESCE(tarif_pajak, 0), COALESCE(dpp, 0), COALESCE(pajak, 0),
+		COALESCE(harga_asli, 0), harga_override, COALESCE(diskon_tipe, ''), COALESCE(diskon_nilai, 0),
+		COALESCE(diskon_item, 0), COALESCE(alasan_override, ''), approver_id, COALESCE(approver_nama, ''),
+		COALESCE(satuan, ''), COALESCE(konversi, 1), COALESCE(harga_pokok, 0),
+		COALESCE(diskon_markdown, 0), COALESCE(keterangan_markdown, ''), created_at
 	FROM transaksi_item WHERE transaksi_id = ?`
 
 	rows, err := database.Query(itemQuery, transaksi.ID)
@@ -301,11 +826,16 @@ func (r *TransaksiRepository) GetByNomorTransaksi(nomorTransaksi string) (*model
 	var items []*models.TransaksiItem
 	for rows.Next() {
 		item := &models.TransaksiItem{}
-		var produkID sql.NullInt64
+		var produkID, hargaOverride, approverID sql.NullInt64
 		err := rows.Scan(
 			&item.ID, &item.TransaksiID, &produkID, &item.ProdukSKU,
 			&item.ProdukNama, &item.ProdukKategori, &item.HargaSatuan,
-			&item.Jumlah, &item.BeratGram, &item.Subtotal, &item.CreatedAt,
+			&item.Jumlah, &item.BeratGram, &item.Subtotal,
+			&item.KodePajak, &item.TarifPajak, &item.DPP, &item.Pajak,
+			&item.HargaAsli, &hargaOverride, &item.DiskonTipe, &item.DiskonNilai,
+			&item.DiskonItem, &item.AlasanOverride, &approverID, &item.ApproverNama,
+			&item.Satuan, &item.Konversi, &item.HargaPokok,
+			&item.DiskonMarkdown, &item.KeteranganMarkdown, &item.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction item: %w", err)
@@ -318,13 +848,21 @@ func (r *TransaksiRepository) GetByNomorTransaksi(nomorTransaksi string) (*model
 		} else {
 			item.ProdukID = nil
 		}
+		if hargaOverride.Valid {
+			harga := int(hargaOverride.Int64)
+			item.HargaOverride = &harga
+		}
+		if approverID.Valid {
+			aid := int(approverID.Int64)
+			item.ApproverID = &aid
+		}
 
 		items = append(items, item)
 	}
 
 	// Get payments
 	paymentQuery := `SELECT id, transaksi_id, metode, jumlah, referensi, created_at
-		FROM pembayaran WHERE transaksi_id = ?`
+		FROM pembayaran WHERE transaksi_id = ? AND piutang_id IS NULL`
 
 	paymentRows, err := database.Query(paymentQuery, transaksi.ID)
 	if err != nil {
@@ -364,27 +902,53 @@ func (r *TransaksiRepository) GetByID(id int) (*models.TransaksiDetail, error) {
 	query := `SELECT
 		id, nomor_transaksi, tanggal, pelanggan_id, pelanggan_nama, pelanggan_telp,
 		subtotal, diskon_promo, diskon_pelanggan, poin_ditukar, diskon_poin, diskon, total, total_bayar, kembalian,
-		status, catatan, kasir, created_at
+		status, catatan, kasir, void_alasan, void_oleh_nama, void_at, promo_id,
+		COALESCE(mode_pajak, ''), COALESCE(total_dpp, 0), COALESCE(total_pajak, 0), shift_id,
+		COALESCE(pembulatan, 0), created_at
 	FROM transaksi WHERE id = ?`
 
 	transaksi := &models.Transaksi{}
+	var voidAlasan, voidOlehNama sql.NullString
+	var voidAt sql.NullTime
+	var promoID, shiftID sql.NullInt64
 	err := database.QueryRow(query, id).Scan(
 		&transaksi.ID, &transaksi.NomorTransaksi, &transaksi.Tanggal,
 		&transaksi.PelangganID, &transaksi.PelangganNama, &transaksi.PelangganTelp,
 		&transaksi.Subtotal, &transaksi.DiskonPromo, &transaksi.DiskonPelanggan, &transaksi.PoinDitukar, &transaksi.DiskonPoin, &transaksi.Diskon, &transaksi.Total,
 		&transaksi.TotalBayar, &transaksi.Kembalian,
 		&transaksi.Status, &transaksi.Catatan, &transaksi.Kasir,
-		&transaksi.CreatedAt,
+		&voidAlasan, &voidOlehNama, &voidAt, &promoID,
+		&transaksi.ModePajak, &transaksi.TotalDPP, &transaksi.TotalPajak, &shiftID,
+		&transaksi.Pembulatan, &transaksi.CreatedAt,
 	)
 	if err != nil {
 		fmt.Printf("[ERROR] Failed to get transaction header: %v\n", err)
 		return nil, fmt.Errorf("failed to get transaction: %w", err)
 	}
 
+	transaksi.VoidAlasan = voidAlasan.String
+	transaksi.VoidOlehNama = voidOlehNama.String
+	if voidAt.Valid {
+		transaksi.VoidAt = &voidAt.Time
+	}
+	if promoID.Valid {
+		pid := int(promoID.Int64)
+		transaksi.PromoID = &pid
+	}
+	if shiftID.Valid {
+		sid := int(shiftID.Int64)
+		transaksi.ShiftID = &sid
+	}
+
 	// Get transaction items
 	itemQuery := `SELECT
 		id, transaksi_id, produk_id, produk_sku, produk_nama,
-		produk_kategori, harga_satuan, jumlah, beratgram, subtotal, created_at
+		produk_kategori, harga_satuan, jumlah, beratgram, subtotal,
+		COALESCE(kode_pajak, ''), COALESCE(tarif_pajak, 0), COALESCE(dpp, 0), COALESCE(pajak, 0),
+		COALESCE(harga_asli, 0), harga_override, COALESCE(diskon_tipe, ''), COALESCE(diskon_nilai, 0),
+		COALESCE(diskon_item, 0), COALESCE(alasan_override, ''), approver_id, COALESCE(approver_nama, ''),
+		COALESCE(satuan, ''), COALESCE(konversi, 1), COALESCE(harga_pokok, 0),
+		COALESCE(diskon_markdown, 0), COALESCE(keterangan_markdown, ''), created_at
 	FROM transaksi_item WHERE transaksi_id = ?`
 
 	rows, err := database.Query(itemQuery, id)
@@ -398,11 +962,16 @@ func (r *TransaksiRepository) GetByID(id int) (*models.TransaksiDetail, error) {
 	itemCount := 0
 	for rows.Next() {
 		item := &models.TransaksiItem{}
-		var produkID sql.NullInt64
+		var produkID, hargaOverride, approverID sql.NullInt64
 		err := rows.Scan(
 			&item.ID, &item.TransaksiID, &produkID, &item.ProdukSKU,
 			&item.ProdukNama, &item.ProdukKategori, &item.HargaSatuan,
-			&item.Jumlah, &item.BeratGram, &item.Subtotal, &item.CreatedAt,
+			&item.Jumlah, &item.BeratGram, &item.Subtotal,
+			&item.KodePajak, &item.TarifPajak, &item.DPP, &item.Pajak,
+			&item.HargaAsli, &hargaOverride, &item.DiskonTipe, &item.DiskonNilai,
+			&item.DiskonItem, &item.AlasanOverride, &approverID, &item.ApproverNama,
+			&item.Satuan, &item.Konversi, &item.HargaPokok,
+			&item.DiskonMarkdown, &item.KeteranganMarkdown, &item.CreatedAt,
 		)
 		if err != nil {
 			fmt.Printf("[ERROR] Failed to scan transaction item: %v\n", err)
@@ -418,6 +987,14 @@ func (r *TransaksiRepository) GetByID(id int) (*models.TransaksiDetail, error) {
 			item.ProdukID = nil
 
 		}
+		if hargaOverride.Valid {
+			harga := int(hargaOverride.Int64)
+			item.HargaOverride = &harga
+		}
+		if approverID.Valid {
+			aid := int(approverID.Int64)
+			item.ApproverID = &aid
+		}
 
 		items = append(items, item)
 		itemCount++
@@ -425,7 +1002,7 @@ func (r *TransaksiRepository) GetByID(id int) (*models.TransaksiDetail, error) {
 
 	// Get payments
 	paymentQuery := `SELECT id, transaksi_id, metode, jumlah, referensi, created_at
-		FROM pembayaran WHERE transaksi_id = ?`
+		FROM pembayaran WHERE transaksi_id = ? AND piutang_id IS NULL`
 
 	paymentRows, err := database.Query(paymentQuery, id)
 	if err != nil {
@@ -470,7 +1047,7 @@ func (r *TransaksiRepository) GetAll(limit, offset int) ([]*models.Transaksi, er
 	query := `SELECT
 		id, nomor_transaksi, tanggal, pelanggan_id, pelanggan_nama, pelanggan_telp,
 		subtotal, diskon_promo, diskon_pelanggan, poin_ditukar, diskon_poin, diskon, total, total_bayar, kembalian,
-		status, catatan, kasir, created_at
+		status, catatan, kasir, COALESCE(mode_pajak, ''), COALESCE(total_dpp, 0), COALESCE(total_pajak, 0), COALESCE(pembulatan, 0), created_at
 	FROM transaksi
 	ORDER BY tanggal DESC
 	LIMIT ? OFFSET ?`
@@ -491,6 +1068,7 @@ func (r *TransaksiRepository) GetAll(limit, offset int) ([]*models.Transaksi, er
 			&t.Subtotal, &t.DiskonPromo, &t.DiskonPelanggan, &t.PoinDitukar, &t.DiskonPoin, &t.Diskon, &t.Total,
 			&t.TotalBayar, &t.Kembalian,
 			&t.Status, &catatan, &kasir,
+			&t.ModePajak, &t.TotalDPP, &t.TotalPajak, &t.Pembulatan,
 			&t.CreatedAt,
 		)
 		if err != nil {
@@ -519,20 +1097,34 @@ func (r *TransaksiRepository) GetAll(limit, offset int) ([]*models.Transaksi, er
 	return transaksis, nil
 }
 
-// GetByDateRange retrieves transactions within a date range
+// GetByDateRange retrieves transactions within a date range (including voided ones)
 func (r *TransaksiRepository) GetByDateRange(startDate, endDate time.Time) ([]*models.Transaksi, error) {
+	return r.getByDateRange(startDate, endDate, false)
+}
+
+// GetSalesByDateRange retrieves transactions within a date range that count as sales (voided ones and gift card sales excluded)
+func (r *TransaksiRepository) GetSalesByDateRange(startDate, endDate time.Time) ([]*models.Transaksi, error) {
+	return r.getByDateRange(startDate, endDate, true)
+}
+
+func (r *TransaksiRepository) getByDateRange(startDate, endDate time.Time, penjualanSaja bool) ([]*models.Transaksi, error) {
 	// Check if database connection is nil
 	if r.db == nil {
 		return []*models.Transaksi{}, nil
 	}
 
+	statusFilter := ""
+	if penjualanSaja {
+		statusFilter = " AND status != 'void' AND jenis = 'penjualan'"
+	}
+
 	// Use the 'created_at' column for time-based filtering
 	query := `SELECT
 		id, nomor_transaksi, tanggal, pelanggan_id, pelanggan_nama, pelanggan_telp,
 		subtotal, diskon_promo, diskon_pelanggan, poin_ditukar, diskon_poin, diskon, total, total_bayar, kembalian,
-		status, catatan, kasir, created_at
+		status, catatan, kasir, COALESCE(mode_pajak, ''), COALESCE(total_dpp, 0), COALESCE(total_pajak, 0), COALESCE(pembulatan, 0), created_at
 	FROM transaksi
-	WHERE created_at >= ? AND created_at < ?
+	WHERE created_at >= ? AND created_at < ?` + statusFilter + `
 	ORDER BY created_at DESC`
 
 	rows, err := database.Query(query, startDate, endDate)
@@ -551,6 +1143,7 @@ func (r *TransaksiRepository) GetByDateRange(startDate, endDate time.Time) ([]*m
 			&t.Subtotal, &t.DiskonPromo, &t.DiskonPelanggan, &t.PoinDitukar, &t.DiskonPoin, &t.Diskon, &t.Total,
 			&t.TotalBayar, &t.Kembalian,
 			&t.Status, &catatan, &kasir,
+			&t.ModePajak, &t.TotalDPP, &t.TotalPajak, &t.Pembulatan,
 			&t.CreatedAt,
 		)
 		if err != nil {
@@ -591,7 +1184,7 @@ func (r *TransaksiRepository) GetTodayStats() (totalTransaksi int, totalPendapat
 		COALESCE(SUM(total), 0) as total_pendapatan,
 		COALESCE(SUM((SELECT SUM(jumlah) FROM transaksi_item WHERE transaksi_id = transaksi.id)), 0) as total_item
 	FROM transaksi
-	WHERE DATE(tanggal) = CURRENT_DATE`
+	WHERE DATE(tanggal) = CURRENT_DATE AND status != 'void' AND jenis = 'penjualan'`
 
 	err = database.QueryRow(query).Scan(&totalTransaksi, &totalPendapatan, &totalItem)
 	return
@@ -606,7 +1199,7 @@ func (r *TransaksiRepository) GetByPelangganID(pelangganID int) ([]*models.Trans
 	query := `SELECT
         id, nomor_transaksi, tanggal, pelanggan_id, pelanggan_nama, pelanggan_telp,
         subtotal, diskon_promo, diskon_pelanggan, poin_ditukar, diskon_poin, diskon, total, total_bayar, kembalian,
-        status, catatan, kasir, created_at
+        status, catatan, kasir, COALESCE(mode_pajak, ''), COALESCE(total_dpp, 0), COALESCE(total_pajak, 0), COALESCE(pembulatan, 0), created_at
     FROM transaksi
     WHERE pelanggan_id = ?
     ORDER BY tanggal DESC`
@@ -627,6 +1220,7 @@ func (r *TransaksiRepository) GetByPelangganID(pelangganID int) ([]*models.Trans
 			&t.Subtotal, &t.DiskonPromo, &t.DiskonPelanggan, &t.PoinDitukar, &t.DiskonPoin, &t.Diskon, &t.Total,
 			&t.TotalBayar, &t.Kembalian,
 			&t.Status, &catatan, &kasir,
+			&t.ModePajak, &t.TotalDPP, &t.TotalPajak, &t.Pembulatan,
 			&t.CreatedAt,
 		)
 		if err != nil {
@@ -673,7 +1267,7 @@ func (r *TransaksiRepository) GetStatsByPelangganID(pelangganID int) (*models.Pe
             ELSE 0 
         END as rata_rata_belanja
     FROM transaksi 
-    WHERE pelanggan_id = ? AND status = 'selesai'`
+    WHERE pelanggan_id = ? AND status = 'selesai' AND jenis = 'penjualan'`
 
 	var stats models.PelangganStats
 	err := database.QueryRow(query, pelangganID).Scan(
@@ -698,6 +1292,7 @@ func (r *TransaksiRepository) GetByStaffIDAndDateRange(staffID int, startDate, e
 		       staff_id, staff_nama, created_at
 		FROM transaksi
 		WHERE staff_id = ? AND DATE(tanggal) >= DATE(?) AND DATE(tanggal) <= DATE(?)
+		  AND status != 'void' AND jenis = 'penjualan'
 		ORDER BY tanggal DESC
 	`
 
@@ -768,6 +1363,7 @@ func (r *TransaksiRepository) GetDailyBreakdownByStaffID(staffID int, startDate,
 		       SUM(total) as total_penjualan
 		FROM transaksi
 		WHERE staff_id = ? AND DATE(tanggal) >= DATE(?) AND DATE(tanggal) <= DATE(?)
+		  AND status != 'void' AND jenis = 'penjualan'
 		GROUP BY DATE(tanggal)
 		ORDER BY DATE(tanggal) ASC
 	`
@@ -803,6 +1399,58 @@ func (r *TransaksiRepository) GetDailyBreakdownByStaffID(staffID int, startDate,
 	return dailyMap, nil
 }
 
+// GetOverrideHargaByDateRange retrieves sold items with a manual price or line discount within date range
+func (r *TransaksiRepository) GetOverrideHargaByDateRange(startDate, endDate time.Time) ([]*models.OverrideHargaItem, error) {
+	query := `
+		SELECT t.id, t.nomor_transaksi, t.tanggal, COALESCE(t.staff_id, 0), COALESCE(t.staff_nama, ''),
+		       ti.produk_nama, COALESCE(ti.harga_asli, 0), ti.harga_satuan, ti.harga_override,
+		       ti.jumlah, ti.beratgram, COALESCE(ti.diskon_tipe, ''), COALESCE(ti.diskon_nilai, 0),
+		       COALESCE(ti.diskon_item, 0), ti.subtotal, COALESCE(ti.alasan_override, ''),
+		       ti.approver_id, COALESCE(ti.approver_nama, '')
+		FROM transaksi_item ti
+		JOIN transaksi t ON ti.transaksi_id = t.id
+		WHERE (ti.harga_override IS NOT NULL OR COALESCE(ti.diskon_item, 0) > 0)
+		  AND DATE(t.tanggal) >= DATE(?) AND DATE(t.tanggal) <= DATE(?)
+		  AND t.status != 'void'
+		ORDER BY t.staff_nama ASC, t.tanggal ASC
+	`
+
+	rows, err := database.Query(query, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.OverrideHargaItem
+	for rows.Next() {
+		item := &models.OverrideHargaItem{}
+		var hargaOverride, approverID sql.NullInt64
+		err := rows.Scan(
+			&item.TransaksiID, &item.NomorTransaksi, &item.Tanggal, &item.StaffID, &item.StaffNama,
+			&item.ProdukNama, &item.HargaAsli, &item.HargaSatuan, &hargaOverride,
+			&item.Jumlah, &item.BeratGram, &item.DiskonTipe, &item.DiskonNilai,
+			&item.DiskonItem, &item.Subtotal, &item.AlasanOverride,
+			&approverID, &item.ApproverNama,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price override: %w", err)
+		}
+
+		if hargaOverride.Valid {
+			harga := int(hargaOverride.Int64)
+			item.HargaOverride = &harga
+		}
+		if approverID.Valid {
+			aid := int(approverID.Int64)
+			item.ApproverID = &aid
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
 // GetTopProductLast30Days gets the most sold product in the last 30 days
 func (r *TransaksiRepository) GetTopProductLast30Days() (string, error) {
 	query := `
@@ -810,6 +1458,7 @@ func (r *TransaksiRepository) GetTopProductLast30Days() (string, error) {
 		FROM transaksi_item ti
 		JOIN transaksi t ON ti.transaksi_id = t.id
 		WHERE t.tanggal >= CURRENT_DATE - INTERVAL '30 days'
+		  AND t.status != 'void' AND t.jenis = 'penjualan'
 		GROUP BY ti.produk_nama
 		ORDER BY total_terjual DESC
 		LIMIT 1
@@ -827,6 +1476,76 @@ func (r *TransaksiRepository) GetTopProductLast30Days() (string, error) {
 	return produkNama, nil
 }
 
+// GetQtyTerjualPerProduk returns per product the quantity sold since startDate in
+// base units: weighed items in kg, other items multiplied by their unit conversion.
+// Components deducted for kits count as sold for the component as well.
+func (r *TransaksiRepository) GetQtyTerjualPerProduk(startDate time.Time) (map[int]float64, error) {
+	query := `
+		SELECT produk_id, SUM(qty)
+		FROM (
+			SELECT ti.produk_id AS produk_id,
+			       CASE WHEN ti.beratgram > 0 THEN ti.beratgram / 1000.0
+			            ELSE ti.jumlah * COALESCE(ti.konversi, 1) END AS qty
+			FROM transaksi_item ti
+			JOIN transaksi t ON ti.transaksi_id = t.id
+			WHERE t.tanggal >= ? AND t.status != 'void' AND ti.produk_id IS NOT NULL
+			UNION ALL
+			SELECT tk.komponen_id AS produk_id, tk.qty AS qty
+			FROM transaksi_item_komponen tk
+			JOIN transaksi t ON tk.transaksi_id = t.id
+			WHERE t.tanggal >= ? AND t.status != 'void' AND tk.komponen_id IS NOT NULL
+		) terjual
+		GROUP BY produk_id
+	`
+
+	rows, err := database.Query(query, startDate, startDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quantity sold per product: %w", err)
+	}
+	defer rows.Close()
+
+	terjual := make(map[int]float64)
+	for rows.Next() {
+		var produkID int
+		var qty float64
+		if err := rows.Scan(&produkID, &qty); err != nil {
+			return nil, fmt.Errorf("failed to scan quantity sold: %w", err)
+		}
+		terjual[produkID] = qty
+	}
+
+	return terjual, rows.Err()
+}
+
+// GetKomponenTerjual returns the components deducted for a kit in a transaction,
+// with Qty as the component quantity per base unit of the kit at sale time
+func (r *TransaksiRepository) GetKomponenTerjual(transaksiID, paketID int) ([]*models.ProdukKomponen, error) {
+	query := `
+		SELECT komponen_id, komponen_nama, MAX(qty_per_paket)
+		FROM transaksi_item_komponen
+		WHERE transaksi_id = ? AND paket_id = ? AND komponen_id IS NOT NULL
+		GROUP BY komponen_id, komponen_nama
+		ORDER BY MIN(id)
+	`
+
+	rows, err := database.Query(query, transaksiID, paketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kit components sold: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*models.ProdukKomponen
+	for rows.Next() {
+		komponen := &models.ProdukKomponen{PaketID: paketID}
+		if err := rows.Scan(&komponen.KomponenID, &komponen.KomponenNama, &komponen.Qty); err != nil {
+			return nil, fmt.Errorf("failed to scan kit component sold: %w", err)
+		}
+		list = append(list, komponen)
+	}
+
+	return list, rows.Err()
+}
+
 // GetItemCountsByDateForStaff gets total item counts grouped by date for a staff
 func (r *TransaksiRepository) GetItemCountsByDateForStaff(staffID int, startDate, endDate time.Time) (map[string]int, error) {
 	query := `
@@ -834,6 +1553,7 @@ func (r *TransaksiRepository) GetItemCountsByDateForStaff(staffID int, startDate
 		FROM transaksi t
 		JOIN transaksi_item ti ON t.id = ti.transaksi_id
 		WHERE t.staff_id = ? AND DATE(t.tanggal) >= DATE(?) AND DATE(t.tanggal) <= DATE(?)
+		  AND t.status != 'void' AND t.jenis = 'penjualan'
 		GROUP BY DATE(t.tanggal)
 	`
 
@@ -864,7 +1584,7 @@ func (r *TransaksiRepository) GetSalesByShift() (map[string]int, error) {
 	startDate := endDate.AddDate(0, 0, -30)
 
 	// Get all transactions in the date range
-	transaksiList, err := r.GetByDateRange(startDate, endDate)
+	transaksiList, err := r.GetSalesByDateRange(startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions for shift analysis: %w", err)
 	}
@@ -940,6 +1660,7 @@ func (r *TransaksiRepository) GetTransactionCountsByDateForStaff(staffID int, st
 		SELECT DATE(tanggal) as tanggal, COUNT(*) as total_transaksi
 		FROM transaksi
 		WHERE staff_id = ? AND DATE(tanggal) >= DATE(?) AND DATE(tanggal) <= DATE(?)
+		  AND status != 'void' AND jenis = 'penjualan'
 		GROUP BY DATE(tanggal)
 	`
 
@@ -971,7 +1692,7 @@ func (r *TransaksiRepository) GetTotalProductsSoldByDateRange(startDate, endDate
 	query := `SELECT COALESCE(SUM(ti.jumlah), 0) as total_products
 	FROM transaksi t
 	INNER JOIN transaksi_item ti ON t.id = ti.transaksi_id
-	WHERE t.created_at BETWEEN ? AND ?`
+	WHERE t.created_at BETWEEN ? AND ? AND t.status != 'void' AND t.jenis = 'penjualan'`
 
 	var totalProducts int
 	err := database.QueryRow(query, startDate, endDate).Scan(&totalProducts)
@@ -982,6 +1703,84 @@ func (r *TransaksiRepository) GetTotalProductsSoldByDateRange(startDate, endDate
 	return totalProducts, nil
 }
 
+// GetDiscountByPromoByDateRange attributes promo discounts to the promo that was applied
+func (r *TransaksiRepository) GetDiscountByPromoByDateRange(startDate, endDate time.Time) ([]*models.PromoDiscountBreakdown, error) {
+	if r.db == nil {
+		return []*models.PromoDiscountBreakdown{}, nil
+	}
+
+	query := `SELECT t.promo_id, COALESCE(p.nama, ''), COALESCE(p.kode, ''),
+		COUNT(*) as jumlah, COALESCE(SUM(t.diskon_promo), 0) as total_diskon
+	FROM transaksi t
+	LEFT JOIN promo p ON t.promo_id = p.id
+	WHERE t.created_at BETWEEN ? AND ? AND t.status != 'void' AND t.promo_id IS NOT NULL
+	GROUP BY t.promo_id, p.nama, p.kode
+	ORDER BY total_diskon DESC`
+
+	rows, err := database.Query(query, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discount by promo: %w", err)
+	}
+	defer rows.Close()
+
+	result := []*models.PromoDiscountBreakdown{}
+	for rows.Next() {
+		item := &models.PromoDiscountBreakdown{}
+		if err := rows.Scan(&item.PromoID, &item.NamaPromo, &item.KodePromo, &item.Jumlah, &item.TotalDiskon); err != nil {
+			return nil, fmt.Errorf("failed to scan discount by promo: %w", err)
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// GetMarkdownByDateRange sums the near-expiry markdown given per product on
+// non-void sales, with the number of marked down transactions
+func (r *TransaksiRepository) GetMarkdownByDateRange(startDate, endDate time.Time) (*models.MarkdownAnalysis, error) {
+	result := &models.MarkdownAnalysis{Produk: []*models.MarkdownBreakdown{}}
+	if r.db == nil {
+		return result, nil
+	}
+
+	query := `SELECT COALESCE(ti.produk_id, 0), ti.produk_nama,
+		COUNT(*) as jumlah, COALESCE(SUM(ti.diskon_markdown), 0) as total_markdown
+	FROM transaksi_item ti
+	INNER JOIN transaksi t ON ti.transaksi_id = t.id
+	WHERE t.created_at BETWEEN ? AND ? AND t.status != 'void' AND ti.diskon_markdown > 0
+	GROUP BY ti.produk_id, ti.produk_nama
+	ORDER BY total_markdown DESC`
+
+	rows, err := database.Query(query, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get markdown by product: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &models.MarkdownBreakdown{}
+		if err := rows.Scan(&item.ProdukID, &item.NamaProduk, &item.JumlahItem, &item.TotalMarkdown); err != nil {
+			return nil, fmt.Errorf("failed to scan markdown by product: %w", err)
+		}
+		result.TotalMarkdown += item.TotalMarkdown
+		result.JumlahItem += item.JumlahItem
+		result.Produk = append(result.Produk, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate markdown by product: %w", err)
+	}
+
+	countQuery := `SELECT COUNT(DISTINCT t.id)
+	FROM transaksi_item ti
+	INNER JOIN transaksi t ON ti.transaksi_id = t.id
+	WHERE t.created_at BETWEEN ? AND ? AND t.status != 'void' AND ti.diskon_markdown > 0`
+	if err := database.QueryRow(countQuery, startDate, endDate).Scan(&result.JumlahTransaksi); err != nil {
+		return nil, fmt.Errorf("failed to count marked down transactions: %w", err)
+	}
+
+	return result, nil
+}
+
 func (r *TransaksiRepository) GetPaymentMethodBreakdownByDateRange(startDate, endDate time.Time) (map[string]int, error) {
 	if r.db == nil {
 		return make(map[string]int), nil
@@ -990,7 +1789,7 @@ func (r *TransaksiRepository) GetPaymentMethodBreakdownByDateRange(startDate, en
 	query := `SELECT p.metode, COUNT(DISTINCT p.transaksi_id) as jumlah
 	FROM pembayaran p
 	INNER JOIN transaksi t ON p.transaksi_id = t.id
-	WHERE t.created_at BETWEEN ? AND ?
+	WHERE t.created_at BETWEEN ? AND ? AND t.status != 'void' AND t.jenis = 'penjualan' AND p.piutang_id IS NULL
 	GROUP BY p.metode`
 
 	rows, err := database.Query(query, startDate, endDate)
@@ -1020,7 +1819,7 @@ func (r *TransaksiRepository) GetTransactionOmsetByPaymentMethod(startDate, endD
 	query := `SELECT p.metode, SUM(t.total) as total_omset
 	FROM pembayaran p
 	INNER JOIN transaksi t ON p.transaksi_id = t.id
-	WHERE t.created_at BETWEEN ? AND ?
+	WHERE t.created_at BETWEEN ? AND ? AND t.status != 'void' AND t.jenis = 'penjualan' AND p.piutang_id IS NULL
 	GROUP BY p.metode`
 
 	rows, err := database.Query(query, startDate, endDate)
@@ -1070,7 +1869,7 @@ func (r *TransaksiRepository) GetSalesGroupedByPeriod(startDate, endDate time.Ti
                 SUM(total) as revenue,
                 COUNT(*) as transactions
             FROM transaksi
-            WHERE created_at BETWEEN $1 AND $2
+            WHERE created_at BETWEEN $1 AND $2 AND status != 'void' AND jenis = 'penjualan'
             GROUP BY day_key, label
             ORDER BY day_key;
         `
@@ -1082,7 +1881,7 @@ func (r *TransaksiRepository) GetSalesGroupedByPeriod(startDate, endDate time.Ti
                 SUM(total) as revenue,
                 COUNT(*) as transactions
             FROM transaksi
-            WHERE created_at BETWEEN $1 AND $2
+            WHERE created_at BETWEEN $1 AND $2 AND status != 'void' AND jenis = 'penjualan'
             GROUP BY label
             ORDER BY label;
         `
@@ -1094,7 +1893,7 @@ func (r *TransaksiRepository) GetSalesGroupedByPeriod(startDate, endDate time.Ti
                 SUM(total) as revenue,
                 COUNT(*) as transactions
             FROM transaksi
-            WHERE created_at BETWEEN $1 AND $2
+            WHERE created_at BETWEEN $1 AND $2 AND status != 'void' AND jenis = 'penjualan'
             GROUP BY label
             ORDER BY label;
         `
@@ -1106,7 +1905,7 @@ func (r *TransaksiRepository) GetSalesGroupedByPeriod(startDate, endDate time.Ti
                 SUM(total) as revenue,
                 COUNT(*) as transactions
             FROM transaksi
-            WHERE created_at BETWEEN $1 AND $2
+            WHERE created_at BETWEEN $1 AND $2 AND status != 'void' AND jenis = 'penjualan'
             GROUP BY label
             ORDER BY label;
         `
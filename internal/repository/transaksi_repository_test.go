@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodeCounter(t *testing.T) {
+	waktu := time.Date(2026, 10, 18, 23, 59, 0, 0, time.Local)
+
+	tests := []struct {
+		name         string
+		resetCounter string
+		want         string
+	}{
+		{"harian", models.ResetCounterHarian, "20261018"},
+		{"bulanan", models.ResetCounterBulanan, "202610"},
+		{"kosong dianggap harian", "", "20261018"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, periodeCounter(tt.resetCounter, waktu))
+		})
+	}
+}
+
+func TestFormatTanggalNomor(t *testing.T) {
+	waktu := time.Date(2026, 3, 5, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{models.FormatTanggalYYYYMMDD, "20260305"},
+		{models.FormatTanggalYYMMDD, "260305"},
+		{models.FormatTanggalYYYYMM, "202603"},
+		{models.FormatTanggalYYMM, "2603"},
+		{"", "20260305"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatTanggalNomor(tt.format, waktu))
+		})
+	}
+}
+
+func TestKunciCounter(t *testing.T) {
+	waktu := time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local)
+
+	assert.Equal(t, "TRX-20261018", kunciCounter("TRX", models.FormatTanggalYYYYMMDD, waktu))
+	assert.Equal(t, "TRX-2610", kunciCounter("TRX", models.FormatTanggalYYMM, waktu))
+	assert.NotEqual(t, kunciCounter("TRX", models.FormatTanggalYYYYMMDD, waktu), kunciCounter("INV", models.FormatTanggalYYYYMMDD, waktu))
+}
+
+// openCounterDB opens an in-memory database with only the transaction counter table
+func openCounterDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE transaksi_counter (
+		kode_terminal TEXT NOT NULL,
+		periode TEXT NOT NULL,
+		periode_reset TEXT,
+		nilai INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (kode_terminal, periode)
+	)`)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGenerateNomorTransaksi(t *testing.T) {
+	harian := &models.NomorTransaksiSettings{
+		Prefix: "TRX", KodeTerminal: "T01", FormatTanggal: models.FormatTanggalYYYYMMDD,
+		PanjangCounter: 4, ResetCounter: models.ResetCounterHarian,
+	}
+	bulanan := *harian
+	bulanan.ResetCounter = models.ResetCounterBulanan
+
+	hari1 := time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)
+	hari2 := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+
+	type langkah struct {
+		settings *models.NomorTransaksiSettings
+		terminal string
+		waktu    time.Time
+		want     string
+	}
+
+	tests := []struct {
+		name    string
+		langkah []langkah
+	}{
+		{
+			name: "counter naik dalam satu transaksi",
+			langkah: []langkah{
+				{harian, "", hari1, "TRX-T01-20261018-0001"},
+				{harian, "", hari1, "TRX-T01-20261018-0002"},
+				{harian, "", hari1, "TRX-T01-20261018-0003"},
+			},
+		},
+		{
+			name: "reset harian dan counter per terminal",
+			langkah: []langkah{
+				{harian, "", hari1, "TRX-T01-20261018-0001"},
+				{harian, "T02", hari1, "TRX-T02-20261018-0001"},
+				{harian, "", hari2, "TRX-T01-20261019-0001"},
+			},
+		},
+		{
+			name: "reset bulanan berlanjut saat tanggal berganti",
+			langkah: []langkah{
+				{&bulanan, "", hari1, "TRX-T01-20261018-0001"},
+				{&bulanan, "", hari1, "TRX-T01-20261018-0002"},
+				{&bulanan, "", hari2, "TRX-T01-20261019-0003"},
+			},
+		},
+		{
+			name: "ganti bulanan ke harian di tengah bulan tidak mengulang nomor",
+			langkah: []langkah{
+				{&bulanan, "", hari1, "TRX-T01-20261018-0001"},
+				{&bulanan, "", hari1, "TRX-T01-20261018-0002"},
+				{harian, "", hari1, "TRX-T01-20261018-0003"},
+			},
+		},
+		{
+			name: "ganti harian ke bulanan di tengah hari tidak mengulang nomor",
+			langkah: []langkah{
+				{harian, "", hari1, "TRX-T01-20261018-0001"},
+				{&bulanan, "", hari1, "TRX-T01-20261018-0002"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openCounterDB(t)
+			r := &TransaksiRepository{db: db}
+
+			tx, err := db.Begin()
+			require.NoError(t, err)
+			defer tx.Rollback()
+
+			for i, l := range tt.langkah {
+				nomor, err := r.GenerateNomorTransaksi(tx, l.settings, l.terminal, l.waktu)
+				require.NoError(t, err)
+				assert.Equal(t, l.want, nomor, "langkah %d", i+1)
+			}
+		})
+	}
+}
+
+func TestGenerateNomorTransaksiMelanjutkanCounterLama(t *testing.T) {
+	db := openCounterDB(t)
+	r := &TransaksiRepository{db: db}
+
+	// Counter sebelum dikunci per awalan nomor, setelah migrasi backfill periode_reset
+	_, err := db.Exec(`INSERT INTO transaksi_counter (kode_terminal, periode, periode_reset, nilai) VALUES ('T01', '202610', '202610', 57)`)
+	require.NoError(t, err)
+
+	settings := &models.NomorTransaksiSettings{
+		Prefix: "TRX", KodeTerminal: "T01", FormatTanggal: models.FormatTanggalYYYYMMDD,
+		PanjangCounter: 4, ResetCounter: models.ResetCounterBulanan,
+	}
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	nomor, err := r.GenerateNomorTransaksi(tx, settings, "", time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, "TRX-T01-20261018-0058", nomor)
+}
+
+// openVoidDB opens an in-memory database with completed sale 1: 3 units of produk 1
+// taken from batch B1, with 7 units left in stock and in the batch
+func openVoidDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	for _, ddl := range []string{
+		`CREATE TABLE transaksi (
+			id INTEGER PRIMARY KEY, nomor_transaksi TEXT, status TEXT, pelanggan_id INTEGER, total INTEGER,
+			poin_ditukar INTEGER, void_alasan TEXT, void_oleh_id INTEGER, void_oleh_nama TEXT, void_at DATETIME,
+			void_shift_id INTEGER
+		)`,
+		`CREATE TABLE transaksi_item (
+			id INTEGER PRIMARY KEY, transaksi_id INTEGER, produk_id INTEGER, produk_nama TEXT,
+			jumlah INTEGER, beratgram REAL, konversi REAL
+		)`,
+		`CREATE TABLE transaksi_item_komponen (
+			id INTEGER PRIMARY KEY, transaksi_id INTEGER, transaksi_item_id INTEGER,
+			komponen_id INTEGER, komponen_nama TEXT, qty REAL
+		)`,
+		`CREATE TABLE transaksi_item_batch (id INTEGER PRIMARY KEY, transaksi_id INTEGER, produk_id INTEGER, batch_id TEXT, qty REAL)`,
+		`CREATE TABLE produk (id INTEGER PRIMARY KEY, stok REAL, harga_beli INTEGER, updated_at DATETIME)`,
+		`CREATE TABLE batch (id TEXT PRIMARY KEY, produk_id INTEGER, qty_tersisa REAL, harga_beli INTEGER, ditarik_at DATETIME)`,
+		`CREATE TABLE batch_penghapusan (
+			id INTEGER PRIMARY KEY, batch_id TEXT, produk_id INTEGER, qty REAL,
+			tipe_kerugian TEXT, keterangan TEXT, created_at DATETIME
+		)`,
+		`CREATE TABLE stok_history (
+			id INTEGER PRIMARY KEY, produk_id INTEGER, stok_sebelum REAL, stok_sesudah REAL, perubahan REAL,
+			jenis_perubahan TEXT, keterangan TEXT, tipe_kerugian TEXT, nilai_kerugian INTEGER, created_at DATETIME
+		)`,
+		`CREATE TABLE piutang (id INTEGER PRIMARY KEY, transaksi_id INTEGER, jumlah INTEGER, sisa INTEGER, status TEXT)`,
+		`CREATE TABLE kartu_hadiah (id INTEGER PRIMARY KEY, kode TEXT, saldo_awal INTEGER, saldo INTEGER, status TEXT, transaksi_id INTEGER)`,
+		`CREATE TABLE kartu_hadiah_mutasi (id INTEGER PRIMARY KEY, kartu_id INTEGER, tipe TEXT, jumlah INTEGER, transaksi_id INTEGER)`,
+		`INSERT INTO transaksi (id, nomor_transaksi, status, pelanggan_id, total, poin_ditukar)
+			VALUES (1, 'TRX-T01-20261018-0001', 'selesai', 0, 30000, 0)`,
+		`INSERT INTO transaksi_item (id, transaksi_id, produk_id, produk_nama, jumlah, beratgram, konversi)
+			VALUES (1, 1, 1, 'Teh', 3, 0, 1)`,
+		`INSERT INTO transaksi_item_batch (transaksi_id, produk_id, batch_id, qty) VALUES (1, 1, 'B1', 3)`,
+		`INSERT INTO produk (id, stok, harga_beli) VALUES (1, 7, 5000)`,
+		`INSERT INTO batch (id, produk_id, qty_tersisa, harga_beli) VALUES ('B1', 1, 7, 5000)`,
+	} {
+		_, err := db.Exec(ddl)
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func TestVoidSekaliSaja(t *testing.T) {
+	db := openVoidDB(t)
+	repo := &TransaksiRepository{db: db}
+	approver := &models.User{ID: 1, NamaLengkap: "Admin"}
+
+	require.NoError(t, repo.Void(1, "salah input", approver, 0, 0))
+	assert.Error(t, repo.Void(1, "salah input", approver, 0, 0), "void kedua ditolak")
+
+	var status string
+	var stok, qtyBatch float64
+	require.NoError(t, db.QueryRow(`SELECT status FROM transaksi WHERE id = 1`).Scan(&status))
+	require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = 1`).Scan(&stok))
+	require.NoError(t, db.QueryRow(`SELECT qty_tersisa FROM batch WHERE id = 'B1'`).Scan(&qtyBatch))
+	assert.Equal(t, "void", status)
+	assert.Equal(t, 10.0, stok, "stok dikembalikan satu kali")
+	assert.Equal(t, 10.0, qtyBatch, "batch dikembalikan satu kali")
+}
+
+func TestVoidBatchDitarik(t *testing.T) {
+	db := openVoidDB(t)
+	_, err := db.Exec(`UPDATE batch SET ditarik_at = CURRENT_TIMESTAMP, qty_tersisa = 0 WHERE id = 'B1'`)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE produk SET stok = 0 WHERE id = 1`)
+	require.NoError(t, err)
+
+	repo := &TransaksiRepository{db: db}
+	require.NoError(t, repo.Void(1, "salah input", &models.User{ID: 1, NamaLengkap: "Admin"}, 0, 0))
+
+	var stok, qtyBatch, dihapus float64
+	var tipe string
+	require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = 1`).Scan(&stok))
+	require.NoError(t, db.QueryRow(`SELECT qty_tersisa FROM batch WHERE id = 'B1'`).Scan(&qtyBatch))
+	require.NoError(t, db.QueryRow(`SELECT qty, tipe_kerugian FROM batch_penghapusan WHERE batch_id = 'B1'`).Scan(&dihapus, &tipe))
+	assert.Equal(t, 0.0, stok, "stok batch yang ditarik tidak kembali dijual")
+	assert.Equal(t, 0.0, qtyBatch)
+	assert.Equal(t, 3.0, dihapus)
+	assert.Equal(t, models.TipeKerugianDitarik, tipe)
+}
+
+func TestGetSalesByDateRangeTanpaKartuHadiah(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	lama := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = lama
+		db.Close()
+	})
+
+	for _, q := range []string{
+		`CREATE TABLE transaksi (
+			id INTEGER PRIMARY KEY, nomor_transaksi TEXT, tanggal DATETIME DEFAULT CURRENT_TIMESTAMP,
+			pelanggan_id INTEGER DEFAULT 0, pelanggan_nama TEXT DEFAULT '', pelanggan_telp TEXT,
+			subtotal INTEGER DEFAULT 0, diskon_promo INTEGER DEFAULT 0, diskon_pelanggan INTEGER DEFAULT 0,
+			poin_ditukar INTEGER DEFAULT 0, diskon_poin INTEGER DEFAULT 0, diskon INTEGER DEFAULT 0,
+			total INTEGER, total_bayar INTEGER DEFAULT 0, kembalian INTEGER DEFAULT 0, status TEXT, catatan TEXT,
+			kasir TEXT, mode_pajak TEXT, total_dpp INTEGER, total_pajak INTEGER, pembulatan INTEGER,
+			jenis TEXT DEFAULT 'penjualan', created_at DATETIME
+		)`,
+		`INSERT INTO transaksi (id, nomor_transaksi, total, status, created_at)
+			VALUES (1, 'TRX-0001', 25000, 'selesai', '2026-10-18 10:00:00')`,
+		`INSERT INTO transaksi (id, nomor_transaksi, total, status, jenis, created_at)
+			VALUES (2, 'TRX-0002', 100000, 'selesai', 'kartu_hadiah', '2026-10-18 11:00:00')`,
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+
+	repo := &TransaksiRepository{db: db}
+	dari := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	sampai := dari.AddDate(0, 0, 1)
+
+	penjualan, err := repo.GetSalesByDateRange(dari, sampai)
+	require.NoError(t, err)
+	require.Len(t, penjualan, 1, "penjualan kartu hadiah bukan omzet")
+	assert.Equal(t, 25000, penjualan[0].Total)
+
+	semua, err := repo.GetByDateRange(dari, sampai)
+	require.NoError(t, err)
+	assert.Len(t, semua, 2, "daftar transaksi tetap memuat penjualan kartu hadiah")
+}
@@ -20,12 +20,12 @@ func NewAnalyticsService() *AnalyticsService {
 	}
 }
 
-// GetTopProducts retrieves top selling products
-func (s *AnalyticsService) GetTopProducts(startDate, endDate string, limit int) ([]*models.TopProductsResponse, error) {
+// GetTopProducts retrieves top selling products, optionally rolled up per parent product
+func (s *AnalyticsService) GetTopProducts(startDate, endDate string, limit int, perInduk bool) ([]*models.TopProductsResponse, error) {
 	if limit <= 0 {
 		limit = 10
 	}
-	return s.analyticsRepo.GetTopProducts(startDate, endDate, limit)
+	return s.analyticsRepo.GetTopProducts(startDate, endDate, limit, perInduk)
 }
 
 // GetPaymentMethodBreakdown retrieves payment method statistics
@@ -38,9 +38,9 @@ func (s *AnalyticsService) GetSalesTrend(startDate, endDate string) ([]*models.S
 	return s.analyticsRepo.GetSalesTrend(startDate, endDate)
 }
 
-// GetCategoryBreakdown retrieves sales by category
-func (s *AnalyticsService) GetCategoryBreakdown(startDate, endDate string) ([]*models.CategoryBreakdownResponse, error) {
-	return s.analyticsRepo.GetCategoryBreakdown(startDate, endDate)
+// GetCategoryBreakdown retrieves sales by category, optionally using the category of the parent product
+func (s *AnalyticsService) GetCategoryBreakdown(startDate, endDate string, perInduk bool) ([]*models.CategoryBreakdownResponse, error) {
+	return s.analyticsRepo.GetCategoryBreakdown(startDate, endDate, perInduk)
 }
 
 // GetHourlySales retrieves sales grouped by hour and day
@@ -53,7 +53,7 @@ func (s *AnalyticsService) GetSalesInsights(startDate, endDate string) (*models.
 	insights := &models.SalesInsightsResponse{}
 
 	// Get top products
-	topProducts, err := s.analyticsRepo.GetTopProducts(startDate, endDate, 10)
+	topProducts, err := s.analyticsRepo.GetTopProducts(startDate, endDate, 10, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top products: %w", err)
 	}
@@ -67,7 +67,7 @@ func (s *AnalyticsService) GetSalesInsights(startDate, endDate string) (*models.
 	insights.PaymentBreakdown = paymentBreakdown
 
 	// Get category breakdown
-	categoryBreakdown, err := s.analyticsRepo.GetCategoryBreakdown(startDate, endDate)
+	categoryBreakdown, err := s.analyticsRepo.GetCategoryBreakdown(startDate, endDate, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get category breakdown: %w", err)
 	}
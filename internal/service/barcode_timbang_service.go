@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"strconv"
+	"strings"
+)
+
+// panjangEAN13 is the number of digits of an EAN-13 barcode; the last digit is the check digit
+const panjangEAN13 = 13
+
+// BarcodeTimbangService decodes EAN-13 scale labels that embed the PLU and the weight or price
+type BarcodeTimbangService struct {
+	repo       *repository.BarcodeTimbangRepository
+	produkRepo *repository.ProdukRepository
+}
+
+// NewBarcodeTimbangService creates a new instance
+func NewBarcodeTimbangService() *BarcodeTimbangService {
+	return &BarcodeTimbangService{
+		repo:       repository.NewBarcodeTimbangRepository(),
+		produkRepo: repository.NewProdukRepository(),
+	}
+}
+
+// GetAllAturan retrieves all scale label rules, including inactive ones
+func (s *BarcodeTimbangService) GetAllAturan() ([]*models.AturanBarcodeTimbang, error) {
+	return s.repo.GetAll()
+}
+
+// CreateAturan adds a scale label rule; each prefix can only have one rule
+func (s *BarcodeTimbangService) CreateAturan(aturan *models.AturanBarcodeTimbang) error {
+	if err := validateAturanBarcodeTimbang(aturan); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByPrefix(aturan.Prefix)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("aturan untuk prefix %s sudah ada", aturan.Prefix)
+	}
+
+	return s.repo.Create(aturan)
+}
+
+// UpdateAturan updates a scale label rule
+func (s *BarcodeTimbangService) UpdateAturan(aturan *models.AturanBarcodeTimbang) error {
+	if err := validateAturanBarcodeTimbang(aturan); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(aturan.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("aturan barcode timbang tidak ditemukan")
+	}
+
+	byPrefix, err := s.repo.GetByPrefix(aturan.Prefix)
+	if err != nil {
+		return err
+	}
+	if byPrefix != nil && byPrefix.ID != aturan.ID {
+		return fmt.Errorf("aturan untuk prefix %s sudah ada", aturan.Prefix)
+	}
+
+	return s.repo.Update(aturan)
+}
+
+// DeleteAturan removes a scale label rule
+func (s *BarcodeTimbangService) DeleteAturan(id int) error {
+	return s.repo.Delete(id)
+}
+
+// BacaLabel decodes a scale label. It returns nil without error when the barcode
+// is not a scale label, i.e. not 13 digits or no active rule for its prefix.
+// For a weight label the price is calculated from the price per kg, and for a
+// price label the weight is derived from it, so both can be used for the sale.
+func (s *BarcodeTimbangService) BacaLabel(barcode string) (*models.LabelTimbang, *models.Produk, error) {
+	if len(barcode) != panjangEAN13 || !isDigits(barcode) {
+		return nil, nil, nil
+	}
+
+	aturan, err := s.repo.GetByPrefix(barcode[:2])
+	if err != nil {
+		return nil, nil, err
+	}
+	if aturan == nil || !aturan.Aktif {
+		return nil, nil, nil
+	}
+
+	if !validCheckDigitEAN13(barcode) {
+		return nil, nil, fmt.Errorf("check digit label timbangan %s tidak valid", barcode)
+	}
+
+	plu := barcode[aturan.PLUDari-1 : aturan.PLUSampai]
+	nilai, err := strconv.Atoi(barcode[aturan.NilaiDari-1 : aturan.NilaiSampai])
+	if err != nil {
+		return nil, nil, fmt.Errorf("nilai label timbangan %s tidak valid", barcode)
+	}
+
+	produk, err := s.produkRepo.GetByPLU(plu)
+	if err != nil {
+		return nil, nil, err
+	}
+	if produk == nil {
+		return nil, nil, fmt.Errorf("produk dengan PLU %s tidak ditemukan", plu)
+	}
+	if produk.JenisProduk != "curah" {
+		return nil, nil, fmt.Errorf("produk %s tidak dijual per berat", produk.Nama)
+	}
+
+	label := &models.LabelTimbang{
+		AturanID:  aturan.ID,
+		TipeNilai: aturan.TipeNilai,
+		PLU:       plu,
+	}
+	nilaiDesimal := float64(nilai) / math.Pow10(aturan.Desimal)
+
+	switch aturan.TipeNilai {
+	case models.TipeNilaiBerat:
+		// Nilai dalam kg
+		label.BeratGram = math.Round(nilaiDesimal*1000*100) / 100
+		label.Harga = int((label.BeratGram / 1000.0) * float64(produk.HargaJual))
+	case models.TipeNilaiHarga:
+		label.Harga = int(math.Round(nilaiDesimal))
+		label.BeratGram = beratDariHarga(label.Harga, produk.HargaJual)
+	}
+
+	if label.BeratGram <= 0 {
+		return nil, nil, fmt.Errorf("berat pada label timbangan %s tidak boleh 0", barcode)
+	}
+
+	return label, produk, nil
+}
+
+// validateAturanBarcodeTimbang checks the prefix and digit positions of a rule.
+// The PLU and the value must lie between the prefix and the check digit and
+// must not overlap.
+func validateAturanBarcodeTimbang(aturan *models.AturanBarcodeTimbang) error {
+	aturan.Nama = strings.TrimSpace(aturan.Nama)
+	aturan.Prefix = strings.TrimSpace(aturan.Prefix)
+	aturan.TipeNilai = strings.ToLower(strings.TrimSpace(aturan.TipeNilai))
+
+	if aturan.Nama == "" {
+		return fmt.Errorf("nama aturan harus diisi")
+	}
+	if len(aturan.Prefix) != 2 || aturan.Prefix[0] != '2' || !isDigits(aturan.Prefix) {
+		return fmt.Errorf("prefix harus dua digit antara 20 dan 29")
+	}
+	if aturan.TipeNilai != models.TipeNilaiBerat && aturan.TipeNilai != models.TipeNilaiHarga {
+		return fmt.Errorf("tipe nilai harus %q atau %q", models.TipeNilaiBerat, models.TipeNilaiHarga)
+	}
+
+	awal, akhir := len(aturan.Prefix)+1, panjangEAN13-1
+	if aturan.PLUDari < awal || aturan.PLUSampai > akhir || aturan.PLUDari > aturan.PLUSampai {
+		return fmt.Errorf("posisi digit PLU harus antara %d dan %d", awal, akhir)
+	}
+	if aturan.NilaiDari < awal || aturan.NilaiSampai > akhir || aturan.NilaiDari > aturan.NilaiSampai {
+		return fmt.Errorf("posisi digit %s harus antara %d dan %d", aturan.TipeNilai, awal, akhir)
+	}
+	if aturan.PLUDari <= aturan.NilaiSampai && aturan.NilaiDari <= aturan.PLUSampai {
+		return fmt.Errorf("posisi digit PLU dan %s tidak boleh tumpang tindih", aturan.TipeNilai)
+	}
+	if aturan.Desimal < 0 || aturan.Desimal > aturan.NilaiSampai-aturan.NilaiDari+1 {
+		return fmt.Errorf("jumlah desimal harus antara 0 dan jumlah digit %s", aturan.TipeNilai)
+	}
+
+	return nil
+}
+
+// validCheckDigitEAN13 verifies the last digit of an EAN-13 code: digits in
+// even positions count three times, and the total must be a multiple of ten
+func validCheckDigitEAN13(kode string) bool {
+	sum := 0
+	for i := 0; i < panjangEAN13-1; i++ {
+		d := int(kode[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(kode[panjangEAN13-1]-'0')
+}
+
+// beratDariHarga derives the weight in grams (two decimals) of a price label,
+// rounded so the sale subtotal is not below the printed price
+func beratDariHarga(harga, hargaPerKg int) float64 {
+	if hargaPerKg <= 0 {
+		return 0
+	}
+	berat := math.Round(float64(harga)*1000/float64(hargaPerKg)*100) / 100
+	if int((berat/1000.0)*float64(hargaPerKg)) < harga {
+		berat += 0.01
+	}
+	return berat
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidCheckDigitEAN13(t *testing.T) {
+	tests := []struct {
+		kode string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"5901234123457", true},
+		{"2012345012349", true},
+		{"2900001001502", true},
+		{"4006381333932", false},
+		{"2012345012340", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kode, func(t *testing.T) {
+			assert.Equal(t, tt.want, validCheckDigitEAN13(tt.kode))
+		})
+	}
+}
+
+func TestBeratDariHarga(t *testing.T) {
+	tests := []struct {
+		name       string
+		harga      int
+		hargaPerKg int
+		want       float64
+	}{
+		{"berat pas", 15000, 30000, 500},
+		{"dibulatkan ke atas agar subtotal tidak kurang dari label", 10000, 30000, 333.34},
+		{"dibulatkan normal jika sudah cukup", 12345, 10000, 1234.5},
+		{"harga per kg nol", 10000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			berat := beratDariHarga(tt.harga, tt.hargaPerKg)
+			assert.InDelta(t, tt.want, berat, 0.0001)
+			if tt.hargaPerKg > 0 {
+				assert.GreaterOrEqual(t, int((berat/1000.0)*float64(tt.hargaPerKg)), tt.harga, "subtotal tidak boleh kurang dari harga label")
+			}
+		})
+	}
+}
+
+func TestValidateAturanBarcodeTimbang(t *testing.T) {
+	valid := func() models.AturanBarcodeTimbang {
+		return models.AturanBarcodeTimbang{
+			Nama: "Timbangan buah", Prefix: "20", TipeNilai: models.TipeNilaiBerat,
+			PLUDari: 3, PLUSampai: 7, NilaiDari: 8, NilaiSampai: 12, Desimal: 3,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		ubah    func(a *models.AturanBarcodeTimbang)
+		wantErr bool
+	}{
+		{"aturan valid", func(a *models.AturanBarcodeTimbang) {}, false},
+		{"tipe nilai harga tanpa desimal", func(a *models.AturanBarcodeTimbang) { a.TipeNilai = " HARGA "; a.Desimal = 0 }, false},
+		{"nama kosong", func(a *models.AturanBarcodeTimbang) { a.Nama = "  " }, true},
+		{"prefix bukan 2x", func(a *models.AturanBarcodeTimbang) { a.Prefix = "30" }, true},
+		{"prefix satu digit", func(a *models.AturanBarcodeTimbang) { a.Prefix = "2" }, true},
+		{"prefix bukan angka", func(a *models.AturanBarcodeTimbang) { a.Prefix = "2A" }, true},
+		{"tipe nilai tidak dikenal", func(a *models.AturanBarcodeTimbang) { a.TipeNilai = "jumlah" }, true},
+		{"PLU menimpa prefix", func(a *models.AturanBarcodeTimbang) { a.PLUDari = 2 }, true},
+		{"nilai menimpa check digit", func(a *models.AturanBarcodeTimbang) { a.NilaiSampai = 13 }, true},
+		{"posisi PLU terbalik", func(a *models.AturanBarcodeTimbang) { a.PLUDari, a.PLUSampai = 7, 3 }, true},
+		{"PLU dan nilai tumpang tindih", func(a *models.AturanBarcodeTimbang) { a.NilaiDari = 7 }, true},
+		{"desimal melebihi jumlah digit", func(a *models.AturanBarcodeTimbang) { a.Desimal = 6 }, true},
+		{"desimal negatif", func(a *models.AturanBarcodeTimbang) { a.Desimal = -1 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aturan := valid()
+			tt.ubah(&aturan)
+
+			err := validateAturanBarcodeTimbang(&aturan)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"ritel-app/internal/repository"
+)
+
+// BatchKadaluarsaJob periodically updates batch statuses and writes off expired
+// batches, on the schedule in the batch kadaluarsa settings
+type BatchKadaluarsaJob struct {
+	batchService *BatchService
+	settingsRepo *repository.SettingsRepository
+	stopChan     chan struct{}
+	stopOnce     sync.Once
+}
+
+// NewBatchKadaluarsaJob creates a new expired batch job
+func NewBatchKadaluarsaJob(batchService *BatchService) *BatchKadaluarsaJob {
+	return &BatchKadaluarsaJob{
+		batchService: batchService,
+		settingsRepo: repository.NewSettingsRepository(),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start runs the job once right away and then on every interval until Stop is called
+func (j *BatchKadaluarsaJob) Start() {
+	go j.worker()
+	log.Println("[BATCH JOB] Expired batch job started")
+}
+
+// Stop stops the job
+func (j *BatchKadaluarsaJob) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stopChan)
+	})
+}
+
+// worker runs the job and waits for the configured interval, re-read after every
+// run so a changed schedule applies from the next run
+func (j *BatchKadaluarsaJob) worker() {
+	for {
+		j.run()
+
+		timer := time.NewTimer(j.interval())
+		select {
+		case <-timer.C:
+		case <-j.stopChan:
+			timer.Stop()
+			log.Println("[BATCH JOB] Expired batch job stopped")
+			return
+		}
+	}
+}
+
+// run processes expired batches once
+func (j *BatchKadaluarsaJob) run() {
+	hasil, err := j.batchService.ProsesKadaluarsa()
+	if err != nil {
+		log.Printf("[BATCH JOB] Error processing expired batches: %v", err)
+		return
+	}
+	if hasil.JumlahBatch > 0 {
+		log.Printf("[BATCH JOB] Wrote off %d expired batches: %.2f units, Rp %d", hasil.JumlahBatch, hasil.QtyDihapus, hasil.NilaiKerugian)
+	}
+}
+
+// interval returns the configured time between runs
+func (j *BatchKadaluarsaJob) interval() time.Duration {
+	settings, err := j.settingsRepo.GetBatchKadaluarsaSettings()
+	if err != nil || settings.IntervalJam <= 0 {
+		return 6 * time.Hour
+	}
+	return time.Duration(settings.IntervalJam) * time.Hour
+}
@@ -1,7 +1,12 @@
 package service
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
+	"log"
+	"math"
+	"strings"
 	"time"
 
 	"ritel-app/internal/models"
@@ -10,15 +15,17 @@ import (
 
 // BatchService handles business logic for batch operations
 type BatchService struct {
-	batchRepo  *repository.BatchRepository
-	produkRepo *repository.ProdukRepository
+	batchRepo    *repository.BatchRepository
+	produkRepo   *repository.ProdukRepository
+	settingsRepo *repository.SettingsRepository
 }
 
 // NewBatchService creates a new batch service
 func NewBatchService() *BatchService {
 	return &BatchService{
-		batchRepo:  repository.NewBatchRepository(),
-		produkRepo: repository.NewProdukRepository(),
+		batchRepo:    repository.NewBatchRepository(),
+		produkRepo:   repository.NewProdukRepository(),
+		settingsRepo: repository.NewSettingsRepository(),
 	}
 }
 
@@ -77,11 +84,23 @@ func (s *BatchService) CreateBatchFromRestok(req *models.UpdateStokRequest) (*mo
 		return nil, fmt.Errorf("product not found: %w", err)
 	}
 
-	// Check if there's an existing batch from today with the same shelf life
+	// Batch cost is the purchase price of this receipt, or the product cost when none is given
+	hargaBeli := req.HargaBeli
+	if hargaBeli <= 0 {
+		hargaBeli = produk.HargaBeli
+	}
+
+	// Check if there's an existing batch from today with the same shelf life and supplier
 	today := time.Now().Format("2006-01-02")
 	existingBatch, err := s.batchRepo.FindBatchByDateAndShelfLife(req.ProdukID, today, req.MasaSimpanHari)
-	if err == nil && existingBatch != nil {
-		// Merge into existing batch
+	if err == nil && existingBatch != nil && existingBatch.SupplierID == req.SupplierID {
+		// Merge into existing batch at the average cost of its remaining and incoming stock
+		if existingBatch.HargaBeli > 0 {
+			existingBatch.HargaBeli = int(math.Round((existingBatch.QtyTersisa*float64(existingBatch.HargaBeli) + req.Perubahan*float64(hargaBeli)) /
+				(existingBatch.QtyTersisa + req.Perubahan)))
+		} else {
+			existingBatch.HargaBeli = hargaBeli
+		}
 		existingBatch.Qty += req.Perubahan
 		existingBatch.QtyTersisa += req.Perubahan
 
@@ -102,7 +121,9 @@ func (s *BatchService) CreateBatchFromRestok(req *models.UpdateStokRequest) (*mo
 		TanggalRestok:  time.Now(),
 		MasaSimpanHari: req.MasaSimpanHari,
 		Supplier:       req.Supplier,
+		SupplierID:     req.SupplierID,
 		Keterangan:     req.Keterangan,
+		HargaBeli:      hargaBeli,
 	}
 
 	// Save batch
@@ -137,7 +158,9 @@ func (s *BatchService) GetBatchesByProduk(produkID int) ([]*models.Batch, error)
 	}
 
 	for _, batch := range batches {
-		batch.Status = s.calculateBatchStatus(batch.TanggalKadaluarsa, notificationDays)
+		if batch.DihapusAt == nil {
+			batch.Status = s.calculateBatchStatus(batch.TanggalKadaluarsa, notificationDays)
+		}
 	}
 
 	return batches, nil
@@ -187,11 +210,40 @@ func (s *BatchService) GetExpiringBatches(daysThreshold int) ([]*models.Batch, e
 	return batches, nil
 }
 
-// ReduceBatchQtyFIFO reduces stock from batches using FIFO method
+// batchPotongan is the quantity taken from one batch by a reduction
+type batchPotongan struct {
+	batchID string
+	qty     float64
+}
+
+// ReduceBatchQty reduces stock from batches in the order of the product's batch policy
 // Returns list of batch IDs that were affected
-func (s *BatchService) ReduceBatchQtyFIFO(produkID int, qtyToReduce float64) ([]string, error) {
-	// Get all available batches for this product (ordered by FIFO)
-	batches, err := s.batchRepo.GetBatchesByProdukID(produkID)
+func (s *BatchService) ReduceBatchQty(produkID int, qtyToReduce float64) ([]string, error) {
+	potongan, err := s.reduceBatchQty(produkID, qtyToReduce, "")
+	if err != nil {
+		return nil, err
+	}
+
+	affectedBatchIDs := make([]string, 0, len(potongan))
+	for _, p := range potongan {
+		affectedBatchIDs = append(affectedBatchIDs, p.batchID)
+	}
+	return affectedBatchIDs, nil
+}
+
+// reduceBatchQty reduces stock from batches in the order of the product's batch policy
+// (FIFO or FEFO) and returns how much was taken from each batch. Expired batches are
+// skipped under the skip-expired policy, except for write-offs (tipeKerugian set),
+// which remove physical stock and may take from any batch.
+func (s *BatchService) reduceBatchQty(produkID int, qtyToReduce float64, tipeKerugian string) ([]batchPotongan, error) {
+	kebijakan, err := s.batchRepo.GetKebijakanBatch(produkID)
+	if err != nil {
+		return nil, err
+	}
+	lewatiKadaluarsa := kebijakan == models.KebijakanBatchFEFOLewatiKadaluarsa && tipeKerugian == ""
+
+	// Get all available batches for this product in consumption order
+	batches, err := s.batchRepo.GetBatchesKonsumsi(produkID, kebijakan, lewatiKadaluarsa)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get batches: %w", err)
 	}
@@ -210,9 +262,9 @@ func (s *BatchService) ReduceBatchQtyFIFO(produkID int, qtyToReduce float64) ([]
 		return nil, fmt.Errorf("insufficient stock: need %.2f, available %.2f", qtyToReduce, totalAvailable)
 	}
 
-	// Reduce from batches using FIFO
+	// Reduce from batches in consumption order
 	remainingToReduce := qtyToReduce
-	affectedBatchIDs := []string{}
+	potongan := []batchPotongan{}
 
 	for _, batch := range batches {
 		if remainingToReduce <= 0 {
@@ -231,16 +283,23 @@ func (s *BatchService) ReduceBatchQtyFIFO(produkID int, qtyToReduce float64) ([]
 			return nil, fmt.Errorf("failed to update batch %s: %w", batch.ID, err)
 		}
 
-		affectedBatchIDs = append(affectedBatchIDs, batch.ID)
+		potongan = append(potongan, batchPotongan{batchID: batch.ID, qty: qtyFromThisBatch})
 		remainingToReduce -= qtyFromThisBatch
 	}
 
-	return affectedBatchIDs, nil
+	return potongan, nil
 }
 
 // DeleteExpiredBatch marks a batch as expired and sets qty to 0
+// The remaining quantity is recorded as written off so it shows in the supplier report
 func (s *BatchService) DeleteExpiredBatch(batchID string) error {
-	return s.batchRepo.DeleteBatch(batchID)
+	qty, nilai, err := s.batchRepo.HapusKadaluarsa(batchID, "Batch dihapus")
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[BATCH SERVICE] Batch %s archived, %.2f units written off (Rp %d)", batchID, qty, nilai)
+	return nil
 }
 
 // UpdateBatchStatuses updates status for all batches
@@ -248,6 +307,42 @@ func (s *BatchService) UpdateBatchStatuses() error {
 	return s.batchRepo.UpdateAllBatchStatuses()
 }
 
+// ProsesKadaluarsa updates batch statuses and, when automatic write-off is enabled,
+// writes off the remaining stock of every expired batch as an expiry loss
+func (s *BatchService) ProsesKadaluarsa() (*models.HasilProsesKadaluarsa, error) {
+	if err := s.UpdateBatchStatuses(); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.settingsRepo.GetBatchKadaluarsaSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	hasil := &models.HasilProsesKadaluarsa{Waktu: time.Now(), HapusOtomatis: settings.HapusOtomatis}
+	if !settings.HapusOtomatis {
+		return hasil, nil
+	}
+
+	ids, err := s.batchRepo.GetKadaluarsaBelumDihapus()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		qty, nilai, err := s.batchRepo.HapusKadaluarsa(id, "Batch kadaluarsa dihapus otomatis")
+		if err != nil {
+			log.Printf("[BATCH SERVICE] Warning: failed to write off expired batch %s: %v", id, err)
+			continue
+		}
+		hasil.JumlahBatch++
+		hasil.QtyDihapus += qty
+		hasil.NilaiKerugian += nilai
+	}
+
+	return hasil, nil
+}
+
 // GetBatchSummaryByProduk returns summary of batches for a product
 func (s *BatchService) GetBatchSummaryByProduk(produkID int) (map[string]interface{}, error) {
 	batches, err := s.batchRepo.GetBatchesByProdukID(produkID)
@@ -297,13 +392,149 @@ func (s *BatchService) GetBatchSummaryByProduk(produkID int) (map[string]interfa
 	return summary, nil
 }
 
-// DeductFromBatches deducts quantity from batches using FIFO method
+// DeductFromBatches deducts quantity from batches in the order of the product's batch policy
 // This is called when manually reducing stock
 func (s *BatchService) DeductFromBatches(produkID int, qtyToDeduct float64) error {
-	_, err := s.ReduceBatchQtyFIFO(produkID, qtyToDeduct)
+	_, err := s.ReduceBatchQty(produkID, qtyToDeduct)
 	return err
 }
 
+// validateKebijakanBatch normalizes and validates a batch consumption policy (empty means inherit)
+func validateKebijakanBatch(kebijakan *string) error {
+	*kebijakan = strings.ToLower(strings.TrimSpace(*kebijakan))
+	switch *kebijakan {
+	case "", models.KebijakanBatchFIFO, models.KebijakanBatchFEFO, models.KebijakanBatchFEFOLewatiKadaluarsa:
+		return nil
+	}
+	return fmt.Errorf("kebijakan batch tidak valid: %s (gunakan %s, %s atau %s)", *kebijakan,
+		models.KebijakanBatchFIFO, models.KebijakanBatchFEFO, models.KebijakanBatchFEFOLewatiKadaluarsa)
+}
+
+// ValidateDeduction checks that a stock reduction that is not a write-off does not
+// need stock held in expired batches when the product's policy skips them
+func (s *BatchService) ValidateDeduction(produk *models.Produk, qtyToDeduct float64) error {
+	kebijakan, err := s.batchRepo.GetKebijakanBatch(produk.ID)
+	if err != nil {
+		return err
+	}
+	if kebijakan != models.KebijakanBatchFEFOLewatiKadaluarsa {
+		return nil
+	}
+
+	kadaluarsa, err := s.batchRepo.GetQtyKadaluarsa(produk.ID)
+	if err != nil {
+		return err
+	}
+	if kadaluarsa > 0 && produk.Stok-kadaluarsa < qtyToDeduct {
+		return fmt.Errorf("stok %s yang belum kadaluarsa tidak mencukupi, %.2f berada di batch kadaluarsa dan hanya dapat dihapus sebagai kerugian kadaluarsa",
+			produk.Nama, kadaluarsa)
+	}
+	return nil
+}
+
+// WriteOffFromBatches deducts written-off stock (damaged, lost, expired) from batches
+// in the order of the product's batch policy and records how much was written off from each batch
+func (s *BatchService) WriteOffFromBatches(produkID int, qtyToDeduct float64, tipeKerugian, keterangan string) error {
+	potongan, err := s.reduceBatchQty(produkID, qtyToDeduct, tipeKerugian)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range potongan {
+		if err := s.batchRepo.CreatePenghapusan(p.batchID, produkID, p.qty, tipeKerugian, keterangan); err != nil {
+			return fmt.Errorf("failed to record write-off of batch %s: %w", p.batchID, err)
+		}
+	}
+
+	return nil
+}
+
+// TarikBatch recalls a batch: its remaining stock is written off as a recall loss and
+// it can no longer be sold, while units returned later are written off on receipt
+func (s *BatchService) TarikBatch(req *models.TarikBatchRequest) (*models.PenarikanBatch, error) {
+	batchID := strings.TrimSpace(req.BatchID)
+	alasan := strings.TrimSpace(req.Alasan)
+	if batchID == "" {
+		return nil, fmt.Errorf("batch harus diisi")
+	}
+	if alasan == "" {
+		return nil, fmt.Errorf("alasan penarikan harus diisi")
+	}
+
+	dihapus, err := s.batchRepo.Tarik(batchID, alasan, strings.TrimSpace(req.DitarikOleh))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[BATCH SERVICE] Batch %s recalled (%s), %.2f units written off", batchID, alasan, dihapus)
+
+	return s.GetPenarikanBatch(batchID)
+}
+
+// GetPenarikanBatch lists the sales and customers that received stock from a batch
+func (s *BatchService) GetPenarikanBatch(batchID string) (*models.PenarikanBatch, error) {
+	batch, err := s.batchRepo.GetBatchByID(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaksi, err := s.batchRepo.GetPenarikan(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	penarikan := &models.PenarikanBatch{Batch: batch, Transaksi: transaksi}
+	if produk, err := s.produkRepo.GetByID(batch.ProdukID); err == nil && produk != nil {
+		penarikan.SKU = produk.SKU
+		penarikan.NamaProduk = produk.Nama
+	}
+
+	pelanggan := make(map[int]bool)
+	for _, t := range transaksi {
+		penarikan.QtyTerjual += t.Qty
+		penarikan.QtyDikembalikan += t.QtyDikembalikan
+		if t.PelangganID > 0 && t.Qty > 0 {
+			pelanggan[t.PelangganID] = true
+		}
+	}
+	penarikan.JumlahPelanggan = len(pelanggan)
+
+	return penarikan, nil
+}
+
+// ExportPenarikanBatch exports the sales and customers of a batch as CSV for
+// contacting customers and reporting to the supplier
+func (s *BatchService) ExportPenarikanBatch(batchID string) ([]byte, string, error) {
+	penarikan, err := s.GetPenarikanBatch(batchID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"Batch", "SKU", "Produk", "No. Transaksi", "Tanggal", "Status", "Qty", "Qty Dikembalikan", "Pelanggan", "Telepon", "Email"})
+	for _, t := range penarikan.Transaksi {
+		w.Write([]string{
+			penarikan.Batch.ID,
+			penarikan.SKU,
+			penarikan.NamaProduk,
+			t.NomorTransaksi,
+			t.Tanggal.Format("2006-01-02 15:04"),
+			t.Status,
+			fmt.Sprintf("%g", t.Qty),
+			fmt.Sprintf("%g", t.QtyDikembalikan),
+			t.NamaPelanggan,
+			t.Telepon,
+			t.Email,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return buf.Bytes(), fmt.Sprintf("penarikan-batch-%s.csv", penarikan.Batch.ID), nil
+}
+
 // calculateBatchStatus determines batch status based on expiry date and notification threshold
 // notificationDays: how many days before expiry to consider "hampir_expired"
 // Uses date comparison (ignoring time) for consistency with SQL julianday calculation
@@ -16,6 +16,7 @@ type DashboardService struct {
 	batchRepo     *repository.BatchRepository
 	promoRepo     *repository.PromoRepository
 	returnRepo    *repository.ReturnRepository
+	piutangRepo   *repository.PiutangRepository
 }
 
 // NewDashboardService creates a new dashboard service
@@ -26,6 +27,7 @@ func NewDashboardService() *DashboardService {
 		batchRepo:     repository.NewBatchRepository(),
 		promoRepo:     repository.NewPromoRepository(),
 		returnRepo:    repository.NewReturnRepository(),
+		piutangRepo:   repository.NewPiutangRepository(),
 	}
 }
 
@@ -86,13 +88,13 @@ func (s *DashboardService) GetStatistikBulanan() (*models.DashboardStatistikBula
 	previousMonthEnd := currentMonthStart.Add(-time.Second)
 
 	// Get current month data
-	currentMonthTransactions, err := s.transaksiRepo.GetByDateRange(currentMonthStart, currentMonthEnd)
+	currentMonthTransactions, err := s.transaksiRepo.GetSalesByDateRange(currentMonthStart, currentMonthEnd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current month transactions: %w", err)
 	}
 
 	// Get previous month data
-	previousMonthTransactions, err := s.transaksiRepo.GetByDateRange(previousMonthStart, previousMonthEnd)
+	previousMonthTransactions, err := s.transaksiRepo.GetSalesByDateRange(previousMonthStart, previousMonthEnd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get previous month transactions: %w", err)
 	}
@@ -103,8 +105,6 @@ func (s *DashboardService) GetStatistikBulanan() (*models.DashboardStatistikBula
 	var currentProdukTerjual int
 	var currentTotalHargaBeli float64
 
-	productHPPCache := make(map[int]int) // Cache for product HPP
-
 	for _, t := range currentMonthTransactions {
 		currentTotalPendapatan += float64(t.Total)
 		currentTotalTransaksi++
@@ -115,27 +115,17 @@ func (s *DashboardService) GetStatistikBulanan() (*models.DashboardStatistikBula
 			for _, d := range details.Items {
 				currentProdukTerjual += d.Jumlah
 
-				// Get produk for harga beli (ProdukID might be nil if product was deleted)
+				// HPP frozen when the item was sold (ProdukID might be nil if product was deleted)
 				if d.ProdukID != nil {
-					var hpp int
-					// Use cache to avoid repeated DB calls
-					if cachedHPP, ok := productHPPCache[*d.ProdukID]; ok {
-						hpp = cachedHPP
-					} else {
-						produk, err := s.produkRepo.GetByID(*d.ProdukID)
-						if err == nil {
-							hpp = produk.HargaBeli
-							productHPPCache[*d.ProdukID] = hpp
-						}
-					}
+					hpp := d.HargaPokok
 
 					// For curah products, calculate based on weight (grams)
 					if d.BeratGram > 0 {
 						// Curah product: HPP = (berat_gram / 1000) * harga_beli_per_kg
 						currentTotalHargaBeli += (d.BeratGram / 1000.0) * float64(hpp)
 					} else {
-						// Regular product: HPP = jumlah * harga_beli
-						currentTotalHargaBeli += float64(d.Jumlah) * float64(hpp)
+						// Regular product: HPP = jumlah * konversi satuan * harga_beli
+						currentTotalHargaBeli += float64(d.Jumlah) * d.Konversi * float64(hpp)
 					}
 				}
 			}
@@ -192,12 +182,12 @@ func (s *DashboardService) GetNotifikasi() ([]models.DashboardNotifikasi, error)
 	notifikasi := []models.DashboardNotifikasi{}
 	notifID := 1
 
-	// Check for low stock products
+	// Check for low stock products; the reorder point applies when set, otherwise fewer than 10 items
 	allProducts, err := s.produkRepo.GetAll()
 	if err == nil {
 		lowStockCount := 0
 		for _, p := range allProducts {
-			if p.Stok < 10 {
+			if (p.StokMinimum > 0 && p.Stok <= p.StokMinimum) || (p.StokMinimum == 0 && p.Stok < 10) {
 				lowStockCount++
 			}
 		}
@@ -206,7 +196,7 @@ func (s *DashboardService) GetNotifikasi() ([]models.DashboardNotifikasi, error)
 				ID:       notifID,
 				Type:     "low-stock",
 				Title:    "Stok Menipis",
-				Message:  fmt.Sprintf("%d produk dengan stok kurang dari 10 item", lowStockCount),
+				Message:  fmt.Sprintf("%d produk dengan stok menipis (di bawah stok minimum atau kurang dari 10 item)", lowStockCount),
 				Priority: "high",
 				Time:     time.Now().Format("15:04"),
 			})
@@ -260,6 +250,34 @@ func (s *DashboardService) GetNotifikasi() ([]models.DashboardNotifikasi, error)
 		}
 	}
 
+	// Check for outstanding customer receivables (kasbon)
+	openPiutang, err := s.piutangRepo.GetAll(0, models.StatusPiutangBelumLunas)
+	if err == nil && len(openPiutang) > 0 {
+		totalPiutang := 0
+		overdue := 0
+		for _, p := range openPiutang {
+			totalPiutang += p.Sisa
+			if p.UmurHari > 30 {
+				overdue++
+			}
+		}
+		message := fmt.Sprintf("%d nota kasbon belum lunas, total Rp %d", len(openPiutang), totalPiutang)
+		priority := "medium"
+		if overdue > 0 {
+			message += fmt.Sprintf(" (%d lebih dari 30 hari)", overdue)
+			priority = "high"
+		}
+		notifikasi = append(notifikasi, models.DashboardNotifikasi{
+			ID:       notifID,
+			Type:     "piutang",
+			Title:    "Piutang Pelanggan",
+			Message:  message,
+			Priority: priority,
+			Time:     time.Now().Format("15:04"),
+		})
+		notifID++
+	}
+
 	return notifikasi, nil
 }
 
@@ -270,13 +288,13 @@ func (s *DashboardService) GetPerformaHariIni() ([]models.DashboardPerforma, err
 	yesterdayStart := todayStart.Add(-24 * time.Hour)
 
 	// Get today's transactions
-	todayTransactions, err := s.transaksiRepo.GetByDateRange(todayStart, now)
+	todayTransactions, err := s.transaksiRepo.GetSalesByDateRange(todayStart, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get today transactions: %w", err)
 	}
 
 	// Get yesterday's transactions for comparison
-	yesterdayTransactions, err := s.transaksiRepo.GetByDateRange(yesterdayStart, todayStart)
+	yesterdayTransactions, err := s.transaksiRepo.GetSalesByDateRange(yesterdayStart, todayStart)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get yesterday transactions: %w", err)
 	}
@@ -406,7 +424,7 @@ func (s *DashboardService) GetProdukTerlaris(limit int) ([]models.DashboardProdu
 	now := time.Now()
 	thirtyDaysAgo := now.AddDate(0, 0, -30)
 
-	transactions, err := s.transaksiRepo.GetByDateRange(thirtyDaysAgo, now)
+	transactions, err := s.transaksiRepo.GetSalesByDateRange(thirtyDaysAgo, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -482,7 +500,7 @@ func (s *DashboardService) GetAktivitasTerakhir(limit int) ([]models.DashboardAk
 	oneDayAgo := now.AddDate(0, 0, -1)
 
 	// Get recent transactions
-	recentTransactions, err := s.transaksiRepo.GetByDateRange(oneDayAgo, now)
+	recentTransactions, err := s.transaksiRepo.GetSalesByDateRange(oneDayAgo, now)
 	if err == nil && len(recentTransactions) > 0 {
 		for _, tx := range recentTransactions {
 			aktivitas = append(aktivitas, models.DashboardAktivitasWithTime{
@@ -635,7 +653,7 @@ func (s *DashboardService) GetSalesChartData() (*models.DashboardSalesData, erro
 		slotEnd := slotStart.Add(3 * time.Hour)
 
 		// Get transactions in this slot
-		transactions, err := s.transaksiRepo.GetByDateRange(slotStart, slotEnd)
+		transactions, err := s.transaksiRepo.GetSalesByDateRange(slotStart, slotEnd)
 		if err != nil {
 			hariData = append(hariData, 0)
 			continue
@@ -665,7 +683,7 @@ func (s *DashboardService) GetSalesChartData() (*models.DashboardSalesData, erro
 		dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 		dayEnd := dayStart.Add(24 * time.Hour)
 
-		transactions, err := s.transaksiRepo.GetByDateRange(dayStart, dayEnd)
+		transactions, err := s.transaksiRepo.GetSalesByDateRange(dayStart, dayEnd)
 		if err != nil {
 			mingguData = append(mingguData, 0)
 			continue
@@ -694,7 +712,7 @@ func (s *DashboardService) GetSalesChartData() (*models.DashboardSalesData, erro
 		dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 		dayEnd := dayStart.Add(24 * time.Hour)
 
-		transactions, err := s.transaksiRepo.GetByDateRange(dayStart, dayEnd)
+		transactions, err := s.transaksiRepo.GetSalesByDateRange(dayStart, dayEnd)
 		if err != nil {
 			bulanData = append(bulanData, 0)
 			continue
@@ -760,7 +778,7 @@ func (s *DashboardService) calculateCategoryComposition(start, end time.Time) mo
 
 	log.Printf("calculateCategoryComposition: Mencari data dari %s hingga %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
 
-	transactions, err := s.transaksiRepo.GetByDateRange(start, end)
+	transactions, err := s.transaksiRepo.GetSalesByDateRange(start, end)
 	if err != nil {
 		return models.DashboardCompositionPeriod{Labels: []string{}, Data: []float64{}}
 	}
@@ -923,7 +941,7 @@ func (s *DashboardService) calculateCategoryTrends(endTime time.Time, periods in
 	log.Printf("calculateCategoryTrends (%s): Mencari data dari %s hingga %s", periodType, overallStart.Format(time.RFC3339), overallEnd.Format(time.RFC3339))
 
 	// Fetch all transactions in the overall range
-	overallTransactions, err := s.transaksiRepo.GetByDateRange(overallStart, overallEnd)
+	overallTransactions, err := s.transaksiRepo.GetSalesByDateRange(overallStart, overallEnd)
 	if err != nil {
 		return models.DashboardCategoryPeriod{Labels: periodLabels, Datasets: []models.CategoryChartDataset{}}
 	}
@@ -1014,7 +1032,7 @@ func (s *DashboardService) calculateCategoryTrends(endTime time.Time, periods in
 			periodStart = periodEnd.AddDate(0, -1, 0)
 		}
 
-		transactions, err := s.transaksiRepo.GetByDateRange(periodStart, periodEnd)
+		transactions, err := s.transaksiRepo.GetSalesByDateRange(periodStart, periodEnd)
 		if err != nil {
 			continue // Skip this period if error
 		}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"time"
+)
+
+// Idempotency scopes, a key is only unique within its scope
+const (
+	IdempotencyScopeTransaksi     = "transaksi"
+	IdempotencyScopeReturn        = "return"
+	IdempotencyScopeStok          = "stok"
+	IdempotencyScopeStokIncrement = "stok_increment"
+)
+
+// maxIdempotencyKeyLength limits the length of client supplied keys
+const maxIdempotencyKeyLength = 255
+
+// idempotencyLease is how long a "processing" key blocks retries. Keys are stored
+// completed in the transaction of the write they protect, so a "processing" key is
+// only left by a request from before that; after the lease the write overwrites it.
+const idempotencyLease = 10 * time.Minute
+
+// idempotencyCompleteAttempts is how often storing a completed key is tried
+// before the request reports that its response could not be saved
+const idempotencyCompleteAttempts = 3
+
+var (
+	// ErrIdempotencyKeyReused is returned when a key is replayed with a different payload
+	ErrIdempotencyKeyReused = errors.New("idempotency key sudah digunakan untuk request yang berbeda")
+
+	// ErrIdempotencyKeyInProgress is returned when the original request is still being processed
+	ErrIdempotencyKeyInProgress = errors.New("request dengan idempotency key ini masih diproses")
+)
+
+// IdempotencyService deduplicates retried write requests by idempotency key
+type IdempotencyService struct {
+	repo *repository.IdempotencyRepository
+}
+
+// NewIdempotencyService creates a new instance
+func NewIdempotencyService() *IdempotencyService {
+	return &IdempotencyService{
+		repo: repository.NewIdempotencyRepository(),
+	}
+}
+
+// Lookup checks a key before a request that stores the key in its own database
+// transaction. It returns the payload hash to store with the key, or the completed
+// record whose response must be replayed.
+func (s *IdempotencyService) Lookup(scope, key string, payload interface{}) (string, *models.IdempotencyRecord, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return "", nil, fmt.Errorf("idempotency key maksimal %d karakter", maxIdempotencyKeyLength)
+	}
+
+	requestHash, err := hashPayload(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	record, err := s.repo.Get(scope, key)
+	if err != nil {
+		return "", nil, err
+	}
+	if record == nil {
+		return requestHash, nil, nil
+	}
+	if record.Status != "completed" && record.RequestHash == requestHash &&
+		time.Since(record.CreatedAt) > idempotencyLease {
+		// Left behind by a request that died; the write transaction overwrites it
+		return requestHash, nil, nil
+	}
+
+	if err := cekIdempotencyRecord(record, requestHash); err != nil {
+		return "", nil, err
+	}
+
+	log.Printf("[IDEMPOTENCY] Replaying %s response for key %s", scope, key)
+	return requestHash, record, nil
+}
+
+// Complete stores the response of a successfully processed request. The write is
+// retried a few times, since a key left in "processing" blocks retries until the
+// lease expires.
+func (s *IdempotencyService) Complete(scope, key string, result interface{}) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotent response: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = s.repo.Complete(scope, key, string(response))
+		if err == nil || attempt == idempotencyCompleteAttempts {
+			return err
+		}
+		log.Printf("[IDEMPOTENCY] Failed to complete %s key %s (attempt %d): %v", scope, key, attempt, err)
+		time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+	}
+}
+
+// Stored returns the completed record of a key that a concurrent request with the
+// same key committed first, checked against the payload hash of this request
+func (s *IdempotencyService) Stored(scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+	record, err := s.repo.Get(scope, key)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("idempotency key %s tidak ditemukan", key)
+	}
+
+	if err := cekIdempotencyRecord(record, requestHash); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[IDEMPOTENCY] Replaying %s response for key %s", scope, key)
+	return record, nil
+}
+
+// cekIdempotencyRecord decides whether a stored key may be replayed for a request
+// with the given payload hash
+func cekIdempotencyRecord(record *models.IdempotencyRecord, requestHash string) error {
+	if record.RequestHash != requestHash {
+		return ErrIdempotencyKeyReused
+	}
+	if record.Status != "completed" {
+		return ErrIdempotencyKeyInProgress
+	}
+	return nil
+}
+
+// hashPayload returns the SHA-256 of the JSON encoded payload
+func hashPayload(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request payload: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCekIdempotencyRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record models.IdempotencyRecord
+		hash   string
+		want   error
+	}{
+		{"completed with same payload is replayed", models.IdempotencyRecord{RequestHash: "abc", Status: "completed"}, "abc", nil},
+		{"completed with different payload", models.IdempotencyRecord{RequestHash: "abc", Status: "completed"}, "def", ErrIdempotencyKeyReused},
+		{"processing with same payload", models.IdempotencyRecord{RequestHash: "abc", Status: "processing"}, "abc", ErrIdempotencyKeyInProgress},
+		{"processing with different payload", models.IdempotencyRecord{RequestHash: "abc", Status: "processing"}, "def", ErrIdempotencyKeyReused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cekIdempotencyRecord(&tt.record, tt.hash))
+		})
+	}
+}
+
+func TestHashPayload(t *testing.T) {
+	a := models.UpdateStokRequest{ProdukID: 1, Perubahan: 5}
+	b := models.UpdateStokRequest{ProdukID: 1, Perubahan: 5, IdempotencyKey: "retry-1"}
+	c := models.UpdateStokRequest{ProdukID: 1, Perubahan: 6}
+
+	hashA, err := hashPayload(&a)
+	assert.NoError(t, err)
+	hashC, err := hashPayload(&c)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hashA, hashC, "different payloads must not share a hash")
+
+	// Callers blank the key before hashing, so the same payload hashes the same
+	b.IdempotencyKey = ""
+	hashB, err := hashPayload(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, hashA, hashB)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+)
+
+// kodeKartuHadiahChars excludes characters that are easily confused when read aloud (0/O, 1/I)
+const kodeKartuHadiahChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// KartuHadiahService handles gift cards and store credit vouchers
+type KartuHadiahService struct {
+	repo          *repository.KartuHadiahRepository
+	transaksiRepo *repository.TransaksiRepository
+	settingsRepo  *repository.SettingsRepository
+	pelangganRepo *repository.PelangganRepository
+	shiftService  *ShiftService
+	metodeService *MetodePembayaranService
+}
+
+// NewKartuHadiahService creates a new instance
+func NewKartuHadiahService() *KartuHadiahService {
+	return &KartuHadiahService{
+		repo:          repository.NewKartuHadiahRepository(),
+		transaksiRepo: repository.NewTransaksiRepository(),
+		settingsRepo:  repository.NewSettingsRepository(),
+		pelangganRepo: repository.NewPelangganRepository(),
+		shiftService:  NewShiftService(),
+		metodeService: NewMetodePembayaranService(),
+	}
+}
+
+// GetAllKartuHadiah lists gift cards, optionally filtered by status
+func (s *KartuHadiahService) GetAllKartuHadiah(status string) ([]*models.KartuHadiah, error) {
+	switch status {
+	case "", models.StatusKartuHadiahAktif, models.StatusKartuHadiahHabis,
+		models.StatusKartuHadiahKedaluwarsa, models.StatusKartuHadiahBatal:
+	default:
+		return nil, fmt.Errorf("status kartu hadiah tidak valid")
+	}
+	return s.repo.GetAll(status)
+}
+
+// GetKartuHadiah retrieves a gift card with its balance history
+func (s *KartuHadiahService) GetKartuHadiah(kode string) (*models.KartuHadiahDetail, error) {
+	kartu, err := s.repo.GetByKode(kode)
+	if err != nil {
+		return nil, err
+	}
+	if kartu == nil {
+		return nil, fmt.Errorf("kartu hadiah tidak ditemukan")
+	}
+
+	mutasi, err := s.repo.GetMutasi(kartu.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.KartuHadiahDetail{Kartu: kartu, Mutasi: mutasi}, nil
+}
+
+// JualKartuHadiah sells a new gift card as a transaction during an open shift
+func (s *KartuHadiahService) JualKartuHadiah(req *models.JualKartuHadiahRequest) (*models.JualKartuHadiahResponse, error) {
+	if req.Nominal <= 0 {
+		return nil, fmt.Errorf("nominal kartu hadiah harus lebih dari 0")
+	}
+	if len(req.Pembayaran) == 0 {
+		return nil, fmt.Errorf("metode pembayaran harus diisi")
+	}
+
+	if err := s.metodeService.ValidatePembayaran(req.Pembayaran, req.Nominal); err != nil {
+		return nil, err
+	}
+	totalBayar := 0
+	for _, p := range req.Pembayaran {
+		if p.Metode == models.MetodeKasbon || p.Metode == models.MetodeKartuHadiah {
+			return nil, fmt.Errorf("kartu hadiah tidak dapat dibeli dengan %s", p.Metode)
+		}
+		if p.Jumlah <= 0 {
+			return nil, fmt.Errorf("jumlah pembayaran harus lebih dari 0")
+		}
+		totalBayar += p.Jumlah
+	}
+	if totalBayar < req.Nominal {
+		return nil, fmt.Errorf("pembayaran tidak mencukupi. Kurang: Rp %d", req.Nominal-totalBayar)
+	}
+
+	var pelangganID *int
+	if req.PelangganID > 0 {
+		pelanggan, err := s.pelangganRepo.GetByID(req.PelangganID)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mengambil data pelanggan: %w", err)
+		}
+		if pelanggan == nil {
+			return nil, fmt.Errorf("pelanggan tidak ditemukan")
+		}
+		req.PelangganNama = pelanggan.Nama
+		req.PelangganTelp = pelanggan.Telepon
+		pelangganID = &req.PelangganID
+	}
+
+	shift, err := s.shiftService.requireShiftAktif(req.KodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+	req.ShiftID = shift.ID
+
+	settings, err := s.settingsRepo.GetKartuHadiahSettings()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil pengaturan kartu hadiah: %w", err)
+	}
+
+	kode, err := s.generateKode()
+	if err != nil {
+		return nil, err
+	}
+
+	kartu := &models.KartuHadiah{
+		Kode:        kode,
+		Sumber:      models.SumberKartuHadiahPenjualan,
+		SaldoAwal:   req.Nominal,
+		PelangganID: pelangganID,
+		ExpiredAt:   masaBerlakuKartuHadiah(time.Now(), settings.MasaBerlakuHari),
+	}
+
+	transaksiID, err := s.repo.Jual(kartu, req)
+	if err != nil {
+		return nil, err
+	}
+
+	transaksi, err := s.transaksiRepo.GetByID(transaksiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	log.Printf("[KARTU HADIAH] Kartu %s senilai Rp %d dijual oleh %s", kartu.Kode, kartu.SaldoAwal, req.StaffNama)
+	return &models.JualKartuHadiahResponse{Kartu: kartu, Transaksi: transaksi}, nil
+}
+
+// SiapkanDariRetur prepares store credit for the refund of a return; the card is
+// stored by the return in the same database transaction as the return itself
+func (s *KartuHadiahService) SiapkanDariRetur(nominal int, pelangganID int) (*models.KartuHadiah, error) {
+	if nominal <= 0 {
+		return nil, fmt.Errorf("nilai refund harus lebih dari 0 untuk diterbitkan sebagai kartu hadiah")
+	}
+
+	settings, err := s.settingsRepo.GetKartuHadiahSettings()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil pengaturan kartu hadiah: %w", err)
+	}
+
+	kode, err := s.generateKode()
+	if err != nil {
+		return nil, err
+	}
+
+	kartu := &models.KartuHadiah{
+		Kode:      kode,
+		Sumber:    models.SumberKartuHadiahRetur,
+		SaldoAwal: nominal,
+		ExpiredAt: masaBerlakuKartuHadiah(time.Now(), settings.MasaBerlakuReturHari),
+	}
+	if pelangganID > 0 {
+		kartu.PelangganID = &pelangganID
+	}
+
+	return kartu, nil
+}
+
+// KedaluwarsakanKartuHadiah forfeits the balance of cards past their expiry date
+func (s *KartuHadiahService) KedaluwarsakanKartuHadiah() (*models.KedaluwarsaKartuHadiahResponse, error) {
+	jumlah, total, err := s.repo.Kedaluwarsakan(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if jumlah > 0 {
+		log.Printf("[KARTU HADIAH] %d kartu kedaluwarsa, saldo hangus Rp %d", jumlah, total)
+	}
+	return &models.KedaluwarsaKartuHadiahResponse{JumlahKartu: jumlah, TotalSaldo: total}, nil
+}
+
+// generateKode returns a random card code that is not in use yet, e.g. GC7KQ2M9XHTA
+func (s *KartuHadiahService) generateKode() (string, error) {
+	max := big.NewInt(int64(len(kodeKartuHadiahChars)))
+	for attempt := 0; attempt < 5; attempt++ {
+		kode := make([]byte, 10)
+		for i := range kode {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", fmt.Errorf("failed to generate kode kartu hadiah: %w", err)
+			}
+			kode[i] = kodeKartuHadiahChars[n.Int64()]
+		}
+
+		existing, err := s.repo.GetByKode("GC" + string(kode))
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return "GC" + string(kode), nil
+		}
+	}
+
+	return "", fmt.Errorf("gagal membuat kode kartu hadiah yang unik")
+}
+
+// masaBerlakuKartuHadiah returns the expiry of a card issued at waktu: the card
+// stays valid until the end of the last day. Zero days means it never expires.
+func masaBerlakuKartuHadiah(waktu time.Time, hari int) *time.Time {
+	if hari <= 0 {
+		return nil
+	}
+	expired := time.Date(waktu.Year(), waktu.Month(), waktu.Day()+hari+1, 0, 0, 0, 0, waktu.Location())
+	return &expired
+}
@@ -26,6 +26,13 @@ func (s *KategoriService) CreateKategori(kategori *models.Kategori) error {
 	if strings.TrimSpace(kategori.Nama) == "" {
 		return fmt.Errorf("category name is required")
 	}
+	kategori.KodePajak = strings.ToUpper(strings.TrimSpace(kategori.KodePajak))
+	if err := validateKodePajak(kategori.KodePajak); err != nil {
+		return err
+	}
+	if err := validateKebijakanBatch(&kategori.KebijakanBatch); err != nil {
+		return err
+	}
 
 	// Check if name already exists
 	existing, err := s.kategoriRepo.GetByNama(kategori.Nama)
@@ -72,6 +79,13 @@ func (s *KategoriService) UpdateKategori(kategori *models.Kategori) error {
 	if strings.TrimSpace(kategori.Nama) == "" {
 		return fmt.Errorf("category name is required")
 	}
+	kategori.KodePajak = strings.ToUpper(strings.TrimSpace(kategori.KodePajak))
+	if err := validateKodePajak(kategori.KodePajak); err != nil {
+		return err
+	}
+	if err := validateKebijakanBatch(&kategori.KebijakanBatch); err != nil {
+		return err
+	}
 
 	// Check if category exists
 	existing, err := s.kategoriRepo.GetByID(kategori.ID)
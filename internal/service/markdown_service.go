@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MarkdownService manages near-expiry markdown rules and prices sale lines from
+// the expiry date of the batches they will be taken from
+type MarkdownService struct {
+	repo      *repository.AturanMarkdownRepository
+	batchRepo *repository.BatchRepository
+}
+
+// NewMarkdownService creates a new instance
+func NewMarkdownService() *MarkdownService {
+	return &MarkdownService{
+		repo:      repository.NewAturanMarkdownRepository(),
+		batchRepo: repository.NewBatchRepository(),
+	}
+}
+
+// GetAllAturan retrieves all markdown rules, including inactive ones
+func (s *MarkdownService) GetAllAturan() ([]*models.AturanMarkdown, error) {
+	return s.repo.GetAll()
+}
+
+// CreateAturan adds a markdown rule
+func (s *MarkdownService) CreateAturan(aturan *models.AturanMarkdown) error {
+	if err := validateAturanMarkdown(aturan); err != nil {
+		return err
+	}
+	return s.repo.Create(aturan)
+}
+
+// UpdateAturan updates a markdown rule
+func (s *MarkdownService) UpdateAturan(aturan *models.AturanMarkdown) error {
+	if err := validateAturanMarkdown(aturan); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(aturan.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("aturan markdown tidak ditemukan")
+	}
+
+	return s.repo.Update(aturan)
+}
+
+// DeleteAturan removes a markdown rule
+func (s *MarkdownService) DeleteAturan(id int) error {
+	return s.repo.Delete(id)
+}
+
+// HitungMarkdown sets the markdown of each sale line from the batches the line
+// will consume, following the batch policy of the product. Units taken from a
+// batch that expires within the days of an active rule get that rule's
+// percentage off. Lines with a manual price or a line discount are left as they
+// are, so a markdown never stacks on a discount the cashier already gave.
+func (s *MarkdownService) HitungMarkdown(items []models.TransaksiItemRequest) error {
+	var aturan []*models.AturanMarkdown
+	aturanDimuat := false
+	terpakai := make(map[string]float64) // batch ID -> qty yang sudah diambil baris sebelumnya
+	hariIni := tanggalSaja(time.Now())
+
+	for i := range items {
+		item := &items[i]
+		item.DiskonMarkdown = 0
+		item.KeteranganMarkdown = ""
+
+		if item.HargaSatuan != item.HargaAsli || item.DiskonItem > 0 {
+			continue
+		}
+
+		if !aturanDimuat {
+			var err error
+			aturan, err = s.repo.GetAktif()
+			if err != nil {
+				return err
+			}
+			aturanDimuat = true
+		}
+		if len(aturan) == 0 {
+			return nil
+		}
+
+		var kotor int
+		var qty float64
+		if item.BeratGram > 0 {
+			kotor = int((item.BeratGram / 1000.0) * float64(item.HargaSatuan))
+			qty = item.BeratGram / 1000.0
+		} else {
+			konversi := item.Konversi
+			if konversi <= 0 {
+				konversi = 1
+			}
+			kotor = item.HargaSatuan * item.Jumlah
+			qty = float64(item.Jumlah) * konversi
+		}
+		if kotor <= 0 || qty <= 0 {
+			continue
+		}
+
+		kebijakan, err := s.batchRepo.GetKebijakanBatch(item.ProdukID)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		batches, err := s.batchRepo.GetBatchesKonsumsi(item.ProdukID, kebijakan, kebijakan == models.KebijakanBatchFEFOLewatiKadaluarsa)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+
+		// Qty per persen markdown, diambil dari batch sesuai urutan konsumsi
+		qtyPerPersen := make(map[float64]float64)
+		sisa := qty
+		for _, batch := range batches {
+			if sisa <= 0 {
+				break
+			}
+			tersedia := batch.QtyTersisa - terpakai[batch.ID]
+			if tersedia <= 0 {
+				continue
+			}
+			ambil := math.Min(tersedia, sisa)
+			terpakai[batch.ID] += ambil
+			sisa -= ambil
+
+			sisaHari := int(tanggalSaja(batch.TanggalKadaluarsa).Sub(hariIni).Hours() / 24)
+			if persen := persenMarkdown(aturan, sisaHari); persen > 0 {
+				qtyPerPersen[persen] += ambil
+			}
+		}
+		if len(qtyPerPersen) == 0 {
+			continue
+		}
+
+		persenList := make([]float64, 0, len(qtyPerPersen))
+		for persen := range qtyPerPersen {
+			persenList = append(persenList, persen)
+		}
+		sort.Float64s(persenList)
+
+		var markdown float64
+		keterangan := make([]string, 0, len(persenList))
+		for _, persen := range persenList {
+			markdown += float64(kotor) * qtyPerPersen[persen] / qty * persen / 100
+			keterangan = append(keterangan, fmt.Sprintf("%s%% x %s", formatAngka(persen), formatAngka(qtyPerPersen[persen])))
+		}
+		item.DiskonMarkdown = int(math.Round(markdown))
+		if item.DiskonMarkdown <= 0 {
+			item.DiskonMarkdown = 0
+			continue
+		}
+		item.KeteranganMarkdown = "Markdown " + strings.Join(keterangan, ", ")
+	}
+
+	return nil
+}
+
+// persenMarkdown returns the largest percentage of the rules that apply to a
+// batch with the given days left until it expires, or 0 if none applies
+func persenMarkdown(aturan []*models.AturanMarkdown, sisaHari int) float64 {
+	var persen float64
+	for _, a := range aturan {
+		if sisaHari <= a.HariSebelum && a.Persen > persen {
+			persen = a.Persen
+		}
+	}
+	return persen
+}
+
+// tanggalSaja keeps only the calendar date of a time, so dates read from the
+// database and the local clock can be compared in whole days
+func tanggalSaja(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// formatAngka formats a number without trailing zero decimals
+func formatAngka(n float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", n), "0"), ".")
+}
+
+func validateAturanMarkdown(aturan *models.AturanMarkdown) error {
+	aturan.Nama = strings.TrimSpace(aturan.Nama)
+	if aturan.Nama == "" {
+		return fmt.Errorf("nama aturan markdown harus diisi")
+	}
+	if aturan.HariSebelum < 0 {
+		return fmt.Errorf("hari sebelum kadaluarsa tidak boleh negatif")
+	}
+	if aturan.Persen <= 0 || aturan.Persen > 100 {
+		return fmt.Errorf("persen markdown harus antara 0 dan 100")
+	}
+	return nil
+}
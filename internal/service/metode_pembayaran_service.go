@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"strings"
+)
+
+// maxPanjangKodeMetode is the maximum length of a payment method code
+const maxPanjangKodeMetode = 30
+
+// MetodePembayaranService handles the payment method registry and split-tender rules
+type MetodePembayaranService struct {
+	repo *repository.MetodePembayaranRepository
+}
+
+// NewMetodePembayaranService creates a new instance
+func NewMetodePembayaranService() *MetodePembayaranService {
+	return &MetodePembayaranService{
+		repo: repository.NewMetodePembayaranRepository(),
+	}
+}
+
+// GetAllMetodePembayaran retrieves all payment methods, including inactive ones
+func (s *MetodePembayaranService) GetAllMetodePembayaran() ([]*models.MetodePembayaran, error) {
+	return s.repo.GetAll()
+}
+
+// GetMetodePembayaranAktif retrieves the payment methods the cashier may use
+func (s *MetodePembayaranService) GetMetodePembayaranAktif() ([]*models.MetodePembayaran, error) {
+	list, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	aktif := make([]*models.MetodePembayaran, 0, len(list))
+	for _, m := range list {
+		if m.Aktif {
+			aktif = append(aktif, m)
+		}
+	}
+	return aktif, nil
+}
+
+// CreateMetodePembayaran adds a payment method to the registry
+func (s *MetodePembayaranService) CreateMetodePembayaran(metode *models.MetodePembayaran) error {
+	metode.Kode = strings.ToLower(strings.TrimSpace(metode.Kode))
+	if !isValidKodeMetode(metode.Kode) {
+		return fmt.Errorf("kode metode pembayaran harus 1-%d karakter huruf kecil, angka atau garis bawah", maxPanjangKodeMetode)
+	}
+	if err := validateMetodePembayaran(metode); err != nil {
+		return err
+	}
+
+	// Pastikan registry sudah berisi metode default sebelum cek duplikat
+	if _, err := s.repo.GetAll(); err != nil {
+		return err
+	}
+	existing, err := s.repo.GetByKode(metode.Kode)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("metode pembayaran dengan kode '%s' sudah ada", metode.Kode)
+	}
+
+	return s.repo.Create(metode)
+}
+
+// UpdateMetodePembayaran updates a payment method. The code cannot be changed
+// because recorded payments refer to it.
+func (s *MetodePembayaranService) UpdateMetodePembayaran(metode *models.MetodePembayaran) error {
+	if err := validateMetodePembayaran(metode); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(metode.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("metode pembayaran tidak ditemukan")
+	}
+	metode.Kode = existing.Kode
+
+	return s.repo.Update(metode)
+}
+
+// ValidatePembayaran checks the payments of a sale against the registry: every
+// method must be active, references must be present where required, and only
+// methods that allow change may be overpaid. Method codes and references are
+// normalized in place.
+func (s *MetodePembayaranService) ValidatePembayaran(pembayaran []models.PembayaranRequest, totalAkhir int) error {
+	list, err := s.repo.GetAll()
+	if err != nil {
+		return fmt.Errorf("gagal mengambil metode pembayaran: %w", err)
+	}
+	registry := make(map[string]*models.MetodePembayaran, len(list))
+	for _, m := range list {
+		registry[m.Kode] = m
+	}
+
+	totalTanpaKembalian := 0
+	for i := range pembayaran {
+		p := &pembayaran[i]
+		p.Metode = strings.ToLower(strings.TrimSpace(p.Metode))
+		p.Referensi = strings.TrimSpace(p.Referensi)
+
+		metode, ok := registry[p.Metode]
+		if !ok || !metode.Aktif {
+			return fmt.Errorf("pembayaran %d: metode pembayaran '%s' tidak tersedia", i+1, p.Metode)
+		}
+		if metode.WajibReferensi && p.Referensi == "" {
+			return fmt.Errorf("pembayaran %d: nomor referensi %s wajib diisi", i+1, metode.Nama)
+		}
+		if !metode.BolehKembalian {
+			totalTanpaKembalian += p.Jumlah
+		}
+	}
+
+	// Kembalian hanya boleh berasal dari metode yang mengizinkan kembalian (tunai)
+	if totalTanpaKembalian > totalAkhir {
+		return fmt.Errorf("pembayaran non-tunai (Rp %d) melebihi total belanja (Rp %d), kembalian hanya dapat diberikan dari pembayaran tunai",
+			totalTanpaKembalian, totalAkhir)
+	}
+
+	return nil
+}
+
+// validateMetodePembayaran checks the editable fields of a payment method
+func validateMetodePembayaran(metode *models.MetodePembayaran) error {
+	metode.Nama = strings.TrimSpace(metode.Nama)
+	if metode.Nama == "" {
+		return fmt.Errorf("nama metode pembayaran harus diisi")
+	}
+	if metode.BiayaPersen < 0 || metode.BiayaPersen > 100 {
+		return fmt.Errorf("biaya metode pembayaran harus antara 0 dan 100 persen")
+	}
+	return nil
+}
+
+// isValidKodeMetode reports whether a payment method code uses only lowercase letters, digits and underscores
+func isValidKodeMetode(kode string) bool {
+	if kode == "" || len(kode) > maxPanjangKodeMetode {
+		return false
+	}
+	for _, ch := range kode {
+		if !(ch >= 'a' && ch <= 'z') && !(ch >= '0' && ch <= '9') && ch != '_' {
+			return false
+		}
+	}
+	return true
+}
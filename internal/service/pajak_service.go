@@ -0,0 +1,176 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+)
+
+// PajakService computes PPN (VAT) for sales
+type PajakService struct {
+	settingsRepo *repository.SettingsRepository
+	produkRepo   *repository.ProdukRepository
+	kategoriRepo *repository.KategoriRepository
+}
+
+// NewPajakService creates a new instance
+func NewPajakService() *PajakService {
+	return &PajakService{
+		settingsRepo: repository.NewSettingsRepository(),
+		produkRepo:   repository.NewProdukRepository(),
+		kategoriRepo: repository.NewKategoriRepository(),
+	}
+}
+
+// HasilPajak is the tax calculated for a whole transaction
+type HasilPajak struct {
+	ModeHarga  string // Kosong jika PPN tidak aktif
+	TotalDPP   int
+	TotalPajak int
+}
+
+// TarifPajak returns the PPN rate (in percent) of a tax code
+func TarifPajak(kodePajak string) (float64, bool) {
+	switch kodePajak {
+	case models.KodePajakPPN11:
+		return 11, true
+	case models.KodePajakPPN12:
+		return 12, true
+	case models.KodePajakBebas:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// validateKodePajak checks an optional tax code on a product or category
+func validateKodePajak(kodePajak string) error {
+	if kodePajak == "" {
+		return nil
+	}
+	if _, ok := TarifPajak(kodePajak); !ok {
+		return fmt.Errorf("kode pajak tidak valid: %s (gunakan %s, %s atau %s)",
+			kodePajak, models.KodePajakPPN11, models.KodePajakPPN12, models.KodePajakBebas)
+	}
+	return nil
+}
+
+// HitungPajak calculates PPN after discounts and fills the tax fields of each item.
+// The transaction discount (promo + poin) is spread over the items in proportion
+// to their subtotal, so the tax base of every item is its discounted price.
+// In inclusive mode the tax is taken out of the price; in exclusive mode it is
+// added on top and the caller must add TotalPajak to the amount due.
+func (s *PajakService) HitungPajak(items []models.TransaksiItemRequest, totalDiskon int) (*HasilPajak, error) {
+	settings, err := s.settingsRepo.GetPajakSettings()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil pengaturan pajak: %w", err)
+	}
+
+	if !settings.Aktif {
+		return &HasilPajak{}, nil
+	}
+
+	kategoriKode := make(map[string]string)
+	kodePajak := make([]string, len(items))
+	for i := range items {
+		kodePajak[i], err = s.resolveKodePajak(items[i].ProdukID, settings.KodePajakDefault, kategoriKode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hitungPajakItems(items, totalDiskon, settings.ModeHarga, kodePajak)
+}
+
+// hitungPajakItems calculates PPN for items whose tax codes are already resolved
+// (kodePajak[i] belongs to items[i]) and fills the tax fields of each item
+func hitungPajakItems(items []models.TransaksiItemRequest, totalDiskon int, modeHarga string, kodePajak []string) (*HasilPajak, error) {
+	hasil := &HasilPajak{ModeHarga: modeHarga}
+
+	subtotals := make([]int, len(items))
+	subtotal := 0
+	for i, item := range items {
+		if item.BeratGram > 0 {
+			subtotals[i] = int((item.BeratGram / 1000.0) * float64(item.HargaSatuan))
+		} else {
+			subtotals[i] = item.HargaSatuan * item.Jumlah
+		}
+		subtotals[i] -= item.DiskonItem + item.DiskonMarkdown // Diskon baris & markdown mengurangi dasar pajak item itu sendiri
+		subtotal += subtotals[i]
+	}
+
+	sisaDiskon := totalDiskon
+	for i := range items {
+		tarif, ok := TarifPajak(kodePajak[i])
+		if !ok {
+			return nil, fmt.Errorf("kode pajak tidak valid pada produk ID %d: %s", items[i].ProdukID, kodePajak[i])
+		}
+
+		// Bagi diskon secara proporsional, sisa pembulatan ke item terakhir
+		diskonItem := sisaDiskon
+		if i < len(items)-1 && subtotal > 0 {
+			diskonItem = totalDiskon * subtotals[i] / subtotal
+		}
+		sisaDiskon -= diskonItem
+
+		dasar := subtotals[i] - diskonItem
+		if dasar < 0 {
+			dasar = 0
+		}
+
+		var pajak, dpp int
+		if modeHarga == models.ModeHargaEksklusif {
+			pajak = int(math.Round(float64(dasar) * tarif / 100))
+			dpp = dasar
+		} else {
+			pajak = int(math.Round(float64(dasar) * tarif / (100 + tarif)))
+			dpp = dasar - pajak
+		}
+
+		items[i].KodePajak = kodePajak[i]
+		items[i].TarifPajak = tarif
+		items[i].DPP = dpp
+		items[i].Pajak = pajak
+
+		hasil.TotalDPP += dpp
+		hasil.TotalPajak += pajak
+	}
+
+	return hasil, nil
+}
+
+// resolveKodePajak returns the tax code of a product: its own code, else its
+// category's code, else the store default
+func (s *PajakService) resolveKodePajak(produkID int, kodeDefault string, kategoriKode map[string]string) (string, error) {
+	produk, err := s.produkRepo.GetByID(produkID)
+	if err != nil {
+		return "", fmt.Errorf("produk ID %d tidak ditemukan: %w", produkID, err)
+	}
+	if produk == nil {
+		return "", fmt.Errorf("produk ID %d tidak ditemukan", produkID)
+	}
+	if produk.KodePajak != "" {
+		return produk.KodePajak, nil
+	}
+
+	if produk.Kategori != "" {
+		kode, cached := kategoriKode[produk.Kategori]
+		if !cached {
+			kategori, err := s.kategoriRepo.GetByNama(produk.Kategori)
+			if err != nil {
+				return "", err
+			}
+			if kategori != nil {
+				kode = kategori.KodePajak
+			}
+			kategoriKode[produk.Kategori] = kode
+		}
+		if kode != "" {
+			return kode, nil
+		}
+	}
+
+	return kodeDefault, nil
+}
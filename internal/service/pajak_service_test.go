@@ -0,0 +1,122 @@
+package service
+
+import (
+	"testing"
+
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHitungPajakItems(t *testing.T) {
+	type itemPajak struct {
+		kode  string
+		dpp   int
+		pajak int
+	}
+
+	tests := []struct {
+		name        string
+		modeHarga   string
+		items       []models.TransaksiItemRequest
+		kodePajak   []string
+		totalDiskon int
+		wantDPP     int
+		wantPajak   int
+		wantItems   []itemPajak
+	}{
+		{
+			name:      "inklusif, pajak diambil dari harga",
+			modeHarga: models.ModeHargaInklusif,
+			items:     []models.TransaksiItemRequest{{ProdukID: 1, Jumlah: 1, HargaSatuan: 111000}},
+			kodePajak: []string{models.KodePajakPPN11},
+			wantDPP:   100000, wantPajak: 11000,
+			wantItems: []itemPajak{{models.KodePajakPPN11, 100000, 11000}},
+		},
+		{
+			name:      "eksklusif, pajak ditambahkan di atas harga",
+			modeHarga: models.ModeHargaEksklusif,
+			items:     []models.TransaksiItemRequest{{ProdukID: 1, Jumlah: 2, HargaSatuan: 50000}},
+			kodePajak: []string{models.KodePajakPPN11},
+			wantDPP:   100000, wantPajak: 11000,
+			wantItems: []itemPajak{{models.KodePajakPPN11, 100000, 11000}},
+		},
+		{
+			name:      "diskon dibagi proporsional, sisa pembulatan ke item terakhir",
+			modeHarga: models.ModeHargaEksklusif,
+			items: []models.TransaksiItemRequest{
+				{ProdukID: 1, Jumlah: 1, HargaSatuan: 10000},
+				{ProdukID: 2, Jumlah: 1, HargaSatuan: 20000},
+				{ProdukID: 3, Jumlah: 1, HargaSatuan: 30000},
+			},
+			kodePajak:   []string{models.KodePajakPPN11, models.KodePajakPPN11, models.KodePajakPPN11},
+			totalDiskon: 1000, // 166 + 333 + 501
+			wantDPP:     59000, wantPajak: 6490,
+			wantItems: []itemPajak{
+				{models.KodePajakPPN11, 9834, 1082},
+				{models.KodePajakPPN11, 19667, 2163},
+				{models.KodePajakPPN11, 29499, 3245},
+			},
+		},
+		{
+			name:      "kode pajak berbeda per item",
+			modeHarga: models.ModeHargaInklusif,
+			items: []models.TransaksiItemRequest{
+				{ProdukID: 1, Jumlah: 1, HargaSatuan: 50000},
+				{ProdukID: 2, Jumlah: 1, HargaSatuan: 56000},
+			},
+			kodePajak: []string{models.KodePajakBebas, models.KodePajakPPN12},
+			wantDPP:   100000, wantPajak: 6000,
+			wantItems: []itemPajak{{models.KodePajakBebas, 50000, 0}, {models.KodePajakPPN12, 50000, 6000}},
+		},
+		{
+			name:      "diskon baris dan markdown mengurangi dasar pajak item",
+			modeHarga: models.ModeHargaEksklusif,
+			items:     []models.TransaksiItemRequest{{ProdukID: 1, Jumlah: 1, HargaSatuan: 100000, DiskonItem: 10000, DiskonMarkdown: 5000}},
+			kodePajak: []string{models.KodePajakPPN11},
+			wantDPP:   85000, wantPajak: 9350,
+			wantItems: []itemPajak{{models.KodePajakPPN11, 85000, 9350}},
+		},
+		{
+			name:      "barang timbang dihitung dari berat",
+			modeHarga: models.ModeHargaInklusif,
+			items:     []models.TransaksiItemRequest{{ProdukID: 1, BeratGram: 500, HargaSatuan: 20000}},
+			kodePajak: []string{models.KodePajakPPN11},
+			wantDPP:   9009, wantPajak: 991,
+			wantItems: []itemPajak{{models.KodePajakPPN11, 9009, 991}},
+		},
+		{
+			name:        "diskon melebihi harga tidak membuat dasar pajak negatif",
+			modeHarga:   models.ModeHargaEksklusif,
+			items:       []models.TransaksiItemRequest{{ProdukID: 1, Jumlah: 1, HargaSatuan: 10000}},
+			kodePajak:   []string{models.KodePajakPPN11},
+			totalDiskon: 15000,
+			wantDPP:     0, wantPajak: 0,
+			wantItems: []itemPajak{{models.KodePajakPPN11, 0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasil, err := hitungPajakItems(tt.items, tt.totalDiskon, tt.modeHarga, tt.kodePajak)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.modeHarga, hasil.ModeHarga)
+			assert.Equal(t, tt.wantDPP, hasil.TotalDPP)
+			assert.Equal(t, tt.wantPajak, hasil.TotalPajak)
+			for i, want := range tt.wantItems {
+				assert.Equal(t, want.kode, tt.items[i].KodePajak, "item %d", i+1)
+				assert.Equal(t, want.dpp, tt.items[i].DPP, "item %d", i+1)
+				assert.Equal(t, want.pajak, tt.items[i].Pajak, "item %d", i+1)
+			}
+		})
+	}
+}
+
+func TestHitungPajakItemsKodeTidakValid(t *testing.T) {
+	items := []models.TransaksiItemRequest{{ProdukID: 1, Jumlah: 1, HargaSatuan: 10000}}
+
+	_, err := hitungPajakItems(items, 0, models.ModeHargaInklusif, []string{"PPN99"})
+	assert.Error(t, err)
+}
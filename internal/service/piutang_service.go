@@ -0,0 +1,255 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+)
+
+// PiutangService handles customer credit sales (kasbon) and their repayments
+type PiutangService struct {
+	repo          *repository.PiutangRepository
+	pelangganRepo *repository.PelangganRepository
+	shiftService  *ShiftService
+	metodeService *MetodePembayaranService
+}
+
+// NewPiutangService creates a new instance
+func NewPiutangService() *PiutangService {
+	return &PiutangService{
+		repo:          repository.NewPiutangRepository(),
+		pelangganRepo: repository.NewPelangganRepository(),
+		shiftService:  NewShiftService(),
+		metodeService: NewMetodePembayaranService(),
+	}
+}
+
+// ValidateKasbon checks the kasbon part of a sale: it is only allowed for
+// registered customers with a credit limit, and the new balance must stay
+// within that limit. The limit is checked again when the sale is stored.
+func (s *PiutangService) ValidateKasbon(pelangganID int, pembayaran []models.PembayaranRequest) error {
+	kasbon := 0
+	for _, p := range pembayaran {
+		if p.Metode == models.MetodeKasbon {
+			kasbon += p.Jumlah
+		}
+	}
+	if kasbon == 0 {
+		return nil
+	}
+
+	if pelangganID <= 0 {
+		return fmt.Errorf("kasbon hanya untuk pelanggan terdaftar")
+	}
+	pelanggan, err := s.pelangganRepo.GetByID(pelangganID)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil data pelanggan: %w", err)
+	}
+	if pelanggan == nil {
+		return fmt.Errorf("kasbon hanya untuk pelanggan terdaftar")
+	}
+	if pelanggan.BatasKredit <= 0 {
+		return fmt.Errorf("pelanggan %s belum memiliki batas kredit", pelanggan.Nama)
+	}
+
+	saldo, err := s.repo.GetSaldoByPelanggan(pelangganID)
+	if err != nil {
+		return err
+	}
+	if saldo+kasbon > pelanggan.BatasKredit {
+		return fmt.Errorf("kasbon Rp %d melebihi sisa batas kredit %s (Rp %d)",
+			kasbon, pelanggan.Nama, pelanggan.BatasKredit-saldo)
+	}
+
+	return nil
+}
+
+// BayarPiutang records a (partial) repayment by a customer during an open shift
+func (s *PiutangService) BayarPiutang(req *models.BayarPiutangRequest) (*models.BayarPiutangResponse, error) {
+	if req.PelangganID <= 0 {
+		return nil, fmt.Errorf("pelanggan harus dipilih")
+	}
+	if req.Jumlah <= 0 {
+		return nil, fmt.Errorf("jumlah pembayaran harus lebih dari 0")
+	}
+
+	pembayaran := []models.PembayaranRequest{{Metode: req.Metode, Jumlah: req.Jumlah, Referensi: req.Referensi}}
+	if err := s.metodeService.ValidatePembayaran(pembayaran, req.Jumlah); err != nil {
+		return nil, err
+	}
+	metode := pembayaran[0].Metode
+	if metode == models.MetodeKasbon {
+		return nil, fmt.Errorf("piutang tidak dapat dibayar dengan kasbon")
+	}
+
+	pelanggan, err := s.pelangganRepo.GetByID(req.PelangganID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data pelanggan: %w", err)
+	}
+	if pelanggan == nil {
+		return nil, fmt.Errorf("pelanggan tidak ditemukan")
+	}
+
+	if req.PiutangID > 0 {
+		piutang, err := s.repo.GetByID(req.PiutangID)
+		if err != nil {
+			return nil, err
+		}
+		if piutang == nil || piutang.PelangganID != req.PelangganID {
+			return nil, fmt.Errorf("piutang tidak ditemukan")
+		}
+		if piutang.Status != models.StatusPiutangBelumLunas {
+			return nil, fmt.Errorf("piutang %s sudah %s", piutang.NomorTransaksi, strings.ReplaceAll(piutang.Status, "_", " "))
+		}
+	}
+
+	// Pembayaran piutang masuk ke laci kasir, jadi dicatat pada shift yang sedang terbuka
+	shift, err := s.shiftService.requireShiftAktif(req.KodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+
+	alokasi, err := s.repo.Bayar(req.PelangganID, req.PiutangID, metode, req.Jumlah, pembayaran[0].Referensi, shift.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	saldo, err := s.repo.GetSaldoByPelanggan(req.PelangganID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PIUTANG] Pembayaran piutang %s Rp %d (%s) diterima oleh %s, sisa piutang Rp %d",
+		pelanggan.Nama, req.Jumlah, metode, req.StaffNama, saldo)
+
+	return &models.BayarPiutangResponse{
+		PelangganID:  req.PelangganID,
+		Dibayar:      req.Jumlah,
+		SaldoPiutang: saldo,
+		Pembayaran:   alokasi,
+	}, nil
+}
+
+// GetPiutangPelanggan retrieves the receivable ledger of a customer
+func (s *PiutangService) GetPiutangPelanggan(pelangganID int) (*models.PiutangPelanggan, error) {
+	pelanggan, err := s.pelangganRepo.GetByID(pelangganID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data pelanggan: %w", err)
+	}
+	if pelanggan == nil {
+		return nil, fmt.Errorf("pelanggan tidak ditemukan")
+	}
+
+	piutang, err := s.repo.GetAll(pelangganID, "")
+	if err != nil {
+		return nil, err
+	}
+	pembayaran, err := s.repo.GetPembayaranByPelanggan(pelangganID)
+	if err != nil {
+		return nil, err
+	}
+
+	saldo := 0
+	for _, p := range piutang {
+		if p.Status == models.StatusPiutangBelumLunas {
+			saldo += p.Sisa
+		}
+	}
+	sisaLimit := pelanggan.BatasKredit - saldo
+	if sisaLimit < 0 {
+		sisaLimit = 0
+	}
+
+	return &models.PiutangPelanggan{
+		Pelanggan:    pelanggan,
+		BatasKredit:  pelanggan.BatasKredit,
+		SaldoPiutang: saldo,
+		SisaLimit:    sisaLimit,
+		Piutang:      piutang,
+		Pembayaran:   pembayaran,
+	}, nil
+}
+
+// GetAllPiutang lists receivables, optionally filtered by status
+func (s *PiutangService) GetAllPiutang(status string) ([]*models.Piutang, error) {
+	switch status {
+	case "", models.StatusPiutangBelumLunas, models.StatusPiutangLunas, models.StatusPiutangBatal:
+	default:
+		return nil, fmt.Errorf("status piutang tidak valid")
+	}
+	return s.repo.GetAll(0, status)
+}
+
+// GetAgingPiutang groups the outstanding receivables per customer by age
+// (0-30, 31-60 and more than 60 days since the credit sale)
+func (s *PiutangService) GetAgingPiutang() (*models.LaporanAgingPiutang, error) {
+	list, err := s.repo.GetAll(0, models.StatusPiutangBelumLunas)
+	if err != nil {
+		return nil, err
+	}
+
+	return susunAgingPiutang(list, time.Now()), nil
+}
+
+// susunAgingPiutang groups outstanding receivables per customer by their age
+func susunAgingPiutang(list []*models.Piutang, dibuatAt time.Time) *models.LaporanAgingPiutang {
+	laporan := &models.LaporanAgingPiutang{
+		Pelanggan: []*models.AgingPiutang{},
+		DibuatAt:  dibuatAt,
+	}
+	perPelanggan := make(map[int]*models.AgingPiutang)
+	for _, p := range list {
+		aging, ok := perPelanggan[p.PelangganID]
+		if !ok {
+			aging = &models.AgingPiutang{
+				PelangganID:   p.PelangganID,
+				PelangganNama: p.PelangganNama,
+			}
+			perPelanggan[p.PelangganID] = aging
+			laporan.Pelanggan = append(laporan.Pelanggan, aging)
+		}
+
+		aging.JumlahNota++
+		aging.Total += p.Sisa
+		laporan.Total += p.Sisa
+		switch {
+		case p.UmurHari <= 30:
+			aging.Hari0s30 += p.Sisa
+			laporan.Hari0s30 += p.Sisa
+		case p.UmurHari <= 60:
+			aging.Hari31s60 += p.Sisa
+			laporan.Hari31s60 += p.Sisa
+		default:
+			aging.HariLebih60 += p.Sisa
+			laporan.HariLebih60 += p.Sisa
+		}
+	}
+
+	return laporan
+}
+
+// UpdateBatasKredit sets the credit limit of a customer
+func (s *PiutangService) UpdateBatasKredit(req *models.UpdateBatasKreditRequest) (*models.Pelanggan, error) {
+	if req.BatasKredit < 0 {
+		return nil, fmt.Errorf("batas kredit tidak boleh negatif")
+	}
+
+	pelanggan, err := s.pelangganRepo.GetByID(req.PelangganID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data pelanggan: %w", err)
+	}
+	if pelanggan == nil {
+		return nil, fmt.Errorf("pelanggan tidak ditemukan")
+	}
+
+	if err := s.pelangganRepo.UpdateBatasKredit(req.PelangganID, req.BatasKredit); err != nil {
+		return nil, err
+	}
+	pelanggan.BatasKredit = req.BatasKredit
+
+	return pelanggan, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSusunAgingPiutangSetelahRetur(t *testing.T) {
+	// Kasbon Rp 100.000 berumur 45 hari yang sebagian diretur: Rp 40.000 dipotong dari sisa
+	potong, dibayar := bagiRefundKasbon(40000, 100000)
+	assert.Equal(t, 0, dibayar, "kasbon belum dibayar, tidak ada uang yang dikembalikan")
+
+	list := []*models.Piutang{
+		{PelangganID: 7, PelangganNama: "Budi", Jumlah: 100000, Sisa: 100000 - potong, UmurHari: 45},
+		{PelangganID: 7, PelangganNama: "Budi", Jumlah: 20000, Sisa: 20000, UmurHari: 3},
+		{PelangganID: 9, PelangganNama: "Sari", Jumlah: 50000, Sisa: 50000, UmurHari: 90},
+	}
+
+	laporan := susunAgingPiutang(list, time.Now())
+
+	assert.Equal(t, 20000, laporan.Hari0s30)
+	assert.Equal(t, 60000, laporan.Hari31s60, "sisa setelah retur tetap di kelompok umur aslinya")
+	assert.Equal(t, 50000, laporan.HariLebih60)
+	assert.Equal(t, 130000, laporan.Total)
+	assert.Equal(t, laporan.Hari0s30+laporan.Hari31s60+laporan.HariLebih60, laporan.Total)
+
+	if assert.Len(t, laporan.Pelanggan, 2) {
+		budi := laporan.Pelanggan[0]
+		assert.Equal(t, 2, budi.JumlahNota)
+		assert.Equal(t, 80000, budi.Total)
+		assert.Equal(t, budi.Hari0s30+budi.Hari31s60+budi.HariLebih60, budi.Total)
+	}
+}
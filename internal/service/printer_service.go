@@ -7,6 +7,7 @@ import (
 	"ritel-app/internal/models"
 	"ritel-app/internal/repository"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,12 +15,16 @@ import (
 type PrinterService struct {
 	repo             *repository.PrinterRepository
 	transaksiService *TransaksiService
+	settingsService  *SettingsService
+	shiftService     *ShiftService
 }
 
 func NewPrinterService() *PrinterService {
 	return &PrinterService{
 		repo:             repository.NewPrinterRepository(),
 		transaksiService: NewTransaksiService(),
+		settingsService:  NewSettingsService(),
+		shiftService:     NewShiftService(),
 	}
 }
 
@@ -678,8 +683,23 @@ func (s *PrinterService) generateReceiptContent(transaksi *models.TransaksiDetai
 
 		// Format quantity, unit price and subtotal
 		qtyPrice := fmt.Sprintf("  %d x %s", item.Jumlah, formatRupiah(float64(item.HargaSatuan)))
-		subtotal := formatRupiah(float64(item.Subtotal))
-		bodyContent += formatLine(qtyPrice, subtotal, effectiveWidth)
+		if item.DiskonItem > 0 || item.DiskonMarkdown > 0 {
+			// Subtotal item sudah setelah diskon baris dan markdown, tampilkan harga sebelumnya lalu potongannya
+			bodyContent += formatLine(qtyPrice, formatRupiah(float64(item.Subtotal+item.DiskonItem+item.DiskonMarkdown)), effectiveWidth)
+			if item.DiskonItem > 0 {
+				diskonLabel := "  Diskon"
+				if item.DiskonTipe == models.DiskonTipePersen {
+					diskonLabel += fmt.Sprintf(" %g%%", item.DiskonNilai)
+				}
+				bodyContent += formatLine(diskonLabel, "-"+formatRupiah(float64(item.DiskonItem)), effectiveWidth)
+			}
+			if item.DiskonMarkdown > 0 {
+				bodyContent += formatLine("  "+item.KeteranganMarkdown, "-"+formatRupiah(float64(item.DiskonMarkdown)), effectiveWidth)
+			}
+		} else {
+			subtotal := formatRupiah(float64(item.Subtotal))
+			bodyContent += formatLine(qtyPrice, subtotal, effectiveWidth)
+		}
 		bodyContent += "\n"
 	}
 
@@ -690,6 +710,16 @@ func (s *PrinterService) generateReceiptContent(transaksi *models.TransaksiDetai
 	if transaksi.Transaksi.Diskon > 0 {
 		bodyContent += formatLine("Diskon:", formatRupiah(float64(transaksi.Transaksi.Diskon)), effectiveWidth)
 	}
+	if transaksi.Transaksi.ModePajak == models.ModeHargaEksklusif {
+		bodyContent += formatLine("PPN:", formatRupiah(float64(transaksi.Transaksi.TotalPajak)), effectiveWidth)
+	}
+	if transaksi.Transaksi.Pembulatan != 0 {
+		pembulatan := formatRupiah(float64(transaksi.Transaksi.Pembulatan))
+		if transaksi.Transaksi.Pembulatan < 0 {
+			pembulatan = "-" + formatRupiah(float64(-transaksi.Transaksi.Pembulatan))
+		}
+		bodyContent += formatLine("Pembulatan:", pembulatan, effectiveWidth)
+	}
 
 	doubleLine := strings.Repeat(settings.DoubleLineChar, effectiveWidth)
 	bodyContent += doubleLine + "\n"
@@ -713,6 +743,16 @@ func (s *PrinterService) generateReceiptContent(transaksi *models.TransaksiDetai
 		}
 		bodyContent += "\n"
 	}
+
+	// Tax summary (only for transactions recorded with PPN)
+	if transaksi.Transaksi.ModePajak != "" {
+		bodyContent += doubleLine + "\n"
+		bodyContent += setAlignment(getAlignmentCode(settings.TitleAlignment))
+		bodyContent += "RINCIAN PAJAK\n"
+		bodyContent += setAlignment(ALIGN_LEFT)
+		bodyContent += s.generateTaxSummary(transaksi, effectiveWidth)
+		bodyContent += "\n"
+	}
 	bodyContent += doubleLine + "\n\n"
 
 	// Apply margin to body section
@@ -732,6 +772,219 @@ func (s *PrinterService) generateReceiptContent(transaksi *models.TransaksiDetai
 	return content
 }
 
+// generateTaxSummary formats the DPP and PPN per tax rate of a transaction
+func (s *PrinterService) generateTaxSummary(transaksi *models.TransaksiDetail, width int) string {
+	var content string
+
+	if pajakSettings, err := s.settingsService.GetPajakSettings(); err == nil && pajakSettings.NPWP != "" {
+		content += formatLine("NPWP:", pajakSettings.NPWP, width)
+	}
+	content += formatLine("DPP:", formatRupiah(float64(transaksi.Transaksi.TotalDPP)), width)
+
+	// Sum PPN per rate, in the order the rates first appear on the receipt
+	var tarifList []float64
+	pajakPerTarif := make(map[float64]int)
+	dppBebas := 0
+	for _, item := range transaksi.Items {
+		if item.TarifPajak == 0 {
+			dppBebas += item.DPP
+			continue
+		}
+		if _, ok := pajakPerTarif[item.TarifPajak]; !ok {
+			tarifList = append(tarifList, item.TarifPajak)
+		}
+		pajakPerTarif[item.TarifPajak] += item.Pajak
+	}
+	for _, tarif := range tarifList {
+		label := fmt.Sprintf("PPN %s%%:", strconv.FormatFloat(tarif, 'f', -1, 64))
+		content += formatLine(label, formatRupiah(float64(pajakPerTarif[tarif])), width)
+	}
+	if dppBebas > 0 {
+		content += formatLine("Bebas PPN:", formatRupiah(float64(dppBebas)), width)
+	}
+	content += formatLine("Total PPN:", formatRupiah(float64(transaksi.Transaksi.TotalPajak)), width)
+
+	if transaksi.Transaksi.ModePajak == models.ModeHargaInklusif {
+		content += "Harga termasuk PPN\n"
+	}
+
+	return content
+}
+
+// PrintLaporanShift prints the X report of an open shift or the Z report of a closed shift
+func (s *PrinterService) PrintLaporanShift(req *models.PrintLaporanShiftRequest) error {
+	log.Printf("Printing shift report: ShiftID=%d, PrinterName=%s", req.ShiftID, req.PrinterName)
+
+	settings, err := s.repo.GetPrintSettings()
+	if err != nil {
+		log.Printf("Warning: Failed to get settings from DB, using defaults: %v", err)
+		settings = s.repo.GetDefaultSettings()
+	}
+
+	printerName := req.PrinterName
+	if printerName == "" {
+		printerName = settings.PrinterName
+	}
+	if printerName == "" {
+		return fmt.Errorf("tidak ada printer yang dikonfigurasi. Silakan atur printer di menu Pengaturan > Pengaturan Struk")
+	}
+
+	laporan, err := s.shiftService.GetLaporanShift(req.ShiftID)
+	if err != nil {
+		return err
+	}
+
+	content := s.generateLaporanShiftContent(laporan, settings)
+
+	if runtime.GOOS == "windows" {
+		err = printRaw(printerName, content)
+	} else {
+		err = s.printContentFallback(printerName, content)
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to print shift report: %v", err)
+		return fmt.Errorf("gagal mencetak ke printer '%s': %v", printerName, err)
+	}
+
+	return nil
+}
+
+// generateLaporanShiftContent creates X/Z report content with ESC/POS
+func (s *PrinterService) generateLaporanShiftContent(laporan *models.LaporanShift, settings *models.PrintSettings) string {
+	var content string
+
+	content += initPrinter()
+
+	// Enable compressed mode untuk lebih banyak karakter
+	content += setCharacterWidth(1)
+
+	paperWidth := settings.PaperWidth
+	if paperWidth == 0 {
+		paperWidth = 40 // Default 40 untuk 58mm (dengan compressed mode)
+		if settings.PaperSize == "80mm" {
+			paperWidth = 48 // Default 48 untuk 80mm (dengan compressed mode)
+		}
+	}
+	effectiveWidth := paperWidth - settings.LeftMargin
+	shift := laporan.Shift
+
+	// ========== SECTION 1: HEADER (Alignment based on setting - NO MARGIN) ==========
+	content += setAlignment(getAlignmentCode(settings.HeaderAlignment))
+	content += s.getTextSizeCommand(settings.FontSize, false)
+	content += settings.HeaderText + "\n"
+	content += setTextSize(1, 1)
+	content += "\n"
+
+	// ========== SECTION 2: BODY (Left - WITH MARGIN) ==========
+	var bodyContent string
+
+	dashLine := strings.Repeat(settings.DashLineChar, effectiveWidth)
+	doubleLine := strings.Repeat(settings.DoubleLineChar, effectiveWidth)
+
+	bodyContent += setAlignment(getAlignmentCode(settings.TitleAlignment))
+	bodyContent += setTextSize(1, 2)
+	if laporan.Jenis == "Z" {
+		bodyContent += "LAPORAN Z (TUTUP SHIFT)\n"
+	} else {
+		bodyContent += "LAPORAN X (SEMENTARA)\n"
+	}
+	bodyContent += setTextSize(1, 1)
+	bodyContent += setAlignment(ALIGN_LEFT)
+	bodyContent += dashLine + "\n"
+
+	bodyContent += formatLine("Shift:", fmt.Sprintf("#%d", shift.ID), effectiveWidth)
+	bodyContent += formatLine("Terminal:", shift.KodeTerminal, effectiveWidth)
+	bodyContent += formatLine("Kasir:", shift.StaffNama, effectiveWidth)
+	bodyContent += formatLine("Dibuka:", formatTimeInWIB(shift.DibukaAt), effectiveWidth)
+	if shift.DitutupAt != nil {
+		bodyContent += formatLine("Ditutup:", formatTimeInWIB(*shift.DitutupAt), effectiveWidth)
+		if shift.DitutupOlehNama != "" {
+			bodyContent += formatLine("Ditutup oleh:", shift.DitutupOlehNama, effectiveWidth)
+		}
+	}
+	bodyContent += formatLine("Dicetak:", formatTimeInWIB(laporan.DibuatAt), effectiveWidth)
+	bodyContent += dashLine + "\n"
+
+	// Penjualan
+	bodyContent += "PENJUALAN\n"
+	bodyContent += formatLine("Jumlah Transaksi:", fmt.Sprintf("%d", laporan.JumlahTransaksi), effectiveWidth)
+	bodyContent += formatLine("Total Penjualan:", formatRupiah(float64(laporan.TotalPenjualan)), effectiveWidth)
+	bodyContent += formatLine("Total Diskon:", formatRupiah(float64(laporan.TotalDiskon)), effectiveWidth)
+	if laporan.TotalPajak > 0 {
+		bodyContent += formatLine("Total PPN:", formatRupiah(float64(laporan.TotalPajak)), effectiveWidth)
+	}
+	if laporan.JumlahVoid > 0 {
+		bodyContent += formatLine(fmt.Sprintf("Void (%d):", laporan.JumlahVoid), formatRupiah(float64(laporan.TotalVoid)), effectiveWidth)
+	}
+	if laporan.JumlahKartuHadiah > 0 {
+		bodyContent += formatLine(fmt.Sprintf("Kartu Hadiah (%d):", laporan.JumlahKartuHadiah), formatRupiah(float64(laporan.TotalKartuHadiah)), effectiveWidth)
+	}
+	bodyContent += dashLine + "\n"
+
+	// Pembayaran per metode
+	if len(laporan.Pembayaran) > 0 {
+		bodyContent += "PEMBAYARAN\n"
+		for _, p := range laporan.Pembayaran {
+			label := fmt.Sprintf("%s (%d):", p.Nama, p.Jumlah)
+			bodyContent += formatLine(label, formatRupiah(float64(p.Total)), effectiveWidth)
+		}
+		bodyContent += dashLine + "\n"
+	}
+
+	// Kas laci
+	bodyContent += "KAS\n"
+	bodyContent += formatLine("Kas Awal:", formatRupiah(float64(shift.KasAwal)), effectiveWidth)
+	bodyContent += formatLine("Penjualan Tunai:", formatRupiah(float64(laporan.PenjualanTunai)), effectiveWidth)
+	if laporan.RefundTunai > 0 {
+		bodyContent += formatLine("Refund Tunai:", "-"+formatRupiah(float64(laporan.RefundTunai)), effectiveWidth)
+	}
+	if laporan.VoidTunai > 0 {
+		bodyContent += formatLine("Void Tunai:", "-"+formatRupiah(float64(laporan.VoidTunai)), effectiveWidth)
+	}
+	for _, m := range laporan.MutasiKas {
+		amount := formatRupiah(float64(m.Jumlah))
+		if m.Jenis == models.MutasiKasKeluar {
+			amount = "-" + amount
+		}
+		bodyContent += formatLine(truncateString(m.Keterangan, effectiveWidth-18)+":", amount, effectiveWidth)
+	}
+	bodyContent += doubleLine + "\n"
+	bodyContent += setEmphasized(true)
+	bodyContent += formatLine("Kas Seharusnya:", formatRupiah(float64(laporan.KasSeharusnya)), effectiveWidth)
+	bodyContent += setEmphasized(false)
+
+	if laporan.KasDihitung != nil {
+		bodyContent += formatLine("Kas Dihitung:", formatRupiah(float64(*laporan.KasDihitung)), effectiveWidth)
+
+		label := "Selisih:"
+		switch {
+		case laporan.Selisih > 0:
+			label = "Selisih (Lebih):"
+		case laporan.Selisih < 0:
+			label = "Selisih (Kurang):"
+		}
+		selisih := laporan.Selisih
+		if selisih < 0 {
+			selisih = -selisih
+		}
+		bodyContent += setEmphasized(true)
+		bodyContent += formatLine(label, formatRupiah(float64(selisih)), effectiveWidth)
+		bodyContent += setEmphasized(false)
+	}
+	bodyContent += doubleLine + "\n\n"
+
+	content += applyLeftMarginToSection(bodyContent, settings.LeftMargin)
+
+	// ========== SECTION 3: FOOTER ==========
+	content += setAlignment(ALIGN_CENTER)
+	content += "\n\n\n"
+	content += "(Tanda tangan kasir)\n\n\n"
+
+	content += cutPaper()
+
+	return content
+}
+
 // generateReceiptFromCustomData creates receipt from custom data with ESC/POS
 func (s *PrinterService) generateReceiptFromCustomData(data *models.CustomReceiptData, settings *models.PrintSettings) string {
 	var content string
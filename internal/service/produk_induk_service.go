@@ -0,0 +1,314 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"strings"
+)
+
+// ProdukIndukService manages parent products and their variant matrix. Variants
+// are ordinary products, so they keep their own SKU, barcode, price and stock
+// and are validated by ProdukService when they are created or edited.
+type ProdukIndukService struct {
+	repo          *repository.ProdukIndukRepository
+	produkRepo    *repository.ProdukRepository
+	satuanRepo    *repository.ProdukSatuanRepository
+	produkService *ProdukService
+}
+
+// NewProdukIndukService creates a new instance
+func NewProdukIndukService() *ProdukIndukService {
+	return &ProdukIndukService{
+		repo:          repository.NewProdukIndukRepository(),
+		produkRepo:    repository.NewProdukRepository(),
+		satuanRepo:    repository.NewProdukSatuanRepository(),
+		produkService: NewProdukService(),
+	}
+}
+
+// GetAllInduk retrieves all parent products with the aggregated stock of their variants
+func (s *ProdukIndukService) GetAllInduk() ([]*models.ProdukInduk, error) {
+	return s.repo.GetAll()
+}
+
+// GetInduk retrieves a parent product with its variants and their aggregated stock
+func (s *ProdukIndukService) GetInduk(id int) (*models.ProdukInduk, error) {
+	induk, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if induk == nil {
+		return nil, fmt.Errorf("produk induk tidak ditemukan")
+	}
+
+	induk.Varian, err = s.produkRepo.GetByInduk(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return induk, nil
+}
+
+// CreateInduk creates a parent product with its variant matrix
+func (s *ProdukIndukService) CreateInduk(req *models.SimpanProdukIndukRequest) (*models.ProdukInduk, error) {
+	req.ID = 0
+	return s.simpanInduk(req)
+}
+
+// UpdateInduk edits a parent product and replaces its variant matrix
+func (s *ProdukIndukService) UpdateInduk(req *models.SimpanProdukIndukRequest) (*models.ProdukInduk, error) {
+	if req.ID <= 0 {
+		return nil, fmt.Errorf("ID produk induk tidak valid")
+	}
+	return s.simpanInduk(req)
+}
+
+// DeleteInduk removes a parent product; its variants stay as standalone products
+func (s *ProdukIndukService) DeleteInduk(id int) error {
+	if id <= 0 {
+		return fmt.Errorf("ID produk induk tidak valid")
+	}
+	return s.repo.Delete(id)
+}
+
+// simpanInduk validates the whole matrix before anything is written, so a
+// duplicate attribute combination, SKU or barcode does not leave half a matrix
+func (s *ProdukIndukService) simpanInduk(req *models.SimpanProdukIndukRequest) (*models.ProdukInduk, error) {
+	if err := validateProdukInduk(req); err != nil {
+		return nil, err
+	}
+
+	var lama []*models.Produk
+	if req.ID > 0 {
+		existing, err := s.repo.GetByID(req.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, fmt.Errorf("produk induk tidak ditemukan")
+		}
+		lama, err = s.produkRepo.GetByInduk(req.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stokLama := make(map[int]float64)
+	for i, varian := range req.Varian {
+		if varian.ID > 0 {
+			existing, err := s.produkRepo.GetByID(varian.ID)
+			if err != nil {
+				return nil, err
+			}
+			if existing == nil {
+				return nil, fmt.Errorf("varian %d: produk dengan ID %d tidak ditemukan", i+1, varian.ID)
+			}
+			if existing.IndukID > 0 && existing.IndukID != req.ID {
+				return nil, fmt.Errorf("varian %d: produk %s sudah menjadi varian produk induk lain", i+1, existing.Nama)
+			}
+			stokLama[varian.ID] = existing.Stok
+			continue
+		}
+
+		if err := s.cekVarianBaru(varian); err != nil {
+			return nil, fmt.Errorf("varian %d: %w", i+1, err)
+		}
+	}
+
+	induk := &models.ProdukInduk{
+		ID:        req.ID,
+		Nama:      req.Nama,
+		Kategori:  req.Kategori,
+		Deskripsi: req.Deskripsi,
+		Gambar:    req.Gambar,
+		Atribut:   req.Atribut,
+	}
+	if induk.ID > 0 {
+		if err := s.repo.Update(induk); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.repo.Create(induk); err != nil {
+			return nil, err
+		}
+	}
+
+	dipakai := make(map[int]bool)
+	for i, varian := range req.Varian {
+		if induk.Kategori != "" {
+			varian.Kategori = induk.Kategori
+		}
+		if varian.Nama == "" {
+			varian.Nama = namaVarian(induk, varian.AtributVarian)
+		}
+
+		if varian.ID > 0 {
+			// Stok varian hanya berubah lewat penerimaan, penjualan dan penyesuaian stok
+			varian.Stok = stokLama[varian.ID]
+			if err := s.produkService.UpdateProduk(varian); err != nil {
+				return nil, fmt.Errorf("varian %d (%s): %w", i+1, varian.Nama, err)
+			}
+		} else {
+			if err := s.produkService.CreateProduk(varian); err != nil {
+				return nil, fmt.Errorf("varian %d (%s): %w", i+1, varian.Nama, err)
+			}
+		}
+
+		if err := s.produkRepo.SetVarian(varian.ID, induk.ID, varian.AtributVarian); err != nil {
+			return nil, err
+		}
+		dipakai[varian.ID] = true
+	}
+
+	for _, produk := range lama {
+		if dipakai[produk.ID] {
+			continue
+		}
+		if err := s.produkRepo.SetVarian(produk.ID, 0, nil); err != nil {
+			return nil, err
+		}
+		log.Printf("[PRODUK INDUK] Produk %s dilepas dari induk %s", produk.Nama, induk.Nama)
+	}
+
+	log.Printf("[PRODUK INDUK] Induk %s disimpan dengan %d varian", induk.Nama, len(req.Varian))
+	return s.GetInduk(induk.ID)
+}
+
+// cekVarianBaru checks the fields of a new variant that the database would
+// reject, before the parent or any other variant is written
+func (s *ProdukIndukService) cekVarianBaru(varian *models.Produk) error {
+	if varian.SKU == "" {
+		return fmt.Errorf("SKU harus diisi")
+	}
+	if varian.HargaJual <= 0 {
+		return fmt.Errorf("harga jual harus lebih dari 0")
+	}
+
+	existing, err := s.produkRepo.GetBySKU(varian.SKU)
+	if err != nil {
+		return fmt.Errorf("failed to check existing SKU: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("SKU %s sudah dipakai produk %s", varian.SKU, existing.Nama)
+	}
+
+	if varian.Barcode == "" {
+		return nil
+	}
+	existing, err = s.produkRepo.GetByBarcode(varian.Barcode)
+	if err != nil {
+		return fmt.Errorf("failed to check existing barcode: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("barcode %s sudah dipakai produk %s", varian.Barcode, existing.Nama)
+	}
+	satuan, err := s.satuanRepo.GetByBarcode(varian.Barcode)
+	if err != nil {
+		return fmt.Errorf("failed to check existing barcode: %w", err)
+	}
+	if satuan != nil {
+		return fmt.Errorf("barcode %s sudah dipakai satuan produk lain", varian.Barcode)
+	}
+
+	return nil
+}
+
+// namaVarian builds a variant name from the parent name and the attribute
+// values in the order of the parent's attributes, e.g. "Susu UHT 1L Coklat"
+func namaVarian(induk *models.ProdukInduk, atribut map[string]string) string {
+	bagian := []string{induk.Nama}
+	for _, nama := range induk.Atribut {
+		bagian = append(bagian, atribut[nama])
+	}
+	return strings.Join(bagian, " ")
+}
+
+// validateProdukInduk normalizes the parent and its variants and checks that
+// every variant has a value for each attribute and a unique combination of them
+func validateProdukInduk(req *models.SimpanProdukIndukRequest) error {
+	req.Nama = strings.TrimSpace(req.Nama)
+	req.Kategori = strings.TrimSpace(req.Kategori)
+	if req.Nama == "" {
+		return fmt.Errorf("nama produk induk harus diisi")
+	}
+
+	if len(req.Atribut) == 0 {
+		return fmt.Errorf("produk induk harus memiliki minimal satu atribut varian, mis. Ukuran atau Rasa")
+	}
+	atributDipakai := make(map[string]bool)
+	for i := range req.Atribut {
+		req.Atribut[i] = strings.TrimSpace(req.Atribut[i])
+		if req.Atribut[i] == "" {
+			return fmt.Errorf("atribut %d: nama atribut harus diisi", i+1)
+		}
+		key := strings.ToLower(req.Atribut[i])
+		if atributDipakai[key] {
+			return fmt.Errorf("atribut %s ditulis lebih dari sekali", req.Atribut[i])
+		}
+		atributDipakai[key] = true
+	}
+
+	kombinasiDipakai := make(map[string]bool)
+	idDipakai := make(map[int]bool)
+	skuDipakai := make(map[string]bool)
+	barcodeDipakai := make(map[string]bool)
+	for i, varian := range req.Varian {
+		if varian == nil {
+			return fmt.Errorf("varian %d tidak boleh kosong", i+1)
+		}
+		varian.Nama = strings.TrimSpace(varian.Nama)
+		varian.SKU = strings.TrimSpace(varian.SKU)
+		varian.Barcode = strings.TrimSpace(varian.Barcode)
+
+		// Hanya atribut milik induk yang disimpan, dengan nama persis seperti di induk
+		atribut := make(map[string]string, len(req.Atribut))
+		for key, nilai := range varian.AtributVarian {
+			key = strings.ToLower(strings.TrimSpace(key))
+			for _, nama := range req.Atribut {
+				if strings.ToLower(nama) == key {
+					atribut[nama] = strings.TrimSpace(nilai)
+				}
+			}
+			if !atributDipakai[key] {
+				return fmt.Errorf("varian %d: atribut %s tidak ada pada produk induk", i+1, key)
+			}
+		}
+		kombinasi := make([]string, 0, len(req.Atribut))
+		for _, nama := range req.Atribut {
+			if atribut[nama] == "" {
+				return fmt.Errorf("varian %d: nilai atribut %s harus diisi", i+1, nama)
+			}
+			kombinasi = append(kombinasi, strings.ToLower(atribut[nama]))
+		}
+		varian.AtributVarian = atribut
+
+		key := strings.Join(kombinasi, "\x00")
+		if kombinasiDipakai[key] {
+			return fmt.Errorf("varian %d: kombinasi %s sudah ada", i+1, strings.Join(kombinasi, " / "))
+		}
+		kombinasiDipakai[key] = true
+
+		if varian.ID > 0 {
+			if idDipakai[varian.ID] {
+				return fmt.Errorf("varian %d: produk dengan ID %d ditulis lebih dari sekali", i+1, varian.ID)
+			}
+			idDipakai[varian.ID] = true
+		}
+		if varian.SKU != "" {
+			if skuDipakai[varian.SKU] {
+				return fmt.Errorf("varian %d: SKU %s dipakai lebih dari satu varian", i+1, varian.SKU)
+			}
+			skuDipakai[varian.SKU] = true
+		}
+		if varian.Barcode != "" {
+			if barcodeDipakai[varian.Barcode] {
+				return fmt.Errorf("varian %d: barcode %s dipakai lebih dari satu varian", i+1, varian.Barcode)
+			}
+			barcodeDipakai[varian.Barcode] = true
+		}
+	}
+
+	return nil
+}
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"ritel-app/internal/models"
 	"ritel-app/internal/repository"
 	"strings"
@@ -13,7 +15,12 @@ import (
 type ProdukService struct {
 	produkRepo    *repository.ProdukRepository
 	keranjangRepo *repository.KeranjangRepository
+	satuanRepo    *repository.ProdukSatuanRepository
+	komponenRepo  *repository.ProdukKomponenRepository
+	labelTimbang  *BarcodeTimbangService
+	supplier      *SupplierService
 	batchService  *BatchService
+	idempotency   *IdempotencyService
 }
 
 // NewProdukService creates a new instance
@@ -21,7 +28,12 @@ func NewProdukService() *ProdukService {
 	return &ProdukService{
 		produkRepo:    repository.NewProdukRepository(),
 		keranjangRepo: repository.NewKeranjangRepository(),
+		satuanRepo:    repository.NewProdukSatuanRepository(),
+		komponenRepo:  repository.NewProdukKomponenRepository(),
+		labelTimbang:  NewBarcodeTimbangService(),
+		supplier:      NewSupplierService(),
 		batchService:  NewBatchService(),
+		idempotency:   NewIdempotencyService(),
 	}
 }
 
@@ -43,6 +55,27 @@ func (s *ProdukService) CreateProduk(produk *models.Produk) error {
 		return fmt.Errorf("hari pemberitahuan (%d hari) tidak boleh melebihi masa simpan (%d hari)", produk.HariPemberitahuanKadaluarsa, produk.MasaSimpanHari)
 	}
 
+	// Validate tax code (empty means follow the category)
+	produk.KodePajak = strings.ToUpper(strings.TrimSpace(produk.KodePajak))
+	if err := validateKodePajak(produk.KodePajak); err != nil {
+		return err
+	}
+
+	// Validate PLU used by scale labels (empty means not sold by scale label)
+	if err := s.validatePLU(produk); err != nil {
+		return err
+	}
+
+	// Validate reorder parameters used by purchase suggestions
+	if err := s.validatePesanUlang(produk); err != nil {
+		return err
+	}
+
+	// Validate batch consumption policy (empty means follow the category)
+	if err := validateKebijakanBatch(&produk.KebijakanBatch); err != nil {
+		return err
+	}
+
 	// Check if SKU already exists
 	existing, err := s.produkRepo.GetBySKU(produk.SKU)
 	if err != nil {
@@ -61,6 +94,13 @@ func (s *ProdukService) CreateProduk(produk *models.Produk) error {
 		if existing != nil {
 			return fmt.Errorf("barcode already exists")
 		}
+		satuan, err := s.satuanRepo.GetByBarcode(produk.Barcode)
+		if err != nil {
+			return fmt.Errorf("failed to check existing barcode: %w", err)
+		}
+		if satuan != nil {
+			return fmt.Errorf("barcode already exists")
+		}
 	}
 
 	// Set timestamps
@@ -109,18 +149,50 @@ func (s *ProdukService) ScanBarcode(barcode string, jumlah int) (*models.ScanBar
 		jumlah = 1
 	}
 
-	// Find product by barcode
-	produk, err := s.produkRepo.GetByBarcode(barcode)
+	// Find product by barcode, either of the base unit or of an additional unit
+	hasil, err := s.CariBarcode(barcode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find product: %w", err)
+		return nil, err
 	}
 
-	if produk == nil {
+	if hasil == nil {
 		return &models.ScanBarcodeResponse{
 			Success: false,
 			Message: fmt.Sprintf("Product with barcode '%s' not found", barcode),
 		}, nil
 	}
+	produk := hasil.Produk
+
+	// A scale label carries a weight, which cannot be added to the whole-number cart
+	if hasil.Label != nil {
+		return &models.ScanBarcodeResponse{
+			Success: false,
+			Message: fmt.Sprintf("Scale label of '%s' cannot be added to the stock cart", produk.Nama),
+			Produk:  produk,
+			Label:   hasil.Label,
+		}, nil
+	}
+
+	// A kit has no stock of its own, only its components can be restocked
+	if len(produk.Komponen) > 0 {
+		return &models.ScanBarcodeResponse{
+			Success: false,
+			Message: fmt.Sprintf("Kit '%s' is stocked through its components and cannot be added to the stock cart", produk.Nama),
+			Produk:  produk,
+		}, nil
+	}
+
+	// Stock is always counted in the base unit, e.g. 1 carton of 40 adds 40
+	if hasil.Satuan != nil {
+		qty := float64(jumlah) * hasil.Satuan.Konversi
+		if qty != math.Trunc(qty) {
+			return &models.ScanBarcodeResponse{
+				Success: false,
+				Message: fmt.Sprintf("Unit '%s' of '%s' does not convert to a whole number of %s", hasil.Satuan.Nama, produk.Nama, produk.Satuan),
+			}, nil
+		}
+		jumlah = int(qty)
+	}
 
 	// Add to cart
 	if err := s.keranjangRepo.AddItem(produk.ID, jumlah, produk.HargaBeli); err != nil {
@@ -145,12 +217,314 @@ func (s *ProdukService) ScanBarcode(barcode string, jumlah int) (*models.ScanBar
 			Subtotal:  cartItem.Subtotal,
 			CreatedAt: cartItem.CreatedAt,
 		},
+		Satuan: hasil.Satuan,
 	}, nil
 }
 
-// GetAllProduk retrieves all products
+// CariBarcode resolves a barcode to its product and, for an additional unit
+// barcode, the unit that was scanned. For a scale label the decoded weight and
+// price are returned in Label. Returns nil when the barcode is unknown.
+func (s *ProdukService) CariBarcode(barcode string) (*models.ProdukBarcode, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, fmt.Errorf("barcode cannot be empty")
+	}
+
+	produk, err := s.produkRepo.GetByBarcode(barcode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if produk != nil {
+		if err := s.lengkapiPaket(produk); err != nil {
+			return nil, err
+		}
+		return &models.ProdukBarcode{Produk: produk, HargaJual: produk.HargaJual}, nil
+	}
+
+	satuan, err := s.satuanRepo.GetByBarcode(barcode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product unit: %w", err)
+	}
+	if satuan == nil {
+		// Scale labels embed the PLU and the weight or price instead of a fixed barcode
+		label, produk, err := s.labelTimbang.BacaLabel(barcode)
+		if err != nil || label == nil {
+			return nil, err
+		}
+		return &models.ProdukBarcode{Produk: produk, Label: label, HargaJual: produk.HargaJual}, nil
+	}
+
+	produk, err = s.produkRepo.GetByID(satuan.ProdukID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if produk == nil {
+		return nil, nil
+	}
+	if err := s.lengkapiPaket(produk); err != nil {
+		return nil, err
+	}
+
+	return &models.ProdukBarcode{Produk: produk, Satuan: satuan, HargaJual: hargaJualSatuan(produk, satuan)}, nil
+}
+
+// GetAllProduk retrieves all products with their additional units. The stock
+// of a kit is the number of kits its components can still make.
 func (s *ProdukService) GetAllProduk() ([]*models.Produk, error) {
-	return s.produkRepo.GetAll()
+	products, err := s.produkRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	satuan, err := s.satuanRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	perProduk := make(map[int][]*models.ProdukSatuan)
+	for _, sat := range satuan {
+		perProduk[sat.ProdukID] = append(perProduk[sat.ProdukID], sat)
+	}
+	for _, produk := range products {
+		produk.SatuanLain = perProduk[produk.ID]
+	}
+
+	komponen, err := s.komponenRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	perPaket := make(map[int][]*models.ProdukKomponen)
+	for _, k := range komponen {
+		perPaket[k.PaketID] = append(perPaket[k.PaketID], k)
+	}
+	for _, produk := range products {
+		if isi, ok := perPaket[produk.ID]; ok {
+			produk.Komponen = isi
+			produk.Stok = stokPaket(isi)
+		}
+	}
+
+	return products, nil
+}
+
+// GetSatuanProduk retrieves the additional units of a product
+func (s *ProdukService) GetSatuanProduk(produkID int) ([]*models.ProdukSatuan, error) {
+	produk, err := s.produkRepo.GetByID(produkID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil produk: %w", err)
+	}
+	if produk == nil {
+		return nil, fmt.Errorf("produk tidak ditemukan")
+	}
+
+	return s.satuanRepo.GetByProduk(produkID)
+}
+
+// SimpanSatuanProduk replaces the additional units of a product. Each unit
+// converts to a number of base units; stock itself stays in the base unit.
+func (s *ProdukService) SimpanSatuanProduk(req *models.SimpanProdukSatuanRequest) ([]*models.ProdukSatuan, error) {
+	produk, err := s.produkRepo.GetByID(req.ProdukID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil produk: %w", err)
+	}
+	if produk == nil {
+		return nil, fmt.Errorf("produk tidak ditemukan")
+	}
+
+	namaDipakai := map[string]bool{strings.ToLower(strings.TrimSpace(produk.Satuan)): true}
+	barcodeDipakai := make(map[string]bool)
+	if produk.Barcode != "" {
+		barcodeDipakai[produk.Barcode] = true
+	}
+
+	for i := range req.Satuan {
+		satuan := &req.Satuan[i]
+		satuan.Nama = strings.TrimSpace(satuan.Nama)
+		satuan.Barcode = strings.TrimSpace(satuan.Barcode)
+
+		if satuan.Nama == "" {
+			return nil, fmt.Errorf("satuan %d: nama satuan harus diisi", i+1)
+		}
+		if len(satuan.Nama) > 50 {
+			return nil, fmt.Errorf("satuan %d: nama satuan maksimal 50 karakter", i+1)
+		}
+		if namaDipakai[strings.ToLower(satuan.Nama)] {
+			return nil, fmt.Errorf("satuan %s sudah ada pada produk %s", satuan.Nama, produk.Nama)
+		}
+		namaDipakai[strings.ToLower(satuan.Nama)] = true
+
+		if satuan.Konversi <= 0 || satuan.Konversi == 1 {
+			return nil, fmt.Errorf("satuan %s: konversi ke %s harus lebih dari 0 dan bukan 1", satuan.Nama, produk.Satuan)
+		}
+		if satuan.HargaJual < 0 {
+			return nil, fmt.Errorf("satuan %s: harga jual tidak boleh negatif", satuan.Nama)
+		}
+
+		if satuan.Barcode == "" {
+			continue
+		}
+		if barcodeDipakai[satuan.Barcode] {
+			return nil, fmt.Errorf("satuan %s: barcode %s sudah dipakai", satuan.Nama, satuan.Barcode)
+		}
+		barcodeDipakai[satuan.Barcode] = true
+
+		existing, err := s.produkRepo.GetByBarcode(satuan.Barcode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing barcode: %w", err)
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("satuan %s: barcode %s sudah dipakai produk %s", satuan.Nama, satuan.Barcode, existing.Nama)
+		}
+		existingSatuan, err := s.satuanRepo.GetByBarcode(satuan.Barcode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing barcode: %w", err)
+		}
+		if existingSatuan != nil && existingSatuan.ProdukID != produk.ID {
+			return nil, fmt.Errorf("satuan %s: barcode %s sudah dipakai produk lain", satuan.Nama, satuan.Barcode)
+		}
+	}
+
+	if err := s.satuanRepo.Simpan(produk.ID, req.Satuan); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PRODUK] %d satuan tambahan disimpan untuk produk %s", len(req.Satuan), produk.Nama)
+	return s.satuanRepo.GetByProduk(produk.ID)
+}
+
+// GetKomponenProduk retrieves the components of a kit product
+func (s *ProdukService) GetKomponenProduk(produkID int) ([]*models.ProdukKomponen, error) {
+	produk, err := s.produkRepo.GetByID(produkID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil produk: %w", err)
+	}
+	if produk == nil {
+		return nil, fmt.Errorf("produk tidak ditemukan")
+	}
+
+	return s.komponenRepo.GetByPaket(produkID)
+}
+
+// SimpanKomponenProduk replaces the bill of materials of a kit product. A kit
+// holds no stock of its own: selling it deducts the stock of its components,
+// so a product can only become a kit while its own stock is empty, and kits
+// cannot be nested.
+func (s *ProdukService) SimpanKomponenProduk(req *models.SimpanProdukKomponenRequest) ([]*models.ProdukKomponen, error) {
+	paket, err := s.produkRepo.GetByID(req.ProdukID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil produk: %w", err)
+	}
+	if paket == nil {
+		return nil, fmt.Errorf("produk tidak ditemukan")
+	}
+
+	if len(req.Komponen) > 0 {
+		if paket.JenisProduk == "curah" {
+			return nil, fmt.Errorf("produk curah %s tidak dapat dijadikan paket", paket.Nama)
+		}
+		if paket.Stok != 0 {
+			return nil, fmt.Errorf("produk %s masih memiliki stok %.2f, sesuaikan stoknya ke 0 sebelum dijadikan paket", paket.Nama, paket.Stok)
+		}
+		dipakai, err := s.komponenRepo.CountPaketByKomponen(paket.ID)
+		if err != nil {
+			return nil, err
+		}
+		if dipakai > 0 {
+			return nil, fmt.Errorf("produk %s menjadi komponen %d paket lain dan tidak dapat dijadikan paket", paket.Nama, dipakai)
+		}
+	}
+
+	komponenDipakai := make(map[int]bool)
+	for i := range req.Komponen {
+		komponen := &req.Komponen[i]
+		if komponen.KomponenID == paket.ID {
+			return nil, fmt.Errorf("komponen %d: paket tidak dapat menjadi komponennya sendiri", i+1)
+		}
+		if komponen.Qty <= 0 {
+			return nil, fmt.Errorf("komponen %d: jumlah harus lebih dari 0", i+1)
+		}
+		if komponenDipakai[komponen.KomponenID] {
+			return nil, fmt.Errorf("komponen %d: produk dengan ID %d ditulis lebih dari sekali", i+1, komponen.KomponenID)
+		}
+		komponenDipakai[komponen.KomponenID] = true
+
+		produk, err := s.produkRepo.GetByID(komponen.KomponenID)
+		if err != nil {
+			return nil, fmt.Errorf("komponen %d: %w", i+1, err)
+		}
+		if produk == nil {
+			return nil, fmt.Errorf("komponen %d: produk dengan ID %d tidak ditemukan", i+1, komponen.KomponenID)
+		}
+		isi, err := s.komponenRepo.GetByPaket(produk.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(isi) > 0 {
+			return nil, fmt.Errorf("komponen %d: %s adalah paket, paket tidak dapat berisi paket lain", i+1, produk.Nama)
+		}
+	}
+
+	if err := s.komponenRepo.Simpan(paket.ID, req.Komponen); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PRODUK] %d komponen disimpan untuk paket %s", len(req.Komponen), paket.Nama)
+	return s.komponenRepo.GetByPaket(paket.ID)
+}
+
+// lengkapiPaket loads the components of a kit and sets its stock from them;
+// other products are left as they are
+func (s *ProdukService) lengkapiPaket(produk *models.Produk) error {
+	komponen, err := s.komponenRepo.GetByPaket(produk.ID)
+	if err != nil {
+		return err
+	}
+	if len(komponen) > 0 {
+		produk.Komponen = komponen
+		produk.Stok = stokPaket(komponen)
+	}
+	return nil
+}
+
+// stokPaket returns how many whole kits can be made from the stock of the components
+func stokPaket(komponen []*models.ProdukKomponen) float64 {
+	stok := -1.0
+	for _, k := range komponen {
+		bisa := math.Floor(math.Max(k.Stok, 0) / k.Qty)
+		if stok < 0 || bisa < stok {
+			stok = bisa
+		}
+	}
+	return math.Max(stok, 0)
+}
+
+// hargaPokokPaket returns the cost of one kit from the cost of its components
+func hargaPokokPaket(komponen []*models.ProdukKomponen) int {
+	var total float64
+	for _, k := range komponen {
+		total += float64(k.HargaBeli) * k.Qty
+	}
+	return int(math.Round(total))
+}
+
+// cekBukanPaket rejects direct stock changes of a kit, whose stock is made up of its components
+func cekBukanPaket(komponenRepo *repository.ProdukKomponenRepository, produk *models.Produk) error {
+	komponen, err := komponenRepo.GetByPaket(produk.ID)
+	if err != nil {
+		return err
+	}
+	if len(komponen) > 0 {
+		return fmt.Errorf("stok paket %s dihitung dari komponennya dan tidak dapat diubah langsung", produk.Nama)
+	}
+	return nil
+}
+
+// hargaJualSatuan returns the selling price of one unit; without a price of
+// its own the unit is sold at the base price times its conversion
+func hargaJualSatuan(produk *models.Produk, satuan *models.ProdukSatuan) int {
+	if satuan.HargaJual > 0 {
+		return satuan.HargaJual
+	}
+	return int(math.Round(float64(produk.HargaJual) * satuan.Konversi))
 }
 
 // GetKeranjang retrieves all cart items
@@ -160,7 +534,7 @@ func (s *ProdukService) GetKeranjang() ([]*models.KeranjangItem, error) {
 
  
 
-// ProcessKeranjang processes cart items and updates stock
+// ProcessKeranjang processes cart items and updates stock; a cart holding a kit is rejected as a whole
 func (s *ProdukService) ProcessKeranjang() error {
 	// Get all cart items
 	items, err := s.keranjangRepo.GetAll()
@@ -172,12 +546,35 @@ func (s *ProdukService) ProcessKeranjang() error {
 		return fmt.Errorf("cart is empty")
 	}
 
-	// Update stock for each item
+	// Kits are stocked through their components; reject the cart before any stock changes
+	for _, item := range items {
+		if err := cekBukanPaket(s.komponenRepo, item.Produk); err != nil {
+			return err
+		}
+	}
+
+	// Update stock for each item; its harga beli is the cost of this receipt
 	for _, item := range items {
-		newStok := item.Produk.Stok + float64(item.Jumlah)
-		if err := s.produkRepo.UpdateStok(item.Produk.ID, newStok); err != nil {
+		qty := float64(item.Jumlah)
+		hpp, err := s.produkRepo.UpdateStokMasuk(item.Produk, qty, item.HargaBeli)
+		if err != nil {
 			return fmt.Errorf("failed to update stock for product %s: %w", item.Produk.Nama, err)
 		}
+		log.Printf("Restocked %s: +%.2f at %d, HPP %d -> %d", item.Produk.Nama, qty, item.HargaBeli, item.Produk.HargaBeli, hpp)
+
+		history := &models.StokHistory{
+			ProdukID:       item.Produk.ID,
+			StokSebelum:    item.Produk.Stok,
+			StokSesudah:    item.Produk.Stok + qty,
+			Perubahan:      qty,
+			JenisPerubahan: "penambahan",
+			Keterangan:     "Restok dari keranjang",
+			HargaBeli:      item.HargaBeli,
+			CreatedAt:      time.Now(),
+		}
+		if err := s.produkRepo.CreateStokHistory(history); err != nil {
+			log.Printf("Failed to record stock history: %v", err)
+		}
 	}
 
 	// Clear cart
@@ -226,6 +623,27 @@ func (s *ProdukService) UpdateProduk(produk *models.Produk) error {
 		return fmt.Errorf("hari pemberitahuan (%d hari) tidak boleh melebihi masa simpan (%d hari)", produk.HariPemberitahuanKadaluarsa, produk.MasaSimpanHari)
 	}
 
+	// Validate tax code (empty means follow the category)
+	produk.KodePajak = strings.ToUpper(strings.TrimSpace(produk.KodePajak))
+	if err := validateKodePajak(produk.KodePajak); err != nil {
+		return err
+	}
+
+	// Validate PLU used by scale labels (empty means not sold by scale label)
+	if err := s.validatePLU(produk); err != nil {
+		return err
+	}
+
+	// Validate reorder parameters used by purchase suggestions
+	if err := s.validatePesanUlang(produk); err != nil {
+		return err
+	}
+
+	// Validate batch consumption policy (empty means follow the category)
+	if err := validateKebijakanBatch(&produk.KebijakanBatch); err != nil {
+		return err
+	}
+
 	// Check if product exists
 	existing, err := s.produkRepo.GetByID(produk.ID)
 	if err != nil {
@@ -253,6 +671,29 @@ func (s *ProdukService) UpdateProduk(produk *models.Produk) error {
 		if existingByBarcode != nil && existingByBarcode.ID != produk.ID {
 			return fmt.Errorf("barcode already exists for another product")
 		}
+		satuanByBarcode, err := s.satuanRepo.GetByBarcode(produk.Barcode)
+		if err != nil {
+			return fmt.Errorf("failed to check existing barcode: %w", err)
+		}
+		if satuanByBarcode != nil {
+			return fmt.Errorf("barcode already exists for a product unit")
+		}
+	}
+
+	// Harga beli of stock on hand is the moving-average cost maintained by stock
+	// receipts; it can only be entered directly while there is no stock or no cost yet
+	if existing.Stok > 0 && existing.HargaBeli > 0 && produk.HargaBeli != existing.HargaBeli {
+		return fmt.Errorf("harga beli produk yang masih memiliki stok adalah rata-rata harga penerimaan (Rp %d) dan tidak dapat diubah langsung",
+			existing.HargaBeli)
+	}
+
+	// Stok paket yang ditampilkan dihitung dari komponen, bukan disimpan pada paket
+	komponen, err := s.komponenRepo.GetByPaket(produk.ID)
+	if err != nil {
+		return err
+	}
+	if len(komponen) > 0 {
+		produk.Stok = existing.Stok
 	}
 
 	// Check if masa_simpan_hari has changed
@@ -280,6 +721,47 @@ func (s *ProdukService) UpdateProduk(produk *models.Produk) error {
 	return nil
 }
 
+// validatePLU normalizes the PLU of a product and checks it is numeric and not
+// used by another product. Leading zeros are ignored when comparing.
+func (s *ProdukService) validatePLU(produk *models.Produk) error {
+	produk.PLU = strings.TrimSpace(produk.PLU)
+	if produk.PLU == "" {
+		return nil
+	}
+	if !isDigits(produk.PLU) {
+		return fmt.Errorf("PLU hanya boleh berisi angka")
+	}
+	if produk.JenisProduk != "curah" {
+		return fmt.Errorf("PLU hanya dapat diisi untuk produk curah")
+	}
+
+	existing, err := s.produkRepo.GetByPLU(produk.PLU)
+	if err != nil {
+		return fmt.Errorf("failed to check existing PLU: %w", err)
+	}
+	if existing != nil && existing.ID != produk.ID {
+		return fmt.Errorf("PLU %s sudah digunakan produk %s", produk.PLU, existing.Nama)
+	}
+
+	return nil
+}
+
+// validatePesanUlang validates the reorder point, target stock, lead time and main supplier of a product
+func (s *ProdukService) validatePesanUlang(produk *models.Produk) error {
+	if produk.StokMinimum < 0 || produk.StokMaksimum < 0 || produk.LeadTimeHari < 0 {
+		return fmt.Errorf("stok minimum, stok maksimum dan lead time tidak boleh negatif")
+	}
+	if produk.StokMaksimum > 0 && produk.StokMaksimum < produk.StokMinimum {
+		return fmt.Errorf("stok maksimum (%.2f) tidak boleh kurang dari stok minimum (%.2f)", produk.StokMaksimum, produk.StokMinimum)
+	}
+	if produk.SupplierID > 0 {
+		if _, err := s.supplier.GetSupplierByID(produk.SupplierID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *ProdukService) DeleteProduk(id int) error {
 	// Validate ID
 	if id <= 0 {
@@ -295,6 +777,15 @@ func (s *ProdukService) DeleteProduk(id int) error {
 		return fmt.Errorf("produk dengan ID %d tidak ditemukan", id)
 	}
 
+	// A product cannot disappear from the kits it is a component of
+	dipakai, err := s.komponenRepo.CountPaketByKomponen(id)
+	if err != nil {
+		return err
+	}
+	if dipakai > 0 {
+		return fmt.Errorf("produk %s masih menjadi komponen %d paket, hapus dari paket terlebih dahulu", existing.Nama, dipakai)
+	}
+
 	// Log cascade delete information
 	fmt.Printf("Deleting product '%s' (ID: %d) and all related data (batches, history, transaction items, cart items)...\n",
 		existing.Nama, id)
@@ -312,6 +803,10 @@ func (s *ProdukService) DeleteProduk(id int) error {
 
 // UpdateStok updates product stock with history tracking
 func (s *ProdukService) UpdateStok(req *models.UpdateStokRequest) error {
+	return s.withStokIdempotency(IdempotencyScopeStok, req, s.updateStok)
+}
+
+func (s *ProdukService) updateStok(req *models.UpdateStokRequest) error {
 	// Validate
 	if req.StokBaru < 0 {
 		return fmt.Errorf("stock cannot be negative")
@@ -325,9 +820,12 @@ func (s *ProdukService) UpdateStok(req *models.UpdateStokRequest) error {
 	if currentProduk == nil {
 		return fmt.Errorf("product not found")
 	}
+	if err := cekBukanPaket(s.komponenRepo, currentProduk); err != nil {
+		return err
+	}
 
 	// Update stock
-	if err := s.produkRepo.UpdateStok(req.ProdukID, req.StokBaru); err != nil {
+	if err := s.simpanStok(IdempotencyScopeStok, req, currentProduk, req.StokBaru, 0); err != nil {
 		return fmt.Errorf("failed to update stock: %w", err)
 	}
 
@@ -354,6 +852,54 @@ func (s *ProdukService) UpdateStok(req *models.UpdateStokRequest) error {
 
 // UpdateStokIncrement updates stock by increment/decrement
 func (s *ProdukService) UpdateStokIncrement(req *models.UpdateStokRequest) error {
+	return s.withStokIdempotency(IdempotencyScopeStokIncrement, req, s.updateStokIncrement)
+}
+
+// withStokIdempotency runs a stock adjustment at most once per idempotency key,
+// so a retried increment is not applied to the stock twice. The key is stored by
+// simpanStok in the same database transaction as the new stock.
+func (s *ProdukService) withStokIdempotency(scope string, req *models.UpdateStokRequest, apply func(*models.UpdateStokRequest) error) error {
+	key := strings.TrimSpace(req.IdempotencyKey)
+	if key == "" {
+		return apply(req)
+	}
+
+	payload := *req
+	payload.IdempotencyKey = ""
+	requestHash, record, err := s.idempotency.Lookup(scope, key, &payload)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		return nil
+	}
+
+	req.IdempotencyKey = key
+	req.IdempotencyHash = requestHash
+	err = apply(req)
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		// A concurrent retry with the same key applied the adjustment first
+		_, err = s.idempotency.Stored(scope, key, requestHash)
+	}
+	return err
+}
+
+// simpanStok writes the new stock of an adjustment; incoming stock bought at
+// hargaBeli also updates the moving-average cost. A keyed adjustment stores its
+// idempotency key in the same database transaction as the stock.
+func (s *ProdukService) simpanStok(scope string, req *models.UpdateStokRequest, produk *models.Produk, stok float64, hargaBeli int) error {
+	if req.IdempotencyKey != "" {
+		_, err := s.produkRepo.UpdateStokIdempoten(produk, stok, hargaBeli, scope, req.IdempotencyKey, req.IdempotencyHash)
+		return err
+	}
+	if hargaBeli > 0 && stok > produk.Stok {
+		_, err := s.produkRepo.UpdateStokMasuk(produk, stok-produk.Stok, hargaBeli)
+		return err
+	}
+	return s.produkRepo.UpdateStok(produk.ID, stok)
+}
+
+func (s *ProdukService) updateStokIncrement(req *models.UpdateStokRequest) error {
 	// Validate
 	if req.Perubahan == 0 {
 		return fmt.Errorf("perubahan stok tidak boleh 0")
@@ -367,16 +913,48 @@ func (s *ProdukService) UpdateStokIncrement(req *models.UpdateStokRequest) error
 	if currentProduk == nil {
 		return fmt.Errorf("product not found")
 	}
+	if err := cekBukanPaket(s.komponenRepo, currentProduk); err != nil {
+		return err
+	}
 
 	newStock := currentProduk.Stok + req.Perubahan
 	if newStock < 0 {
 		return fmt.Errorf("stok tidak boleh negatif")
 	}
+	if req.HargaBeli < 0 {
+		return fmt.Errorf("harga beli tidak boleh negatif")
+	}
 
-	// Update stock
-	if err := s.produkRepo.UpdateStok(req.ProdukID, newStock); err != nil {
+	// Stock in expired batches can only leave as a write-off when the batch policy skips them
+	if req.Perubahan < 0 && req.TipeKerugian == "" {
+		if err := s.batchService.ValidateDeduction(currentProduk, -req.Perubahan); err != nil {
+			return err
+		}
+	}
+
+	// Resolve the supplier before changing stock, so an unknown or inactive supplier fails the restock
+	if req.Perubahan > 0 && req.MasaSimpanHari > 0 {
+		supplier, err := s.supplier.ResolveSupplier(req.SupplierID, req.Supplier)
+		if err != nil {
+			return err
+		}
+		if supplier != nil {
+			req.SupplierID = supplier.ID
+			req.Supplier = supplier.Nama
+		}
+	}
+
+	// Update stock; incoming stock with a purchase price updates the moving-average cost
+	var hargaBeli int
+	if req.Perubahan > 0 && req.HargaBeli > 0 {
+		hargaBeli = req.HargaBeli
+	}
+	if err := s.simpanStok(IdempotencyScopeStokIncrement, req, currentProduk, newStock, hargaBeli); err != nil {
 		return fmt.Errorf("failed to update stock: %w", err)
 	}
+	if req.Perubahan > 0 && hargaBeli == 0 {
+		hargaBeli = currentProduk.HargaBeli
+	}
 
 	// ===  BATCH SYSTEM: Handle batch updates ===
 	if req.Perubahan > 0 && req.MasaSimpanHari > 0 {
@@ -391,11 +969,17 @@ func (s *ProdukService) UpdateStokIncrement(req *models.UpdateStokRequest) error
 			log.Printf("Batch created successfully: %s", batch.ID)
 		}
 	} else if req.Perubahan < 0 {
-		// NEGATIVE CHANGE (Reducing stock): Deduct from batches using FIFO
+		// NEGATIVE CHANGE (Reducing stock): Deduct from batches in the order of the batch policy
 		qtyToDeduct := -req.Perubahan
-		log.Printf("Reducing stock for produk=%d, qty=%.2f - deducting from batches (FIFO)", req.ProdukID, qtyToDeduct)
+		log.Printf("Reducing stock for produk=%d, qty=%.2f - deducting from batches", req.ProdukID, qtyToDeduct)
 
-		err := s.batchService.DeductFromBatches(req.ProdukID, qtyToDeduct)
+		// Write-offs are recorded per batch so losses can be traced to the supplier
+		var err error
+		if req.TipeKerugian != "" {
+			err = s.batchService.WriteOffFromBatches(req.ProdukID, qtyToDeduct, req.TipeKerugian, req.Keterangan)
+		} else {
+			err = s.batchService.DeductFromBatches(req.ProdukID, qtyToDeduct)
+		}
 		if err != nil {
 			// Log error but don't fail the stock update
 			log.Printf("Warning: Failed to deduct from batches: %v", err)
@@ -414,6 +998,7 @@ func (s *ProdukService) UpdateStokIncrement(req *models.UpdateStokRequest) error
 		Keterangan:     req.Keterangan,
 		TipeKerugian:   req.TipeKerugian,
 		NilaiKerugian:  req.NilaiKerugian,
+		HargaBeli:      hargaBeli,
 		CreatedAt:      time.Now(),
 	}
 
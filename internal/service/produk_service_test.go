@@ -0,0 +1,122 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openStokPaketDB opens an in-memory database with a kit (3) made of two products
+// (1 and 2) and makes it the database used by the repositories
+func openStokPaketDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	lama := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = lama
+		db.Close()
+	})
+
+	for _, ddl := range []string{
+		`CREATE TABLE produk (
+			id INTEGER PRIMARY KEY, sku TEXT DEFAULT '', barcode TEXT DEFAULT '', nama TEXT, kategori TEXT DEFAULT '',
+			berat REAL DEFAULT 0, harga_beli INTEGER DEFAULT 0, harga_jual INTEGER DEFAULT 0, stok REAL DEFAULT 0,
+			satuan TEXT DEFAULT 'pcs', jenis_produk TEXT, kadaluarsa TEXT, tanggal_masuk TEXT, deskripsi TEXT DEFAULT '',
+			gambar TEXT, hari_pemberitahuan_kadaluarsa INTEGER DEFAULT 0, masa_simpan_hari INTEGER DEFAULT 0,
+			kode_pajak TEXT, plu TEXT, stok_minimum REAL DEFAULT 0, stok_maksimum REAL DEFAULT 0,
+			lead_time_hari INTEGER DEFAULT 0, supplier_id INTEGER, kebijakan_batch TEXT, induk_id INTEGER,
+			atribut_varian TEXT, deleted_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE produk_komponen (
+			id INTEGER PRIMARY KEY, paket_id INTEGER, komponen_id INTEGER, qty REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE keranjang (
+			id INTEGER PRIMARY KEY, produk_id INTEGER, jumlah INTEGER, harga_beli INTEGER, subtotal INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE stok_history (
+			id INTEGER PRIMARY KEY, produk_id INTEGER, stok_sebelum REAL, stok_sesudah REAL, perubahan REAL,
+			jenis_perubahan TEXT, keterangan TEXT, tipe_kerugian TEXT, nilai_kerugian INTEGER, harga_beli INTEGER,
+			created_at DATETIME
+		)`,
+		`INSERT INTO produk (id, nama, stok, harga_beli) VALUES (1, 'Kopi', 10, 3000), (2, 'Gula', 20, 1000)`,
+		`INSERT INTO produk (id, nama, stok, harga_beli) VALUES (3, 'Paket Kopi Gula', 0, 4000)`,
+		`INSERT INTO produk_komponen (paket_id, komponen_id, qty) VALUES (3, 1, 1), (3, 2, 1)`,
+	} {
+		_, err := db.Exec(ddl)
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func TestProcessKeranjangTolakPaket(t *testing.T) {
+	db := openStokPaketDB(t)
+	_, err := db.Exec(`INSERT INTO keranjang (produk_id, jumlah, harga_beli, subtotal) VALUES (1, 5, 3000, 15000), (3, 2, 4000, 8000)`)
+	require.NoError(t, err)
+
+	s := &ProdukService{
+		produkRepo:    repository.NewProdukRepository(),
+		keranjangRepo: repository.NewKeranjangRepository(),
+		komponenRepo:  repository.NewProdukKomponenRepository(),
+	}
+	assert.ErrorContains(t, s.ProcessKeranjang(), "stok paket")
+
+	var stokKomponen, stokPaket float64
+	require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = 1`).Scan(&stokKomponen))
+	require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = 3`).Scan(&stokPaket))
+	assert.Equal(t, 10.0, stokKomponen, "keranjang ditolak seluruhnya")
+	assert.Zero(t, stokPaket, "paket tidak mendapat stok sendiri")
+
+	var keranjang int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM keranjang`).Scan(&keranjang))
+	assert.Equal(t, 2, keranjang)
+}
+
+func TestUpdateProdukTolakUbahHargaBeliRataRata(t *testing.T) {
+	db := openStokPaketDB(t)
+	_, err := db.Exec(`INSERT INTO produk (id, sku, nama, stok, harga_beli, harga_jual) VALUES (4, 'TEH', 'Teh', 0, 2000, 3000)`)
+	require.NoError(t, err)
+
+	s := &ProdukService{
+		produkRepo:   repository.NewProdukRepository(),
+		komponenRepo: repository.NewProdukKomponenRepository(),
+	}
+
+	// Produk dengan stok: harga beli adalah rata-rata penerimaan
+	kopi, err := s.produkRepo.GetByID(1)
+	require.NoError(t, err)
+	kopi.SKU = "KOPI"
+	kopi.HargaJual = 5000
+	kopi.HargaBeli = 3500
+	assert.ErrorContains(t, s.UpdateProduk(kopi), "rata-rata harga penerimaan")
+
+	var hargaBeli int
+	require.NoError(t, db.QueryRow(`SELECT harga_beli FROM produk WHERE id = 1`).Scan(&hargaBeli))
+	assert.Equal(t, 3000, hargaBeli)
+
+	// Perubahan lain tetap dapat disimpan selama harga beli tidak diubah
+	kopi.HargaBeli = 3000
+	require.NoError(t, s.UpdateProduk(kopi))
+	var hargaJual int
+	require.NoError(t, db.QueryRow(`SELECT harga_jual FROM produk WHERE id = 1`).Scan(&hargaJual))
+	assert.Equal(t, 5000, hargaJual)
+
+	// Tanpa stok harga beli dapat diisi langsung
+	teh, err := s.produkRepo.GetByID(4)
+	require.NoError(t, err)
+	teh.HargaBeli = 2500
+	require.NoError(t, s.UpdateProduk(teh))
+	require.NoError(t, db.QueryRow(`SELECT harga_beli FROM produk WHERE id = 4`).Scan(&hargaBeli))
+	assert.Equal(t, 2500, hargaBeli)
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Default saran pembelian jika tidak diatur
+const (
+	defaultHariAnalisisSaran = 30
+	defaultHariCakupanSaran  = 14
+	defaultLeadTimeHari      = 7
+)
+
+// PurchaseOrderService handles purchase orders to suppliers and the goods receipts against them
+type PurchaseOrderService struct {
+	repo          *repository.PurchaseOrderRepository
+	produkRepo    *repository.ProdukRepository
+	transaksiRepo *repository.TransaksiRepository
+	komponenRepo  *repository.ProdukKomponenRepository
+	supplier      *SupplierService
+}
+
+// NewPurchaseOrderService creates a new instance
+func NewPurchaseOrderService() *PurchaseOrderService {
+	return &PurchaseOrderService{
+		repo:          repository.NewPurchaseOrderRepository(),
+		produkRepo:    repository.NewProdukRepository(),
+		transaksiRepo: repository.NewTransaksiRepository(),
+		komponenRepo:  repository.NewProdukKomponenRepository(),
+		supplier:      NewSupplierService(),
+	}
+}
+
+// GetAllPurchaseOrder retrieves purchase orders without their lines, optionally filtered by status
+func (s *PurchaseOrderService) GetAllPurchaseOrder(status string) ([]*models.PurchaseOrder, error) {
+	return s.repo.GetAll(status)
+}
+
+// GetPurchaseOrderByID retrieves a purchase order with its lines, goods receipts and variances
+func (s *PurchaseOrderService) GetPurchaseOrderByID(id int) (*models.PurchaseOrder, error) {
+	po, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if po == nil {
+		return nil, fmt.Errorf("purchase order tidak ditemukan")
+	}
+	return po, nil
+}
+
+// SimpanPurchaseOrder creates a draft purchase order, or replaces the lines of an existing draft
+func (s *PurchaseOrderService) SimpanPurchaseOrder(req *models.SimpanPurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	if req.SupplierID <= 0 {
+		return nil, fmt.Errorf("supplier harus dipilih")
+	}
+	supplier, err := s.supplier.ResolveSupplier(req.SupplierID, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("purchase order harus memiliki minimal 1 item")
+	}
+
+	po := &models.PurchaseOrder{
+		ID:                req.ID,
+		SupplierID:        supplier.ID,
+		NamaSupplier:      supplier.Nama,
+		TanggalDiharapkan: req.TanggalDiharapkan,
+		Catatan:           strings.TrimSpace(req.Catatan),
+		DibuatOleh:        strings.TrimSpace(req.DibuatOleh),
+	}
+
+	dipesan := make(map[int]bool)
+	for _, itemReq := range req.Items {
+		if itemReq.QtyPesan <= 0 {
+			return nil, fmt.Errorf("jumlah pesanan harus lebih dari 0")
+		}
+		if itemReq.HargaEstimasi < 0 {
+			return nil, fmt.Errorf("harga estimasi tidak boleh negatif")
+		}
+		if dipesan[itemReq.ProdukID] {
+			return nil, fmt.Errorf("produk ID %d dipesan lebih dari sekali, gabungkan jumlahnya", itemReq.ProdukID)
+		}
+		dipesan[itemReq.ProdukID] = true
+
+		produk, err := s.produkRepo.GetByID(itemReq.ProdukID)
+		if err != nil {
+			return nil, err
+		}
+		if produk == nil {
+			return nil, fmt.Errorf("produk ID %d tidak ditemukan", itemReq.ProdukID)
+		}
+
+		hargaEstimasi := itemReq.HargaEstimasi
+		if hargaEstimasi == 0 {
+			hargaEstimasi = produk.HargaBeli
+		}
+
+		item := &models.PurchaseOrderItem{
+			ProdukID:      produk.ID,
+			NamaProduk:    produk.Nama,
+			QtyPesan:      itemReq.QtyPesan,
+			HargaEstimasi: hargaEstimasi,
+			Subtotal:      int(itemReq.QtyPesan * float64(hargaEstimasi)),
+		}
+		po.Items = append(po.Items, item)
+		po.TotalEstimasi += item.Subtotal
+	}
+
+	if po.ID == 0 {
+		if err := s.repo.Create(po); err != nil {
+			return nil, err
+		}
+	} else if err := s.repo.UpdateDraft(po); err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(po.ID)
+}
+
+// KirimPurchaseOrder marks a draft purchase order as sent to the supplier
+func (s *PurchaseOrderService) KirimPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	if _, err := s.GetPurchaseOrderByID(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Kirim(id); err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrderByID(id)
+}
+
+// TutupPurchaseOrder closes a purchase order; goods not yet received are no longer expected
+func (s *PurchaseOrderService) TutupPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	if _, err := s.GetPurchaseOrderByID(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Tutup(id); err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrderByID(id)
+}
+
+// DeletePurchaseOrder removes a purchase order that has not been sent yet
+func (s *PurchaseOrderService) DeletePurchaseOrder(id int) error {
+	if _, err := s.GetPurchaseOrderByID(id); err != nil {
+		return err
+	}
+	return s.repo.DeleteDraft(id)
+}
+
+// TerimaBarang records goods received for a sent purchase order. Stock is
+// increased and logged as "pembelian"; lines with a shelf life get a batch
+// attributed to the PO supplier in the same database transaction. Kits are
+// rejected since their stock is made up of their components. Receiving more or less than ordered is
+// allowed and shows up as the variance of the PO line.
+func (s *PurchaseOrderService) TerimaBarang(req *models.TerimaBarangRequest) (*models.PenerimaanBarang, error) {
+	po, err := s.GetPurchaseOrderByID(req.POID)
+	if err != nil {
+		return nil, err
+	}
+	if po.Status != models.StatusPODikirim && po.Status != models.StatusPODiterimaSebagian {
+		return nil, fmt.Errorf("purchase order %s berstatus %s, barang hanya dapat diterima setelah PO dikirim", po.Nomor, po.Status)
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("penerimaan barang harus memiliki minimal 1 item")
+	}
+
+	poItems := make(map[int]*models.PurchaseOrderItem, len(po.Items))
+	for _, item := range po.Items {
+		poItems[item.ID] = item
+	}
+
+	penerimaan := &models.PenerimaanBarang{
+		Penerima: strings.TrimSpace(req.Penerima),
+		Catatan:  strings.TrimSpace(req.Catatan),
+	}
+	for _, itemReq := range req.Items {
+		poItem, ok := poItems[itemReq.POItemID]
+		if !ok {
+			return nil, fmt.Errorf("item ID %d bukan bagian dari purchase order %s", itemReq.POItemID, po.Nomor)
+		}
+		if itemReq.Qty <= 0 {
+			return nil, fmt.Errorf("jumlah diterima untuk %s harus lebih dari 0", poItem.NamaProduk)
+		}
+		if itemReq.HargaBeli < 0 || itemReq.MasaSimpanHari < 0 {
+			return nil, fmt.Errorf("harga beli dan masa simpan tidak boleh negatif")
+		}
+
+		produk, err := s.produkRepo.GetByID(poItem.ProdukID)
+		if err != nil {
+			return nil, err
+		}
+		if produk == nil {
+			return nil, fmt.Errorf("produk %s sudah tidak ada", poItem.NamaProduk)
+		}
+		if err := cekBukanPaket(s.komponenRepo, produk); err != nil {
+			return nil, err
+		}
+
+		hargaBeli := itemReq.HargaBeli
+		if hargaBeli == 0 {
+			hargaBeli = poItem.HargaEstimasi
+		}
+		masaSimpan := itemReq.MasaSimpanHari
+		if masaSimpan == 0 {
+			masaSimpan = produk.MasaSimpanHari
+		}
+
+		penerimaan.Items = append(penerimaan.Items, &models.PenerimaanBarangItem{
+			POItemID:       poItem.ID,
+			ProdukID:       poItem.ProdukID,
+			NamaProduk:     poItem.NamaProduk,
+			Qty:            itemReq.Qty,
+			HargaBeli:      hargaBeli,
+			MasaSimpanHari: masaSimpan,
+		})
+	}
+
+	if err := s.repo.Terima(po, penerimaan); err != nil {
+		return nil, err
+	}
+
+	return penerimaan, nil
+}
+
+// GetSaranPembelian proposes order quantities, grouped per supplier, for products
+// whose stock plus open purchase orders has reached the reorder point. The reorder
+// point and target stock come from the product settings, or from the daily sales
+// over the analysis period when not set. For products with a shelf life the
+// target is capped at what is sold before it expires.
+func (s *PurchaseOrderService) GetSaranPembelian(req *models.SaranPembelianRequest) ([]*models.SaranPembelianSupplier, error) {
+	if req.HariAnalisis < 0 || req.HariCakupan < 0 {
+		return nil, fmt.Errorf("hari analisis dan hari cakupan tidak boleh negatif")
+	}
+	hariAnalisis := req.HariAnalisis
+	if hariAnalisis == 0 {
+		hariAnalisis = defaultHariAnalisisSaran
+	}
+	hariCakupan := req.HariCakupan
+	if hariCakupan == 0 {
+		hariCakupan = defaultHariCakupanSaran
+	}
+
+	now := time.Now()
+	sejak := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -hariAnalisis)
+	terjual, err := s.transaksiRepo.GetQtyTerjualPerProduk(sejak)
+	if err != nil {
+		return nil, err
+	}
+	dipesan, err := s.repo.GetQtyDalamPesanan()
+	if err != nil {
+		return nil, err
+	}
+	supplierTerakhir, err := s.repo.GetSupplierTerakhir()
+	if err != nil {
+		return nil, err
+	}
+	suppliers, err := s.supplier.GetAllSupplier()
+	if err != nil {
+		return nil, err
+	}
+	namaSupplier := make(map[int]string, len(suppliers))
+	for _, supplier := range suppliers {
+		namaSupplier[supplier.ID] = supplier.Nama
+	}
+
+	products, err := s.produkRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Paket tidak dibeli dari supplier; penjualannya sudah dihitung pada komponennya
+	komponen, err := s.komponenRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	paket := make(map[int]bool)
+	for _, k := range komponen {
+		paket[k.PaketID] = true
+	}
+
+	perSupplier := make(map[int]*models.SaranPembelianSupplier)
+	for _, produk := range products {
+		if paket[produk.ID] {
+			continue
+		}
+		penjualanHarian := terjual[produk.ID] / float64(hariAnalisis)
+		if penjualanHarian <= 0 && produk.StokMinimum <= 0 {
+			continue
+		}
+
+		leadTime := produk.LeadTimeHari
+		if leadTime == 0 {
+			leadTime = defaultLeadTimeHari
+		}
+		titikPesan := produk.StokMinimum
+		if titikPesan == 0 {
+			titikPesan = penjualanHarian * float64(leadTime)
+		}
+		stokTarget := produk.StokMaksimum
+		if stokTarget == 0 {
+			stokTarget = titikPesan + penjualanHarian*float64(hariCakupan)
+		}
+
+		// Barang dengan masa simpan tidak dipesan melebihi yang terjual sebelum kadaluarsa
+		dibatasi := false
+		if produk.MasaSimpanHari > 0 {
+			batas := math.Max(penjualanHarian*float64(produk.MasaSimpanHari), titikPesan)
+			if stokTarget > batas {
+				stokTarget = batas
+				dibatasi = true
+			}
+		}
+
+		posisi := produk.Stok + dipesan[produk.ID]
+		if posisi > titikPesan {
+			continue
+		}
+		qty := stokTarget - posisi
+		if produk.JenisProduk == "curah" {
+			qty = math.Ceil(qty*100) / 100
+		} else {
+			qty = math.Ceil(qty)
+		}
+		if qty <= 0 {
+			continue
+		}
+
+		supplierID := produk.SupplierID
+		if supplierID == 0 {
+			supplierID = supplierTerakhir[produk.ID]
+		}
+		if req.SupplierID > 0 && supplierID != req.SupplierID {
+			continue
+		}
+
+		grup, ok := perSupplier[supplierID]
+		if !ok {
+			grup = &models.SaranPembelianSupplier{SupplierID: supplierID, NamaSupplier: namaSupplier[supplierID]}
+			if supplierID == 0 {
+				grup.NamaSupplier = "Tanpa supplier"
+			}
+			perSupplier[supplierID] = grup
+		}
+
+		item := &models.SaranPembelianItem{
+			ProdukID:           produk.ID,
+			SKU:                produk.SKU,
+			NamaProduk:         produk.Nama,
+			Satuan:             produk.Satuan,
+			Stok:               produk.Stok,
+			QtyDalamPesanan:    dipesan[produk.ID],
+			PenjualanHarian:    math.Round(penjualanHarian*100) / 100,
+			LeadTimeHari:       leadTime,
+			TitikPesan:         math.Round(titikPesan*100) / 100,
+			StokTarget:         math.Round(stokTarget*100) / 100,
+			QtyDisarankan:      qty,
+			HargaBeli:          produk.HargaBeli,
+			Subtotal:           int(math.Round(qty * float64(produk.HargaBeli))),
+			MasaSimpanHari:     produk.MasaSimpanHari,
+			DibatasiMasaSimpan: dibatasi,
+		}
+		grup.Items = append(grup.Items, item)
+		grup.TotalEstimasi += item.Subtotal
+	}
+
+	saran := make([]*models.SaranPembelianSupplier, 0, len(perSupplier))
+	for _, grup := range perSupplier {
+		sort.Slice(grup.Items, func(i, j int) bool { return grup.Items[i].NamaProduk < grup.Items[j].NamaProduk })
+		saran = append(saran, grup)
+	}
+	// Produk tanpa supplier ditampilkan paling akhir
+	sort.Slice(saran, func(i, j int) bool {
+		if (saran[i].SupplierID == 0) != (saran[j].SupplierID == 0) {
+			return saran[j].SupplierID == 0
+		}
+		return saran[i].NamaSupplier < saran[j].NamaSupplier
+	})
+
+	return saran, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerimaBarangTolakPaket(t *testing.T) {
+	db := openStokPaketDB(t)
+	for _, q := range []string{
+		`CREATE TABLE supplier (id INTEGER PRIMARY KEY, nama TEXT)`,
+		`CREATE TABLE purchase_order (
+			id INTEGER PRIMARY KEY, nomor TEXT, supplier_id INTEGER, status TEXT, tanggal_diharapkan DATETIME,
+			catatan TEXT, total_estimasi INTEGER, dibuat_oleh TEXT, dikirim_at DATETIME, ditutup_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE purchase_order_item (
+			id INTEGER PRIMARY KEY, po_id INTEGER, produk_id INTEGER, qty_pesan REAL, harga_estimasi INTEGER,
+			qty_diterima REAL DEFAULT 0, nilai_diterima INTEGER DEFAULT 0
+		)`,
+		`CREATE TABLE penerimaan_barang (
+			id INTEGER PRIMARY KEY, nomor TEXT, po_id INTEGER, penerima TEXT, catatan TEXT, created_at DATETIME
+		)`,
+		`CREATE TABLE penerimaan_barang_item (
+			id INTEGER PRIMARY KEY, penerimaan_id INTEGER, po_item_id INTEGER, produk_id INTEGER, qty REAL,
+			harga_beli INTEGER, masa_simpan_hari INTEGER, batch_id TEXT
+		)`,
+		`INSERT INTO supplier (id, nama) VALUES (1, 'CV Sumber')`,
+		`INSERT INTO purchase_order (id, nomor, supplier_id, status) VALUES (1, 'PO-20261018-0001', 1, 'dikirim')`,
+		`INSERT INTO purchase_order_item (id, po_id, produk_id, qty_pesan, harga_estimasi)
+			VALUES (1, 1, 1, 5, 3000), (2, 1, 3, 2, 4000)`,
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+
+	s := &PurchaseOrderService{
+		repo:         repository.NewPurchaseOrderRepository(),
+		produkRepo:   repository.NewProdukRepository(),
+		komponenRepo: repository.NewProdukKomponenRepository(),
+	}
+	_, err := s.TerimaBarang(&models.TerimaBarangRequest{
+		POID: 1,
+		Items: []models.TerimaBarangItemRequest{
+			{POItemID: 1, Qty: 5},
+			{POItemID: 2, Qty: 2},
+		},
+	})
+	assert.ErrorContains(t, err, "stok paket")
+
+	var stokKomponen, stokPaket float64
+	require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = 1`).Scan(&stokKomponen))
+	require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = 3`).Scan(&stokPaket))
+	assert.Equal(t, 10.0, stokKomponen, "penerimaan ditolak seluruhnya")
+	assert.Zero(t, stokPaket, "paket tidak mendapat stok sendiri")
+
+	var penerimaan int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM penerimaan_barang`).Scan(&penerimaan))
+	assert.Zero(t, penerimaan)
+
+	// Tanpa baris paket penerimaan diterima
+	_, err = s.TerimaBarang(&models.TerimaBarangRequest{
+		POID:  1,
+		Items: []models.TerimaBarangItemRequest{{POItemID: 1, Qty: 5}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.QueryRow(`SELECT stok FROM produk WHERE id = 1`).Scan(&stokKomponen))
+	assert.Equal(t, 15.0, stokKomponen)
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"ritel-app/internal/models"
 	"ritel-app/internal/repository"
+	"strings"
 	"time"
 )
 
@@ -12,7 +14,10 @@ type ReturnService struct {
 	returnRepo     *repository.ReturnRepository
 	transaksiRepo  *repository.TransaksiRepository
 	produkService  *ProdukService
-	pelangganRepo  *repository.PelangganRepository
+	idempotency    *IdempotencyService
+	shiftService   *ShiftService
+	kartuHadiah    *KartuHadiahService
+	piutangRepo    *repository.PiutangRepository
 }
 
 // NewReturnService creates a new instance
@@ -21,12 +26,44 @@ func NewReturnService() *ReturnService {
 		returnRepo:    repository.NewReturnRepository(),
 		transaksiRepo: repository.NewTransaksiRepository(),
 		produkService: NewProdukService(),
-		pelangganRepo: repository.NewPelangganRepository(),
+		idempotency:   NewIdempotencyService(),
+		shiftService:  NewShiftService(),
+		kartuHadiah:   NewKartuHadiahService(),
+		piutangRepo:   repository.NewPiutangRepository(),
 	}
 }
 
-// CreateReturn creates a new return transaction with complete business logic
+// CreateReturn creates a new return transaction. The idempotency key is stored in
+// the same database transaction as the return, so a retry carrying the key of a
+// committed return is acknowledged without processing the return again.
 func (s *ReturnService) CreateReturn(req *models.CreateReturnRequest) error {
+	key := strings.TrimSpace(req.IdempotencyKey)
+	if key == "" {
+		return s.createReturn(req)
+	}
+
+	payload := *req
+	payload.IdempotencyKey = ""
+	requestHash, record, err := s.idempotency.Lookup(IdempotencyScopeReturn, key, &payload)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		return nil
+	}
+
+	req.IdempotencyKey = key
+	req.IdempotencyHash = requestHash
+	err = s.createReturn(req)
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		// A concurrent retry with the same key committed its return first
+		_, err = s.idempotency.Stored(IdempotencyScopeReturn, key, requestHash)
+	}
+	return err
+}
+
+// createReturn creates a new return transaction with complete business logic
+func (s *ReturnService) createReturn(req *models.CreateReturnRequest) error {
 	// Validate required fields
 	if req.TransaksiID == 0 && req.NoTransaksi == "" {
 		return fmt.Errorf("transaksi ID or number is required")
@@ -48,6 +85,13 @@ func (s *ReturnService) CreateReturn(req *models.CreateReturnRequest) error {
 		return fmt.Errorf("replacement product is required for exchange")
 	}
 
+	if strings.EqualFold(req.RefundMethod, models.MetodeKartuHadiah) {
+		if req.Type != "refund" {
+			return fmt.Errorf("store credit can only be issued for refund returns")
+		}
+		req.RefundMethod = models.MetodeKartuHadiah
+	}
+
 	// Get original transaction
 	var transaksi *models.TransaksiDetail
 	var err error
@@ -62,6 +106,9 @@ func (s *ReturnService) CreateReturn(req *models.CreateReturnRequest) error {
 	if transaksi == nil {
 		return fmt.Errorf("transaction not found")
 	}
+	if transaksi.Transaksi.Status == "void" {
+		return fmt.Errorf("transaction has been voided and cannot be returned")
+	}
 
 	// Set TransaksiID if not provided
 	if req.TransaksiID == 0 {
@@ -79,34 +126,35 @@ func (s *ReturnService) CreateReturn(req *models.CreateReturnRequest) error {
 		return fmt.Errorf("return window exceeded (max %d days from transaction date)", returnWindowDays)
 	}
 
-	// Validate products and quantities
-	for _, returnProduct := range req.Products {
+	// Validate products and quantities against the sale line each product comes from
+	baris := make([]*models.TransaksiItem, len(req.Products))
+	diretur := make(map[int]int) // Qty per baris transaksi yang sudah masuk return ini
+	for i := range req.Products {
+		returnProduct := &req.Products[i]
 		if returnProduct.Quantity <= 0 {
 			return fmt.Errorf("product quantity must be greater than 0")
 		}
 
-		// Find product in original transaction
-		found := false
-		for _, item := range transaksi.Items {
-			if item.ProdukID != nil && *item.ProdukID == returnProduct.ProductID {
-				// Check if return quantity exceeds purchased quantity
-				alreadyReturned, err := s.returnRepo.GetReturnedQuantity(req.TransaksiID, returnProduct.ProductID)
-				if err != nil {
-					return fmt.Errorf("failed to check returned quantity: %w", err)
-				}
-
-				availableToReturn := item.Jumlah - alreadyReturned
-				if returnProduct.Quantity > availableToReturn {
-					return fmt.Errorf("cannot return %d units of product (purchased: %d, already returned: %d, available: %d)",
-						returnProduct.Quantity, item.Jumlah, alreadyReturned, availableToReturn)
-				}
-				found = true
-				break
-			}
+		item, err := itemReturn(transaksi, *returnProduct)
+		if err != nil {
+			return err
 		}
-		if !found {
-			return fmt.Errorf("product ID %d was not in the original transaction", returnProduct.ProductID)
+		returnProduct.TransaksiItemID = item.ID
+		baris[i] = item
+
+		// Check if return quantity exceeds purchased quantity
+		alreadyReturned, err := s.returnRepo.GetReturnedQuantity(req.TransaksiID, item.ID, returnProduct.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to check returned quantity: %w", err)
 		}
+		alreadyReturned += diretur[item.ID]
+
+		availableToReturn := item.Jumlah - alreadyReturned
+		if returnProduct.Quantity > availableToReturn {
+			return fmt.Errorf("cannot return %d units of product (purchased: %d, already returned: %d, available: %d)",
+				returnProduct.Quantity, item.Jumlah, alreadyReturned, availableToReturn)
+		}
+		diretur[item.ID] += returnProduct.Quantity
 	}
 
 	// Validate replacement product for exchange
@@ -118,16 +166,23 @@ func (s *ReturnService) CreateReturn(req *models.CreateReturnRequest) error {
 		if replacementProduct == nil {
 			return fmt.Errorf("replacement product not found")
 		}
+		komponen, err := s.produkService.komponenRepo.GetByPaket(replacementProduct.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get replacement product components: %w", err)
+		}
+		if len(komponen) > 0 {
+			return fmt.Errorf("kit product %s cannot be given as replacement", replacementProduct.Nama)
+		}
 
-		// Calculate total return quantity
-		totalReturnQty := 0
-		for _, p := range req.Products {
-			totalReturnQty += p.Quantity
+		// Calculate total return quantity in base units
+		totalReturnQty := 0.0
+		for i, p := range req.Products {
+			totalReturnQty += float64(p.Quantity) * konversiItem(baris[i])
 		}
 
 		// Check if replacement product has sufficient stock
-		if replacementProduct.Stok < float64(totalReturnQty) {
-			return fmt.Errorf("insufficient stock for replacement product (available: %.2f, needed: %d)",
+		if replacementProduct.Stok < totalReturnQty {
+			return fmt.Errorf("insufficient stock for replacement product (available: %.2f, needed: %.2f)",
 				replacementProduct.Stok, totalReturnQty)
 		}
 	}
@@ -138,12 +193,39 @@ func (s *ReturnService) CreateReturn(req *models.CreateReturnRequest) error {
 		return fmt.Errorf("failed to calculate refund amount: %w", err)
 	}
 
+	// On a kasbon sale the refund first cancels what the customer still owes;
+	// only the part that was actually paid is refunded in cash or store credit
+	potongPiutang := 0
+	if req.Type == "refund" {
+		sisaPiutang, err := s.piutangRepo.GetSisaByTransaksi(req.TransaksiID)
+		if err != nil {
+			return fmt.Errorf("failed to get outstanding kasbon: %w", err)
+		}
+		potongPiutang, _ = bagiRefundKasbon(refundAmount, sisaPiutang)
+	}
+
+	// Link the return to the terminal's open shift; cash refunds come out of
+	// the drawer, so they need an open shift to be counted against
+	shift, err := s.shiftService.GetShiftAktif(req.KodeTerminal)
+	if err != nil {
+		return fmt.Errorf("failed to get active shift: %w", err)
+	}
+	if shift == nil && req.Type == "refund" && strings.EqualFold(req.RefundMethod, models.MetodeTunai) {
+		return fmt.Errorf("cash refund requires an open cashier shift")
+	}
+
 	// Parse return date
 	returnDate, err := time.Parse(time.RFC3339, req.ReturnDate)
 	if err != nil {
 		returnDate = time.Now()
 	}
 
+	// Refund status is completed right away when the refund is paid out
+	refundStatus := "pending"
+	if req.RefundMethod != "" {
+		refundStatus = "completed"
+	}
+
 	// Create return transaction
 	returnData := &models.Return{
 		TransaksiID:          req.TransaksiID,
@@ -154,106 +236,80 @@ func (s *ReturnService) CreateReturn(req *models.CreateReturnRequest) error {
 		ReplacementProductID: req.ReplacementProductID,
 		RefundAmount:         refundAmount,
 		RefundMethod:         req.RefundMethod,
-		RefundStatus:         "pending",
+		RefundStatus:         refundStatus,
 		Notes:                req.Notes,
+		PotongPiutang:        potongPiutang,
+	}
+	if shift != nil {
+		returnData.ShiftID = shift.ID
 	}
 
-	if err := s.returnRepo.Create(returnData); err != nil {
-		return fmt.Errorf("failed to create return: %w", err)
+	baru := &models.ReturnBaru{
+		Return:          returnData,
+		KembalikanStok:  req.Reason != "damaged",
+		IdempotencyKey:  req.IdempotencyKey,
+		IdempotencyHash: req.IdempotencyHash,
 	}
 
-	// Create return items and process stock changes
-	for _, product := range req.Products {
+	// Return items with the stock they put back, in base units
+	var returnItems []*models.ReturnItem
+	for i, product := range req.Products {
 		item := &models.ReturnItem{
-			ReturnID:  returnData.ID,
-			ProductID: product.ProductID,
-			Quantity:  product.Quantity,
+			ProductID:       product.ProductID,
+			TransaksiItemID: product.TransaksiItemID,
+			Quantity:        product.Quantity,
 		}
+		returnItems = append(returnItems, item)
 
-		if err := s.returnRepo.CreateReturnItem(item); err != nil {
-			return fmt.Errorf("failed to create return item: %w", err)
-		}
+		qty := float64(product.Quantity) * konversiItem(baris[i])
+		baruItem := &models.ReturnBaruItem{Item: item, QtyDasar: qty}
 
-		// Restore stock if not damaged
-		if req.Reason != "damaged" {
-			stockReq := &models.UpdateStokRequest{
-				ProdukID:   product.ProductID,
-				Perubahan:  float64(product.Quantity), // Positive = increase
-				Jenis:      "return",
-				Keterangan: fmt.Sprintf("Return dari transaksi %s - alasan: %s", req.NoTransaksi, req.Reason),
-			}
-			if err := s.produkService.UpdateStokIncrement(stockReq); err != nil {
-				return fmt.Errorf("failed to restore stock: %w", err)
-			}
-		} else {
-			// For damaged goods, still record in history but don't restore sellable stock
-			stockReq := &models.UpdateStokRequest{
-				ProdukID:   product.ProductID,
-				Perubahan:  0, // No stock change
-				Jenis:      "return_damaged",
-				Keterangan: fmt.Sprintf("Return barang rusak dari transaksi %s (stok tidak dikembalikan)", req.NoTransaksi),
-			}
-			// Create history entry without changing stock
-			produk, _ := s.produkService.produkRepo.GetByID(product.ProductID)
-			if produk != nil {
-				history := &models.StokHistory{
-					ProdukID:       product.ProductID,
-					StokSebelum:    produk.Stok,
-					StokSesudah:    produk.Stok,
-					Perubahan:      0,
-					JenisPerubahan: "return_damaged",
-					Keterangan:     stockReq.Keterangan,
-					CreatedAt:      time.Now(),
-				}
-				s.produkService.produkRepo.CreateStokHistory(history)
+		// A kit holds no stock; the components deducted at sale time go back instead
+		if baru.KembalikanStok {
+			komponen, err := s.transaksiRepo.GetKomponenTerjual(req.TransaksiID, product.ProductID)
+			if err != nil {
+				return err
 			}
+			baruItem.Komponen = komponen
 		}
+
+		baru.Items = append(baru.Items, baruItem)
+		baru.QtyPengganti += qty
 	}
 
-	// For exchange type, deduct stock for replacement product
-	if req.Type == "exchange" && req.ReplacementProductID > 0 {
-		totalReturnQty := 0
-		for _, p := range req.Products {
-			totalReturnQty += p.Quantity
-		}
+	// Transaction status after this return
+	baru.StatusTransaksi = s.calculateTransactionStatus(transaksi, req.TransaksiID, returnItems)
 
-		stockReq := &models.UpdateStokRequest{
-			ProdukID:   req.ReplacementProductID,
-			Perubahan:  -float64(totalReturnQty), // Negative = decrease
-			Jenis:      "exchange",
-			Keterangan: fmt.Sprintf("Tukar barang untuk return %s", req.NoTransaksi),
-		}
-		if err := s.produkService.UpdateStokIncrement(stockReq); err != nil {
-			return fmt.Errorf("failed to deduct replacement product stock: %w", err)
-		}
+	// Customer points and spending are reduced in proportion to the refund
+	if transaksi.Transaksi.PelangganID > 0 && transaksi.Transaksi.Total > 0 {
+		baru.PelangganID = transaksi.Transaksi.PelangganID
+		baru.PoinDikurangi = poinRetur(refundAmount)
 	}
 
-	// Update transaction status
-	newStatus := s.calculateTransactionStatus(transaksi, req.TransaksiID)
-	if err := s.returnRepo.UpdateTransactionStatus(req.TransaksiID, newStatus); err != nil {
-		return fmt.Errorf("failed to update transaction status: %w", err)
+	// Refund as store credit: a gift card for the paid part of the refund
+	if dibayar := refundAmount - potongPiutang; req.RefundMethod == models.MetodeKartuHadiah && dibayar > 0 {
+		kartu, err := s.kartuHadiah.SiapkanDariRetur(dibayar, transaksi.Transaksi.PelangganID)
+		if err != nil {
+			return fmt.Errorf("failed to issue store credit: %w", err)
+		}
+		baru.KartuHadiah = kartu
 	}
 
-	// Adjust customer points if customer exists
-	if transaksi.Transaksi.PelangganID > 0 {
-		if err := s.adjustCustomerPoints(transaksi, refundAmount); err != nil {
-			// Log error but don't fail the return
-			fmt.Printf("Warning: failed to adjust customer points: %v\n", err)
-		}
+	if err := s.returnRepo.Create(baru); err != nil {
+		return fmt.Errorf("failed to create return: %w", err)
 	}
 
-	// Mark refund as completed if method is provided
-	if req.RefundMethod != "" {
-		if err := s.returnRepo.UpdateRefundStatus(returnData.ID, "completed"); err != nil {
-			fmt.Printf("Warning: failed to update refund status: %v\n", err)
-		}
+	if baru.KartuHadiah != nil {
+		fmt.Printf("[RETURN SERVICE] Store credit %s senilai Rp %d diterbitkan untuk retur %s\n",
+			baru.KartuHadiah.Kode, baru.KartuHadiah.SaldoAwal, req.NoTransaksi)
+		req.KartuHadiah = baru.KartuHadiah
 	}
 
 	return nil
 }
 
 // CalculateRefundAmount calculates the refund amount for returned products
-// UPDATED: Menggunakan harga_beli (modal/HPP) bukan harga_jual
+// UPDATED: Menggunakan HPP saat barang dijual (modal) bukan harga_jual
 func (s *ReturnService) CalculateRefundAmount(transaksi *models.TransaksiDetail, returnProducts []models.ReturnProductRequest) (int, error) {
 	if transaksi == nil || len(returnProducts) == 0 {
 		return 0, fmt.Errorf("invalid transaction or products")
@@ -261,31 +317,23 @@ func (s *ReturnService) CalculateRefundAmount(transaksi *models.TransaksiDetail,
 
 	refundAmount := 0
 
-	// Calculate refund based on cost price (harga_beli) instead of selling price
+	// Calculate refund based on the cost frozen on the sold line instead of selling price
 	for _, returnProduct := range returnProducts {
-		for _, item := range transaksi.Items {
-			if item.ProdukID != nil && *item.ProdukID == returnProduct.ProductID {
-				// Get product to retrieve harga_beli (cost price)
-				produk, err := s.produkService.produkRepo.GetByID(*item.ProdukID)
-				if err != nil || produk == nil {
-					// Fallback to selling price if product not found
-					itemRefund := item.HargaSatuan * returnProduct.Quantity
-					refundAmount += itemRefund
-				} else {
-					// Use harga_beli (cost price) for refund calculation
-					// This ensures refund is based on modal, not profit margin
-					if item.BeratGram > 0 {
-						// Barang curah: refund = (berat_gram / 1000) * harga_beli_per_kg
-						itemRefund := int((item.BeratGram / 1000.0) * float64(produk.HargaBeli))
-						refundAmount += itemRefund
-					} else {
-						// Barang satuan: refund = jumlah * harga_beli
-						itemRefund := returnProduct.Quantity * produk.HargaBeli
-						refundAmount += itemRefund
-					}
-				}
-				break
-			}
+		item, err := itemReturn(transaksi, returnProduct)
+		if err != nil {
+			return 0, err
+		}
+
+		// Use HPP (cost price) for refund calculation
+		// This ensures refund is based on modal, not profit margin
+		if item.BeratGram > 0 {
+			// Barang curah: refund = (berat_gram / 1000) * hpp_per_kg
+			itemRefund := int((item.BeratGram / 1000.0) * float64(item.HargaPokok))
+			refundAmount += itemRefund
+		} else {
+			// Barang satuan: refund = jumlah * konversi satuan * hpp
+			itemRefund := int(float64(returnProduct.Quantity) * konversiItem(item) * float64(item.HargaPokok))
+			refundAmount += itemRefund
 		}
 	}
 
@@ -300,13 +348,67 @@ func (s *ReturnService) CalculateRefundAmount(transaksi *models.TransaksiDetail,
 	return refundAmount, nil
 }
 
-// calculateTransactionStatus determines the new status of the transaction after return
-func (s *ReturnService) calculateTransactionStatus(transaksi *models.TransaksiDetail, transaksiID int) string {
+// bagiRefundKasbon splits a refund on a sale with outstanding kasbon into the part
+// that cancels the receivable and the part that is paid back to the customer
+func bagiRefundKasbon(refund, sisaPiutang int) (potongPiutang, dibayar int) {
+	potongPiutang = refund
+	if potongPiutang > sisaPiutang {
+		potongPiutang = sisaPiutang
+	}
+	if potongPiutang < 0 {
+		potongPiutang = 0
+	}
+	return potongPiutang, refund - potongPiutang
+}
+
+// itemReturn finds the sale line a product is returned from. The line is chosen by
+// TransaksiItemID; without it the product must be on a single line, since the same
+// product sold in two units has a different conversion and price on each line.
+func itemReturn(transaksi *models.TransaksiDetail, p models.ReturnProductRequest) (*models.TransaksiItem, error) {
+	var found *models.TransaksiItem
+	for _, item := range transaksi.Items {
+		if item.ProdukID == nil || *item.ProdukID != p.ProductID {
+			continue
+		}
+		if p.TransaksiItemID > 0 {
+			if item.ID == p.TransaksiItemID {
+				return item, nil
+			}
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("product ID %d is on more than one line of the transaction, transaksi_item_id is required", p.ProductID)
+		}
+		found = item
+	}
+
+	if found == nil {
+		if p.TransaksiItemID > 0 {
+			return nil, fmt.Errorf("transaction line %d with product ID %d was not in the original transaction", p.TransaksiItemID, p.ProductID)
+		}
+		return nil, fmt.Errorf("product ID %d was not in the original transaction", p.ProductID)
+	}
+	return found, nil
+}
+
+// konversiItem returns the base units per sold unit of a sale line; returned
+// quantities are counted in the unit printed on the receipt
+func konversiItem(item *models.TransaksiItem) float64 {
+	if item.Konversi > 0 {
+		return item.Konversi
+	}
+	return 1
+}
+
+// calculateTransactionStatus determines the new status of the transaction after a
+// return of the baru items
+func (s *ReturnService) calculateTransactionStatus(transaksi *models.TransaksiDetail, transaksiID int, baru []*models.ReturnItem) string {
 	// Get all returned items for this transaction
 	returnedItems, err := s.returnRepo.GetAllReturnedItemsByTransaksi(transaksiID)
 	if err != nil {
 		return transaksi.Transaksi.Status // Keep current status on error
 	}
+	returnedItems = append(returnedItems, baru...)
 
 	// Count total purchased vs returned quantities
 	allReturned := true
@@ -315,7 +417,9 @@ func (s *ReturnService) calculateTransactionStatus(transaksi *models.TransaksiDe
 	for _, item := range transaksi.Items {
 		returnedQty := 0
 		for _, returnedItem := range returnedItems {
-			if item.ProdukID != nil && returnedItem.ProductID == *item.ProdukID {
+			// Returns recorded before lines were tracked count against every line of their product
+			if returnedItem.TransaksiItemID == item.ID ||
+				(returnedItem.TransaksiItemID == 0 && item.ProdukID != nil && returnedItem.ProductID == *item.ProdukID) {
 				returnedQty += returnedItem.Quantity
 				hasReturns = true
 			}
@@ -336,45 +440,10 @@ func (s *ReturnService) calculateTransactionStatus(transaksi *models.TransaksiDe
 	}
 }
 
-// adjustCustomerPoints adjusts customer points based on return amount
-func (s *ReturnService) adjustCustomerPoints(transaksi *models.TransaksiDetail, refundAmount int) error {
-	if transaksi.Transaksi.PelangganID == 0 {
-		return nil // No customer to adjust
-	}
-
-	pelanggan, err := s.pelangganRepo.GetByID(transaksi.Transaksi.PelangganID)
-	if err != nil {
-		return fmt.Errorf("failed to get customer: %w", err)
-	}
-	if pelanggan == nil {
-		return fmt.Errorf("customer not found")
-	}
-
-	// Calculate points to deduct (proportional to refund amount)
-	// Assuming original transaction earned points based on total spent
-	if transaksi.Transaksi.Total > 0 {
-		// Get point earning rate (example: 1 point per 25000 spent)
-		// This should match your point earning logic
-		pointsToDeduct := int(float64(refundAmount) / 25000.0) // Adjust based on your point system
-
-		if pointsToDeduct > pelanggan.Poin {
-			pointsToDeduct = pelanggan.Poin // Don't deduct more than available
-		}
-
-		// Update customer points and total spending
-		newPoints := pelanggan.Poin - pointsToDeduct
-		newTotalSpending := pelanggan.TotalBelanja - refundAmount
-		if newTotalSpending < 0 {
-			newTotalSpending = 0
-		}
-
-		// Update customer record
-		if err := s.pelangganRepo.UpdatePointsAndSpending(pelanggan.ID, newPoints, newTotalSpending); err != nil {
-			return fmt.Errorf("failed to update customer points: %w", err)
-		}
-	}
-
-	return nil
+// poinRetur returns the customer points taken back for a refund
+// (1 point per 25000 refunded, matching the point earning rate)
+func poinRetur(refundAmount int) int {
+	return int(float64(refundAmount) / 25000.0)
 }
 
 // GetAllReturn retrieves all returns with their products
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"testing"
+
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBagiRefundKasbon(t *testing.T) {
+	tests := []struct {
+		name        string
+		refund      int
+		sisaPiutang int
+		wantPotong  int
+		wantDibayar int
+	}{
+		{"transaksi tanpa kasbon", 50000, 0, 0, 50000},
+		{"kasbon belum dibayar menutup seluruh refund", 50000, 100000, 50000, 0},
+		{"refund sama dengan sisa kasbon", 100000, 100000, 100000, 0},
+		{"kasbon sebagian dibayar, sisanya dikembalikan", 80000, 30000, 30000, 50000},
+		{"refund nol", 0, 30000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			potong, dibayar := bagiRefundKasbon(tt.refund, tt.sisaPiutang)
+			assert.Equal(t, tt.wantPotong, potong)
+			assert.Equal(t, tt.wantDibayar, dibayar)
+			assert.Equal(t, tt.refund, potong+dibayar, "refund terbagi habis")
+		})
+	}
+}
+
+// transaksiDuaSatuan is a sale of one product in pcs and in boxes of 12
+func transaksiDuaSatuan() *models.TransaksiDetail {
+	produkID := 5
+	return &models.TransaksiDetail{
+		Transaksi: &models.Transaksi{ID: 1},
+		Items: []*models.TransaksiItem{
+			{ID: 11, ProdukID: &produkID, Satuan: "pcs", Konversi: 1, Jumlah: 10, HargaPokok: 1000},
+			{ID: 12, ProdukID: &produkID, Satuan: "dus", Konversi: 12, Jumlah: 2, HargaPokok: 1000},
+		},
+	}
+}
+
+func TestItemReturn(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          models.ReturnProductRequest
+		wantItemID   int
+		wantKonversi float64
+		wantErr      bool
+	}{
+		{"baris pcs", models.ReturnProductRequest{ProductID: 5, TransaksiItemID: 11, Quantity: 1}, 11, 1, false},
+		{"baris dus", models.ReturnProductRequest{ProductID: 5, TransaksiItemID: 12, Quantity: 1}, 12, 12, false},
+		{"produk di dua baris tanpa baris", models.ReturnProductRequest{ProductID: 5, Quantity: 1}, 0, 0, true},
+		{"baris bukan milik produk", models.ReturnProductRequest{ProductID: 6, TransaksiItemID: 12, Quantity: 1}, 0, 0, true},
+		{"baris tidak ada", models.ReturnProductRequest{ProductID: 5, TransaksiItemID: 99, Quantity: 1}, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := itemReturn(transaksiDuaSatuan(), tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantItemID, item.ID)
+			assert.Equal(t, tt.wantKonversi, konversiItem(item))
+		})
+	}
+}
+
+func TestCalculateRefundAmountDuaSatuan(t *testing.T) {
+	tests := []struct {
+		name     string
+		products []models.ReturnProductRequest
+		want     int
+	}{
+		{"tiga pcs", []models.ReturnProductRequest{{ProductID: 5, TransaksiItemID: 11, Quantity: 3}}, 3000},
+		{"satu dus", []models.ReturnProductRequest{{ProductID: 5, TransaksiItemID: 12, Quantity: 1}}, 12000},
+		{"pcs dan dus", []models.ReturnProductRequest{
+			{ProductID: 5, TransaksiItemID: 11, Quantity: 2},
+			{ProductID: 5, TransaksiItemID: 12, Quantity: 2},
+		}, 26000},
+	}
+
+	s := &ReturnService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.CalculateRefundAmount(transaksiDuaSatuan(), tt.products)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := s.CalculateRefundAmount(transaksiDuaSatuan(), []models.ReturnProductRequest{{ProductID: 5, Quantity: 1}})
+	assert.Error(t, err, "baris wajib dipilih untuk produk di dua baris")
+}
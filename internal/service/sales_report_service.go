@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"ritel-app/internal/database"
@@ -28,7 +29,7 @@ func NewSalesReportService() *SalesReportService {
 // GetComprehensiveSalesReport generates a complete sales report for a date range
 func (s *SalesReportService) GetComprehensiveSalesReport(startDate, endDate time.Time) (*models.ComprehensiveSalesReport, error) {
 	// Get all transactions in the date range
-	transaksiList, err := s.transaksiRepo.GetByDateRange(startDate, endDate)
+	transaksiList, err := s.transaksiRepo.GetSalesByDateRange(startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -48,7 +49,7 @@ func (s *SalesReportService) GetComprehensiveSalesReport(startDate, endDate time
 	prevStartDate := startDate.Add(-duration)
 	prevEndDate := startDate.AddDate(0, 0, -1)
 
-	prevTransaksiList, err := s.transaksiRepo.GetByDateRange(prevStartDate, prevEndDate)
+	prevTransaksiList, err := s.transaksiRepo.GetSalesByDateRange(prevStartDate, prevEndDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get previous transactions: %w", err)
 	}
@@ -125,12 +126,34 @@ func (s *SalesReportService) GetComprehensiveSalesReport(startDate, endDate time
 	// Generate discount type breakdown
 	discountTypeBreakdown := s.calculateDiscountTypeBreakdown(transaksiList)
 
+	// Generate discount breakdown per applied promo
+	promoBreakdown, err := s.transaksiRepo.GetDiscountByPromoByDateRange(startDate, endDate)
+	if err != nil {
+		promoBreakdown = []*models.PromoDiscountBreakdown{}
+	}
+
+	// Generate near-expiry markdown analysis, reported apart from promo discounts
+	markdownAnalysis, err := s.transaksiRepo.GetMarkdownByDateRange(startDate, endDate)
+	if err != nil {
+		markdownAnalysis = &models.MarkdownAnalysis{Produk: []*models.MarkdownBreakdown{}}
+	}
+
 	// Generate payment method breakdown
 	paymentMethodBreakdown := s.calculatePaymentMethodBreakdown(startDate, endDate)
 
 	// Generate loss analysis
 	lossAnalysis := s.calculateLossAnalysis(startDate, endDate)
 
+	// Generate tax (PPN) summary, net of the tax given back through refunds
+	pajakRetur, err := s.returnRepo.GetPajakReturByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get returned tax: %w", err)
+	}
+	taxSummary := s.calculateTaxSummary(currentDetailedTransactions, pajakRetur)
+
+	// Generate cumulative cash rounding
+	pembulatanSummary := s.calculatePembulatanSummary(transaksiList)
+
 	return &models.ComprehensiveSalesReport{
 		Summary:                summary,
 		SalesTrendData:         salesTrendData,
@@ -141,8 +164,12 @@ func (s *SalesReportService) GetComprehensiveSalesReport(startDate, endDate time
 		TopProducts:            topProducts,
 		DiscountAnalysis:       discountAnalysis,
 		DiscountTypeBreakdown:  discountTypeBreakdown,
+		PromoBreakdown:         promoBreakdown,
+		MarkdownAnalysis:       markdownAnalysis,
 		PaymentMethodBreakdown: paymentMethodBreakdown,
 		LossAnalysis:           lossAnalysis,
+		TaxSummary:             taxSummary,
+		PembulatanSummary:      pembulatanSummary,
 		StartDate:              startDate,
 		EndDate:                endDate,
 		GeneratedAt:            time.Now(),
@@ -157,35 +184,22 @@ func (s *SalesReportService) calculateSummary(currentDetailed, previousDetailed
 	totalTransaksi := len(currentDetailed)
 	totalProdukTerjual := currentProductsSold
 
-	productHPPCache := make(map[int]int)
-
 	for _, tDetail := range currentDetailed {
 		totalOmset += tDetail.Transaksi.Total
 
 		transactionHPP := 0
 		for _, item := range tDetail.Items {
 			if item.ProdukID != nil {
-				// Check cache first
-				hpp, ok := productHPPCache[*item.ProdukID]
-				if !ok {
-					// Fetch from repo if not in cache
-					produk, err := s.produkRepo.GetByID(*item.ProdukID)
-					if err == nil && produk != nil {
-						hpp = produk.HargaBeli
-						productHPPCache[*item.ProdukID] = hpp
-					} else {
-						// Log error or handle missing product HPP
-						hpp = 0 // Assume 0 HPP if not found
-					}
-				}
+				// HPP frozen when the item was sold
+				hpp := item.HargaPokok
 
 				// Calculate HPP based on product type (curah vs satuan)
 				if item.BeratGram > 0 {
 					// Barang curah: HPP = (berat_gram / 1000) * harga_beli_per_kg
 					transactionHPP += int((item.BeratGram / 1000.0) * float64(hpp))
 				} else {
-					// Barang satuan tetap: HPP = jumlah * harga_beli
-					transactionHPP += item.Jumlah * hpp
+					// Barang satuan tetap: HPP = jumlah * konversi satuan * harga_beli
+					transactionHPP += int(float64(item.Jumlah) * item.Konversi * float64(hpp))
 				}
 			}
 		}
@@ -214,24 +228,15 @@ func (s *SalesReportService) calculateSummary(currentDetailed, previousDetailed
 		prevTransactionHPP := 0
 		for _, item := range tDetail.Items {
 			if item.ProdukID != nil {
-				hpp, ok := productHPPCache[*item.ProdukID]
-				if !ok {
-					produk, err := s.produkRepo.GetByID(*item.ProdukID)
-					if err == nil && produk != nil {
-						hpp = produk.HargaBeli
-						productHPPCache[*item.ProdukID] = hpp
-					} else {
-						hpp = 0
-					}
-				}
+				hpp := item.HargaPokok
 
 				// Calculate HPP based on product type (curah vs satuan)
 				if item.BeratGram > 0 {
 					// Barang curah: HPP = (berat_gram / 1000) * harga_beli_per_kg
 					prevTransactionHPP += int((item.BeratGram / 1000.0) * float64(hpp))
 				} else {
-					// Barang satuan tetap: HPP = jumlah * harga_beli
-					prevTransactionHPP += item.Jumlah * hpp
+					// Barang satuan tetap: HPP = jumlah * konversi satuan * harga_beli
+					prevTransactionHPP += int(float64(item.Jumlah) * item.Konversi * float64(hpp))
 				}
 			}
 		}
@@ -275,8 +280,6 @@ func (s *SalesReportService) calculateSummary(currentDetailed, previousDetailed
 func (s *SalesReportService) calculateMonthlySales(detailedTransactions []*models.TransaksiDetail) []*models.MonthlySalesData {
 	monthlyMap := make(map[string]*models.MonthlySalesData)
 
-	productHPPCache := make(map[int]int) // Cache for product HargaBeli
-
 	for _, tDetail := range detailedTransactions {
 		monthKey := fmt.Sprintf("%d-%02d", tDetail.Transaksi.Tanggal.Year(), tDetail.Transaksi.Tanggal.Month())
 
@@ -303,31 +306,16 @@ func (s *SalesReportService) calculateMonthlySales(detailedTransactions []*model
 		transactionHPP := 0
 		for _, item := range tDetail.Items {
 			if item.ProdukID != nil {
-				hpp, ok := productHPPCache[*item.ProdukID]
-				if !ok {
-					produk, err := s.produkRepo.GetByID(*item.ProdukID)
-					if err != nil {
-
-						hpp = 0 // Assume 0 HPP if not found
-					} else if produk != nil {
-						hpp = produk.HargaBeli
-						productHPPCache[*item.ProdukID] = hpp
-
-					} else {
-						hpp = 0 // Assume 0 HPP if not found
-
-					}
-				} else {
-					fmt.Printf("[DEBUG] Using cached HPP for ProdukID %d: %d\n", *item.ProdukID, hpp)
-				}
+				// HPP frozen when the item was sold
+				hpp := item.HargaPokok
 
 				// Calculate HPP based on product type (curah vs satuan)
 				if item.BeratGram > 0 {
 					// Barang curah: HPP = (berat_gram / 1000) * harga_beli_per_kg
 					transactionHPP += int((item.BeratGram / 1000.0) * float64(hpp))
 				} else {
-					// Barang satuan tetap: HPP = jumlah * harga_beli
-					transactionHPP += item.Jumlah * hpp
+					// Barang satuan tetap: HPP = jumlah * konversi satuan * harga_beli
+					transactionHPP += int(float64(item.Jumlah) * item.Konversi * float64(hpp))
 				}
 			}
 		}
@@ -444,7 +432,7 @@ func (s *SalesReportService) calculateTopProducts(transaksiList []*models.Transa
 			SUM(ti.subtotal) as total_omset
 		FROM transaksi_item ti
 		INNER JOIN transaksi t ON ti.transaksi_id = t.id
-		WHERE t.status = 'selesai'
+		WHERE t.status = 'selesai' AND t.jenis = 'penjualan'
 		GROUP BY ti.produk_nama, ti.produk_kategori
 		ORDER BY total_omset DESC
 		LIMIT 10
@@ -586,6 +574,98 @@ func (s *SalesReportService) calculateDiscountTypeBreakdown(transaksiList []*mod
 	return result
 }
 
+// calculateTaxSummary calculates DPP and PPN per tax code from the stored transaction items,
+// less the DPP and PPN of refunded items in the period
+func (s *SalesReportService) calculateTaxSummary(detailedTransactions []*models.TransaksiDetail, pajakRetur []*models.PajakRetur) *models.TaxSummary {
+	summary := &models.TaxSummary{
+		Breakdown: make([]*models.TaxBreakdown, 0),
+	}
+	breakdownMap := make(map[string]*models.TaxBreakdown)
+
+	for _, tDetail := range detailedTransactions {
+		switch tDetail.Transaksi.ModePajak {
+		case models.ModeHargaInklusif:
+			summary.TransaksiInklusif++
+		case models.ModeHargaEksklusif:
+			summary.TransaksiEksklusif++
+		default:
+			summary.TransaksiTanpaPajak++
+			continue
+		}
+
+		summary.TotalDPP += tDetail.Transaksi.TotalDPP
+		summary.TotalPajak += tDetail.Transaksi.TotalPajak
+
+		for _, item := range tDetail.Items {
+			if item.KodePajak == "" {
+				continue
+			}
+			key := fmt.Sprintf("%s|%g", item.KodePajak, item.TarifPajak)
+			breakdown, ok := breakdownMap[key]
+			if !ok {
+				breakdown = &models.TaxBreakdown{
+					KodePajak:  item.KodePajak,
+					TarifPajak: item.TarifPajak,
+				}
+				breakdownMap[key] = breakdown
+				summary.Breakdown = append(summary.Breakdown, breakdown)
+			}
+			breakdown.TotalDPP += item.DPP
+			breakdown.TotalPajak += item.Pajak
+			breakdown.Jumlah++
+		}
+	}
+
+	// Refund mengembalikan PPN; penjualan asalnya bisa berada di periode sebelumnya
+	for _, retur := range pajakRetur {
+		key := fmt.Sprintf("%s|%g", retur.KodePajak, retur.TarifPajak)
+		breakdown, ok := breakdownMap[key]
+		if !ok {
+			breakdown = &models.TaxBreakdown{
+				KodePajak:  retur.KodePajak,
+				TarifPajak: retur.TarifPajak,
+			}
+			breakdownMap[key] = breakdown
+			summary.Breakdown = append(summary.Breakdown, breakdown)
+		}
+		breakdown.TotalDPP -= retur.DPP
+		breakdown.TotalPajak -= retur.Pajak
+		breakdown.ReturDPP += retur.DPP
+		breakdown.ReturPajak += retur.Pajak
+
+		summary.TotalDPP -= retur.DPP
+		summary.TotalPajak -= retur.Pajak
+		summary.ReturDPP += retur.DPP
+		summary.ReturPajak += retur.Pajak
+	}
+
+	sort.Slice(summary.Breakdown, func(i, j int) bool {
+		return summary.Breakdown[i].TarifPajak > summary.Breakdown[j].TarifPajak
+	})
+
+	return summary
+}
+
+// calculatePembulatanSummary sums the rounding adjustments stored on each transaction
+func (s *SalesReportService) calculatePembulatanSummary(transaksiList []*models.Transaksi) *models.PembulatanSummary {
+	summary := &models.PembulatanSummary{}
+
+	for _, t := range transaksiList {
+		if t.Pembulatan == 0 {
+			continue
+		}
+		summary.TransaksiDibulatkan++
+		summary.TotalPembulatan += t.Pembulatan
+		if t.Pembulatan > 0 {
+			summary.TotalPembulatanAtas += t.Pembulatan
+		} else {
+			summary.TotalPembulatanBawah += t.Pembulatan
+		}
+	}
+
+	return summary
+}
+
 // calculatePaymentMethodBreakdown calculates breakdown by payment method
 func (s *SalesReportService) calculatePaymentMethodBreakdown(startDate, endDate time.Time) []*models.PaymentMethodBreakdown {
 	// Get payment method breakdown from repository
@@ -664,6 +744,7 @@ func (s *SalesReportService) calculateLossAnalysis(startDate, endDate time.Time)
 		"kadaluarsa": "Barang Kadaluarsa",
 		"rusak":      "Barang Rusak",
 		"hilang":     "Kehilangan",
+		"ditarik":    "Penarikan Produk",
 		"other":      "Lainnya",
 	}
 
@@ -671,6 +752,7 @@ func (s *SalesReportService) calculateLossAnalysis(startDate, endDate time.Time)
 		"kadaluarsa": "#ef4444", // red
 		"rusak":      "#f97316", // orange
 		"hilang":     "#eab308", // yellow
+		"ditarik":    "#8b5cf6", // purple
 		"other":      "#6b7280", // gray
 	}
 
@@ -736,8 +818,8 @@ func (s *SalesReportService) calculateLossAnalysis(startDate, endDate time.Time)
 	colors := []string{}
 	breakdown := []*models.LossBreakdownItem{}
 
-	// Sort order: kadaluarsa, rusak, hilang, other
-	sortOrder := []string{"kadaluarsa", "rusak", "hilang", "other"}
+	// Sort order: kadaluarsa, rusak, hilang, ditarik, other
+	sortOrder := []string{"kadaluarsa", "rusak", "hilang", "ditarik", "other"}
 
 	for _, tipe := range sortOrder {
 		if item, exists := lossMap[tipe]; exists {
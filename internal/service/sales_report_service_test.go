@@ -0,0 +1,75 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateTaxSummaryDikurangiRefund(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	lama := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = lama
+		db.Close()
+	})
+
+	for _, q := range []string{
+		`CREATE TABLE transaksi_item (
+			id INTEGER PRIMARY KEY, transaksi_id INTEGER, produk_id INTEGER, jumlah INTEGER,
+			kode_pajak TEXT DEFAULT '', tarif_pajak REAL DEFAULT 0, dpp INTEGER DEFAULT 0, pajak INTEGER DEFAULT 0
+		)`,
+		`CREATE TABLE returns (
+			id INTEGER PRIMARY KEY, transaksi_id INTEGER, return_date DATETIME, type TEXT, refund_status TEXT
+		)`,
+		`CREATE TABLE return_items (
+			id INTEGER PRIMARY KEY, return_id INTEGER, product_id INTEGER, transaksi_item_id INTEGER, quantity INTEGER
+		)`,
+		// Baris 1: 4 x PPN11, baris 2: bebas pajak
+		`INSERT INTO transaksi_item (id, transaksi_id, produk_id, jumlah, kode_pajak, tarif_pajak, dpp, pajak)
+			VALUES (1, 1, 1, 4, 'PPN11', 11, 40000, 4400), (2, 1, 2, 1, '', 0, 0, 0)`,
+		// Refund 1 dari 4 (ditautkan ke baris), refund lama tanpa baris, tukar barang dan refund batal
+		`INSERT INTO returns (id, transaksi_id, return_date, type, refund_status) VALUES
+			(1, 1, '2026-10-10 10:00:00', 'refund', 'completed'),
+			(2, 1, '2026-10-11 10:00:00', 'refund', 'completed'),
+			(3, 1, '2026-10-12 10:00:00', 'exchange', 'completed'),
+			(4, 1, '2026-10-13 10:00:00', 'refund', 'cancelled')`,
+		`INSERT INTO return_items (return_id, product_id, transaksi_item_id, quantity) VALUES
+			(1, 1, 1, 1), (1, 2, 2, 1), (2, 1, NULL, 1), (3, 1, 1, 1), (4, 1, 1, 1)`,
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+
+	startDate := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2026, 10, 31, 23, 59, 59, 0, time.UTC)
+	pajakRetur, err := repository.NewReturnRepository().GetPajakReturByDateRange(startDate, endDate)
+	require.NoError(t, err)
+
+	s := &SalesReportService{}
+	summary := s.calculateTaxSummary([]*models.TransaksiDetail{{
+		Transaksi: &models.Transaksi{ModePajak: models.ModeHargaInklusif, TotalDPP: 40000, TotalPajak: 4400},
+		Items: []*models.TransaksiItem{
+			{KodePajak: "PPN11", TarifPajak: 11, DPP: 40000, Pajak: 4400},
+		},
+	}}, pajakRetur)
+
+	assert.Equal(t, 20000, summary.ReturDPP)
+	assert.Equal(t, 2200, summary.ReturPajak)
+	assert.Equal(t, 20000, summary.TotalDPP)
+	assert.Equal(t, 2200, summary.TotalPajak)
+	require.Len(t, summary.Breakdown, 1)
+	assert.Equal(t, 2200, summary.Breakdown[0].TotalPajak)
+	assert.Equal(t, 2200, summary.Breakdown[0].ReturPajak)
+}
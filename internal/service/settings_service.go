@@ -2,6 +2,8 @@ package service
 
 import (
 	"fmt"
+	"strings"
+
 	"ritel-app/internal/models"
 	"ritel-app/internal/repository"
 )
@@ -64,3 +66,258 @@ func (s *SettingsService) UpdatePoinSettings(req *models.UpdatePoinSettingsReque
 
 	return settings, nil
 }
+
+// GetNomorTransaksiSettings retrieves the transaction numbering scheme
+func (s *SettingsService) GetNomorTransaksiSettings() (*models.NomorTransaksiSettings, error) {
+	settings, err := s.settingsRepo.GetNomorTransaksiSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nomor transaksi settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateNomorTransaksiSettings updates the transaction numbering scheme
+func (s *SettingsService) UpdateNomorTransaksiSettings(req *models.UpdateNomorTransaksiSettingsRequest) (*models.NomorTransaksiSettings, error) {
+	prefix := strings.ToUpper(strings.TrimSpace(req.Prefix))
+	kodeTerminal := strings.ToUpper(strings.TrimSpace(req.KodeTerminal))
+
+	// VALIDASI
+	if !isValidKodeNomor(prefix) {
+		return nil, fmt.Errorf("prefix harus 1-%d karakter huruf atau angka", maxPanjangKodeNomor)
+	}
+	if !isValidKodeNomor(kodeTerminal) {
+		return nil, fmt.Errorf("kode terminal harus 1-%d karakter huruf atau angka", maxPanjangKodeNomor)
+	}
+	if req.PanjangCounter < 3 || req.PanjangCounter > 8 {
+		return nil, fmt.Errorf("panjang counter harus antara 3 dan 8 digit")
+	}
+
+	switch req.ResetCounter {
+	case models.ResetCounterHarian, models.ResetCounterBulanan:
+	default:
+		return nil, fmt.Errorf("reset counter harus %q atau %q", models.ResetCounterHarian, models.ResetCounterBulanan)
+	}
+
+	switch req.FormatTanggal {
+	case models.FormatTanggalYYYYMMDD, models.FormatTanggalYYMMDD:
+	case models.FormatTanggalYYYYMM, models.FormatTanggalYYMM:
+		// Tanpa tanggal hari, counter harian akan menghasilkan nomor ganda
+		if req.ResetCounter == models.ResetCounterHarian {
+			return nil, fmt.Errorf("format tanggal %s hanya dapat digunakan dengan reset counter bulanan", req.FormatTanggal)
+		}
+	default:
+		return nil, fmt.Errorf("format tanggal tidak valid: %s", req.FormatTanggal)
+	}
+
+	settings := &models.NomorTransaksiSettings{
+		ID:             1,
+		Prefix:         prefix,
+		KodeTerminal:   kodeTerminal,
+		FormatTanggal:  req.FormatTanggal,
+		PanjangCounter: req.PanjangCounter,
+		ResetCounter:   req.ResetCounter,
+	}
+
+	if err := s.settingsRepo.UpdateNomorTransaksiSettings(settings); err != nil {
+		return nil, fmt.Errorf("gagal update pengaturan nomor transaksi: %w", err)
+	}
+
+	return settings, nil
+}
+
+// GetPajakSettings retrieves the PPN configuration
+func (s *SettingsService) GetPajakSettings() (*models.PajakSettings, error) {
+	settings, err := s.settingsRepo.GetPajakSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pajak settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdatePajakSettings updates the PPN configuration
+func (s *SettingsService) UpdatePajakSettings(req *models.UpdatePajakSettingsRequest) (*models.PajakSettings, error) {
+	kodePajakDefault := strings.ToUpper(strings.TrimSpace(req.KodePajakDefault))
+	npwp := strings.TrimSpace(req.NPWP)
+
+	// VALIDASI
+	switch req.ModeHarga {
+	case models.ModeHargaInklusif, models.ModeHargaEksklusif:
+	default:
+		return nil, fmt.Errorf("mode harga harus %q atau %q", models.ModeHargaInklusif, models.ModeHargaEksklusif)
+	}
+	if kodePajakDefault == "" {
+		return nil, fmt.Errorf("kode pajak default harus diisi")
+	}
+	if err := validateKodePajak(kodePajakDefault); err != nil {
+		return nil, err
+	}
+	if req.Aktif && npwp == "" {
+		return nil, fmt.Errorf("NPWP harus diisi jika PPN diaktifkan")
+	}
+
+	settings := &models.PajakSettings{
+		ID:               1,
+		Aktif:            req.Aktif,
+		ModeHarga:        req.ModeHarga,
+		KodePajakDefault: kodePajakDefault,
+		NPWP:             npwp,
+	}
+
+	if err := s.settingsRepo.UpdatePajakSettings(settings); err != nil {
+		return nil, fmt.Errorf("gagal update pengaturan pajak: %w", err)
+	}
+
+	return settings, nil
+}
+
+// GetHargaSettings retrieves the limits for manual price overrides and line discounts
+func (s *SettingsService) GetHargaSettings() (*models.HargaSettings, error) {
+	settings, err := s.settingsRepo.GetHargaSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harga settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateHargaSettings updates the limits for manual price overrides and line discounts
+func (s *SettingsService) UpdateHargaSettings(req *models.UpdateHargaSettingsRequest) (*models.HargaSettings, error) {
+	// VALIDASI
+	if req.BatasOverridePersen < 0 || req.BatasOverridePersen > 100 {
+		return nil, fmt.Errorf("batas perubahan harga harus antara 0 dan 100 persen")
+	}
+
+	settings := &models.HargaSettings{
+		ID:                  1,
+		BatasOverridePersen: req.BatasOverridePersen,
+	}
+
+	if err := s.settingsRepo.UpdateHargaSettings(settings); err != nil {
+		return nil, fmt.Errorf("gagal update pengaturan harga: %w", err)
+	}
+
+	return settings, nil
+}
+
+// GetPembulatanSettings retrieves the cash rounding policy
+func (s *SettingsService) GetPembulatanSettings() (*models.PembulatanSettings, error) {
+	settings, err := s.settingsRepo.GetPembulatanSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pembulatan settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdatePembulatanSettings updates the cash rounding policy
+func (s *SettingsService) UpdatePembulatanSettings(req *models.UpdatePembulatanSettingsRequest) (*models.PembulatanSettings, error) {
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+
+	// VALIDASI
+	switch mode {
+	case models.ModePembulatanTerdekat, models.ModePembulatanBawah, models.ModePembulatanAtas:
+	default:
+		return nil, fmt.Errorf("mode pembulatan harus %q, %q atau %q",
+			models.ModePembulatanTerdekat, models.ModePembulatanBawah, models.ModePembulatanAtas)
+	}
+	if req.Kelipatan < 1 || req.Kelipatan > 1000 {
+		return nil, fmt.Errorf("kelipatan pembulatan harus antara 1 dan 1000")
+	}
+
+	settings := &models.PembulatanSettings{
+		ID:         1,
+		Aktif:      req.Aktif,
+		Mode:       mode,
+		Kelipatan:  req.Kelipatan,
+		HanyaTunai: req.HanyaTunai,
+	}
+
+	if err := s.settingsRepo.UpdatePembulatanSettings(settings); err != nil {
+		return nil, fmt.Errorf("gagal update pengaturan pembulatan: %w", err)
+	}
+
+	return settings, nil
+}
+
+// maxMasaBerlakuKartuHadiah is the longest validity (in days) that can be configured for gift cards
+const maxMasaBerlakuKartuHadiah = 3650
+
+// GetKartuHadiahSettings retrieves the validity rules of gift cards and store credit
+func (s *SettingsService) GetKartuHadiahSettings() (*models.KartuHadiahSettings, error) {
+	settings, err := s.settingsRepo.GetKartuHadiahSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kartu hadiah settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateKartuHadiahSettings updates the validity rules. Cards that were already
+// issued keep the expiry date they were issued with.
+func (s *SettingsService) UpdateKartuHadiahSettings(req *models.UpdateKartuHadiahSettingsRequest) (*models.KartuHadiahSettings, error) {
+	// VALIDASI
+	if req.MasaBerlakuHari < 0 || req.MasaBerlakuHari > maxMasaBerlakuKartuHadiah {
+		return nil, fmt.Errorf("masa berlaku kartu hadiah harus antara 0 dan %d hari", maxMasaBerlakuKartuHadiah)
+	}
+	if req.MasaBerlakuReturHari < 0 || req.MasaBerlakuReturHari > maxMasaBerlakuKartuHadiah {
+		return nil, fmt.Errorf("masa berlaku store credit harus antara 0 dan %d hari", maxMasaBerlakuKartuHadiah)
+	}
+
+	settings := &models.KartuHadiahSettings{
+		ID:                   1,
+		MasaBerlakuHari:      req.MasaBerlakuHari,
+		MasaBerlakuReturHari: req.MasaBerlakuReturHari,
+	}
+
+	if err := s.settingsRepo.UpdateKartuHadiahSettings(settings); err != nil {
+		return nil, fmt.Errorf("gagal update pengaturan kartu hadiah: %w", err)
+	}
+
+	return settings, nil
+}
+
+// maxIntervalBatchKadaluarsa is the longest interval (in hours) between runs of the expired batch job
+const maxIntervalBatchKadaluarsa = 168
+
+// GetBatchKadaluarsaSettings retrieves the schedule of the expired batch job
+func (s *SettingsService) GetBatchKadaluarsaSettings() (*models.BatchKadaluarsaSettings, error) {
+	settings, err := s.settingsRepo.GetBatchKadaluarsaSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch kadaluarsa settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateBatchKadaluarsaSettings updates the schedule of the expired batch job.
+// The new interval applies from the next run.
+func (s *SettingsService) UpdateBatchKadaluarsaSettings(req *models.UpdateBatchKadaluarsaSettingsRequest) (*models.BatchKadaluarsaSettings, error) {
+	// VALIDASI
+	if req.IntervalJam < 1 || req.IntervalJam > maxIntervalBatchKadaluarsa {
+		return nil, fmt.Errorf("interval pemeriksaan batch kadaluarsa harus antara 1 dan %d jam", maxIntervalBatchKadaluarsa)
+	}
+
+	settings := &models.BatchKadaluarsaSettings{
+		ID:            1,
+		HapusOtomatis: req.HapusOtomatis,
+		IntervalJam:   req.IntervalJam,
+	}
+
+	if err := s.settingsRepo.UpdateBatchKadaluarsaSettings(settings); err != nil {
+		return nil, fmt.Errorf("gagal update pengaturan batch kadaluarsa: %w", err)
+	}
+
+	return settings, nil
+}
+
+// maxPanjangKodeNomor is the maximum length of the prefix and terminal code parts
+const maxPanjangKodeNomor = 10
+
+// isValidKodeNomor reports whether a prefix or terminal code only contains letters and digits
+func isValidKodeNomor(kode string) bool {
+	if kode == "" || len(kode) > maxPanjangKodeNomor {
+		return false
+	}
+	for _, ch := range kode {
+		if !(ch >= 'A' && ch <= 'Z') && !(ch >= 'a' && ch <= 'z') && !(ch >= '0' && ch <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"strings"
+	"time"
+)
+
+// ShiftService handles cashier shift sessions: opening float, cash in/out,
+// closing cash count and the X/Z reports
+type ShiftService struct {
+	repo         *repository.ShiftRepository
+	settingsRepo *repository.SettingsRepository
+}
+
+// NewShiftService creates a new instance
+func NewShiftService() *ShiftService {
+	return &ShiftService{
+		repo:         repository.NewShiftRepository(),
+		settingsRepo: repository.NewSettingsRepository(),
+	}
+}
+
+// BukaShift opens a shift on a terminal with the opening cash in the drawer
+func (s *ShiftService) BukaShift(req *models.BukaShiftRequest) (*models.ShiftKasir, error) {
+	if req.KasAwal < 0 {
+		return nil, fmt.Errorf("kas awal tidak boleh negatif")
+	}
+
+	kodeTerminal, err := s.resolveKodeTerminal(req.KodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+
+	aktif, err := s.repo.GetActiveByTerminal(kodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+	if aktif != nil {
+		return nil, fmt.Errorf("shift di terminal %s sudah dibuka oleh %s sejak %s",
+			kodeTerminal, aktif.StaffNama, aktif.DibukaAt.Format("02/01/2006 15:04"))
+	}
+
+	shift := &models.ShiftKasir{
+		KodeTerminal: kodeTerminal,
+		StaffNama:    req.StaffNama,
+		KasAwal:      req.KasAwal,
+		Catatan:      strings.TrimSpace(req.Catatan),
+		DibukaAt:     time.Now(),
+	}
+	if req.StaffID > 0 {
+		staffID := req.StaffID
+		shift.StaffID = &staffID
+	}
+
+	if err := s.repo.Create(shift); err != nil {
+		return nil, fmt.Errorf("gagal membuka shift: %w", err)
+	}
+
+	log.Printf("[SHIFT] Shift %d dibuka di terminal %s oleh %s (kas awal: %d)", shift.ID, kodeTerminal, req.StaffNama, req.KasAwal)
+	return shift, nil
+}
+
+// GetShiftAktif retrieves the open shift of a terminal, or nil when no shift is open
+func (s *ShiftService) GetShiftAktif(kodeTerminal string) (*models.ShiftKasir, error) {
+	kode, err := s.resolveKodeTerminal(kodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetActiveByTerminal(kode)
+}
+
+// GetShiftByID retrieves a shift by ID
+func (s *ShiftService) GetShiftByID(id int) (*models.ShiftKasir, error) {
+	shift, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil {
+		return nil, fmt.Errorf("shift tidak ditemukan")
+	}
+	return shift, nil
+}
+
+// GetAllShift lists shifts, newest first, optionally filtered by terminal
+func (s *ShiftService) GetAllShift(kodeTerminal string, limit, offset int) ([]*models.ShiftKasir, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	return s.repo.GetAll(strings.ToUpper(strings.TrimSpace(kodeTerminal)), limit, offset)
+}
+
+// CatatMutasiKas records a cash drop, petty cash expense or cash top-up on the open shift
+func (s *ShiftService) CatatMutasiKas(req *models.MutasiKasRequest) (*models.ShiftKasMutasi, error) {
+	if req.Jenis != models.MutasiKasMasuk && req.Jenis != models.MutasiKasKeluar {
+		return nil, fmt.Errorf("jenis mutasi kas harus %q atau %q", models.MutasiKasMasuk, models.MutasiKasKeluar)
+	}
+	if req.Jumlah <= 0 {
+		return nil, fmt.Errorf("jumlah mutasi kas harus lebih besar dari 0")
+	}
+	keterangan := strings.TrimSpace(req.Keterangan)
+	if keterangan == "" {
+		return nil, fmt.Errorf("keterangan mutasi kas harus diisi")
+	}
+
+	shift, err := s.requireShiftAktif(req.KodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+
+	mutasi := &models.ShiftKasMutasi{
+		ShiftID:    shift.ID,
+		Jenis:      req.Jenis,
+		Jumlah:     req.Jumlah,
+		Keterangan: keterangan,
+		StaffNama:  req.StaffNama,
+		CreatedAt:  time.Now(),
+	}
+	if req.StaffID > 0 {
+		staffID := req.StaffID
+		mutasi.StaffID = &staffID
+	}
+
+	if err := s.repo.CreateMutasi(mutasi); err != nil {
+		return nil, fmt.Errorf("gagal mencatat mutasi kas: %w", err)
+	}
+
+	log.Printf("[SHIFT] Kas %s %d pada shift %d: %s", req.Jenis, req.Jumlah, shift.ID, keterangan)
+	return mutasi, nil
+}
+
+// GetLaporanShift returns the X report of an open shift or the Z report of a closed shift
+func (s *ShiftService) GetLaporanShift(shiftID int) (*models.LaporanShift, error) {
+	shift, err := s.GetShiftByID(shiftID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildLaporan(shift)
+}
+
+// TutupShift closes the open shift of a terminal with the counted cash and
+// returns its Z report, including the over/short variance. The expected cash is
+// computed when the shift is closed, so a sale completed while closing either
+// lands before the close and is counted, or is rejected for having no open shift.
+func (s *ShiftService) TutupShift(req *models.TutupShiftRequest) (*models.LaporanShift, error) {
+	if req.KasDihitung < 0 {
+		return nil, fmt.Errorf("kas dihitung tidak boleh negatif")
+	}
+
+	shift, err := s.requireShiftAktif(req.KodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	kasDihitung := req.KasDihitung
+	shift.KasDihitung = &kasDihitung
+	shift.DitutupAt = &now
+	shift.DitutupOlehNama = req.StaffNama
+	if req.StaffID > 0 {
+		staffID := req.StaffID
+		shift.DitutupOlehID = &staffID
+	}
+	if catatan := strings.TrimSpace(req.Catatan); catatan != "" {
+		if shift.Catatan != "" {
+			shift.Catatan += "\n"
+		}
+		shift.Catatan += catatan
+	}
+
+	closed, err := s.repo.Close(shift)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menutup shift: %w", err)
+	}
+	if !closed {
+		return nil, fmt.Errorf("shift sudah ditutup")
+	}
+	shift.Status = models.StatusShiftTutup
+
+	laporan, err := s.buildLaporan(shift)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[SHIFT] Shift %d ditutup di terminal %s - seharusnya: %d, dihitung: %d, selisih: %d",
+		shift.ID, shift.KodeTerminal, shift.KasSeharusnya, kasDihitung, shift.Selisih)
+	return laporan, nil
+}
+
+// buildLaporan aggregates a shift into an X report (open shift) or Z report (closed shift)
+func (s *ShiftService) buildLaporan(shift *models.ShiftKasir) (*models.LaporanShift, error) {
+	laporan, err := s.repo.GetLaporan(shift)
+	if err != nil {
+		return nil, err
+	}
+
+	mutasi, err := s.repo.GetMutasiByShift(shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	laporan.Shift = shift
+	laporan.MutasiKas = mutasi
+	laporan.DibuatAt = time.Now()
+
+	if shift.Status == models.StatusShiftTutup {
+		// Gunakan nilai yang tercatat saat shift ditutup
+		laporan.Jenis = "Z"
+		laporan.KasSeharusnya = shift.KasSeharusnya
+		laporan.KasDihitung = shift.KasDihitung
+		laporan.Selisih = shift.Selisih
+		return laporan, nil
+	}
+
+	laporan.Jenis = "X"
+	return laporan, nil
+}
+
+// requireShiftAktif returns the open shift of a terminal or an error when no shift is open
+func (s *ShiftService) requireShiftAktif(kodeTerminal string) (*models.ShiftKasir, error) {
+	kode, err := s.resolveKodeTerminal(kodeTerminal)
+	if err != nil {
+		return nil, err
+	}
+
+	shift, err := s.repo.GetActiveByTerminal(kode)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil {
+		return nil, fmt.Errorf("belum ada shift kasir yang dibuka di terminal %s, buka shift terlebih dahulu", kode)
+	}
+
+	return shift, nil
+}
+
+// resolveKodeTerminal normalizes a terminal code, falling back to the default
+// terminal of the transaction numbering settings
+func (s *ShiftService) resolveKodeTerminal(kodeTerminal string) (string, error) {
+	kode := strings.ToUpper(strings.TrimSpace(kodeTerminal))
+	if kode == "" {
+		settings, err := s.settingsRepo.GetNomorTransaksiSettings()
+		if err != nil {
+			return "", fmt.Errorf("gagal mengambil kode terminal default: %w", err)
+		}
+		kode = settings.KodeTerminal
+	}
+
+	if !isValidKodeNomor(kode) {
+		return "", fmt.Errorf("kode terminal harus 1-%d karakter huruf atau angka", maxPanjangKodeNomor)
+	}
+
+	return kode, nil
+}
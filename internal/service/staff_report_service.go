@@ -49,7 +49,6 @@ func (s *StaffReportService) GetStaffReport(staffID int, startDate, endDate time
 	totalPenjualan := 0
 	totalProfit := 0
 	totalItemTerjual := 0
-	productHPPCache := make(map[int]int) // Cache for product HPP
 
 	for _, t := range transaksiList {
 		totalPenjualan += t.Total
@@ -61,26 +60,17 @@ func (s *StaffReportService) GetStaffReport(staffID int, startDate, endDate time
 			for _, item := range detail.Items {
 				totalItemTerjual += item.Jumlah
 
-				// Calculate HPP for profit
+				// Calculate HPP for profit from the cost frozen when the item was sold
 				if item.ProdukID != nil {
-					hpp, ok := productHPPCache[*item.ProdukID]
-					if !ok {
-						produk, err := s.produkRepo.GetByID(*item.ProdukID)
-						if err == nil && produk != nil {
-							hpp = produk.HargaBeli
-							productHPPCache[*item.ProdukID] = hpp
-						} else {
-							hpp = 0
-						}
-					}
+					hpp := item.HargaPokok
 
 					// Calculate HPP based on product type (curah vs satuan)
 					if item.BeratGram > 0 {
 						// Barang curah: HPP = (berat_gram / 1000) * harga_beli_per_kg
 						transactionHPP += int((item.BeratGram / 1000.0) * float64(hpp))
 					} else {
-						// Barang satuan tetap: HPP = jumlah * harga_beli
-						transactionHPP += item.Jumlah * hpp
+						// Barang satuan tetap: HPP = jumlah * konversi satuan * harga_beli
+						transactionHPP += int(float64(item.Jumlah) * item.Konversi * float64(hpp))
 					}
 				}
 			}
@@ -485,6 +475,51 @@ func (s *StaffReportService) GetStaffShiftData(staffID int, startDate, endDate t
 	return s.transaksiRepo.GetShiftDataByStaffIDAndDateRange(staffID, startDate, endDate)
 }
 
+// GetLaporanOverrideHarga groups the manual price overrides and line discounts per staff
+func (s *StaffReportService) GetLaporanOverrideHarga(startDate, endDate time.Time) ([]*models.LaporanOverrideHargaStaff, error) {
+	items, err := s.transaksiRepo.GetOverrideHargaByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price overrides: %w", err)
+	}
+
+	laporan := make([]*models.LaporanOverrideHargaStaff, 0)
+	perStaff := make(map[string]*models.LaporanOverrideHargaStaff)
+	for _, item := range items {
+		// Selisih terhadap harga jual asli (berat: harga per 1000 gram)
+		hargaAsliTotal := item.HargaAsli * item.Jumlah
+		if item.BeratGram > 0 {
+			hargaAsliTotal = int((item.BeratGram / 1000.0) * float64(item.HargaAsli))
+		}
+		item.Selisih = hargaAsliTotal - item.Subtotal
+
+		key := fmt.Sprintf("%d|%s", item.StaffID, item.StaffNama)
+		staff, ok := perStaff[key]
+		if !ok {
+			staff = &models.LaporanOverrideHargaStaff{
+				StaffID:   item.StaffID,
+				StaffNama: item.StaffNama,
+				Items:     []*models.OverrideHargaItem{},
+			}
+			perStaff[key] = staff
+			laporan = append(laporan, staff)
+		}
+
+		if item.HargaOverride != nil {
+			staff.JumlahOverride++
+		}
+		if item.DiskonItem > 0 {
+			staff.JumlahDiskonBaris++
+		}
+		if item.ApproverID != nil {
+			staff.JumlahDisetujui++
+		}
+		staff.TotalSelisih += item.Selisih
+		staff.Items = append(staff.Items, item)
+	}
+
+	return laporan, nil
+}
+
 // GetMonthlyComparisonTrend gets 30-day comparison with previous 30 days for all metrics
 func (s *StaffReportService) GetMonthlyComparisonTrend() (map[string]interface{}, error) {
 	now := time.Now()
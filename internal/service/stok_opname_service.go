@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"strings"
+)
+
+// StokOpnameService handles physical inventory counts and posting their variances to stock
+type StokOpnameService struct {
+	repo   *repository.StokOpnameRepository
+	produk *ProdukService
+}
+
+// NewStokOpnameService creates a new instance
+func NewStokOpnameService() *StokOpnameService {
+	return &StokOpnameService{
+		repo:   repository.NewStokOpnameRepository(),
+		produk: NewProdukService(),
+	}
+}
+
+// GetAllOpname retrieves stock opname sessions without their items, optionally filtered by status
+func (s *StokOpnameService) GetAllOpname(status string) ([]*models.StokOpname, error) {
+	return s.repo.GetAll(status)
+}
+
+// GetOpnameByID retrieves a stock opname session with its items and variances
+func (s *StokOpnameService) GetOpnameByID(id int) (*models.StokOpname, error) {
+	opname, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if opname == nil {
+		return nil, fmt.Errorf("stok opname tidak ditemukan")
+	}
+	return opname, nil
+}
+
+// MulaiOpname starts a stock opname for all products or one category and
+// freezes their current stock as the system quantity
+func (s *StokOpnameService) MulaiOpname(req *models.MulaiOpnameRequest) (*models.StokOpname, error) {
+	opname := &models.StokOpname{
+		Kategori:   strings.TrimSpace(req.Kategori),
+		Catatan:    strings.TrimSpace(req.Catatan),
+		DibuatOleh: strings.TrimSpace(req.DibuatOleh),
+	}
+	if err := s.repo.Mulai(opname); err != nil {
+		return nil, err
+	}
+	return s.GetOpnameByID(opname.ID)
+}
+
+// HitungOpname records a counted quantity. By product ID the quantity replaces
+// the count, so a recount corrects it; by barcode the scanned quantity is added,
+// converted to the base unit for unit barcodes and read from scale labels.
+func (s *StokOpnameService) HitungOpname(req *models.HitungOpnameRequest) (*models.StokOpnameItem, error) {
+	if req.Qty < 0 {
+		return nil, fmt.Errorf("jumlah hitung tidak boleh negatif")
+	}
+
+	produkID, qty, tambah := req.ProdukID, req.Qty, false
+	if barcode := strings.TrimSpace(req.Barcode); barcode != "" {
+		hasil, err := s.produk.CariBarcode(barcode)
+		if err != nil {
+			return nil, err
+		}
+		if hasil == nil {
+			return nil, fmt.Errorf("produk dengan barcode %s tidak ditemukan", barcode)
+		}
+
+		produkID, tambah = hasil.Produk.ID, true
+		switch {
+		case hasil.Label != nil && qty == 0:
+			qty = hasil.Label.BeratGram / 1000
+		case qty == 0:
+			qty = 1
+		}
+		if hasil.Satuan != nil {
+			qty *= hasil.Satuan.Konversi
+		}
+	} else if produkID <= 0 {
+		return nil, fmt.Errorf("produk atau barcode harus diisi")
+	}
+
+	if err := s.repo.CatatHitung(req.OpnameID, produkID, qty, tambah); err != nil {
+		return nil, err
+	}
+	return s.repo.GetItem(req.OpnameID, produkID)
+}
+
+// SetujuiOpname approves a running stock opname and adjusts stock by the
+// variance of every counted product; products not counted are left unchanged.
+// Shortages are written off from batches in the order of the batch policy,
+// surpluses of products with a shelf life get a batch.
+func (s *StokOpnameService) SetujuiOpname(req *models.SetujuiOpnameRequest) (*models.StokOpname, error) {
+	opname, err := s.GetOpnameByID(req.OpnameID)
+	if err != nil {
+		return nil, err
+	}
+	if opname.Status != models.StatusOpnameBerjalan {
+		return nil, fmt.Errorf("stok opname %s berstatus %s, hanya stok opname yang sedang berjalan yang dapat disetujui", opname.Nomor, opname.Status)
+	}
+	if opname.JumlahDihitung == 0 {
+		return nil, fmt.Errorf("belum ada produk yang dihitung pada stok opname %s", opname.Nomor)
+	}
+
+	if err := s.repo.Setujui(opname, strings.TrimSpace(req.DisetujuiOleh)); err != nil {
+		return nil, err
+	}
+
+	return s.GetOpnameByID(opname.ID)
+}
+
+// BatalkanOpname cancels a running stock opname without changing any stock
+func (s *StokOpnameService) BatalkanOpname(id int) (*models.StokOpname, error) {
+	if _, err := s.GetOpnameByID(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Batalkan(id); err != nil {
+		return nil, err
+	}
+	return s.GetOpnameByID(id)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"strings"
+	"time"
+)
+
+// SupplierService handles supplier master data and the per-supplier stock report
+type SupplierService struct {
+	repo *repository.SupplierRepository
+}
+
+// NewSupplierService creates a new instance
+func NewSupplierService() *SupplierService {
+	return &SupplierService{
+		repo: repository.NewSupplierRepository(),
+	}
+}
+
+// GetAllSupplier retrieves all suppliers, including inactive ones but not merged duplicates
+func (s *SupplierService) GetAllSupplier() ([]*models.Supplier, error) {
+	return s.repo.GetAll()
+}
+
+// GetSupplierByID retrieves a supplier by ID
+func (s *SupplierService) GetSupplierByID(id int) (*models.Supplier, error) {
+	supplier, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, fmt.Errorf("supplier tidak ditemukan")
+	}
+	return supplier, nil
+}
+
+// CreateSupplier adds a supplier; names are unique regardless of case
+func (s *SupplierService) CreateSupplier(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByNama(supplier.Nama)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return supplierSudahAda(existing)
+	}
+
+	return s.repo.Create(supplier)
+}
+
+// UpdateSupplier updates a supplier; the new name is also shown on its batches
+func (s *SupplierService) UpdateSupplier(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByNama(supplier.Nama)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != supplier.ID {
+		return supplierSudahAda(existing)
+	}
+
+	return s.repo.Update(supplier)
+}
+
+// DeleteSupplier removes a supplier without batches; suppliers with batches can only be deactivated
+func (s *SupplierService) DeleteSupplier(id int) error {
+	return s.repo.Delete(id)
+}
+
+// ResolveSupplier finds the supplier of a restock. A supplier ID takes precedence;
+// a free-text name is matched regardless of case and creates a new supplier when
+// unknown, so the same supplier is not recorded under several spellings.
+// A merged duplicate resolves to the supplier it was merged into.
+// Returns nil when neither is given.
+func (s *SupplierService) ResolveSupplier(id int, nama string) (*models.Supplier, error) {
+	if id > 0 {
+		supplier, err := s.GetSupplierByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if supplier, err = s.ikutiGabung(supplier); err != nil {
+			return nil, err
+		}
+		if !supplier.Aktif {
+			return nil, fmt.Errorf("supplier %s tidak aktif", supplier.Nama)
+		}
+		return supplier, nil
+	}
+
+	nama = strings.Join(strings.Fields(nama), " ")
+	if nama == "" {
+		return nil, nil
+	}
+
+	supplier, err := s.repo.GetByNama(nama)
+	if err != nil {
+		return nil, err
+	}
+	if supplier != nil {
+		if supplier, err = s.ikutiGabung(supplier); err != nil {
+			return nil, err
+		}
+		if !supplier.Aktif {
+			return nil, fmt.Errorf("supplier %s tidak aktif", supplier.Nama)
+		}
+		return supplier, nil
+	}
+
+	supplier = &models.Supplier{Nama: nama, Aktif: true}
+	if err := s.repo.Create(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// GabungSupplier merges a duplicate supplier, e.g. one recorded under a different
+// spelling, into the supplier that is kept. Batches, products and purchase orders
+// of the duplicate move to the kept supplier and the duplicate is soft-deleted;
+// its name keeps resolving to the kept supplier.
+func (s *SupplierService) GabungSupplier(req *models.GabungSupplierRequest) (*models.Supplier, error) {
+	if req.DuplikatID <= 0 || req.TujuanID <= 0 {
+		return nil, fmt.Errorf("supplier yang digabung dan supplier tujuan harus dipilih")
+	}
+	if req.DuplikatID == req.TujuanID {
+		return nil, fmt.Errorf("supplier tidak dapat digabung dengan dirinya sendiri")
+	}
+
+	duplikat, err := s.GetSupplierByID(req.DuplikatID)
+	if err != nil {
+		return nil, err
+	}
+	if duplikat.DeletedAt != nil {
+		return nil, fmt.Errorf("supplier %s sudah digabung", duplikat.Nama)
+	}
+
+	tujuan, err := s.GetSupplierByID(req.TujuanID)
+	if err != nil {
+		return nil, err
+	}
+	if tujuan.DeletedAt != nil {
+		return nil, fmt.Errorf("supplier tujuan %s sudah digabung ke supplier lain", tujuan.Nama)
+	}
+
+	if err := s.repo.Gabung(duplikat.ID, tujuan); err != nil {
+		return nil, err
+	}
+
+	return tujuan, nil
+}
+
+// ikutiGabung returns the supplier a merged duplicate was merged into, or the supplier itself
+func (s *SupplierService) ikutiGabung(supplier *models.Supplier) (*models.Supplier, error) {
+	if supplier.DigabungKeID == nil {
+		return supplier, nil
+	}
+	return s.GetSupplierByID(*supplier.DigabungKeID)
+}
+
+// supplierSudahAda reports a name already taken, by an active supplier or a merged duplicate
+func supplierSudahAda(existing *models.Supplier) error {
+	if existing.DigabungKeID != nil {
+		return fmt.Errorf("supplier %s sudah digabung ke supplier lain", existing.Nama)
+	}
+	return fmt.Errorf("supplier %s sudah ada", existing.Nama)
+}
+
+// GetLaporanSupplier summarizes per supplier the stock received, expired and written off in a period
+func (s *SupplierService) GetLaporanSupplier(startDate, endDate time.Time) ([]*models.LaporanSupplier, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("tanggal akhir tidak boleh sebelum tanggal awal")
+	}
+	return s.repo.GetLaporan(startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+}
+
+// validateSupplier normalizes and validates supplier fields
+func validateSupplier(supplier *models.Supplier) error {
+	supplier.Nama = strings.Join(strings.Fields(supplier.Nama), " ")
+	supplier.Kontak = strings.TrimSpace(supplier.Kontak)
+	supplier.Alamat = strings.TrimSpace(supplier.Alamat)
+	supplier.NPWP = strings.TrimSpace(supplier.NPWP)
+
+	if supplier.Nama == "" {
+		return fmt.Errorf("nama supplier harus diisi")
+	}
+	if supplier.NPWP != "" {
+		digit := 0
+		for _, ch := range supplier.NPWP {
+			switch {
+			case ch >= '0' && ch <= '9':
+				digit++
+			case ch == '.' || ch == '-':
+			default:
+				return fmt.Errorf("NPWP hanya boleh berisi angka, titik dan strip")
+			}
+		}
+		// NPWP lama 15 digit, NPWP format NIK 16 digit
+		if digit != 15 && digit != 16 {
+			return fmt.Errorf("NPWP harus 15 atau 16 digit")
+		}
+	}
+	if supplier.TerminHari < 0 {
+		return fmt.Errorf("termin pembayaran tidak boleh negatif")
+	}
+
+	return nil
+}
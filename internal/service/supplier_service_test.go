@@ -0,0 +1,117 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"ritel-app/internal/database"
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSupplierDB opens an in-memory database with two spellings of one supplier
+// (1 is kept, 2 is the duplicate) and the rows that reference them
+func openSupplierDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	lama := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = lama
+		db.Close()
+	})
+
+	for _, q := range []string{
+		`CREATE TABLE supplier (
+			id INTEGER PRIMARY KEY, nama TEXT UNIQUE NOT NULL, kontak TEXT DEFAULT '', alamat TEXT DEFAULT '',
+			npwp TEXT DEFAULT '', termin_hari INTEGER DEFAULT 0, aktif INTEGER DEFAULT 1,
+			digabung_ke_id INTEGER, deleted_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE batch (id TEXT PRIMARY KEY, produk_id INTEGER, supplier TEXT, supplier_id INTEGER)`,
+		`CREATE TABLE produk (id INTEGER PRIMARY KEY, nama TEXT, supplier_id INTEGER)`,
+		`CREATE TABLE purchase_order (id INTEGER PRIMARY KEY, nomor TEXT, supplier_id INTEGER)`,
+		`INSERT INTO supplier (id, nama) VALUES (1, 'CV Sumber Makmur'), (2, 'CV. Sumber Makmur'), (3, 'UD Lain')`,
+		// Supplier 4 sudah digabung ke duplikat sebelumnya
+		`INSERT INTO supplier (id, nama, aktif, digabung_ke_id, deleted_at) VALUES (4, 'Sumber Makmur', 0, 2, '2026-10-01 08:00:00')`,
+		`INSERT INTO batch (id, produk_id, supplier, supplier_id) VALUES
+			('B1', 1, 'CV Sumber Makmur', 1), ('B2', 1, 'CV. Sumber Makmur', 2), ('B3', 2, 'UD Lain', 3)`,
+		`INSERT INTO produk (id, nama, supplier_id) VALUES (1, 'Kopi', 2), (2, 'Gula', 3)`,
+		`INSERT INTO purchase_order (id, nomor, supplier_id) VALUES (1, 'PO-20261018-0001', 2)`,
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func TestGabungSupplier(t *testing.T) {
+	db := openSupplierDB(t)
+	s := NewSupplierService()
+
+	tujuan, err := s.GabungSupplier(&models.GabungSupplierRequest{DuplikatID: 2, TujuanID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, tujuan.ID)
+
+	// Referensi pindah ke supplier tujuan
+	var batchDuplikat, produkDuplikat, poDuplikat int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM batch WHERE supplier_id = 2`).Scan(&batchDuplikat))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM produk WHERE supplier_id = 2`).Scan(&produkDuplikat))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM purchase_order WHERE supplier_id = 2`).Scan(&poDuplikat))
+	assert.Zero(t, batchDuplikat)
+	assert.Zero(t, produkDuplikat)
+	assert.Zero(t, poDuplikat)
+
+	var namaBatch string
+	require.NoError(t, db.QueryRow(`SELECT supplier FROM batch WHERE id = 'B2'`).Scan(&namaBatch))
+	assert.Equal(t, "CV Sumber Makmur", namaBatch)
+
+	var supplierLain int
+	require.NoError(t, db.QueryRow(`SELECT supplier_id FROM batch WHERE id = 'B3'`).Scan(&supplierLain))
+	assert.Equal(t, 3, supplierLain, "supplier lain tidak tersentuh")
+
+	// Duplikat dihapus lunak dan tidak tampil lagi
+	duplikat, err := s.GetSupplierByID(2)
+	require.NoError(t, err)
+	assert.NotNil(t, duplikat.DeletedAt)
+	assert.False(t, duplikat.Aktif)
+	require.NotNil(t, duplikat.DigabungKeID)
+	assert.Equal(t, 1, *duplikat.DigabungKeID)
+
+	list, err := s.GetAllSupplier()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	for _, supplier := range list {
+		assert.NotEqual(t, 2, supplier.ID)
+		assert.NotEqual(t, 4, supplier.ID)
+	}
+
+	// Nama dan ID duplikat, termasuk yang digabung sebelumnya, mengarah ke supplier tujuan
+	for _, tt := range []struct {
+		id   int
+		nama string
+	}{
+		{2, ""},
+		{0, "cv. sumber makmur"},
+		{0, "Sumber Makmur"},
+	} {
+		supplier, err := s.ResolveSupplier(tt.id, tt.nama)
+		require.NoError(t, err)
+		assert.Equal(t, 1, supplier.ID, "resolve %d %q", tt.id, tt.nama)
+	}
+
+	assert.ErrorContains(t, s.CreateSupplier(&models.Supplier{Nama: "CV. Sumber Makmur", Aktif: true}), "sudah digabung")
+
+	// Duplikat tidak dapat digabung lagi atau menjadi tujuan
+	_, err = s.GabungSupplier(&models.GabungSupplierRequest{DuplikatID: 2, TujuanID: 3})
+	assert.ErrorContains(t, err, "sudah digabung")
+	_, err = s.GabungSupplier(&models.GabungSupplierRequest{DuplikatID: 3, TujuanID: 2})
+	assert.ErrorContains(t, err, "sudah digabung")
+	_, err = s.GabungSupplier(&models.GabungSupplierRequest{DuplikatID: 3, TujuanID: 3})
+	assert.Error(t, err)
+}
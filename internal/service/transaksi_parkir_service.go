@@ -0,0 +1,224 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"ritel-app/internal/config"
+	"ritel-app/internal/models"
+	"ritel-app/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TransaksiParkirService handles held (parked) sales that cashiers can resume later
+type TransaksiParkirService struct {
+	repo         *repository.TransaksiParkirRepository
+	satuanRepo   *repository.ProdukSatuanRepository
+	komponenRepo *repository.ProdukKomponenRepository
+	expiry       time.Duration
+}
+
+// NewTransaksiParkirService creates a new instance
+func NewTransaksiParkirService() *TransaksiParkirService {
+	return &TransaksiParkirService{
+		repo:         repository.NewTransaksiParkirRepository(),
+		satuanRepo:   repository.NewProdukSatuanRepository(),
+		komponenRepo: repository.NewProdukKomponenRepository(),
+		expiry:       config.GetParkirConfig().Expiry,
+	}
+}
+
+// ParkirTransaksi stores a transaction draft so it can be resumed later,
+// optionally soft-reserving the stock of its items
+func (s *TransaksiParkirService) ParkirTransaksi(req *models.ParkirTransaksiRequest) (*models.TransaksiParkir, error) {
+	if len(req.Draft.Items) == 0 {
+		return nil, fmt.Errorf("transaksi yang diparkir harus memiliki minimal 1 item")
+	}
+
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		label = fmt.Sprintf("Parkir %s", time.Now().Format("15:04"))
+	}
+	if len(label) > 100 {
+		return nil, fmt.Errorf("label maksimal 100 karakter")
+	}
+
+	kodeTerminal := strings.ToUpper(strings.TrimSpace(req.Draft.KodeTerminal))
+	if kodeTerminal != "" && !isValidKodeNomor(kodeTerminal) {
+		return nil, fmt.Errorf("kode terminal harus 1-%d karakter huruf atau angka", maxPanjangKodeNomor)
+	}
+	req.Draft.KodeTerminal = kodeTerminal
+	// Password admin tidak pernah disimpan di draft, persetujuan diminta lagi saat checkout
+	req.Draft.ApproverPassword = ""
+
+	var reservasi []models.TransaksiParkirItem
+	if req.ReservasiStok {
+		var err error
+		if reservasi, err = s.reservasiFromItems(req.Draft.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	parkir := &models.TransaksiParkir{
+		Label:         label,
+		StaffNama:     req.Draft.StaffNama,
+		KodeTerminal:  kodeTerminal,
+		Draft:         req.Draft,
+		JumlahItem:    len(req.Draft.Items),
+		ReservasiStok: req.ReservasiStok,
+		ExpiresAt:     time.Now().Add(s.expiry),
+	}
+	if req.Draft.StaffID > 0 {
+		staffID := req.Draft.StaffID
+		parkir.StaffID = &staffID
+	}
+
+	s.expireOld()
+
+	if err := s.repo.Create(parkir, reservasi); err != nil {
+		return nil, fmt.Errorf("gagal memarkir transaksi: %w", err)
+	}
+
+	log.Printf("[PARKIR] Transaksi %q diparkir (id=%d, item=%d, reservasi stok=%t)", label, parkir.ID, parkir.JumlahItem, parkir.ReservasiStok)
+	return parkir, nil
+}
+
+// GetTransaksiParkir lists active parked sales, optionally per staff and terminal
+func (s *TransaksiParkirService) GetTransaksiParkir(filter models.TransaksiParkirFilter) ([]*models.TransaksiParkir, error) {
+	s.expireOld()
+
+	filter.KodeTerminal = strings.ToUpper(strings.TrimSpace(filter.KodeTerminal))
+	list, err := s.repo.GetActive(filter)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []*models.TransaksiParkir{}
+	}
+	return list, nil
+}
+
+// LanjutkanTransaksiParkir resumes a parked sale and returns its draft. The stock
+// reservation is released so the draft can be checked out with CreateTransaksi.
+func (s *TransaksiParkirService) LanjutkanTransaksiParkir(id int) (*models.TransaksiParkir, error) {
+	parkir, err := s.getActive(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.UpdateStatus(id, "dilanjutkan")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("transaksi parkir sudah dilanjutkan atau dibatalkan")
+	}
+
+	now := time.Now()
+	parkir.Status = "dilanjutkan"
+	parkir.ResumedAt = &now
+	return parkir, nil
+}
+
+// BatalkanTransaksiParkir discards a parked sale and releases its reserved stock
+func (s *TransaksiParkirService) BatalkanTransaksiParkir(id int) error {
+	if _, err := s.getActive(id); err != nil {
+		return err
+	}
+
+	ok, err := s.repo.UpdateStatus(id, "dibatalkan")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("transaksi parkir sudah dilanjutkan atau dibatalkan")
+	}
+	return nil
+}
+
+// getActive retrieves a parked sale that can still be resumed
+func (s *TransaksiParkirService) getActive(id int) (*models.TransaksiParkir, error) {
+	s.expireOld()
+
+	parkir, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if parkir == nil {
+		return nil, fmt.Errorf("transaksi parkir tidak ditemukan")
+	}
+
+	switch parkir.Status {
+	case "parkir":
+		return parkir, nil
+	case "kadaluarsa":
+		return nil, fmt.Errorf("transaksi parkir sudah kadaluarsa")
+	default:
+		return nil, fmt.Errorf("transaksi parkir sudah %s", parkir.Status)
+	}
+}
+
+// expireOld expires parked sales past their expiry time
+func (s *TransaksiParkirService) expireOld() {
+	expired, err := s.repo.ExpireOld()
+	if err != nil {
+		log.Printf("[PARKIR] Warning: failed to expire parked sales: %v", err)
+		return
+	}
+	if expired > 0 {
+		log.Printf("[PARKIR] %d transaksi parkir kadaluarsa", expired)
+	}
+}
+
+// reservasiFromItems sums the stock needed per product in its base unit (kg for
+// weighed items). A kit reserves its components, since it holds no stock itself.
+func (s *TransaksiParkirService) reservasiFromItems(items []models.TransaksiItemRequest) ([]models.TransaksiParkirItem, error) {
+	qtyPerProduk := make(map[int]float64)
+	for i, item := range items {
+		if item.ProdukID <= 0 {
+			return nil, fmt.Errorf("item %d: produk ID tidak valid", i+1)
+		}
+		if item.BeratGram > 0 {
+			qtyPerProduk[item.ProdukID] += item.BeratGram / 1000.0
+			continue
+		}
+
+		konversi := 1.0
+		if item.SatuanID > 0 {
+			satuan, err := s.satuanRepo.GetByID(item.SatuanID)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			if satuan == nil || satuan.ProdukID != item.ProdukID {
+				return nil, fmt.Errorf("item %d: satuan tidak ditemukan", i+1)
+			}
+			konversi = satuan.Konversi
+		}
+		qty := float64(item.Jumlah) * konversi
+
+		komponen, err := s.komponenRepo.GetByPaket(item.ProdukID)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if len(komponen) == 0 {
+			qtyPerProduk[item.ProdukID] += qty
+			continue
+		}
+		for _, k := range komponen {
+			qtyPerProduk[k.KomponenID] += qty * k.Qty
+		}
+	}
+
+	reservasi := make([]models.TransaksiParkirItem, 0, len(qtyPerProduk))
+	for produkID, qty := range qtyPerProduk {
+		if qty <= 0 {
+			continue
+		}
+		reservasi = append(reservasi, models.TransaksiParkirItem{ProdukID: produkID, Qty: qty})
+	}
+
+	// Sort by product so reservations are stored in a deterministic order
+	sort.Slice(reservasi, func(i, j int) bool { return reservasi[i].ProdukID < reservasi[j].ProdukID })
+	return reservasi, nil
+}
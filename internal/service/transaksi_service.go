@@ -1,18 +1,37 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"strings"
 	"time"
 
 	"ritel-app/internal/models"
 	"ritel-app/internal/repository"
 )
 
+// promoDiscountTolerance is the maximum difference (in rupiah) allowed between the
+// promo discount sent by the client and the one recalculated by the server
+const promoDiscountTolerance = 100
+
 type TransaksiService struct {
 	repo             *repository.TransaksiRepository
 	pelangganService *PelangganService
 	promoService     *PromoService
 	settingsService  *SettingsService
+	userService      *UserService
+	idempotency      *IdempotencyService
+	pajakService     *PajakService
+	shiftService     *ShiftService
+	metodeService    *MetodePembayaranService
+	produkRepo       *repository.ProdukRepository
+	satuanRepo       *repository.ProdukSatuanRepository
+	komponenRepo     *repository.ProdukKomponenRepository
+	piutangService   *PiutangService
+	markdownService  *MarkdownService
 }
 
 func NewTransaksiService() *TransaksiService {
@@ -21,10 +40,93 @@ func NewTransaksiService() *TransaksiService {
 		pelangganService: NewPelangganService(),
 		promoService:     NewPromoService(),
 		settingsService:  NewSettingsService(),
+		userService:      NewUserService(),
+		idempotency:      NewIdempotencyService(),
+		pajakService:     NewPajakService(),
+		shiftService:     NewShiftService(),
+		metodeService:    NewMetodePembayaranService(),
+		produkRepo:       repository.NewProdukRepository(),
+		satuanRepo:       repository.NewProdukSatuanRepository(),
+		komponenRepo:     repository.NewProdukKomponenRepository(),
+		piutangService:   NewPiutangService(),
+		markdownService:  NewMarkdownService(),
 	}
 }
 
+// CreateTransaksi creates a transaction. When the request carries an idempotency
+// key, the key is stored in the same database transaction as the sale, and a retry
+// with the same key and payload returns the original response instead of
+// recording the sale twice.
 func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (*models.TransaksiResponse, error) {
+	key := strings.TrimSpace(req.IdempotencyKey)
+	if key == "" {
+		return s.createTransaksi(req)
+	}
+
+	payload := *req
+	payload.IdempotencyKey = ""
+	payload.ApproverPassword = ""
+	requestHash, record, err := s.idempotency.Lookup(IdempotencyScopeTransaksi, key, &payload)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		return s.replayTransaksi(record)
+	}
+
+	req.IdempotencyKey = key
+	req.IdempotencyHash = requestHash
+	result, err := s.createTransaksi(req)
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		// A concurrent retry with the same key committed its sale first; the hash
+		// is the one of the original payload, createTransaksi has changed req since
+		record, err := s.idempotency.Stored(IdempotencyScopeTransaksi, key, requestHash)
+		if err != nil {
+			return nil, err
+		}
+		return s.replayTransaksi(record)
+	}
+	if err != nil || result == nil || !result.Success {
+		return result, err
+	}
+
+	// The key is already committed with the sale; storing the full response only
+	// makes the replay identical. Without it the replay is rebuilt from the sale.
+	if err := s.idempotency.Complete(IdempotencyScopeTransaksi, key, result); err != nil {
+		log.Printf("[TRANSACTION SERVICE] Response for idempotency key %s not stored, replay will be rebuilt from transaction %d: %v",
+			key, result.Transaksi.Transaksi.ID, err)
+	}
+
+	return result, nil
+}
+
+// replayTransaksi returns the original response of a sale stored under an
+// idempotency key
+func (s *TransaksiService) replayTransaksi(record *models.IdempotencyRecord) (*models.TransaksiResponse, error) {
+	if record.Response != "" {
+		var original models.TransaksiResponse
+		if err := json.Unmarshal([]byte(record.Response), &original); err != nil {
+			return nil, fmt.Errorf("failed to decode stored transaction response: %w", err)
+		}
+		return &original, nil
+	}
+
+	transaksiDetail, err := s.repo.GetByID(record.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if transaksiDetail == nil {
+		return nil, fmt.Errorf("transaksi %d dari idempotency key tidak ditemukan", record.ResourceID)
+	}
+
+	return &models.TransaksiResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Transaksi %s berhasil dibuat", transaksiDetail.Transaksi.NomorTransaksi),
+		Transaksi: transaksiDetail,
+	}, nil
+}
+
+func (s *TransaksiService) createTransaksi(req *models.CreateTransaksiRequest) (*models.TransaksiResponse, error) {
 	fmt.Printf("[TRANSACTION SERVICE] Starting transaction creation\n")
 
 	// 1. VALIDASI DASAR
@@ -35,6 +137,31 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 		}, nil
 	}
 
+	// 1a. PENJUALAN HANYA BISA DILAKUKAN SAAT SHIFT KASIR TERBUKA
+	shift, err := s.shiftService.requireShiftAktif(req.KodeTerminal)
+	if err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	// 1b. DISKON BARIS & PERUBAHAN HARGA MANUAL (persetujuan admin jika di luar batas)
+	if err := s.applyLinePricing(req); err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	// 1c. MARKDOWN MENJELANG KADALUARSA (dihitung dari batch yang akan terpakai)
+	if err := s.markdownService.HitungMarkdown(req.Items); err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
 	// 2. HITUNG SUBTOTAL (support berat or quantity)
 	subtotal := 0
 	for _, item := range req.Items {
@@ -46,10 +173,40 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 			// Perhitungan biasa untuk backward compatibility
 			itemSubtotal = item.HargaSatuan * item.Jumlah
 		}
-		subtotal += itemSubtotal
+		subtotal += itemSubtotal - item.DiskonItem - item.DiskonMarkdown
 	}
 	fmt.Printf("[TRANSACTION SERVICE] Subtotal: %d\n", subtotal)
 
+	// 2a. HITUNG ULANG DISKON PROMO DI SERVER (jangan percaya diskon dari client)
+	diskonPromo := 0
+	promoID := 0
+	if req.PromoKode != "" {
+		totalQuantity := 0
+		for _, item := range req.Items {
+			totalQuantity += item.Jumlah
+		}
+
+		_, promoDiskon, err := s.promoService.CalculateTotalDiscount(subtotal, totalQuantity, req.PromoKode, req.PelangganID, req.Items)
+		if err != nil {
+			return &models.TransaksiResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+
+		promo, err := s.promoService.GetPromoByKode(req.PromoKode)
+		if err != nil || promo == nil {
+			return &models.TransaksiResponse{
+				Success: false,
+				Message: "Kode promo tidak valid",
+			}, nil
+		}
+
+		diskonPromo = promoDiskon
+		promoID = promo.ID
+		fmt.Printf("[TRANSACTION SERVICE] Promo %s (ID: %d) recalculated discount: %d\n", req.PromoKode, promoID, diskonPromo)
+	}
+
 	// 3. PROSES PELANGGAN & POIN (JIKA ADA)
 	var pelanggan *models.Pelanggan
 	poinDipakai := 0
@@ -86,7 +243,7 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 
 			// VALIDASI DAN PENYESUAIAN POIN OTOMATIS
 			poinDipakai, diskonPoin = s.CalculatePointsDiscount(
-				subtotal,
+				subtotal-diskonPromo,
 				req.PoinDitukar,
 				pelanggan.Poin,
 				settings.PointValue,
@@ -118,14 +275,38 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 	}
 
 	// 4. HITUNG TOTAL DISKON (PROMO + POIN)
-	// Diskon hanya dari promo dan poin, tidak ada diskon berdasarkan level pelanggan
-	totalDiskon := req.Diskon
+	// Diskon hanya dari promo dan poin, tidak ada diskon berdasarkan level pelanggan.
+	// Diskon promo dari client hanya dibandingkan, nilai yang disimpan adalah hasil hitung server.
+	if err := checkClientPromoDiscount(req, diskonPromo, diskonPoin); err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	totalDiskon := diskonPromo + diskonPoin
 	diskonPelanggan := 0 // Tidak ada diskon level, hanya dari poin
 
 	fmt.Printf("[TRANSACTION SERVICE] Total discount: %d (promo + points)\n", totalDiskon)
 
+	// 4a. HITUNG PPN (SETELAH DISKON)
+	pajak, err := s.pajakService.HitungPajak(req.Items, totalDiskon)
+	if err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+	if pajak.ModeHarga != "" {
+		fmt.Printf("[TRANSACTION SERVICE] Tax (%s) - DPP: %d, PPN: %d\n", pajak.ModeHarga, pajak.TotalDPP, pajak.TotalPajak)
+	}
+
 	// 5. HITUNG TOTAL AKHIR & VALIDASI
 	totalAkhir := subtotal - totalDiskon
+	if pajak.ModeHarga == models.ModeHargaEksklusif {
+		// Harga eksklusif: PPN ditambahkan di atas harga setelah diskon
+		totalAkhir += pajak.TotalPajak
+	}
 
 	// Validasi total tidak negatif (seharusnya sudah di-handle oleh CalculatePointsDiscount)
 	if totalAkhir < 0 {
@@ -135,6 +316,20 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 		}, nil
 	}
 
+	// 5a. PEMBULATAN TOTAL (setelah diskon, poin dan PPN)
+	pembulatanSettings, err := s.settingsService.GetPembulatanSettings()
+	if err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+	pembulatan := 0
+	if !pembulatanSettings.HanyaTunai || semuaTunai(req.Pembayaran) {
+		pembulatan = hitungPembulatan(totalAkhir, pembulatanSettings)
+	}
+	totalAkhir += pembulatan
+
 	// Validasi pembayaran
 	totalPembayaran := 0
 	for _, payment := range req.Pembayaran {
@@ -149,8 +344,24 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 		}, nil
 	}
 
-	fmt.Printf("[TRANSACTION SERVICE] Final calculation - Subtotal: %d, Discount: %d, Total: %d, Payment: %d, Change: %d\n",
-		subtotal, totalDiskon, totalAkhir, totalPembayaran, kembalian)
+	// Validasi metode pembayaran (split tender): metode aktif, referensi, kembalian
+	if err := s.metodeService.ValidatePembayaran(req.Pembayaran, totalAkhir); err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	// Kasbon hanya untuk pelanggan terdaftar dan tidak boleh melebihi batas kredit
+	if err := s.piutangService.ValidateKasbon(req.PelangganID, req.Pembayaran); err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	fmt.Printf("[TRANSACTION SERVICE] Final calculation - Subtotal: %d, Discount: %d, Rounding: %d, Total: %d, Payment: %d, Change: %d\n",
+		subtotal, totalDiskon, pembulatan, totalAkhir, totalPembayaran, kembalian)
 
 	// 6. CREATE TRANSACTION DI DATABASE
 	repoRequest := &models.CreateTransaksiRequest{
@@ -159,19 +370,33 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 		PelangganTelp:   req.PelangganTelp,
 		Items:           req.Items,
 		Pembayaran:      req.Pembayaran,
+		PromoKode:       req.PromoKode,
+		PromoID:         promoID,
 		PoinDitukar:     poinDipakai, // Gunakan poin yang sudah disesuaikan
 		Diskon:          totalDiskon,
-		DiskonPromo:     req.Diskon,       // Diskon promo dari frontend
-		DiskonPelanggan: diskonPelanggan,  // Diskon level pelanggan dari backend
+		DiskonPromo:     diskonPromo,     // Diskon promo hasil hitung ulang server
+		DiskonPelanggan: diskonPelanggan, // Diskon level pelanggan dari backend
+		DiskonPoin:      diskonPoin,
 		Catatan:         req.Catatan,
 		Kasir:           req.Kasir,
 		StaffID:         req.StaffID,
 		StaffNama:       req.StaffNama,
+		KodeTerminal:    req.KodeTerminal,
+		IdempotencyKey:  req.IdempotencyKey,
+		IdempotencyHash: req.IdempotencyHash,
+		ShiftID:         shift.ID,
+		ModePajak:       pajak.ModeHarga,
+		TotalDPP:        pajak.TotalDPP,
+		TotalPajak:      pajak.TotalPajak,
+		Pembulatan:      pembulatan,
 	}
 
 	fmt.Printf("[TRANSACTION SERVICE] Creating transaction with StaffID: %d, StaffNama: %s\n", req.StaffID, req.StaffNama)
 
 	transaksiDetail, err := s.repo.Create(repoRequest)
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		return nil, err
+	}
 	if err != nil {
 		return &models.TransaksiResponse{
 			Success: false,
@@ -237,6 +462,121 @@ func (s *TransaksiService) CreateTransaksi(req *models.CreateTransaksiRequest) (
 	}, nil
 }
 
+// VoidTransaksi cancels a completed transaction after admin approval.
+// Stock and batch quantities are restored and the customer's points are reversed.
+// The void is linked to the terminal's open shift, which pays back any cash;
+// voiding a sale paid in cash therefore needs an open shift.
+func (s *TransaksiService) VoidTransaksi(req *models.VoidTransaksiRequest) (*models.TransaksiResponse, error) {
+	fmt.Printf("[TRANSACTION SERVICE] Void requested for transaction ID: %d\n", req.TransaksiID)
+
+	// 1. VALIDASI
+	if req.TransaksiID <= 0 {
+		return &models.TransaksiResponse{Success: false, Message: "ID transaksi tidak valid"}, nil
+	}
+	if strings.TrimSpace(req.Alasan) == "" {
+		return &models.TransaksiResponse{Success: false, Message: "Alasan pembatalan harus diisi"}, nil
+	}
+
+	// 2. PERSETUJUAN ADMIN
+	approver, err := s.userService.VerifyApprover(req.ApproverUsername, req.ApproverPassword)
+	if err != nil {
+		return &models.TransaksiResponse{Success: false, Message: err.Error()}, nil
+	}
+
+	transaksi, err := s.repo.GetByID(req.TransaksiID)
+	if err != nil || transaksi == nil {
+		return &models.TransaksiResponse{Success: false, Message: "Transaksi tidak ditemukan"}, nil
+	}
+
+	// Void dicatat pada shift yang aktif; uang tunai dikembalikan dari laci shift tersebut
+	shift, err := s.shiftService.GetShiftAktif(req.KodeTerminal)
+	if err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: fmt.Sprintf("Gagal mengambil shift aktif: %v", err),
+		}, nil
+	}
+	voidShiftID := 0
+	if shift != nil {
+		voidShiftID = shift.ID
+	} else {
+		for _, p := range transaksi.Pembayaran {
+			if strings.EqualFold(p.Metode, models.MetodeTunai) {
+				return &models.TransaksiResponse{
+					Success: false,
+					Message: "Void transaksi tunai memerlukan shift kasir yang sedang buka",
+				}, nil
+			}
+		}
+	}
+
+	settings, err := s.settingsService.GetPoinSettings()
+	if err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: fmt.Sprintf("Gagal mengambil pengaturan poin: %v", err),
+		}, nil
+	}
+
+	// 3. VOID DI DATABASE (stok, batch dan poin pelanggan dikembalikan dalam satu transaksi DB)
+	err = s.repo.Void(req.TransaksiID, strings.TrimSpace(req.Alasan), approver, settings.MinTransactionForPoints, voidShiftID)
+	if err != nil {
+		return &models.TransaksiResponse{
+			Success: false,
+			Message: fmt.Sprintf("Gagal membatalkan transaksi: %v", err),
+		}, nil
+	}
+
+	fmt.Printf("[TRANSACTION SERVICE] Transaction %s voided by %s: %s\n",
+		transaksi.Transaksi.NomorTransaksi, approver.Username, req.Alasan)
+
+	message := fmt.Sprintf("Transaksi %s berhasil dibatalkan", transaksi.Transaksi.NomorTransaksi)
+	voided, err := s.repo.GetByID(req.TransaksiID)
+	if err != nil || voided == nil {
+		// The void is committed; report it with the transaction as it was before
+		fmt.Printf("[WARNING] Failed to reload voided transaction %d: %v\n", req.TransaksiID, err)
+		transaksi.Transaksi.Status = "void"
+		transaksi.Transaksi.VoidAlasan = strings.TrimSpace(req.Alasan)
+		transaksi.Transaksi.VoidOlehNama = approver.NamaLengkap
+		voidAt := time.Now()
+		transaksi.Transaksi.VoidAt = &voidAt
+		voided = transaksi
+	}
+
+	return &models.TransaksiResponse{
+		Success:   true,
+		Message:   message,
+		Transaksi: voided,
+	}, nil
+}
+
+// checkClientPromoDiscount rejects requests whose promo discount differs from the
+// server-side calculation by more than promoDiscountTolerance.
+// The frontend sends the combined discount (promo + poin) in Diskon.
+func checkClientPromoDiscount(req *models.CreateTransaksiRequest, diskonPromo, diskonPoin int) error {
+	clientPromo := req.DiskonPromo
+	if clientPromo == 0 {
+		clientPromo = req.Diskon - diskonPoin
+	}
+	if clientPromo < 0 {
+		clientPromo = 0
+	}
+
+	if req.PromoKode == "" && clientPromo == 0 {
+		return nil
+	}
+
+	selisih := clientPromo - diskonPromo
+	if selisih < 0 {
+		selisih = -selisih
+	}
+	if selisih > promoDiscountTolerance {
+		return fmt.Errorf("diskon promo tidak sesuai (dikirim: Rp %d, seharusnya: Rp %d)", clientPromo, diskonPromo)
+	}
+
+	return nil
+}
+
 func (s *TransaksiService) CalculatePointsDiscount(subtotal int, poinDitukar int, saldoPoin int, pointValue int) (int, int) {
 	// ATURAN 1: Tidak boleh lebih dari saldo poin
 	poinMaksimumBerdasarSaldo := poinDitukar
@@ -257,6 +597,171 @@ func (s *TransaksiService) CalculatePointsDiscount(subtotal int, poinDitukar int
 	return poinMaksimumBerdasarSaldo, diskonPoin
 }
 
+// hitungPembulatan returns the rounding adjustment for a total under the given
+// policy. A positive value means the total is rounded up.
+func hitungPembulatan(total int, settings *models.PembulatanSettings) int {
+	if !settings.Aktif || settings.Kelipatan <= 1 || total <= 0 {
+		return 0
+	}
+
+	sisa := total % settings.Kelipatan
+	if sisa == 0 {
+		return 0
+	}
+
+	switch settings.Mode {
+	case models.ModePembulatanBawah:
+		return -sisa
+	case models.ModePembulatanAtas:
+		return settings.Kelipatan - sisa
+	default:
+		// Terdekat: setengah kelipatan atau lebih dibulatkan ke atas
+		if sisa*2 >= settings.Kelipatan {
+			return settings.Kelipatan - sisa
+		}
+		return -sisa
+	}
+}
+
+// semuaTunai reports whether every payment of a sale is cash
+func semuaTunai(pembayaran []models.PembayaranRequest) bool {
+	for _, p := range pembayaran {
+		if !strings.EqualFold(strings.TrimSpace(p.Metode), models.MetodeTunai) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyLinePricing checks manual price changes and line discounts against the
+// product's selling price. The cashier must give a reason for every change, and
+// an admin must approve it when the effective price falls below the purchase
+// price or moves more than the configured limit away from the selling price.
+func (s *TransaksiService) applyLinePricing(req *models.CreateTransaksiRequest) error {
+	var settings *models.HargaSettings
+	var approver *models.User
+
+	for i := range req.Items {
+		item := &req.Items[i]
+		item.DiskonTipe = strings.ToLower(strings.TrimSpace(item.DiskonTipe))
+		item.AlasanOverride = strings.TrimSpace(item.AlasanOverride)
+		item.DiskonItem = 0
+		item.ApproverID = 0
+		item.ApproverNama = ""
+
+		produk, err := s.produkRepo.GetByID(item.ProdukID)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if produk == nil {
+			return fmt.Errorf("item %d: produk tidak ditemukan", i+1)
+		}
+
+		// Harga pokok paket adalah jumlah harga pokok komponennya
+		komponen, err := s.komponenRepo.GetByPaket(produk.ID)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if len(komponen) > 0 {
+			if item.BeratGram > 0 {
+				return fmt.Errorf("item %d: paket %s tidak dapat dijual per berat", i+1, produk.Nama)
+			}
+			produk.HargaBeli = hargaPokokPaket(komponen)
+		}
+
+		// Harga jual & harga beli dibandingkan per satuan yang dijual
+		hargaJual, hargaBeli := produk.HargaJual, produk.HargaBeli
+		item.Satuan = produk.Satuan
+		item.Konversi = 1
+		if item.SatuanID > 0 {
+			if item.BeratGram > 0 {
+				return fmt.Errorf("item %d: %s dijual per berat, bukan per satuan", i+1, produk.Nama)
+			}
+			satuan, err := s.satuanRepo.GetByID(item.SatuanID)
+			if err != nil {
+				return fmt.Errorf("item %d: %w", i+1, err)
+			}
+			if satuan == nil || satuan.ProdukID != produk.ID {
+				return fmt.Errorf("item %d: satuan tidak ditemukan untuk produk %s", i+1, produk.Nama)
+			}
+			item.Satuan = satuan.Nama
+			item.Konversi = satuan.Konversi
+			hargaJual = hargaJualSatuan(produk, satuan)
+			hargaBeli = int(math.Round(float64(produk.HargaBeli) * satuan.Konversi))
+		}
+		item.HargaAsli = hargaJual
+
+		var kotor int
+		if item.BeratGram > 0 {
+			kotor = int((item.BeratGram / 1000.0) * float64(item.HargaSatuan))
+		} else {
+			kotor = item.HargaSatuan * item.Jumlah
+		}
+
+		switch item.DiskonTipe {
+		case "":
+			if item.DiskonNilai != 0 {
+				return fmt.Errorf("item %d: tipe diskon harus %q atau %q", i+1, models.DiskonTipePersen, models.DiskonTipeNominal)
+			}
+		case models.DiskonTipePersen:
+			if item.DiskonNilai <= 0 || item.DiskonNilai > 100 {
+				return fmt.Errorf("item %d: diskon persen harus antara 0 dan 100", i+1)
+			}
+			item.DiskonItem = int(math.Round(float64(kotor) * item.DiskonNilai / 100))
+		case models.DiskonTipeNominal:
+			if item.DiskonNilai <= 0 || item.DiskonNilai > float64(kotor) || item.DiskonNilai != math.Trunc(item.DiskonNilai) {
+				return fmt.Errorf("item %d: diskon nominal harus bilangan bulat antara 1 dan %d", i+1, kotor)
+			}
+			item.DiskonItem = int(item.DiskonNilai)
+		default:
+			return fmt.Errorf("item %d: tipe diskon harus %q atau %q", i+1, models.DiskonTipePersen, models.DiskonTipeNominal)
+		}
+
+		override := item.HargaSatuan != hargaJual
+		if !override && item.DiskonItem == 0 {
+			continue
+		}
+		if override && item.AlasanOverride == "" {
+			return fmt.Errorf("item %d: alasan perubahan harga %s harus diisi", i+1, produk.Nama)
+		}
+
+		// Harga efektif per satuan setelah diskon baris
+		hargaEfektif := float64(item.HargaSatuan)
+		if kotor > 0 {
+			hargaEfektif = float64(item.HargaSatuan) * float64(kotor-item.DiskonItem) / float64(kotor)
+		}
+
+		if settings == nil {
+			settings, err = s.settingsService.GetHargaSettings()
+			if err != nil {
+				return err
+			}
+		}
+
+		perluPersetujuan := hargaBeli > 0 && hargaEfektif < float64(hargaBeli)
+		if hargaJual > 0 && math.Abs(hargaEfektif-float64(hargaJual))/float64(hargaJual)*100 > settings.BatasOverridePersen {
+			perluPersetujuan = true
+		}
+		if !perluPersetujuan {
+			continue
+		}
+
+		if item.AlasanOverride == "" {
+			return fmt.Errorf("item %d: alasan diskon %s harus diisi", i+1, produk.Nama)
+		}
+		if approver == nil {
+			approver, err = s.userService.VerifyApprover(req.ApproverUsername, req.ApproverPassword)
+			if err != nil {
+				return fmt.Errorf("item %d: harga %s di luar batas, %w", i+1, produk.Nama, err)
+			}
+		}
+		item.ApproverID = approver.ID
+		item.ApproverNama = approver.NamaLengkap
+	}
+
+	return nil
+}
+
 // validateCreateRequest validates the create transaction request
 func (s *TransaksiService) validateCreateRequest(req *models.CreateTransaksiRequest) error {
 	// Validasi items
@@ -276,6 +781,12 @@ func (s *TransaksiService) validateCreateRequest(req *models.CreateTransaksiRequ
 		}
 	}
 
+	// Validasi kode terminal (opsional, default dari pengaturan nomor transaksi)
+	req.KodeTerminal = strings.ToUpper(strings.TrimSpace(req.KodeTerminal))
+	if req.KodeTerminal != "" && !isValidKodeNomor(req.KodeTerminal) {
+		return fmt.Errorf("kode terminal harus 1-%d karakter huruf atau angka", maxPanjangKodeNomor)
+	}
+
 	// Validasi payments
 	if len(req.Pembayaran) == 0 {
 		return fmt.Errorf("transaksi harus memiliki minimal 1 metode pembayaran")
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"ritel-app/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHitungPembulatan(t *testing.T) {
+	terdekat := &models.PembulatanSettings{Aktif: true, Mode: models.ModePembulatanTerdekat, Kelipatan: 100}
+	bawah := &models.PembulatanSettings{Aktif: true, Mode: models.ModePembulatanBawah, Kelipatan: 100}
+	atas := &models.PembulatanSettings{Aktif: true, Mode: models.ModePembulatanAtas, Kelipatan: 500}
+
+	tests := []struct {
+		name     string
+		total    int
+		settings *models.PembulatanSettings
+		want     int
+	}{
+		{"terdekat ke bawah", 12340, terdekat, -40},
+		{"terdekat tepat setengah ke atas", 12350, terdekat, 50},
+		{"terdekat ke atas", 12390, terdekat, 10},
+		{"bawah", 12390, bawah, -90},
+		{"atas", 12010, atas, 490},
+		{"sudah kelipatan", 12500, atas, 0},
+		{"tidak aktif", 12345, &models.PembulatanSettings{Aktif: false, Mode: models.ModePembulatanAtas, Kelipatan: 100}, 0},
+		{"kelipatan 1 tidak membulatkan", 12345, &models.PembulatanSettings{Aktif: true, Mode: models.ModePembulatanAtas, Kelipatan: 1}, 0},
+		{"total nol", 0, terdekat, 0},
+		{"total negatif", -150, terdekat, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hitungPembulatan(tt.total, tt.settings)
+			assert.Equal(t, tt.want, got)
+			if got != 0 {
+				assert.Zero(t, (tt.total+got)%tt.settings.Kelipatan, "total setelah pembulatan harus kelipatan")
+			}
+		})
+	}
+}
+
+func TestSemuaTunai(t *testing.T) {
+	tests := []struct {
+		name       string
+		pembayaran []models.PembayaranRequest
+		want       bool
+	}{
+		{"tunai saja", []models.PembayaranRequest{{Metode: "tunai"}, {Metode: " TUNAI "}}, true},
+		{"campur non tunai", []models.PembayaranRequest{{Metode: "tunai"}, {Metode: "qris"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, semuaTunai(tt.pembayaran))
+		})
+	}
+}
@@ -340,6 +340,32 @@ func (s *UserService) ChangePassword(req *models.ChangePasswordRequest) error {
 	return nil
 }
 
+// VerifyApprover checks admin credentials entered to approve a restricted action
+// (e.g. voiding a transaction) and returns the approving admin
+func (s *UserService) VerifyApprover(username, password string) (*models.User, error) {
+	if strings.TrimSpace(username) == "" || strings.TrimSpace(password) == "" {
+		return nil, fmt.Errorf("persetujuan admin diperlukan")
+	}
+
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil || s.userRepo.VerifyPassword(user.Password, password) != nil {
+		return nil, fmt.Errorf("username atau password admin salah")
+	}
+
+	if user.Status != "active" || user.Role != "admin" {
+		return nil, fmt.Errorf("user %s tidak berhak memberikan persetujuan", user.Username)
+	}
+
+	// Don't send password
+	user.Password = ""
+
+	return user, nil
+}
+
 // EnsureDefaultAdmin creates default admin if no admin exists
 func (s *UserService) EnsureDefaultAdmin() error {
 	adminCount, err := s.userRepo.CountAdmins()